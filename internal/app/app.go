@@ -3,19 +3,22 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/chriscorrea/slop/internal/cache"
 	"github.com/chriscorrea/slop/internal/config"
 	slopContext "github.com/chriscorrea/slop/internal/context"
 	"github.com/chriscorrea/slop/internal/format"
 	slopIO "github.com/chriscorrea/slop/internal/io"
 	"github.com/chriscorrea/slop/internal/llm/common"
-	"github.com/chriscorrea/slop/internal/registry"
 	"github.com/chriscorrea/slop/internal/template"
+	"github.com/chriscorrea/slop/internal/tools"
 	"github.com/chriscorrea/slop/internal/verbose"
 
 	"github.com/fatih/color"
@@ -74,20 +77,126 @@ func cleanFormattedResponse(response string, cfg config.Format) string {
 	return format.CleanResponse(response, cfg)
 }
 
+// maxToolRounds bounds how many times the model may request tools before
+// it must produce a final answer
+const maxToolRounds = 10
+
+// Result is the outcome of Run
+type Result struct {
+	// Output is the response after thinking filters and format cleaning
+	Output string
+
+	// ExitCode is the code chosen by the active exit mode (0 when none)
+	ExitCode int
+
+	// Generation is the provider's final result: token usage (summed across
+	// tool rounds), finish reason, resolved model and native thinking
+	Generation *common.GenerateResult
+
+	// Latency is the wall time spent generating, including any tool rounds
+	Latency time.Duration
+
+	// Provider and Model name the configured model that answered; they differ
+	// from the requested ones when a fallback was used
+	Provider string
+	Model    string
+
+	// Fallbacks lists the models that were unavailable before one answered
+	Fallbacks []FailedAttempt
+}
+
 // App represents the main application and holds its dependencies
 type App struct {
-	cfg     *config.Config
-	logger  *slog.Logger
-	verbose bool
+	cfg         *config.Config
+	logger      *slog.Logger
+	verbose     bool
+	out         io.Writer
+	toolConfirm tools.ConfirmFunc
+	cacheDir    string
+	fallbacks   []config.ModelRef
+	modelPull   PullFunc
+	retrieve    RetrieveFunc
+	quiet       bool
+
+	contextWindow int
+	mapReduce     bool
 }
 
+// PullFunc offers to pull a model the provider reported missing and reports
+// whether it was pulled, so the request can be retried
+type PullFunc func(ctx context.Context, providerName, modelName string) (bool, error)
+
 // NewApp creates a new App instance with the provided configuration, logger, and verbose setting
 func NewApp(cfg *config.Config, logger *slog.Logger, verbose bool) *App {
 	return &App{
 		cfg:     cfg,
 		logger:  logger,
 		verbose: verbose,
+		out:     os.Stdout,
+	}
+}
+
+// WithOutput sets the writer that streamed responses are written to
+func (a *App) WithOutput(w io.Writer) *App {
+	a.out = w
+	return a
+}
+
+// WithQuiet turns off the spinner, for unattended and concurrent runs
+func (a *App) WithQuiet(quiet bool) *App {
+	a.quiet = quiet
+	return a
+}
+
+// WithToolConfirm replaces the interactive prompt shown before running a tool
+func (a *App) WithToolConfirm(fn tools.ConfirmFunc) *App {
+	a.toolConfirm = fn
+	return a
+}
+
+// WithModelPull sets the hook offered a model an Ollama server has not
+// pulled; without one the not-found error is returned as is
+func (a *App) WithModelPull(fn PullFunc) *App {
+	a.modelPull = fn
+	return a
+}
+
+// WithCacheDir overrides where the response cache is kept (~/.slop/cache)
+func (a *App) WithCacheDir(dir string) *App {
+	a.cacheDir = dir
+	return a
+}
+
+// withCache wraps the provider client with the response cache. the cache is
+// an optimization, so a missing cache location only disables it
+func (a *App) withCache(llm common.LLM, providerName string) common.LLM {
+	dir := a.cacheDir
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			if a.logger != nil {
+				a.logger.Warn("Response cache disabled", "error", err)
+			}
+			return llm
+		}
+	}
+
+	responseCache := cache.New(dir, a.cfg.Parameters.CacheTTLDuration(), int64(a.cfg.Parameters.CacheMaxMB)*1024*1024)
+	return cache.NewClient(llm, responseCache, providerName, a.logger)
+}
+
+// StreamsOutput reports whether Run writes the response to the output writer
+// itself as tokens arrive. format flags and schemas need the complete response
+// for cleaning, so they fall back to buffered output
+func (a *App) StreamsOutput() bool {
+	if a.cfg == nil || !a.cfg.Parameters.Stream {
+		return false
+	}
+	f := a.cfg.Format
+	if f.JSON || f.JSONL || f.YAML || f.MD || f.XML {
+		return false
 	}
+	return strings.TrimSpace(a.cfg.Parameters.ResponseSchema) == ""
 }
 
 // getSpinnerChars returns spinner characters
@@ -158,9 +267,9 @@ func getSpinner(providerName, modelName string) (glyphs []string, speed int) {
 }
 
 // Run executes the main application logic
-func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopContext.ContextResult, commandContext, providerName, modelName, messageTemplate, exitMode string, hideThinking, showThinking bool) (string, int, error) {
+func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopContext.ContextResult, commandContext, providerName, modelName, messageTemplate, exitMode string, hideThinking, showThinking bool) (*Result, error) {
 	if a.cfg == nil {
-		return "", 0, fmt.Errorf("configuration is nil")
+		return nil, fmt.Errorf("configuration is nil")
 	}
 
 	// read input using structured processing for synthetic message history
@@ -169,20 +278,49 @@ func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopCont
 		contextFiles = contextResult.ContextFileContents
 	}
 
-	// calculate project context count for spinner display
-	projectContextCount := len(contextFiles)
-
 	structuredInput, err := slopIO.ReadInput(os.Stdin, cliArgs, contextFiles, commandContext)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to read structured input: %w", err)
+		return nil, fmt.Errorf("failed to read structured input: %w", err)
 	}
 
-	// create a provider using the registry
-	provider, err := registry.CreateProvider(providerName, a.cfg, a.logger)
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to create provider: %w", err)
+	return a.RunInput(ctx, structuredInput, contextResult, providerName, modelName, messageTemplate, exitMode, hideThinking, showThinking)
+}
+
+// RunInput is Run for input that has already been read, such as a batch record
+func (a *App) RunInput(ctx context.Context, structuredInput *slopIO.StructuredInput, contextResult *slopContext.ContextResult, providerName, modelName, messageTemplate, exitMode string, hideThinking, showThinking bool) (*Result, error) {
+	if a.cfg == nil {
+		return nil, fmt.Errorf("configuration is nil")
 	}
 
+	var err error
+
+	// send the project context passages relevant to the prompt
+	if a.retrieve != nil {
+		contextResult, err = a.retrieveContext(ctx, structuredInput, contextResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// input too large for one request is answered in parts
+	if a.mapReduce {
+		return a.runMapReduce(ctx, structuredInput, contextResult, providerName, modelName, messageTemplate, exitMode, hideThinking, showThinking)
+	}
+
+	return a.respond(ctx, structuredInput, contextResult, providerName, modelName, messageTemplate, exitMode, hideThinking, showThinking)
+}
+
+// respond sends the input to the model and writes the response, applying
+// thinking filters, format cleaning and the exit mode
+func (a *App) respond(ctx context.Context, structuredInput *slopIO.StructuredInput, contextResult *slopContext.ContextResult, providerName, modelName, messageTemplate, exitMode string, hideThinking, showThinking bool) (*Result, error) {
+	var contextFiles []slopContext.ContextFile
+	if contextResult != nil {
+		contextFiles = contextResult.ContextFileContents
+	}
+
+	// calculate project context count for spinner display
+	projectContextCount := len(contextFiles)
+
 	// create messages using either synthetic message history or traditional approach
 	var messages []common.Message
 
@@ -199,12 +337,19 @@ func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopCont
 		})
 	}
 
-	// build synthetic message history from structured input and context result
-	messages = append(messages, buildSyntheticMessageHistory(structuredInput, contextResult, messageTemplate)...)
+	// build synthetic message history from structured input and context
+	// result, fitting the context to the model's context window
+	history, contextResult, budget, err := a.fitContextWindow(messages, contextResult, func(cr *slopContext.ContextResult) []common.Message {
+		return buildSyntheticMessageHistory(structuredInput, cr, messageTemplate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages = append(messages, history...)
 
 	// if no messages created, return an error
 	if len(messages) == 0 {
-		return "", 0, fmt.Errorf("no input provided")
+		return nil, fmt.Errorf("no input provided")
 	}
 
 	// display verbose output if enabled
@@ -224,9 +369,21 @@ func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopCont
 				case "file":
 					fmt.Fprintf(os.Stderr, "  %s (text file, %d chars)\n",
 						filepath.Base(item.Path), len(item.Content))
+				case "image":
+					fmt.Fprintf(os.Stderr, "  %s (image, %d bytes)\n",
+						filepath.Base(item.Path), len(item.Image.Data))
+				case "chunk":
+					fmt.Fprintf(os.Stderr, "  %s:%d-%d (retrieved chunk, score %.2f)\n",
+						filepath.Base(item.Path), item.Chunk.StartLine, item.Chunk.EndLine, item.Chunk.Score)
 				}
 			}
 		}
+
+		if budget != nil {
+			printContextBudget(os.Stderr, budget)
+		}
+	} else if budget != nil && len(budget.actions) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: input exceeded the context window; applied %q to fit (use --verbose for details)\n", budget.strategy)
 	}
 
 	// log request parameters when debug is enabled
@@ -263,76 +420,129 @@ func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopCont
 		}
 	}
 
-	// build generation options from configuration using the registry
-	opts := registry.BuildProviderOptions(providerName, a.cfg)
+	streaming := a.StreamsOutput()
 
-	// force color output for spinner, even in chained commands
-	// (where TTY detection might cause color to be disabled)
-	color.NoColor = false
-
-	// spinner
+	// spinner; quiet runs have none
 	done := make(chan bool, 1) // buffered channel to prevent goroutine leaks
-	go func() {
-		defer func() {
-			// always clear this line when the goroutine exits
-			fmt.Fprintf(os.Stderr, "\r%s\r", "                                                                                ")
-		}()
-
-		// get spinner properties (informed by provider and model)
-		spinGlyphs, spinSpeed := getSpinner(providerName, modelName)
-
-		i := 0
-		cyan := color.New(color.FgCyan).SprintFunc()
-
-		for {
-			select {
-			case <-done:
-				return
-			case <-ctx.Done(): // handle context cancellation
-				return
-			case <-time.After(time.Duration(spinSpeed) * time.Millisecond):
-				baseMessage := fmt.Sprintf("%s %s", spinGlyphs[i], modelName) // always display model name and glyph
-				switch projectContextCount {
-				case 0:
-					baseMessage += " is generating..." // default
-				case 1:
-					if len(contextFiles) > 0 {
-						fileName := filepath.Base(contextFiles[0].Path)
-						baseMessage += fmt.Sprintf(" is generating (using %s)", fileName)
-					} else {
-						baseMessage += " is generating using 1 project context file..."
+	cleared := make(chan struct{})
+	if a.quiet {
+		close(cleared)
+	} else {
+		// force color output for spinner, even in chained commands
+		// (where TTY detection might cause color to be disabled)
+		color.NoColor = false
+
+		go func() {
+			defer func() {
+				// always clear this line when the goroutine exits
+				fmt.Fprintf(os.Stderr, "\r%s\r", "                                                                                ")
+				close(cleared)
+			}()
+
+			// get spinner properties (informed by provider and model)
+			spinGlyphs, spinSpeed := getSpinner(providerName, modelName)
+
+			i := 0
+			cyan := color.New(color.FgCyan).SprintFunc()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done(): // handle context cancellation
+					return
+				case <-time.After(time.Duration(spinSpeed) * time.Millisecond):
+					baseMessage := fmt.Sprintf("%s %s", spinGlyphs[i], modelName) // always display model name and glyph
+					switch projectContextCount {
+					case 0:
+						baseMessage += " is generating..." // default
+					case 1:
+						if len(contextFiles) > 0 {
+							fileName := filepath.Base(contextFiles[0].Path)
+							baseMessage += fmt.Sprintf(" is generating (using %s)", fileName)
+						} else {
+							baseMessage += " is generating using 1 project context file..."
+						}
+					default:
+						baseMessage += fmt.Sprintf(" is generating (using %d project context files)", projectContextCount)
 					}
-				default:
-					baseMessage += fmt.Sprintf(" is generating (using %d project context files)", projectContextCount)
+					// print the message
+					fmt.Fprintf(os.Stderr, "\r%s", cyan(baseMessage))
+					i = (i + 1) % len(spinGlyphs)
 				}
-				// print the message
-				fmt.Fprintf(os.Stderr, "\r%s", cyan(baseMessage))
-				i = (i + 1) % len(spinGlyphs)
 			}
+		}()
+	}
+
+	// stop the spinner exactly once: on the first streamed token or after generation
+	var stopOnce sync.Once
+	stopSpinner := func() {
+		stopOnce.Do(func() {
+			done <- true
+			// wait for the line to be cleared, so output starts on a clean line
+			<-cleared
+		})
+	}
+
+	// when streaming, write tokens as they arrive instead of behind the spinner
+	var streamFilter *format.StreamFilter
+	var streamHandler common.StreamHandler
+	streamed := false
+	if streaming {
+		streamFilter = format.NewStreamFilter(hideThinking, showThinking)
+		streamHandler = func(chunk common.StreamChunk) {
+			stopSpinner()
+			streamed = true
+			fmt.Fprint(a.out, streamFilter.Process(chunk.Thinking, chunk.Content))
 		}
-	}()
+	}
+
+	// tools the model may call; the runner is disabled when none are enabled
+	toolRunner := tools.NewRunner(a.cfg, a.logger)
+	if a.toolConfirm != nil {
+		toolRunner = toolRunner.WithConfirm(a.toolConfirm)
+	}
 
-	// generate response using the provider with the specified model
-	response, err := provider.Generate(ctx, messages, modelName, opts...)
+	// generate with the requested model, moving on to fallbacks while it's unavailable
+	start := time.Now()
+	generation, answered, failed, err := a.generateWithFallbacks(ctx, a.candidates(providerName, modelName), messages,
+		streamHandler, func() bool { return streamed }, toolRunner, stopSpinner)
+	latency := time.Since(start)
 
 	// stop the spinner
-	done <- true
-	// give a tiny moment for the goroutine to clean up
-	time.Sleep(10 * time.Millisecond)
+	stopSpinner()
+
+	if streamed {
+		fmt.Fprint(a.out, streamFilter.Flush())
+	}
 
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to generate response: %w", err)
+		// a cancelled run (Ctrl-C) keeps the text streamed so far, ending its
+		// line, and is reported as cancelled rather than as a provider failure
+		if ctx.Err() != nil {
+			if streamed {
+				fmt.Fprintln(a.out)
+			}
+			return nil, ctx.Err()
+		}
+		return nil, err
 	}
 
 	// apply thinking filter to raw response before format cleaning
-	thinkingFilteredResponse, err := a.applyThinkingFilter(response, hideThinking, showThinking)
+	thinkingFilteredResponse, err := a.applyThinkingFilter(generation.Content, hideThinking, showThinking)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to apply thinking filter: %w", err)
+		return nil, fmt.Errorf("failed to apply thinking filter: %w", err)
 	}
 
 	// clean the response based on format requirements
 	cleanedResponse := cleanFormattedResponse(thinkingFilteredResponse, a.cfg.Format)
 
+	// providers without streaming support return the whole response at once;
+	// write it here so streaming mode always owns the output
+	if streaming && !streamed {
+		fmt.Fprint(a.out, cleanedResponse)
+	}
+
 	// determine exit code based on exit mode
 	var exitCode int
 	switch {
@@ -346,7 +556,82 @@ func (a *App) Run(ctx context.Context, cliArgs []string, contextResult *slopCont
 		exitCode = 0 // no mode active
 	}
 
-	return cleanedResponse, exitCode, nil
+	return &Result{
+		Output:     cleanedResponse,
+		ExitCode:   exitCode,
+		Generation: generation,
+		Latency:    latency,
+		Provider:   answered.Provider,
+		Model:      answered.Name,
+		Fallbacks:  failed,
+	}, nil
+}
+
+// generateWithTools calls the model and, when tools are enabled, runs the tool
+// calls it requests and feeds the results back until it returns a final answer
+func (a *App) generateWithTools(ctx context.Context, llm common.LLM, messages []common.Message, modelName string, req *common.GenerateRequest, runner *tools.Runner, beforeTools func()) (*common.GenerateResult, error) {
+	if !runner.Enabled() {
+		return llm.Generate(ctx, messages, modelName, req)
+	}
+
+	var usage *common.Usage
+	for round := 0; ; round++ {
+		result, err := llm.Generate(ctx, messages, modelName, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// report the tokens spent across every round
+		usage = addUsage(usage, result.Usage)
+		result.Usage = usage
+
+		if len(result.ToolCalls) == 0 {
+			return result, nil
+		}
+		if round >= maxToolRounds {
+			return nil, fmt.Errorf("model requested tools %d times without a final answer", maxToolRounds)
+		}
+
+		// tool output and confirmation prompts share stderr with the spinner
+		beforeTools()
+
+		// replay the assistant turn without inline thinking
+		content, err := format.ApplyThinkingFilter(result.Content, true, false)
+		if err != nil {
+			content = result.Content
+		}
+		messages = append(messages, common.Message{
+			Role:      "assistant",
+			Content:   content,
+			ToolCalls: result.ToolCalls,
+		})
+
+		for _, call := range result.ToolCalls {
+			if a.verbose {
+				fmt.Fprintf(os.Stderr, "Tool call: %s %s\n", call.Function.Name, call.Function.Arguments)
+			}
+			messages = append(messages, common.Message{
+				Role:       "tool",
+				Content:    runner.Run(ctx, call),
+				ToolCallID: call.ID,
+				ToolName:   call.Function.Name,
+			})
+		}
+	}
+}
+
+// addUsage sums token usage; nil means the provider reported none
+func addUsage(total, usage *common.Usage) *common.Usage {
+	if usage == nil {
+		return total
+	}
+	sum := *usage
+	if total != nil {
+		sum.PromptTokens += total.PromptTokens
+		sum.CompletionTokens += total.CompletionTokens
+		sum.TotalTokens += total.TotalTokens
+	}
+	return &sum
 }
 
 // createFileMessage formats a file's content as a user message
@@ -372,6 +657,17 @@ func buildSyntheticMessageHistory(input *slopIO.StructuredInput, contextResult *
 			case "file":
 				// wrap as user message with file header (existing behavior)
 				messages = append(messages, createFileMessage(item.Path, item.Content))
+			case "image":
+				// name the image so the prompt can refer to it
+				messages = append(messages, common.Message{
+					Role:    "user",
+					Content: fmt.Sprintf("Image: %s", item.Path),
+					Images:  []common.Image{*item.Image},
+				})
+			case "chunk":
+				// an excerpt, so say which lines it covers
+				path := fmt.Sprintf("%s (lines %d-%d)", item.Path, item.Chunk.StartLine, item.Chunk.EndLine)
+				messages = append(messages, createFileMessage(path, item.Chunk.Content))
 			}
 		}
 	} else if input != nil {
@@ -400,15 +696,22 @@ func buildSyntheticMessageHistory(input *slopIO.StructuredInput, contextResult *
 	}
 
 	// 4: CLI arg (user prompt) becomes the final/most recent message
-	// apply message template processing if template is provided
+	// apply message template processing if template is provided.
+	// images from --image travel with the prompt, or alone when there is none
+	var images []common.Image
+	if contextResult != nil {
+		images = contextResult.Images
+	}
+	var content string
 	if input.CLIArgs != "" || messageTemplate != "" {
-		content := template.ProcessTemplate(messageTemplate, input.CLIArgs)
-		if content != "" {
-			messages = append(messages, common.Message{
-				Role:    "user",
-				Content: content,
-			})
-		}
+		content = template.ProcessTemplate(messageTemplate, input.CLIArgs)
+	}
+	if content != "" || len(images) > 0 {
+		messages = append(messages, common.Message{
+			Role:    "user",
+			Content: content,
+			Images:  images,
+		})
 	}
 
 	return messages
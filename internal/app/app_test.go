@@ -1,10 +1,15 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/chriscorrea/slop/internal/config"
 	slopContext "github.com/chriscorrea/slop/internal/context"
@@ -21,10 +26,16 @@ type MockLLM struct {
 	mock.Mock
 }
 
-// mocks the Generate method
-func (m *MockLLM) Generate(ctx context.Context, messages []common.Message, modelName string, options ...interface{}) (string, error) {
-	args := m.Called(ctx, messages, modelName, options)
-	return args.String(0), args.Error(1)
+// mocks the Generate method; a plain string return is wrapped as the content
+func (m *MockLLM) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	args := m.Called(ctx, messages, modelName, req)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	if result, ok := args.Get(0).(*common.GenerateResult); ok {
+		return result, nil
+	}
+	return &common.GenerateResult{Content: args.String(0), Model: modelName}, nil
 }
 
 // MockProvider implements registry.Provider for testing
@@ -36,8 +47,8 @@ func (m *MockProvider) CreateClient(cfg *config.Config, logger *slog.Logger) (co
 	return m.mockLLM, nil
 }
 
-func (m *MockProvider) BuildOptions(cfg *config.Config) []interface{} {
-	return []interface{}{}
+func (m *MockProvider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
+	return &common.GenerateRequest{}
 }
 
 func (m *MockProvider) RequiresAPIKey() bool {
@@ -48,7 +59,7 @@ func (m *MockProvider) ProviderName() string {
 	return "mock-provider"
 }
 
-func (m *MockProvider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
+func (m *MockProvider) BuildRequest(messages []common.Message, modelName string, options *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
 	return nil, nil
 }
 
@@ -131,11 +142,11 @@ func TestApp_SyntheticMessageHistory_BasicScenario(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "mocked response", result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "mocked response", result.Output)
 	mockLLM.AssertExpectations(t)
 }
 
@@ -202,11 +213,11 @@ func TestApp_SyntheticMessageHistory_ContextFiles(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"analyze these files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"analyze these files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "analysis response", result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "analysis response", result.Output)
 	mockLLM.AssertExpectations(t)
 }
 
@@ -271,11 +282,11 @@ func TestApp_SyntheticMessageHistory_ComplexScenario(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"find security vulnerabilities"}, contextResult, "You are reviewing windmill plans", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"find security vulnerabilities"}, contextResult, "You are reviewing windmill plans", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "security analysis", result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "security analysis", result.Output)
 	mockLLM.AssertExpectations(t)
 }
 
@@ -317,11 +328,11 @@ func TestApp_SyntheticMessageHistory_EmptyContextFiles(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"process files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"process files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "processed", result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "processed", result.Output)
 	mockLLM.AssertExpectations(t)
 }
 
@@ -386,11 +397,11 @@ func TestApp_SyntheticMessageHistory_ManyContextFiles(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"summarize all files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"summarize all files"}, contextResult, "", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "summary of all files", result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "summary of all files", result.Output)
 	mockLLM.AssertExpectations(t)
 }
 
@@ -481,11 +492,10 @@ func TestApp_Run_CreateProvider_Error(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "nonexistent", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "nonexistent", "test-model", "", "", false, false)
 
 	assert.Error(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to create provider")
 	assert.Contains(t, err.Error(), "unsupported provider 'nonexistent'")
 }
@@ -511,11 +521,10 @@ func TestApp_Run_Generate_Error(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
 
 	assert.Error(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to generate response")
 	assert.ErrorIs(t, err, expectedError)
 
@@ -532,11 +541,10 @@ func TestApp_Run_NoInput_Error(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{}, createEmptyContextResult(), "", "mock", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{}, createEmptyContextResult(), "", "mock", "test-model", "", "", false, false)
 
 	assert.Error(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "no input provided")
 }
 
@@ -544,11 +552,10 @@ func TestApp_Run_NilConfig_Error(t *testing.T) {
 	app := NewApp(nil, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
 
 	assert.Error(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "configuration is nil")
 }
 
@@ -583,14 +590,98 @@ func TestApp_Run_FormatEnhancement_JSON(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.NotEmpty(t, result.Output)
+	mockLLM.AssertExpectations(t)
+}
+
+func TestApp_Run_Streaming(t *testing.T) {
+	mockLLM := &MockLLM{}
+	mockLLM.On("Generate",
+		context.Background(),
+		mock.Anything,
+		"test-model",
+		mock.Anything).
+		Run(func(args mock.Arguments) {
+			// deliver chunks through the request's handler
+			handler := args.Get(3).(*common.GenerateRequest).OnChunk
+			handler(common.StreamChunk{Content: "<think>plan</think>"})
+			handler(common.StreamChunk{Content: "\nHello"})
+			handler(common.StreamChunk{Content: " world"})
+		}).
+		Return("<think>plan</think>\nHello world", nil)
+
+	mockProvider := &MockProvider{mockLLM: mockLLM}
+	defer setupMockRegistry(mockProvider)()
+
+	cfg := &config.Config{
+		Parameters: config.Parameters{
+			SystemPrompt: "You are a helpful assistant",
+			Stream:       true,
+		},
+	}
+
+	var out bytes.Buffer
+	app := NewApp(cfg, slog.Default(), false).WithOutput(&out)
+	assert.True(t, app.StreamsOutput())
+
+	result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.NotEmpty(t, result)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "Hello world", result.Output)
+	assert.Equal(t, "Hello world", out.String())
+	mockLLM.AssertExpectations(t)
+}
+
+func TestApp_Run_StreamingCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockLLM := &MockLLM{}
+	mockLLM.On("Generate",
+		mock.Anything,
+		mock.Anything,
+		"test-model",
+		mock.Anything).
+		Run(func(args mock.Arguments) {
+			// stream part of the answer, then cancel as Ctrl-C would
+			args.Get(3).(*common.GenerateRequest).OnChunk(common.StreamChunk{Content: "Hello"})
+			cancel()
+		}).
+		Return("", errors.New("failed to read stream: connection reset"))
+
+	mockProvider := &MockProvider{mockLLM: mockLLM}
+	defer setupMockRegistry(mockProvider)()
+
+	cfg := &config.Config{
+		Parameters: config.Parameters{Stream: true},
+	}
+
+	var out bytes.Buffer
+	app := NewApp(cfg, slog.Default(), false).WithOutput(&out)
+	result, err := app.Run(ctx, []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", false, false)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "Hello\n", out.String(), "partial output is kept and its line ended")
 	mockLLM.AssertExpectations(t)
 }
 
+func TestApp_StreamsOutput_DisabledForFormats(t *testing.T) {
+	cfg := &config.Config{
+		Parameters: config.Parameters{Stream: true},
+		Format:     config.Format{JSON: true},
+	}
+
+	app := NewApp(cfg, slog.Default(), false)
+
+	assert.False(t, app.StreamsOutput())
+}
+
 func TestGetSpinner(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -738,10 +829,520 @@ func TestApp_Run_WithMessageTemplate(t *testing.T) {
 	app := NewApp(cfg, slog.Default(), false)
 
 	ctx := context.Background()
-	result, exitCode, err := app.Run(ctx, []string{"function main() {}"}, createEmptyContextResult(), "", "test-provider", "test-model", "Please review: {input}", "", false, false)
+	result, err := app.Run(ctx, []string{"function main() {}"}, createEmptyContextResult(), "", "test-provider", "test-model", "Please review: {input}", "", false, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "Code review complete", result.Output)
+	mockLLM.AssertExpectations(t)
+}
+
+func TestBuildSyntheticMessageHistory_Images(t *testing.T) {
+	diagram := common.Image{Path: "/farm/plans.png", MediaType: "image/png", Data: []byte("plans")}
+	photo := common.Image{Path: "/farm/windmill.jpg", MediaType: "image/jpeg", Data: []byte("photo")}
+
+	contextResult := &slopContext.ContextResult{
+		ProcessedItems: []slopContext.ContextItem{
+			{Path: "/farm/plans.png", Type: "image", Image: &diagram},
+		},
+		Images: []common.Image{photo},
+	}
+	input := &slopIO.StructuredInput{CLIArgs: "does the windmill match the plans?"}
+
+	messages := buildSyntheticMessageHistory(input, contextResult, "")
+	assert.Len(t, messages, 2)
+
+	// context images are named so the prompt can refer to them
+	assert.Equal(t, "Image: /farm/plans.png", messages[0].Content)
+	assert.Equal(t, []common.Image{diagram}, messages[0].Images)
 
+	// --image images travel with the prompt
+	assert.Equal(t, "does the windmill match the plans?", messages[1].Content)
+	assert.Equal(t, []common.Image{photo}, messages[1].Images)
+
+	// without a prompt, images are still sent on their own
+	messages = buildSyntheticMessageHistory(&slopIO.StructuredInput{}, &slopContext.ContextResult{Images: []common.Image{photo}}, "")
+	assert.Len(t, messages, 1)
+	assert.Empty(t, messages[0].Content)
+	assert.Equal(t, []common.Image{photo}, messages[0].Images)
+}
+
+func TestRetrieveContext(t *testing.T) {
+	var query string
+	chunks := []slopContext.Chunk{{Path: "/farm/rules.md", StartLine: 12, EndLine: 20, Content: "All animals are equal", Score: 0.9}}
+	a := NewApp(&config.Config{}, nil, false).WithRetriever(func(ctx context.Context, q string) ([]slopContext.Chunk, error) {
+		query = q
+		return chunks, nil
+	})
+
+	notes := slopContext.ContextItem{Path: "notes.txt", Type: "file", Content: "notes"}
+	contextResult := &slopContext.ContextResult{ProcessedItems: []slopContext.ContextItem{notes}}
+	input := &slopIO.StructuredInput{CLIArgs: "what are the rules?", StdinContent: "  piped  "}
+
+	result, err := a.retrieveContext(context.Background(), input, contextResult)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, exitCode)
-	assert.Equal(t, "Code review complete", result)
+	assert.Equal(t, "what are the rules?\n\npiped", query)
+	if assert.Len(t, result.ProcessedItems, 2) {
+		assert.Equal(t, "chunk", result.ProcessedItems[0].Type)
+		assert.True(t, result.ProcessedItems[0].Project)
+		assert.Equal(t, notes, result.ProcessedItems[1])
+	}
+	assert.Len(t, contextResult.ProcessedItems, 1, "the caller's result is not modified")
+
+	// retrieved chunks are sent as file excerpts ahead of the prompt
+	messages := buildSyntheticMessageHistory(input, result, "")
+	assert.Equal(t, "File: /farm/rules.md (lines 12-20)\n\nAll animals are equal", messages[0].Content)
+
+	// nothing to search with leaves the context as it is
+	query = ""
+	result, err = a.retrieveContext(context.Background(), &slopIO.StructuredInput{}, contextResult)
+	assert.NoError(t, err)
+	assert.Same(t, contextResult, result)
+	assert.Empty(t, query)
+
+	failing := NewApp(&config.Config{}, nil, false).WithRetriever(func(ctx context.Context, q string) ([]slopContext.Chunk, error) {
+		return nil, errors.New("index offline")
+	})
+	_, err = failing.retrieveContext(context.Background(), input, nil)
+	assert.ErrorContains(t, err, "failed to retrieve project context: index offline")
+}
+
+func TestRetrievalQuery(t *testing.T) {
+	assert.Empty(t, retrievalQuery(nil))
+	assert.Equal(t, "prompt\n\nreview this", retrievalQuery(&slopIO.StructuredInput{CLIArgs: "prompt", CommandContext: "review this"}))
+
+	long := retrievalQuery(&slopIO.StructuredInput{StdinContent: strings.Repeat("é", maxQueryChars)})
+	assert.LessOrEqual(t, len(long), maxQueryChars)
+	assert.True(t, utf8.ValidString(long))
+}
+
+func TestFitContextWindow(t *testing.T) {
+	items := []slopContext.ContextItem{
+		{Path: "big.md", Type: "file", Content: strings.Repeat("a", 40000), Project: true},
+		{Path: "small.md", Type: "file", Content: strings.Repeat("b", 400), Project: true},
+		{Path: "notes.txt", Type: "file", Content: strings.Repeat("c", 8000)},
+	}
+	contextResult := &slopContext.ContextResult{ProcessedItems: items}
+	input := &slopIO.StructuredInput{CLIArgs: "summarize"}
+	build := func(cr *slopContext.ContextResult) []common.Message {
+		return buildSyntheticMessageHistory(input, cr, "")
+	}
+	fit := func(window int, strategy string) ([]common.Message, *slopContext.ContextResult, *contextBudget, error) {
+		cfg := &config.Config{Parameters: config.Parameters{MaxTokens: 1000, ContextOverflow: strategy}}
+		return NewApp(cfg, nil, false).WithContextWindow(window).fitContextWindow(nil, contextResult, build)
+	}
+
+	t.Run("No window skips budgeting", func(t *testing.T) {
+		history, result, budget, err := fit(0, "fail")
+		assert.NoError(t, err)
+		assert.Nil(t, budget)
+		assert.Same(t, contextResult, result)
+		assert.Len(t, history, 4)
+	})
+
+	t.Run("Input that fits is sent whole", func(t *testing.T) {
+		_, result, budget, err := fit(100000, "fail")
+		assert.NoError(t, err)
+		assert.Same(t, contextResult, result)
+		assert.Empty(t, budget.strategy)
+		assert.Equal(t, budget.estimated, budget.final)
+	})
+
+	t.Run("Truncate cuts the largest file", func(t *testing.T) {
+		history, result, budget, err := fit(8000, "truncate")
+		if assert.NoError(t, err) {
+			assert.LessOrEqual(t, budget.final, 7000)
+			assert.Equal(t, budget.final, common.EstimateRequestTokens(history))
+			assert.True(t, strings.HasSuffix(result.ProcessedItems[0].Content, truncationMarker))
+			assert.Equal(t, items[1], result.ProcessedItems[1], "small files are sent whole")
+			assert.Equal(t, items[2], result.ProcessedItems[2])
+			if assert.Len(t, budget.actions, 1) {
+				assert.Contains(t, budget.actions[0], "truncated big.md from ~10000")
+			}
+		}
+		assert.Len(t, contextResult.ProcessedItems[0].Content, 40000, "the caller's items are not modified")
+	})
+
+	t.Run("Drop leaves out project context from the end", func(t *testing.T) {
+		_, result, budget, err := fit(5000, "drop")
+		if assert.NoError(t, err) {
+			if assert.Len(t, result.ProcessedItems, 1) {
+				assert.Equal(t, "notes.txt", result.ProcessedItems[0].Path)
+			}
+			assert.Equal(t, []string{"dropped small.md (~100 tokens)", "dropped big.md (~10000 tokens)"}, budget.actions)
+		}
+	})
+
+	t.Run("Drop keeps context named on the command line", func(t *testing.T) {
+		_, _, budget, err := fit(2000, "drop")
+		assert.ErrorIs(t, err, ErrContextOverflow)
+		assert.ErrorContains(t, err, `after applying "drop"`)
+		assert.Len(t, budget.actions, 2)
+	})
+
+	t.Run("Fail reports the estimate", func(t *testing.T) {
+		_, _, _, err := fit(8000, "fail")
+		assert.ErrorIs(t, err, ErrContextOverflow)
+		assert.ErrorContains(t, err, "the 8000-token window leaves 7000")
+		assert.ErrorContains(t, err, "--context-overflow")
+	})
+
+	t.Run("No room left by max_tokens", func(t *testing.T) {
+		_, _, _, err := fit(1000, "truncate")
+		assert.ErrorContains(t, err, "leaves no room for input")
+	})
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "line one\nline two", truncateText("line one\nline two\nline three", 20))
+	assert.Equal(t, "éé", truncateText("ééé", 2))
+}
+
+func TestPrintContextBudget(t *testing.T) {
+	var buf bytes.Buffer
+	printContextBudget(&buf, &contextBudget{window: 8000, reserved: 1000, estimated: 9000, final: 6900, strategy: "truncate", actions: []string{"truncated big.md from ~8000 to ~5900 tokens"}})
+	output := buf.String()
+	assert.Contains(t, output, "window 8000 tokens, 1000 reserved for the response")
+	assert.Contains(t, output, `over by ~2000 tokens; applied "truncate"`)
+	assert.Contains(t, output, "truncated big.md")
+	assert.Contains(t, output, "input sent ~6900 tokens")
+}
+
+func TestApp_RunMapReduce(t *testing.T) {
+	isPart := func(messages []common.Message) bool {
+		return strings.Contains(messages[0].Content, "you are given part")
+	}
+	mockLLM := &MockLLM{}
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(isPart), "test-model", mock.Anything).
+		Return(&common.GenerateResult{Content: "<think>hmm</think>\npartial", Usage: &common.Usage{TotalTokens: 11}}, nil).
+		Times(3)
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(messages []common.Message) bool { return !isPart(messages) }), "test-model", mock.Anything).
+		Return(&common.GenerateResult{Content: "combined", Usage: &common.Usage{TotalTokens: 7}}, nil).
+		Once()
+	defer setupMockRegistry(&MockProvider{mockLLM: mockLLM})()
+
+	// 15 lines of 100 characters, in parts of 150 tokens (600 characters)
+	cfg := &config.Config{Parameters: config.Parameters{MapReduce: config.MapReduce{ChunkTokens: 150, Concurrency: 2}}}
+	input := &slopIO.StructuredInput{StdinContent: strings.Repeat(strings.Repeat("x", 99)+"\n", 15), CLIArgs: "summarize"}
+	a := NewApp(cfg, nil, false).WithMapReduce(true)
+
+	result, err := a.runMapReduce(context.Background(), input, nil, "test-provider", "test-model", "", "", true, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "combined", result.Output)
+		assert.Equal(t, 3*11+7, result.Generation.Usage.TotalTokens, "usage covers every part")
+	}
+	mockLLM.AssertExpectations(t)
+
+	// the reduce step sees the answers without thinking, then the request
+	reduce := mockLLM.Calls[len(mockLLM.Calls)-1].Arguments.Get(1).([]common.Message)
+	combined := reduce[len(reduce)-2].Content
+	assert.True(t, strings.HasPrefix(combined, defaultReducePrompt))
+	assert.Contains(t, combined, "Answer for part 3 of 3:\n\npartial")
+	assert.NotContains(t, combined, "hmm")
+	assert.Equal(t, "summarize", reduce[len(reduce)-1].Content)
+
+	// a failed part stops the run
+	failing := &MockLLM{}
+	failing.On("Generate", mock.Anything, mock.Anything, "test-model", mock.Anything).Return("", errors.New("bad request"))
+	defer setupMockRegistry(&MockProvider{mockLLM: failing})()
+	_, err = a.runMapReduce(context.Background(), input, nil, "test-provider", "test-model", "", "", true, false)
+	assert.ErrorContains(t, err, "failed to answer part 1 of 3")
+
+	_, err = a.runMapReduce(context.Background(), &slopIO.StructuredInput{CLIArgs: "summarize"}, nil, "test-provider", "test-model", "", "", true, false)
+	assert.ErrorContains(t, err, "needs piped input or a text context file")
+}
+
+func TestApp_RunMapReduce_Rounds(t *testing.T) {
+	stage := func(marker string) func([]common.Message) bool {
+		return func(messages []common.Message) bool { return strings.Contains(messages[0].Content, marker) }
+	}
+	isFinal := func(messages []common.Message) bool {
+		return !stage("you are given part")(messages) && !stage("combined in stages")(messages)
+	}
+	// each answer takes about 40 tokens, so two fit in a 100 token request
+	answer := strings.Repeat("y", 150)
+	mockLLM := &MockLLM{}
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(stage("you are given part")), "test-model", mock.Anything).
+		Return(&common.GenerateResult{Content: answer, Usage: &common.Usage{TotalTokens: 1}}, nil).
+		Times(8)
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(stage("combined in stages")), "test-model", mock.Anything).
+		Return(&common.GenerateResult{Content: answer, Usage: &common.Usage{TotalTokens: 1}}, nil).
+		Times(4 + 2)
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(isFinal), "test-model", mock.Anything).
+		Return(&common.GenerateResult{Content: "combined", Usage: &common.Usage{TotalTokens: 1}}, nil).
+		Once()
+	defer setupMockRegistry(&MockProvider{mockLLM: mockLLM})()
+
+	// 8 parts are combined in 4 groups, then 2, then answered at once
+	cfg := &config.Config{Parameters: config.Parameters{MapReduce: config.MapReduce{ChunkTokens: 100, Concurrency: 2, ReducePrompt: "Combine."}}}
+	input := &slopIO.StructuredInput{StdinContent: strings.Repeat(strings.Repeat("x", 99)+"\n", 32), CLIArgs: "summarize"}
+	a := NewApp(cfg, nil, false).WithMapReduce(true)
+
+	result, err := a.runMapReduce(context.Background(), input, nil, "test-provider", "test-model", "", "", true, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "combined", result.Output)
+		assert.Equal(t, 8+4+2+1, result.Generation.Usage.TotalTokens, "usage covers every round")
+	}
+	mockLLM.AssertExpectations(t)
+
+	reduce := mockLLM.Calls[len(mockLLM.Calls)-1].Arguments.Get(1).([]common.Message)
+	combined := reduce[len(reduce)-2].Content
+	assert.Contains(t, combined, "Answer for part 2 of 2:")
+	assert.NotContains(t, combined, "of 8")
+}
+
+func TestGroupAnswers(t *testing.T) {
+	short, long := "a", strings.Repeat("b", 400)
+	assert.Equal(t, []answerGroup{{0, 3}}, groupAnswers("Combine.", []string{short, short, short}, 100))
+	assert.Equal(t, []answerGroup{{0, 2}, {2, 3}}, groupAnswers("Combine.", []string{long, long, long}, 100), "answers too long to fit are still paired")
+
+	group := groupAnswers("Combine.", []string{short, short}, 100)[0]
+	assert.Equal(t, "Combine.\n\nAnswer for part 1 of 2:\n\na\n\nAnswer for part 2 of 2:\n\na", reduceContent("Combine.", []string{short, short}, group))
+}
+
+func TestMapMessages(t *testing.T) {
+	cfg := &config.Config{Parameters: config.Parameters{SystemPrompt: "Be brief."}}
+	a := NewApp(cfg, nil, false)
+	contextResult := &slopContext.ContextResult{ProcessedItems: []slopContext.ContextItem{
+		{Path: "style.md", Type: "file", Content: "short"},
+		{Path: "app.log", Type: "file", Content: "a much longer log file"},
+	}}
+	input := &slopIO.StructuredInput{CLIArgs: "find errors"}
+
+	// without stdin the largest context file is split
+	source, ok := findMapSource(input, contextResult)
+	assert.True(t, ok)
+	assert.Equal(t, "app.log", source.name)
+
+	messages := a.mapMessages(input, contextResult, source, "log part", 2, 5, "")
+	if assert.Len(t, messages, 4) {
+		assert.Equal(t, "Be brief.\n\n"+fmt.Sprintf(mapInstructions, 2, 5), messages[0].Content)
+		assert.Equal(t, "File: style.md\n\nshort", messages[1].Content)
+		assert.Equal(t, "File: app.log (part 2 of 5)\n\nlog part", messages[2].Content)
+		assert.Equal(t, "find errors", messages[3].Content)
+	}
+	assert.Equal(t, "a much longer log file", contextResult.ProcessedItems[1].Content, "the caller's items are not modified")
+
+	// piped input is preferred
+	source, _ = findMapSource(&slopIO.StructuredInput{StdinContent: "piped"}, contextResult)
+	assert.Equal(t, "stdin", source.name)
+}
+
+func TestMapChunkTokens(t *testing.T) {
+	cfg := &config.Config{Parameters: config.Parameters{MaxTokens: 1000}}
+	a := NewApp(cfg, nil, false)
+	assert.Equal(t, defaultMapChunkTokens, a.mapChunkTokens(500), "unknown window")
+
+	a.WithContextWindow(12000)
+	assert.Equal(t, 9000, a.mapChunkTokens(1000), "the window less output, fixed input and a margin")
+	assert.Equal(t, minMapChunkTokens, a.mapChunkTokens(20000))
+
+	cfg.Parameters.MapReduce.ChunkTokens = 2000
+	assert.Equal(t, 2000, a.mapChunkTokens(1000))
+}
+
+func TestSplitText(t *testing.T) {
+	assert.Equal(t, []string{"short"}, splitText("short", 100, 10))
+
+	// parts break at lines and repeat the end of the part before
+	text := "one\ntwo\nthree\nfour\nfive\nsix"
+	parts := splitText(text, 10, 5)
+	assert.Equal(t, []string{"one\ntwo", "two\nthree", "four\nfive", "five\nsix"}, parts)
+	for _, part := range parts {
+		assert.LessOrEqual(t, utf8.RuneCountInString(part), 10)
+	}
+
+	// without overlap every character appears once
+	assert.Equal(t, "one\ntwo\nthree\nfour\nfive\nsix", strings.Join(splitText(text, 10, 0), "\n"))
+
+	// long lines are split wherever they must be
+	assert.Equal(t, []string{"éééé", "éééé", "éé"}, splitText(strings.Repeat("é", 10), 4, 0))
+}
+
+func TestAddUsage(t *testing.T) {
+	// nil usage leaves the running total alone
+	assert.Nil(t, addUsage(nil, nil))
+	first := &common.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}
+	assert.Equal(t, first, addUsage(first, nil))
+
+	// tool rounds sum into a fresh value without mutating the inputs
+	total := addUsage(nil, first)
+	total = addUsage(total, &common.Usage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25})
+	assert.Equal(t, &common.Usage{PromptTokens: 30, CompletionTokens: 7, TotalTokens: 37}, total)
+	assert.Equal(t, 12, first.TotalTokens)
+}
+
+func TestApp_Run_Cache(t *testing.T) {
+	mockLLM := &MockLLM{}
+	mockLLM.On("Generate", context.Background(), mock.Anything, "test-model", mock.Anything).
+		Return("<think>plan</think>\nHello world", nil).
+		Once()
+
+	mockProvider := &MockProvider{mockLLM: mockLLM}
+	defer setupMockRegistry(mockProvider)()
+
+	cfg := &config.Config{
+		Parameters: config.Parameters{
+			Cache:    true,
+			CacheTTL: "1h",
+			Stream:   true,
+		},
+	}
+	cacheDir := t.TempDir()
+
+	run := func() (*Result, string) {
+		var out bytes.Buffer
+		app := NewApp(cfg, slog.Default(), false).WithOutput(&out).WithCacheDir(cacheDir)
+		result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "test-model", "", "", true, false)
+		assert.NoError(t, err)
+		return result, out.String()
+	}
+
+	first, _ := run()
+	assert.False(t, first.Generation.Cached)
+
+	// the repeat is answered from disk and still written to the output
+	second, out := run()
+	assert.True(t, second.Generation.Cached)
+	assert.Equal(t, "Hello world", second.Output)
+	assert.Equal(t, "Hello world", out)
 	mockLLM.AssertExpectations(t)
 }
+
+func TestApp_Run_Fallbacks(t *testing.T) {
+	unavailable := &common.APIError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("Mistral API error: service unavailable\n\nTry again later")}
+	badRequest := &common.APIError{StatusCode: http.StatusBadRequest, Err: errors.New("Mistral API error: invalid temperature")}
+
+	setup := func(t *testing.T, primaryErr error) (*MockLLM, *MockLLM) {
+		primary := &MockLLM{}
+		primary.On("Generate", context.Background(), mock.Anything, "primary-model", mock.Anything).Return("", primaryErr)
+		backup := &MockLLM{}
+
+		originalProviders := registry.AllProviders
+		registry.AllProviders = map[string]common.Provider{
+			"test-provider":   &MockProvider{mockLLM: primary},
+			"backup-provider": &MockProvider{mockLLM: backup},
+		}
+		t.Cleanup(func() { registry.AllProviders = originalProviders })
+		return primary, backup
+	}
+
+	cfg := &config.Config{Parameters: config.Parameters{SystemPrompt: "You are a helpful assistant"}}
+	fallbacks := []config.ModelRef{
+		{Provider: "missing-provider", Name: "any-model"},
+		{Provider: "backup-provider", Name: "backup-model"},
+	}
+
+	t.Run("unavailable model falls back", func(t *testing.T) {
+		primary, backup := setup(t, unavailable)
+		backup.On("Generate", context.Background(), mock.Anything, "backup-model", mock.Anything).Return("backup answer", nil)
+
+		app := NewApp(cfg, slog.Default(), false).WithFallbacks(fallbacks)
+		result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "primary-model", "", "", false, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "backup answer", result.Output)
+		assert.Equal(t, "backup-provider", result.Provider)
+		assert.Equal(t, "backup-model", result.Model)
+		if assert.Len(t, result.Fallbacks, 2) {
+			assert.Equal(t, "test-provider", result.Fallbacks[0].Provider)
+			assert.ErrorIs(t, result.Fallbacks[0].Err, unavailable)
+			assert.Equal(t, "missing-provider", result.Fallbacks[1].Provider)
+			assert.ErrorContains(t, result.Fallbacks[1].Err, "failed to create provider")
+		}
+		primary.AssertExpectations(t)
+		backup.AssertExpectations(t)
+	})
+
+	t.Run("bad request does not fall back", func(t *testing.T) {
+		primary, backup := setup(t, badRequest)
+
+		app := NewApp(cfg, slog.Default(), false).WithFallbacks(fallbacks)
+		result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "primary-model", "", "", false, false)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, badRequest)
+		primary.AssertExpectations(t)
+		backup.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("last candidate error is returned", func(t *testing.T) {
+		primary, _ := setup(t, unavailable)
+
+		app := NewApp(cfg, slog.Default(), false).WithFallbacks(fallbacks[:1])
+		result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "primary-model", "", "", false, false)
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "unsupported provider 'missing-provider'")
+		assert.ErrorContains(t, err, "after 1 unavailable model(s)")
+		primary.AssertExpectations(t)
+	})
+}
+
+func TestApp_Run_ModelPull(t *testing.T) {
+	missing := &common.APIError{StatusCode: http.StatusNotFound, Err: fmt.Errorf("gemma3: %w", common.ErrModelNotFound)}
+	cfg := &config.Config{Parameters: config.Parameters{SystemPrompt: "You are a helpful assistant"}}
+
+	setup := func(t *testing.T) *MockLLM {
+		mockLLM := &MockLLM{}
+		t.Cleanup(setupMockRegistry(&MockProvider{mockLLM: mockLLM}))
+		return mockLLM
+	}
+
+	t.Run("pulled model is retried", func(t *testing.T) {
+		mockLLM := setup(t)
+		mockLLM.On("Generate", context.Background(), mock.Anything, "gemma3", mock.Anything).Return("", missing).Once()
+		mockLLM.On("Generate", context.Background(), mock.Anything, "gemma3", mock.Anything).Return("pulled answer", nil).Once()
+
+		var pulls []string
+		app := NewApp(cfg, slog.Default(), false).WithModelPull(func(ctx context.Context, providerName, modelName string) (bool, error) {
+			pulls = append(pulls, providerName+"/"+modelName)
+			return true, nil
+		})
+		result, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "gemma3", "", "", false, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pulled answer", result.Output)
+		assert.Equal(t, []string{"test-provider/gemma3"}, pulls)
+		mockLLM.AssertExpectations(t)
+	})
+
+	t.Run("declined pull returns the not-found error", func(t *testing.T) {
+		mockLLM := setup(t)
+		mockLLM.On("Generate", context.Background(), mock.Anything, "gemma3", mock.Anything).Return("", missing).Once()
+
+		app := NewApp(cfg, slog.Default(), false).WithModelPull(func(ctx context.Context, providerName, modelName string) (bool, error) {
+			return false, nil
+		})
+		_, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "gemma3", "", "", false, false)
+
+		assert.ErrorIs(t, err, common.ErrModelNotFound)
+		mockLLM.AssertExpectations(t)
+	})
+
+	t.Run("other errors skip the hook", func(t *testing.T) {
+		mockLLM := setup(t)
+		mockLLM.On("Generate", context.Background(), mock.Anything, "gemma3", mock.Anything).Return("", errors.New("bad request")).Once()
+
+		app := NewApp(cfg, slog.Default(), false).WithModelPull(func(ctx context.Context, providerName, modelName string) (bool, error) {
+			t.Error("pull hook should not be called")
+			return false, nil
+		})
+		_, err := app.Run(context.Background(), []string{"test input"}, createEmptyContextResult(), "", "test-provider", "gemma3", "", "", false, false)
+		assert.ErrorContains(t, err, "bad request")
+	})
+}
+
+func TestApp_Candidates(t *testing.T) {
+	app := NewApp(&config.Config{}, slog.Default(), false).WithFallbacks([]config.ModelRef{
+		{Provider: "groq", Name: "llama-3.1-8b-instant"},
+		{Provider: "mistral", Name: "mistral-small-latest"},
+		{Provider: "groq", Name: "llama-3.1-8b-instant"},
+	})
+
+	assert.Equal(t, []config.ModelRef{
+		{Provider: "mistral", Name: "mistral-small-latest"},
+		{Provider: "groq", Name: "llama-3.1-8b-instant"},
+	}, app.candidates("mistral", "mistral-small-latest"))
+}
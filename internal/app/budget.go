@@ -0,0 +1,244 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	slopContext "github.com/chriscorrea/slop/internal/context"
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// ErrContextOverflow is returned when input can't be fitted to the model's
+// context window
+var ErrContextOverflow = errors.New("input does not fit the model's context window")
+
+// truncationMarker ends a file cut short to fit the context window
+const truncationMarker = "\n\n[... truncated to fit the context window ...]"
+
+// fitAttempts bounds how often a strategy is reapplied when the rebuilt
+// request is still a little over, as rounding in the estimates can leave it
+const fitAttempts = 3
+
+// WithContextWindow sets the selected model's context window in tokens.
+// input that would overflow it, less max_tokens for the response, is fitted
+// with parameters.context_overflow; 0 skips the check
+func (a *App) WithContextWindow(tokens int) *App {
+	a.contextWindow = tokens
+	return a
+}
+
+// contextBudget records how the input was fitted to the context window
+type contextBudget struct {
+	window    int      // model context window in tokens
+	reserved  int      // tokens kept for the response (max_tokens)
+	estimated int      // estimated input tokens as given
+	final     int      // estimated input tokens sent
+	strategy  string   // overflow strategy applied; empty when the input fit
+	actions   []string // what was truncated or dropped
+}
+
+// limit is the most input tokens the request may carry
+func (b *contextBudget) limit() int {
+	return b.window - b.reserved
+}
+
+// fitContextWindow builds the message history with build and, when the
+// request would overflow the context window, applies the overflow strategy
+// to the context items and rebuilds it. the budget is nil when no window is set
+func (a *App) fitContextWindow(system []common.Message, contextResult *slopContext.ContextResult, build func(*slopContext.ContextResult) []common.Message) ([]common.Message, *slopContext.ContextResult, *contextBudget, error) {
+	history := build(contextResult)
+	if a.contextWindow <= 0 {
+		return history, contextResult, nil, nil
+	}
+
+	budget := &contextBudget{window: a.contextWindow, reserved: a.cfg.Parameters.MaxTokens}
+	systemTokens := common.EstimateRequestTokens(system)
+	budget.estimated = systemTokens + common.EstimateRequestTokens(history)
+	budget.final = budget.estimated
+	if budget.final <= budget.limit() {
+		return history, contextResult, budget, nil
+	}
+
+	budget.strategy = a.cfg.Parameters.ContextOverflow
+	if budget.strategy == "" {
+		budget.strategy = "truncate"
+	}
+	if budget.limit() <= 0 {
+		return nil, nil, budget, fmt.Errorf("%w: max_tokens %d leaves no room for input in a %d-token window", ErrContextOverflow, budget.reserved, budget.window)
+	}
+
+	var fit func(*slopContext.ContextResult, int) (*slopContext.ContextResult, []string)
+	switch budget.strategy {
+	case "truncate":
+		fit = truncateLargestFiles
+	case "drop":
+		fit = dropProjectContext
+	}
+
+	fitted := contextResult
+	if fit != nil && contextResult != nil {
+		overflow := budget.final - budget.limit()
+		for range fitAttempts {
+			fitted, budget.actions = fit(contextResult, overflow)
+			history = build(fitted)
+			budget.final = systemTokens + common.EstimateRequestTokens(history)
+			if budget.final <= budget.limit() || len(budget.actions) == 0 {
+				break
+			}
+			overflow += budget.final - budget.limit()
+		}
+	}
+
+	if budget.final > budget.limit() {
+		return nil, nil, budget, budget.overflowError()
+	}
+	return history, fitted, budget, nil
+}
+
+// overflowError explains why the input could not be sent
+func (b *contextBudget) overflowError() error {
+	hint := fmt.Sprintf("still about %d after applying %q", b.final, b.strategy)
+	if b.strategy == "fail" {
+		hint = "shorten the input, use --retrieve, or set --context-overflow to truncate or drop"
+	}
+	return fmt.Errorf("%w: about %d input tokens, but the %d-token window leaves %d after reserving max_tokens %d for the response; %s",
+		ErrContextOverflow, b.estimated, b.window, b.limit(), b.reserved, hint)
+}
+
+// truncateLargestFiles cuts the largest text files down to a common size,
+// just enough to free overflow tokens, so small files are sent whole
+func truncateLargestFiles(contextResult *slopContext.ContextResult, overflow int) (*slopContext.ContextResult, []string) {
+	markerTokens := common.EstimateTokens(truncationMarker) + 1 // +1 for rounding
+
+	var sizes []int
+	for _, item := range contextResult.ProcessedItems {
+		if item.Type == "file" {
+			sizes = append(sizes, common.EstimateTokens(item.Content))
+		}
+	}
+	if len(sizes) == 0 {
+		return contextResult, nil
+	}
+
+	// freed returns the tokens saved by capping every file at limit
+	freed := func(limit int) int {
+		total := 0
+		for _, size := range sizes {
+			if size > limit+markerTokens {
+				total += size - limit - markerTokens
+			}
+		}
+		return total
+	}
+
+	// the largest cap that frees enough; 0 when even that falls short
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	limit := sort.Search(sizes[0]+1, func(limit int) bool { return freed(limit) < overflow }) - 1
+	limit = max(limit, 0)
+
+	result := copyContextResult(contextResult)
+	var actions []string
+	for i, item := range result.ProcessedItems {
+		size := common.EstimateTokens(item.Content)
+		if item.Type != "file" || size <= limit+markerTokens {
+			continue
+		}
+		result.ProcessedItems[i].Content = truncateText(item.Content, common.TokensToChars(limit)) + truncationMarker
+		actions = append(actions, fmt.Sprintf("truncated %s from ~%d to ~%d tokens", item.Path, size, limit))
+	}
+	return result, actions
+}
+
+// dropProjectContext leaves out project context, last first, until overflow
+// tokens are freed: manifest entries in reverse order and retrieved chunks
+// from the lowest score. context named with --context is always kept
+func dropProjectContext(contextResult *slopContext.ContextResult, overflow int) (*slopContext.ContextResult, []string) {
+	dropped := make(map[int]bool)
+	var actions []string
+	freed := 0
+	for i := len(contextResult.ProcessedItems) - 1; i >= 0 && freed < overflow; i-- {
+		item := contextResult.ProcessedItems[i]
+		if !item.Project {
+			continue
+		}
+		tokens := itemTokens(item)
+		dropped[i] = true
+		freed += tokens
+		actions = append(actions, fmt.Sprintf("dropped %s (~%d tokens)", itemLabel(item), tokens))
+	}
+	if len(dropped) == 0 {
+		return contextResult, nil
+	}
+
+	result := copyContextResult(contextResult)
+	result.ProcessedItems = result.ProcessedItems[:0]
+	for i, item := range contextResult.ProcessedItems {
+		if !dropped[i] {
+			result.ProcessedItems = append(result.ProcessedItems, item)
+		}
+	}
+	return result, actions
+}
+
+// copyContextResult copies a result with its own items, so fitting never
+// changes the caller's
+func copyContextResult(contextResult *slopContext.ContextResult) *slopContext.ContextResult {
+	copied := *contextResult
+	copied.ProcessedItems = append([]slopContext.ContextItem(nil), contextResult.ProcessedItems...)
+	return &copied
+}
+
+// itemTokens estimates the tokens a context item adds to the request
+func itemTokens(item slopContext.ContextItem) int {
+	switch item.Type {
+	case "conversation":
+		return common.EstimateRequestTokens(item.Messages)
+	case "image":
+		return common.EstimateMessageTokens(common.Message{Images: []common.Image{*item.Image}})
+	case "chunk":
+		return common.EstimateTokens(item.Chunk.Content)
+	default:
+		return common.EstimateTokens(item.Content)
+	}
+}
+
+// itemLabel names a context item in budget reports
+func itemLabel(item slopContext.ContextItem) string {
+	if item.Type == "chunk" {
+		return fmt.Sprintf("%s:%d-%d", item.Path, item.Chunk.StartLine, item.Chunk.EndLine)
+	}
+	return item.Path
+}
+
+// truncateText cuts text to about maxChars characters, at a line break when
+// one falls in the second half
+func truncateText(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	cut := string(runes[:maxChars])
+	if i := strings.LastIndex(cut, "\n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return cut
+}
+
+// printContextBudget writes the budget and any fitting to w for verbose output
+func printContextBudget(w io.Writer, budget *contextBudget) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Context Budget:")
+	fmt.Fprintf(w, "  window %d tokens, %d reserved for the response\n", budget.window, budget.reserved)
+	fmt.Fprintf(w, "  input ~%d tokens\n", budget.estimated)
+	if budget.strategy == "" {
+		return
+	}
+	fmt.Fprintf(w, "  over by ~%d tokens; applied %q\n", budget.estimated-budget.limit(), budget.strategy)
+	for _, action := range budget.actions {
+		fmt.Fprintf(w, "  %s\n", action)
+	}
+	fmt.Fprintf(w, "  input sent ~%d tokens\n", budget.final)
+}
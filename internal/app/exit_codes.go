@@ -23,6 +23,10 @@ const (
 	ExitNeutral  = 12
 	ExitPass     = 30
 	ExitFail     = 31
+
+	// ExitInterrupted is used when Ctrl-C or SIGTERM cancels a run, as
+	// shells report for SIGINT
+	ExitInterrupted = 130
 )
 
 // pre-compiled regex patterns for sentiment and pass/fail detection
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/registry"
+	"github.com/chriscorrea/slop/internal/tools"
+)
+
+// FailedAttempt is a candidate model that was unavailable, so the next one was tried
+type FailedAttempt struct {
+	Provider string
+	Model    string
+	Err      error
+}
+
+// WithFallbacks sets the models tried in order when the requested one is
+// unreachable or returns a 5xx, 429 or authentication error
+func (a *App) WithFallbacks(fallbacks []config.ModelRef) *App {
+	a.fallbacks = fallbacks
+	return a
+}
+
+// candidates returns the requested model followed by its fallbacks, without repeats
+func (a *App) candidates(providerName, modelName string) []config.ModelRef {
+	candidates := []config.ModelRef{{Provider: providerName, Name: modelName}}
+	seen := map[config.ModelRef]bool{candidates[0]: true}
+	for _, ref := range a.fallbacks {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		candidates = append(candidates, ref)
+	}
+	return candidates
+}
+
+// generateWithFallbacks generates with each candidate in turn until one
+// answers. a candidate is only abandoned when it can't be created or its
+// error means it is unavailable, and never once output was streamed or a
+// tool ran, since starting over would repeat them
+func (a *App) generateWithFallbacks(ctx context.Context, candidates []config.ModelRef, messages []common.Message, stream common.StreamHandler, streamed func() bool, runner *tools.Runner, beforeTools func()) (*common.GenerateResult, config.ModelRef, []FailedAttempt, error) {
+	var failed []FailedAttempt
+	toolsRan := false
+
+	for i, candidate := range candidates {
+		var generation *common.GenerateResult
+		unavailable := true
+
+		provider, err := a.createClient(candidate.Provider)
+		if err == nil {
+			req := registry.BuildProviderOptions(candidate.Provider, a.cfg)
+			if req == nil {
+				req = &common.GenerateRequest{}
+			}
+			req.OnChunk = stream
+			generate := func() (*common.GenerateResult, error) {
+				// the tool loop appends to the history, so each attempt starts from a copy
+				history := append([]common.Message(nil), messages...)
+				return a.generateWithTools(ctx, provider, history, candidate.Name, req, runner, func() {
+					toolsRan = true
+					beforeTools()
+				})
+			}
+
+			generation, err = generate()
+			if err != nil && !toolsRan && !streamed() && a.offerPull(ctx, candidate, err, beforeTools) {
+				generation, err = generate()
+			}
+			if err == nil {
+				return generation, candidate, failed, nil
+			}
+			err = fmt.Errorf("failed to generate response: %w", err)
+			unavailable = common.ShouldFallback(err)
+		}
+
+		if i == len(candidates)-1 || !unavailable || toolsRan || streamed() {
+			if len(failed) > 0 {
+				err = fmt.Errorf("%w (after %d unavailable model(s))", err, len(failed))
+			}
+			return nil, candidate, failed, err
+		}
+
+		if a.logger != nil {
+			a.logger.Warn("Model unavailable, trying fallback",
+				"provider", candidate.Provider,
+				"model", candidate.Name,
+				"next_provider", candidates[i+1].Provider,
+				"next_model", candidates[i+1].Name,
+				"error", err)
+		}
+		failed = append(failed, FailedAttempt{Provider: candidate.Provider, Model: candidate.Name, Err: err})
+	}
+
+	return nil, config.ModelRef{}, failed, fmt.Errorf("no model to generate with")
+}
+
+// offerPull hands a model the Ollama server reported missing to the pull
+// hook, pausing the spinner first, and reports whether it is now pulled
+func (a *App) offerPull(ctx context.Context, candidate config.ModelRef, err error, pause func()) bool {
+	if a.modelPull == nil || !errors.Is(err, common.ErrModelNotFound) {
+		return false
+	}
+
+	pause()
+	pulled, pullErr := a.modelPull(ctx, candidate.Provider, candidate.Name)
+	if pullErr != nil {
+		if a.logger != nil {
+			a.logger.Warn("Failed to pull model", "provider", candidate.Provider, "model", candidate.Name, "error", pullErr)
+		}
+		return false
+	}
+	return pulled
+}
+
+// createClient creates the provider client, wrapped with the response cache when enabled
+func (a *App) createClient(providerName string) (common.LLM, error) {
+	provider, err := registry.CreateProvider(providerName, a.cfg, a.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	// serve identical requests from the on-disk cache when enabled
+	if a.cfg.Parameters.Cache {
+		provider = a.withCache(provider, providerName)
+	}
+	return provider, nil
+}
@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chriscorrea/slop/internal/config"
+	slopContext "github.com/chriscorrea/slop/internal/context"
+	"github.com/chriscorrea/slop/internal/format"
+	slopIO "github.com/chriscorrea/slop/internal/io"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/tools"
+)
+
+const (
+	// defaultMapChunkTokens sizes parts when the model's context window is unknown
+	defaultMapChunkTokens = 8000
+
+	// minMapChunkTokens keeps parts useful when little of the window is left
+	minMapChunkTokens = 500
+
+	// mapInstructions tell the model it sees one part of the input
+	mapInstructions = "The input is too long to read at once, so you are given part %d of %d. Answer the request for this part alone; your answer will be combined with the answers for the other parts."
+
+	// combineInstructions tell the model it combines some of the partial
+	// answers, when all of them won't fit in one request
+	combineInstructions = "The input is too long to read at once, so the answers for its parts are combined in stages. You are given the answers for parts %d to %d of %d. Combine them into one answer for those parts; it will be combined with the answers for the other parts."
+
+	// defaultReducePrompt combines the partial answers when
+	// parameters.map_reduce.reduce_prompt is empty
+	defaultReducePrompt = "The input was too long to read at once, so it was split into parts and the request was answered for each part separately. Those answers follow. Combine them into a single answer to the request, as if the whole input had been read at once: merge repeated points, keep the details that matter, and don't mention the parts."
+)
+
+// WithMapReduce answers the prompt for each part of the piped input (or the
+// largest context file) and then combines the answers, so input larger than
+// the context window can be used
+func (a *App) WithMapReduce(enabled bool) *App {
+	a.mapReduce = enabled
+	return a
+}
+
+// mapSource is the input split by map-reduce: stdin, or a text context file
+type mapSource struct {
+	name  string
+	text  string
+	index int // position in the context items; -1 for stdin
+}
+
+// runMapReduce splits the input, answers the prompt for each part
+// concurrently and responds with the combined answer
+func (a *App) runMapReduce(ctx context.Context, input *slopIO.StructuredInput, contextResult *slopContext.ContextResult, providerName, modelName, messageTemplate, exitMode string, hideThinking, showThinking bool) (*Result, error) {
+	source, ok := findMapSource(input, contextResult)
+	if !ok {
+		return nil, fmt.Errorf("--map-reduce needs piped input or a text context file to split")
+	}
+
+	// size parts to what the rest of the request leaves of the window
+	settings := a.cfg.Parameters.MapReduce
+	fixed := common.EstimateRequestTokens(a.mapMessages(input, contextResult, source, "", 1, 1, messageTemplate))
+	chunkTokens := a.mapChunkTokens(fixed)
+	overlapTokens := min(settings.OverlapTokens, chunkTokens/2)
+	parts := splitText(source.text, common.TokensToChars(chunkTokens), common.TokensToChars(overlapTokens))
+	if len(parts) == 1 {
+		return a.respond(ctx, input, contextResult, providerName, modelName, messageTemplate, exitMode, hideThinking, showThinking)
+	}
+
+	a.progress("Map-reduce: split %s into %d parts of up to ~%d tokens\n", source.name, len(parts), chunkTokens)
+	start := time.Now()
+	answers, usage, err := a.mapParts(ctx, parts, func(i int) []common.Message {
+		return a.mapMessages(input, contextResult, source, parts[i], i+1, len(parts), messageTemplate)
+	}, providerName, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reduce step answers the original request from the partial answers.
+	// answers that won't fit in one request are combined in groups first,
+	// round after round, until they do
+	reducePrompt := settings.ReducePrompt
+	if reducePrompt == "" {
+		reducePrompt = defaultReducePrompt
+	}
+	for {
+		groups := groupAnswers(reducePrompt, answers, chunkTokens)
+		if len(groups) == 1 {
+			break
+		}
+		a.progress("Map-reduce: combining %d partial answers in %d groups\n", len(answers), len(groups))
+		combined, combineUsage, err := a.mapParts(ctx, make([]string, len(groups)), func(i int) []common.Message {
+			group := groups[i]
+			system := fmt.Sprintf(combineInstructions, group.start+1, group.end, len(answers))
+			if a.cfg.Parameters.SystemPrompt != "" {
+				system = a.cfg.Parameters.SystemPrompt + "\n\n" + system
+			}
+			groupInput := &slopIO.StructuredInput{
+				CommandContext: input.CommandContext,
+				StdinContent:   reduceContent(reducePrompt, answers, group),
+				CLIArgs:        input.CLIArgs,
+			}
+			messages := []common.Message{{Role: "system", Content: system}}
+			return append(messages, buildSyntheticMessageHistory(groupInput, nil, messageTemplate)...)
+		}, providerName, modelName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to combine %d partial answers: %w", len(answers), err)
+		}
+		answers, usage = combined, addUsage(usage, combineUsage)
+	}
+	reduceInput := &slopIO.StructuredInput{
+		CommandContext: input.CommandContext,
+		StdinContent:   reduceContent(reducePrompt, answers, answerGroup{start: 0, end: len(answers)}),
+		CLIArgs:        input.CLIArgs,
+	}
+
+	result, err := a.respond(ctx, reduceInput, nil, providerName, modelName, messageTemplate, exitMode, hideThinking, showThinking)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine %d partial answers: %w", len(answers), err)
+	}
+	if result.Generation != nil {
+		result.Generation.Usage = addUsage(usage, result.Generation.Usage)
+	}
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// answerGroup is a run of partial answers, answers[start:end], combined in
+// one request
+type answerGroup struct {
+	start, end int
+}
+
+// groupAnswers splits the partial answers into runs whose reduce input fits
+// in maxTokens. a group takes at least two answers, so each round of
+// combining leaves fewer, even when the answers are long
+func groupAnswers(reducePrompt string, answers []string, maxTokens int) []answerGroup {
+	headTokens := common.EstimateTokens(reducePrompt)
+	var groups []answerGroup
+	group, tokens := answerGroup{}, headTokens
+	for i, answer := range answers {
+		answerTokens := common.EstimateTokens(reduceSection(answer, i, len(answers)))
+		if group.end-group.start >= 2 && tokens+answerTokens > maxTokens {
+			groups = append(groups, group)
+			group, tokens = answerGroup{start: i}, headTokens
+		}
+		group.end = i + 1
+		tokens += answerTokens
+	}
+	return append(groups, group)
+}
+
+// reduceContent joins a group of partial answers under the reduce prompt
+func reduceContent(reducePrompt string, answers []string, group answerGroup) string {
+	sections := []string{reducePrompt}
+	for i := group.start; i < group.end; i++ {
+		sections = append(sections, reduceSection(answers[i], i, len(answers)))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// reduceSection labels one partial answer with its part number
+func reduceSection(answer string, index, total int) string {
+	return fmt.Sprintf("Answer for part %d of %d:\n\n%s", index+1, total, answer)
+}
+
+// findMapSource picks the input to split: stdin when piped, else the
+// largest text context file
+func findMapSource(input *slopIO.StructuredInput, contextResult *slopContext.ContextResult) (mapSource, bool) {
+	if input != nil && input.StdinContent != "" {
+		return mapSource{name: "stdin", text: input.StdinContent, index: -1}, true
+	}
+	source := mapSource{index: -1}
+	if contextResult != nil {
+		for i, item := range contextResult.ProcessedItems {
+			if item.Type == "file" && len(item.Content) > len(source.text) {
+				source = mapSource{name: item.Path, text: item.Content, index: i}
+			}
+		}
+	}
+	return source, source.text != ""
+}
+
+// mapChunkTokens returns the part size: parameters.map_reduce.chunk_tokens
+// when set, else what the context window leaves beside the fixed tokens of
+// each request, with a margin for estimation error
+func (a *App) mapChunkTokens(fixed int) int {
+	if tokens := a.cfg.Parameters.MapReduce.ChunkTokens; tokens > 0 {
+		return tokens
+	}
+	if a.contextWindow <= 0 {
+		return defaultMapChunkTokens
+	}
+	available := (a.contextWindow - a.cfg.Parameters.MaxTokens - fixed) * 9 / 10
+	return max(available, minMapChunkTokens)
+}
+
+// mapMessages builds the request for one part: the input with the source
+// replaced by the part, and instructions saying which part it is
+func (a *App) mapMessages(input *slopIO.StructuredInput, contextResult *slopContext.ContextResult, source mapSource, part string, number, total int, messageTemplate string) []common.Message {
+	system := fmt.Sprintf(mapInstructions, number, total)
+	if a.cfg.Parameters.SystemPrompt != "" {
+		system = a.cfg.Parameters.SystemPrompt + "\n\n" + system
+	}
+
+	partInput := *input
+	if source.index < 0 {
+		partInput.StdinContent = part
+	} else {
+		contextResult = copyContextResult(contextResult)
+		item := &contextResult.ProcessedItems[source.index]
+		item.Path = fmt.Sprintf("%s (part %d of %d)", item.Path, number, total)
+		item.Content = part
+	}
+
+	messages := []common.Message{{Role: "system", Content: system}}
+	return append(messages, buildSyntheticMessageHistory(&partInput, contextResult, messageTemplate)...)
+}
+
+// mapParts answers every part, reporting progress on stderr. the first part
+// runs alone, so a missing model is pulled, or a bad key reported, once;
+// the rest run parameters.map_reduce.concurrency at a time
+func (a *App) mapParts(ctx context.Context, parts []string, messages func(int) []common.Message, providerName, modelName string) ([]string, *common.Usage, error) {
+	// parts are plain notes for the reduce step: no tools, format or schema
+	cfg := *a.cfg
+	cfg.Parameters.Tools = nil
+	cfg.Parameters.ResponseSchema = ""
+	cfg.Parameters.Stream = false
+	cfg.Format = config.Format{}
+	mapper := *a
+	mapper.cfg = &cfg
+	runner := tools.NewRunner(nil, a.logger)
+	candidates := a.candidates(providerName, modelName)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make([]string, len(parts))
+	var usage *common.Usage
+	var mu sync.Mutex
+	var firstErr error
+	done := 0
+
+	mapPart := func(i int) {
+		generation, _, _, err := mapper.generateWithFallbacks(ctx, candidates, messages(i), nil, func() bool { return false }, runner, func() {})
+		if err == nil {
+			answers[i], err = format.ApplyThinkingFilter(generation.Content, true, false)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to answer part %d of %d: %w", i+1, len(parts), err)
+				cancel()
+			}
+			return
+		}
+		answers[i] = strings.TrimSpace(answers[i])
+		usage = addUsage(usage, generation.Usage)
+		done++
+		a.progress("\rMap-reduce: answered %d/%d parts", done, len(parts))
+	}
+
+	a.progress("Map-reduce: answered 0/%d parts", len(parts))
+	mapPart(0)
+
+	concurrency := max(a.cfg.Parameters.MapReduce.Concurrency, 1)
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 1; i < len(parts) && ctx.Err() == nil; i++ {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			mapPart(i)
+		}(i)
+	}
+	wg.Wait()
+	a.progress("\n")
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	return answers, usage, nil
+}
+
+// progress reports map-reduce progress on stderr, unless the run is quiet
+func (a *App) progress(format string, args ...interface{}) {
+	if !a.quiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// splitText breaks text into parts of at most maxChars characters, at line
+// breaks where possible. each part after the first starts with about
+// overlapChars from the end of the one before, so a passage cut at a
+// boundary is seen whole in one of them
+func splitText(text string, maxChars, overlapChars int) []string {
+	if utf8.RuneCountInString(text) <= maxChars {
+		return []string{text}
+	}
+
+	var lines []string
+	for _, line := range strings.SplitAfter(text, "\n") {
+		// a line longer than a part is split wherever it must be
+		for runes := []rune(line); len(runes) > 0; {
+			n := min(len(runes), maxChars)
+			lines = append(lines, string(runes[:n]))
+			runes = runes[n:]
+		}
+	}
+
+	var parts, current []string
+	size := 0
+	for _, line := range lines {
+		length := utf8.RuneCountInString(line)
+		if size > 0 && size+length > maxChars {
+			parts = append(parts, strings.TrimRight(strings.Join(current, ""), "\n"))
+
+			// carry the last lines of the part into the next one
+			var carry []string
+			carried := 0
+			for i := len(current) - 1; i >= 0; i-- {
+				n := utf8.RuneCountInString(current[i])
+				if carried+n > overlapChars || carried+n+length > maxChars {
+					break
+				}
+				carry = append([]string{current[i]}, carry...)
+				carried += n
+			}
+			current, size = carry, carried
+		}
+		current = append(current, line)
+		size += length
+	}
+	return append(parts, strings.TrimRight(strings.Join(current, ""), "\n"))
+}
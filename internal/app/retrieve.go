@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	slopContext "github.com/chriscorrea/slop/internal/context"
+	slopIO "github.com/chriscorrea/slop/internal/io"
+)
+
+// maxQueryChars bounds the prompt text embedded as the retrieval query;
+// embedding models accept a few thousand tokens at most
+const maxQueryChars = 4000
+
+// RetrieveFunc returns the project context chunks most relevant to a query
+type RetrieveFunc func(ctx context.Context, query string) ([]slopContext.Chunk, error)
+
+// WithRetriever sets the hook that picks project context chunks for the
+// prompt; they are sent ahead of the other context items
+func (a *App) WithRetriever(fn RetrieveFunc) *App {
+	a.retrieve = fn
+	return a
+}
+
+// retrieveContext returns contextResult with the chunks retrieved for the
+// prompt prepended to its items, where the project files would have been
+func (a *App) retrieveContext(ctx context.Context, input *slopIO.StructuredInput, contextResult *slopContext.ContextResult) (*slopContext.ContextResult, error) {
+	query := retrievalQuery(input)
+	if query == "" {
+		return contextResult, nil
+	}
+
+	chunks, err := a.retrieve(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve project context: %w", err)
+	}
+
+	result := &slopContext.ContextResult{}
+	if contextResult != nil {
+		copied := *contextResult
+		result = &copied
+	}
+	items := make([]slopContext.ContextItem, 0, len(chunks)+len(result.ProcessedItems))
+	for i := range chunks {
+		items = append(items, slopContext.ContextItem{Path: chunks[i].Path, Type: "chunk", Chunk: &chunks[i], Project: true})
+	}
+	result.ProcessedItems = append(items, result.ProcessedItems...)
+	return result, nil
+}
+
+// retrievalQuery is the text the prompt is about: the prompt itself, the
+// command context and piped input, cut to maxQueryChars
+func retrievalQuery(input *slopIO.StructuredInput) string {
+	if input == nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{input.CLIArgs, input.CommandContext, input.StdinContent} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	query := strings.Join(parts, "\n\n")
+	if len(query) > maxQueryChars {
+		query = strings.ToValidUTF8(query[:maxQueryChars], "")
+	}
+	return query
+}
@@ -0,0 +1,115 @@
+// Package batch answers a prompt for each record of a JSONL file with
+// bounded concurrency, keeping a checkpoint of finished records so an
+// interrupted or partly failed run can be resumed.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxRecordBytes bounds a single input line
+const maxRecordBytes = 10 * 1024 * 1024
+
+// Record is one line of batch input
+type Record struct {
+	Index int             // position among the records, from 0
+	Data  json.RawMessage // the record as given
+}
+
+// Result is one line of batch output
+type Result struct {
+	Index    int             `json:"index"`
+	Record   json.RawMessage `json:"record"`
+	Response string          `json:"response"`
+	ExitCode int             `json:"exit_code"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Failed reports whether the record went unanswered
+func (r Result) Failed() bool {
+	return r.Error != ""
+}
+
+// AnswerFunc answers one record
+type AnswerFunc func(ctx context.Context, record Record) Result
+
+// Read returns each non-blank line of r as a record, failing on the first
+// line that is not valid JSON
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordBytes)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("line %d is not a JSON record", line)
+		}
+		records = append(records, Record{Index: len(records), Data: append(json.RawMessage(nil), data...)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return records, nil
+}
+
+// Run answers the records, concurrency at a time, and passes each result to
+// emit in input order. an emit error, or ctx ending, stops the run; records
+// already being answered see the cancellation through their context
+func Run(ctx context.Context, records []Record, concurrency int, answer AnswerFunc, emit func(Result) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// one buffered channel per record keeps output in order without
+	// holding back the workers
+	results := make([]chan Result, len(records))
+	for i := range results {
+		results[i] = make(chan Result, 1)
+	}
+
+	go func() {
+		slots := make(chan struct{}, max(concurrency, 1))
+		for i, record := range records {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int, record Record) {
+				defer func() { <-slots }()
+				results[i] <- answer(ctx, record)
+			}(i, record)
+		}
+	}()
+
+	for i := range records {
+		select {
+		case result := <-results[i]:
+			if err := emit(result); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// recordHash identifies a record's content, so a checkpoint entry is not
+// applied to a record that has since changed
+func recordHash(record Record) string {
+	sum := sha256.Sum256(record.Data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	records, err := Read(strings.NewReader("{\"text\":\"a\"}\n\n  \"plain\"  \r\n{\"text\":\"b\"}"))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, 0, records[0].Index)
+	assert.JSONEq(t, `{"text":"a"}`, string(records[0].Data))
+	assert.Equal(t, `"plain"`, string(records[1].Data))
+	assert.Equal(t, 2, records[2].Index)
+
+	_, err = Read(strings.NewReader("{\"text\":\"a\"}\n\nnot json\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestRun(t *testing.T) {
+	records, err := Read(strings.NewReader("1\n2\n3\n4\n5\n6\n"))
+	require.NoError(t, err)
+
+	var running, peak int32
+	answer := func(ctx context.Context, record Record) Result {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		// later records finish first, so ordering is up to Run
+		time.Sleep(time.Duration(len(records)-record.Index) * 5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return Result{Index: record.Index, Record: record.Data, Response: "r" + string(record.Data)}
+	}
+
+	var emitted []string
+	err = Run(context.Background(), records, 2, answer, func(result Result) error {
+		emitted = append(emitted, result.Response)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2", "r3", "r4", "r5", "r6"}, emitted)
+	assert.LessOrEqual(t, peak, int32(2))
+
+	// an emit error stops the run
+	stop := errors.New("disk full")
+	count := 0
+	err = Run(context.Background(), records, 3, answer, func(Result) error {
+		count++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, count)
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "checkpoint.jsonl")
+	records, err := Read(strings.NewReader("{\"id\":1}\n{\"id\":2}\n"))
+	require.NoError(t, err)
+
+	checkpoint, err := OpenCheckpoint(path)
+	require.NoError(t, err)
+	assert.False(t, checkpoint.Done(records[0]))
+	require.NoError(t, checkpoint.Mark(records[0]))
+	assert.True(t, checkpoint.Done(records[0]))
+
+	reopened, err := OpenCheckpoint(path)
+	require.NoError(t, err)
+	assert.True(t, reopened.Done(records[0]))
+	assert.False(t, reopened.Done(records[1]))
+
+	// an edited record is answered again
+	edited, err := Read(strings.NewReader("{\"id\":9}\n"))
+	require.NoError(t, err)
+	assert.False(t, reopened.Done(edited[0]))
+}
@@ -0,0 +1,93 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint is an append-only JSONL file of the records a batch has
+// answered, so running it again skips them. entries match a record by
+// position and content, so an edited input is answered again where it changed
+type Checkpoint struct {
+	path string
+	mu   sync.Mutex
+	done map[int]string // record index -> content hash
+}
+
+// checkpointEntry is one line of the checkpoint file
+type checkpointEntry struct {
+	Index int    `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+// OpenCheckpoint reads the checkpoint at path; a missing file is an empty
+// checkpoint. lines that don't parse, as a write cut short can leave, are ignored
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, done: make(map[int]string)}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Hash == "" {
+			continue
+		}
+		c.done[entry.Index] = entry.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Path returns the checkpoint file path
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// Done reports whether the record was answered by an earlier run
+func (c *Checkpoint) Done(record Record) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[record.Index] == recordHash(record)
+}
+
+// Mark records the record as answered
+func (c *Checkpoint) Mark(record Record) error {
+	entry := checkpointEntry{Index: record.Index, Hash: recordHash(record)}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dir := filepath.Dir(c.path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.done[entry.Index] = entry.Hash
+	return nil
+}
@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// keyVersion is mixed into every key; bump it when the key or entry format
+// changes so stale entries are never read
+const keyVersion = 2
+
+// Cache is an on-disk response cache with one JSON file per entry
+type Cache struct {
+	dir      string
+	ttl      time.Duration // zero means entries never expire
+	maxBytes int64         // zero means no size cap
+	now      func() time.Time
+}
+
+// entry is the on-disk form of a cached response
+type entry struct {
+	CreatedAt time.Time              `json:"created_at"`
+	Provider  string                 `json:"provider"`
+	Model     string                 `json:"model"`
+	Result    *common.GenerateResult `json:"result"`
+}
+
+// Stats describes the cache contents
+type Stats struct {
+	Dir      string
+	Entries  int
+	Expired  int // entries past the TTL, removed on next access or clear
+	Bytes    int64
+	MaxBytes int64
+	TTL      time.Duration
+	Oldest   time.Time
+	Newest   time.Time
+}
+
+// DefaultDir returns the cache location, ~/.slop/cache
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".slop", "cache"), nil
+}
+
+// New creates a cache in dir. a zero ttl never expires entries and a zero
+// maxBytes never evicts them
+func New(dir string, ttl time.Duration, maxBytes int64) *Cache {
+	return &Cache{dir: dir, ttl: ttl, maxBytes: maxBytes, now: time.Now}
+}
+
+// Key hashes a request into a cache key. options that can't affect the
+// response, such as the stream flag and handler, are left out
+func Key(providerName, modelName string, messages []common.Message, req *common.GenerateRequest) (string, error) {
+	var options struct {
+		Common    common.GenerateOptions `json:"common"`
+		Extension common.Extension       `json:"extension,omitempty"`
+	}
+	if req != nil {
+		options.Common = req.GenerateOptions
+		options.Extension = req.Extension
+	}
+	// streaming changes delivery, not content
+	options.Common.Stream = false
+
+	// tool results are addressed by name on some providers, but the wire
+	// encoding of a message leaves the name out
+	type keyMessage struct {
+		Message  json.RawMessage `json:"message"`
+		ToolName string          `json:"tool_name,omitempty"`
+	}
+	keyMessages := make([]keyMessage, 0, len(messages))
+	for _, msg := range messages {
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode messages for cache key: %w", err)
+		}
+		keyMessages = append(keyMessages, keyMessage{Message: encoded, ToolName: msg.ToolName})
+	}
+
+	payload, err := json.Marshal(struct {
+		Version  int          `json:"version"`
+		Provider string       `json:"provider"`
+		Model    string       `json:"model"`
+		Messages []keyMessage `json:"messages"`
+		Options  interface{}  `json:"options"`
+	}{keyVersion, providerName, modelName, keyMessages, options})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the cached result for key. expired and unreadable entries are
+// removed and reported as misses
+func (c *Cache) Get(key string) (*common.GenerateResult, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Result == nil || c.expired(e.CreatedAt) {
+		_ = os.Remove(path)
+		return nil, false
+	}
+
+	// refresh the modification time so eviction drops least recently used first
+	now := c.now()
+	_ = os.Chtimes(path, now, now)
+	return e.Result, true
+}
+
+// Put stores a result under key, then evicts the least recently used
+// entries while the cache is over its size cap
+func (c *Cache) Put(key, providerName, modelName string, result *common.GenerateResult) error {
+	data, err := json.Marshal(entry{
+		CreatedAt: c.now().UTC(),
+		Provider:  providerName,
+		Model:     modelName,
+		Result:    result,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// write to a temp file and rename so readers never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.evict()
+}
+
+// Stats reports the number, size and age of cached entries
+func (c *Cache) Stats() (Stats, error) {
+	stats := Stats{Dir: c.dir, MaxBytes: c.maxBytes, TTL: c.ttl}
+
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+	for _, f := range files {
+		stats.Entries++
+		stats.Bytes += f.size
+
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			continue
+		}
+		if c.expired(e.CreatedAt) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || e.CreatedAt.Before(stats.Oldest) {
+			stats.Oldest = e.CreatedAt
+		}
+		if e.CreatedAt.After(stats.Newest) {
+			stats.Newest = e.CreatedAt
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes every entry and returns how many were removed
+func (c *Cache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// cacheFile is an entry file found on disk
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the entry files in the cache directory
+func (c *Cache) files() ([]cacheFile, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var files []cacheFile
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{
+			path:    filepath.Join(c.dir, d.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// evict removes least recently used entries until the cache fits its cap
+func (c *Cache) evict() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// expired reports whether an entry created at the given time is past the TTL
+func (c *Cache) expired(createdAt time.Time) bool {
+	return c.ttl > 0 && c.now().Sub(createdAt) > c.ttl
+}
+
+// path returns the entry file for a key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
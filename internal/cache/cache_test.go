@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedExtension stands in for a provider's typed options
+type seedExtension struct {
+	Seed int `json:"seed"`
+}
+
+func (e *seedExtension) Provider() string { return "openai" }
+func (e *seedExtension) Validate() error  { return nil }
+
+func TestKey(t *testing.T) {
+	messages := []common.Message{{Role: "user", Content: "hello"}}
+	req := common.NewGenerateRequest(common.WithTemperature(0.2))
+
+	key, err := Key("openai", "gpt-4o", messages, req)
+	require.NoError(t, err)
+	assert.Len(t, key, 64)
+
+	t.Run("stable", func(t *testing.T) {
+		again, err := Key("openai", "gpt-4o", []common.Message{{Role: "user", Content: "hello"}}, common.NewGenerateRequest(common.WithTemperature(0.2)))
+		require.NoError(t, err)
+		assert.Equal(t, key, again)
+	})
+
+	t.Run("ignores streaming", func(t *testing.T) {
+		streamed := common.NewGenerateRequest(common.WithTemperature(0.2), common.WithStream())
+		streamed.OnChunk = func(common.StreamChunk) {}
+		again, err := Key("openai", "gpt-4o", messages, streamed)
+		require.NoError(t, err)
+		assert.Equal(t, key, again)
+	})
+
+	t.Run("varies with request", func(t *testing.T) {
+		seeded := common.NewGenerateRequest(common.WithTemperature(0.2))
+		seeded.Extension = &seedExtension{Seed: 7}
+		variants := []struct {
+			provider, model string
+			messages        []common.Message
+			req             *common.GenerateRequest
+		}{
+			{"anthropic", "gpt-4o", messages, req},
+			{"openai", "gpt-4o-mini", messages, req},
+			{"openai", "gpt-4o", []common.Message{{Role: "user", Content: "hello!"}}, req},
+			{"openai", "gpt-4o", messages, common.NewGenerateRequest(common.WithTemperature(0.9))},
+			{"openai", "gpt-4o", messages, seeded},
+			{"openai", "gpt-4o", []common.Message{{Role: "user", Content: "hello", Images: []common.Image{{MediaType: "image/png", Data: []byte{1}}}}}, req},
+			{"openai", "gpt-4o", []common.Message{{Role: "tool", Content: "hello", ToolName: "date"}}, req},
+		}
+		for _, v := range variants {
+			other, err := Key(v.provider, v.model, v.messages, v.req)
+			require.NoError(t, err)
+			assert.NotEqual(t, key, other, "variant %s %s", v.provider, v.model)
+		}
+	})
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	result := &common.GenerateResult{
+		Content:      "cached answer",
+		Model:        "gpt-4o-2024-08-06",
+		FinishReason: "stop",
+		Usage:        &common.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+	}
+	require.NoError(t, c.Put("k1", "openai", "gpt-4o", result))
+
+	got, ok := c.Get("k1")
+	require.True(t, ok)
+	assert.Equal(t, "cached answer", got.Content)
+	assert.Equal(t, "gpt-4o-2024-08-06", got.Model)
+	assert.Equal(t, 5, got.Usage.TotalTokens)
+
+	// no temp files are left behind
+	files, err := os.ReadDir(c.dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "k1.json", files[0].Name())
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := New(t.TempDir(), time.Hour, 0)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Put("k1", "openai", "gpt-4o", &common.GenerateResult{Content: "a"}))
+
+	now = now.Add(59 * time.Minute)
+	_, ok := c.Get("k1")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Expired)
+
+	_, ok = c.Get("k1")
+	assert.False(t, ok)
+	_, err = os.Stat(c.path("k1"))
+	assert.True(t, os.IsNotExist(err), "expired entry should be removed")
+
+	// a zero TTL never expires
+	forever := New(c.dir, 0, 0)
+	forever.now = func() time.Time { return now }
+	require.NoError(t, forever.Put("k2", "openai", "gpt-4o", &common.GenerateResult{Content: "b"}))
+	now = now.Add(24 * 365 * time.Hour)
+	_, ok = forever.Get("k2")
+	assert.True(t, ok)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("x", 400)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	c := New(dir, 0, 0)
+	c.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, c.Put(key, "openai", "gpt-4o", &common.GenerateResult{Content: content}))
+		// entries written in the same instant would tie on modification time
+		past := now.Add(-time.Hour)
+		require.NoError(t, os.Chtimes(c.path(key), past, past))
+		now = now.Add(time.Minute)
+	}
+
+	// cap the cache at three and a half entries
+	info, err := os.Stat(c.path("a"))
+	require.NoError(t, err)
+	c.maxBytes = info.Size()*3 + info.Size()/2
+
+	// reading "a" makes "b" the least recently used
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	require.NoError(t, c.Put("d", "openai", "gpt-4o", &common.GenerateResult{Content: content}))
+
+	_, err = os.Stat(filepath.Join(dir, "b.json"))
+	assert.True(t, os.IsNotExist(err), "least recently used entry should be evicted")
+	for _, key := range []string{"a", "c", "d"} {
+		_, ok := c.Get(key)
+		assert.True(t, ok, "entry %s should survive", key)
+	}
+
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, stats.Bytes, c.maxBytes)
+}
+
+func TestCache_StatsAndClear(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "cache"), time.Hour, 1024*1024)
+
+	// a cache that was never written is empty
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+
+	require.NoError(t, c.Put("k1", "openai", "gpt-4o", &common.GenerateResult{Content: "a"}))
+	require.NoError(t, c.Put("k2", "ollama", "llama3", &common.GenerateResult{Content: "b"}))
+
+	stats, err = c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Positive(t, stats.Bytes)
+	assert.Equal(t, int64(1024*1024), stats.MaxBytes)
+	assert.False(t, stats.Oldest.IsZero())
+
+	removed, err := c.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	stats, err = c.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// ensure Client can stand in for a provider client
+var _ common.LLM = (*Client)(nil)
+
+// Client wraps an LLM, answering repeated requests from the cache
+type Client struct {
+	llm          common.LLM
+	cache        *Cache
+	providerName string
+	logger       *slog.Logger
+}
+
+// NewClient wraps llm so identical requests to providerName are served from cache
+func NewClient(llm common.LLM, cache *Cache, providerName string, logger *slog.Logger) *Client {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Client{llm: llm, cache: cache, providerName: providerName, logger: logger}
+}
+
+// Generate returns the cached result for an identical earlier request, or
+// calls the wrapped LLM and caches its result. cache failures never fail the
+// request; they only cost a provider call
+func (c *Client) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	key, err := Key(c.providerName, modelName, messages, req)
+	if err != nil {
+		c.logger.Warn("Skipping response cache", "error", err)
+		return c.llm.Generate(ctx, messages, modelName, req)
+	}
+
+	if result, ok := c.cache.Get(key); ok {
+		c.logger.Info("Response cache hit", "provider", c.providerName, "model", modelName, "key", key)
+		result.Cached = true
+		return result, nil
+	}
+
+	result, err := c.llm.Generate(ctx, messages, modelName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.Put(key, c.providerName, modelName, result); err != nil {
+		c.logger.Warn("Failed to cache response", "error", err)
+	}
+	return result, nil
+}
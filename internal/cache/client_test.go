@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLLM answers every request and counts the calls
+type countingLLM struct {
+	calls int
+	err   error
+}
+
+func (c *countingLLM) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &common.GenerateResult{Content: "answer to " + messages[len(messages)-1].Content, Model: modelName}, nil
+}
+
+func TestClient_Generate(t *testing.T) {
+	llm := &countingLLM{}
+	client := NewClient(llm, New(t.TempDir(), time.Hour, 0), "openai", nil)
+	ctx := context.Background()
+	question := []common.Message{{Role: "user", Content: "why?"}}
+
+	first, err := client.Generate(ctx, question, "gpt-4o", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "answer to why?", first.Content)
+	assert.False(t, first.Cached)
+
+	second, err := client.Generate(ctx, question, "gpt-4o", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "answer to why?", second.Content)
+	assert.True(t, second.Cached)
+	assert.Equal(t, 1, llm.calls)
+
+	// a different request goes to the provider
+	_, err = client.Generate(ctx, []common.Message{{Role: "user", Content: "how?"}}, "gpt-4o", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, llm.calls)
+}
+
+func TestClient_GenerateErrorNotCached(t *testing.T) {
+	llm := &countingLLM{err: errors.New("rate limited")}
+	responseCache := New(t.TempDir(), time.Hour, 0)
+	client := NewClient(llm, responseCache, "openai", nil)
+	question := []common.Message{{Role: "user", Content: "why?"}}
+
+	_, err := client.Generate(context.Background(), question, "gpt-4o", nil)
+	assert.ErrorContains(t, err, "rate limited")
+
+	stats, err := responseCache.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+}
@@ -0,0 +1,172 @@
+// Package catalog caches the model names each provider reports from its
+// model-listing endpoint, so init and config set can suggest and validate
+// names without a network call.
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Listing is the set of models one provider reported
+type Listing struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Models    []string  `json:"models"`
+}
+
+// Has reports whether the listing contains name. Ollama reports tagged
+// names, so "llama3.2" matches a listed "llama3.2:latest"
+func (l Listing) Has(name string) bool {
+	for _, model := range l.Models {
+		if model == name || model == name+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog is a JSON file of listings keyed by provider name
+type Catalog struct {
+	path      string
+	Providers map[string]Listing `json:"providers"`
+}
+
+// DefaultPath returns the catalog location, ~/.slop/models.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".slop", "models.json"), nil
+}
+
+// Load reads the catalog at path. a missing file is an empty catalog
+func Load(path string) (*Catalog, error) {
+	c := &Catalog{path: path, Providers: make(map[string]Listing)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse model catalog %s: %w", path, err)
+	}
+	if c.Providers == nil {
+		c.Providers = make(map[string]Listing)
+	}
+	return c, nil
+}
+
+// Path returns the catalog file path
+func (c *Catalog) Path() string {
+	return c.path
+}
+
+// Get returns the cached listing for a provider
+func (c *Catalog) Get(provider string) (Listing, bool) {
+	listing, ok := c.Providers[provider]
+	return listing, ok
+}
+
+// Set replaces a provider's listing
+func (c *Catalog) Set(provider string, models []string, fetchedAt time.Time) {
+	c.Providers[provider] = Listing{FetchedAt: fetchedAt, Models: models}
+}
+
+// Save writes the catalog, replacing the file in one rename so a concurrent
+// reader never sees a partial write
+func (c *Catalog) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode model catalog: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create model catalog directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".models-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write model catalog: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write model catalog: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write model catalog: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write model catalog: %w", err)
+	}
+	return nil
+}
+
+// Suggest returns up to n models close to name: those containing it or
+// within a few edits of it, nearest first
+func Suggest(models []string, name string, n int) []string {
+	type candidate struct {
+		model    string
+		distance int
+	}
+
+	name = strings.ToLower(name)
+	maxDistance := max(2, len(name)/3)
+	var candidates []candidate
+	for _, model := range models {
+		lower := strings.ToLower(model)
+		distance := levenshtein(name, lower)
+		if name != "" && strings.Contains(lower, name) {
+			distance = min(distance, 1)
+		}
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{model, distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].model < candidates[j].model
+	})
+
+	suggestions := make([]string, 0, min(n, len(candidates)))
+	for _, c := range candidates {
+		if len(suggestions) == n {
+			break
+		}
+		suggestions = append(suggestions, c.model)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".slop", "models.json")
+
+	// a missing catalog loads as empty
+	c, err := Load(path)
+	require.NoError(t, err)
+	_, ok := c.Get("openai")
+	assert.False(t, ok)
+
+	fetched := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	c.Set("openai", []string{"gpt-4o", "gpt-4o-mini"}, fetched)
+	c.Set("ollama", []string{"llama3.2:latest"}, fetched)
+	require.NoError(t, c.Save())
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, reloaded.Path())
+	listing, ok := reloaded.Get("openai")
+	require.True(t, ok)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, listing.Models)
+	assert.True(t, listing.FetchedAt.Equal(fetched))
+
+	// replacing a listing keeps the others
+	reloaded.Set("openai", []string{"gpt-5"}, fetched.Add(time.Hour))
+	require.NoError(t, reloaded.Save())
+	again, err := Load(path)
+	require.NoError(t, err)
+	listing, _ = again.Get("openai")
+	assert.Equal(t, []string{"gpt-5"}, listing.Models)
+	_, ok = again.Get("ollama")
+	assert.True(t, ok)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "failed to parse model catalog")
+}
+
+func TestListing_Has(t *testing.T) {
+	listing := Listing{Models: []string{"gpt-4o", "llama3.2:latest", "gemma3:4b"}}
+
+	assert.True(t, listing.Has("gpt-4o"))
+	assert.True(t, listing.Has("llama3.2"))
+	assert.True(t, listing.Has("llama3.2:latest"))
+	assert.True(t, listing.Has("gemma3:4b"))
+	assert.False(t, listing.Has("gemma3"))
+	assert.False(t, listing.Has("gpt-4"))
+}
+
+func TestSuggest(t *testing.T) {
+	models := []string{"claude-haiku-4-5", "claude-opus-4-1", "claude-sonnet-4-5", "gpt-4o", "gpt-4o-mini"}
+
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, Suggest(models, "gpt-4", 3))
+	assert.Equal(t, []string{"claude-sonnet-4-5"}, Suggest(models, "claude-sonet-4-5", 1))
+	assert.Equal(t, []string{"claude-haiku-4-5", "claude-opus-4-1", "claude-sonnet-4-5"}, Suggest(models, "CLAUDE", 5))
+	assert.Empty(t, Suggest(models, "mistral-large", 3))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("gpt", "gpt"))
+	assert.Equal(t, 3, levenshtein("", "gpt"))
+	assert.Equal(t, 1, levenshtein("sonet", "sonnet"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
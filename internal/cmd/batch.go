@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chriscorrea/slop/internal/app"
+	"github.com/chriscorrea/slop/internal/batch"
+	slopContext "github.com/chriscorrea/slop/internal/context"
+	slopIO "github.com/chriscorrea/slop/internal/io"
+	"github.com/chriscorrea/slop/internal/template"
+
+	"github.com/spf13/cobra"
+)
+
+// createBatchCommand creates the batch command
+func createBatchCommand() *cobra.Command {
+	batchCmd := &cobra.Command{
+		Use:   "batch <command> [file]",
+		Short: "Run a named command over JSONL records",
+		Long: `Run a named command once for each JSONL record, several at a time.
+
+Records are read from the file, or from stdin. Each is rendered through the
+command's message template: {field} placeholders take the record's top-level
+fields, and {input} takes its "input" field, or the whole record when it has
+none. One JSON object is written per record, in input order:
+
+  {"index":0,"record":{"text":"great product"},"response":"positive","exit_code":0}
+  {"index":1,"record":{"text":"..."},"response":"","exit_code":1,"error":"..."}
+
+With --checkpoint, answered records are noted in a file; running the batch
+again with the same checkpoint answers only the records that failed or were
+not reached. Results already written are not repeated.
+
+Records run unattended, so tools that ask for confirmation are never run;
+the model is told the call was declined. Set confirm = false on a tool to
+let batches use it.
+
+Examples:
+  slop batch classify reviews.jsonl > labels.jsonl
+  cat tickets.jsonl | slop batch triage --concurrency 8
+  slop batch classify reviews.jsonl --checkpoint .slop/reviews.ckpt >> labels.jsonl`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runBatch,
+	}
+
+	batchCmd.Flags().Int("concurrency", 4, "Number of records answered at once")
+	batchCmd.Flags().String("checkpoint", "", "File noting answered records, so a rerun skips them")
+	return batchCmd
+}
+
+// runBatch answers a named command for each record and writes the results
+func runBatch(cmd *cobra.Command, args []string) error {
+	if state.manager == nil {
+		return fmt.Errorf("config manager not initialized")
+	}
+
+	cmdName := args[0]
+	baseConfig := state.manager.Config()
+	cmdConfig, exists := baseConfig.Commands[cmdName]
+	if !exists {
+		return fmt.Errorf("unknown command %q; see 'slop list'", cmdName)
+	}
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return fmt.Errorf("failed to get concurrency flag: %w", err)
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+	}
+	checkpointPath, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint flag: %w", err)
+	}
+
+	records, err := readBatchRecords(cmd.InOrStdin(), args[1:])
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no records: pipe JSONL on stdin or name a file")
+	}
+
+	var checkpoint *batch.Checkpoint
+	pending := records
+	if checkpointPath != "" {
+		checkpoint, err = batch.OpenCheckpoint(checkpointPath)
+		if err != nil {
+			return err
+		}
+		pending = nil
+		for _, record := range records {
+			if !checkpoint.Done(record) {
+				pending = append(pending, record)
+			}
+		}
+		if skipped := len(records) - len(pending); skipped > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Batch: skipping %d records answered in an earlier run\n", skipped)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+	}
+
+	// results are written whole, so nothing is streamed
+	cfg := baseConfig.WithCommandOverrides(cmdConfig)
+	cfg.Parameters.Stream = false
+
+	skipProjectContext, err := cmd.Flags().GetBool("ignore-context")
+	if err != nil {
+		return fmt.Errorf("failed to get ignore-context flag: %w", err)
+	}
+	contextResult, err := NewContextManager().ProcessContextWithFlags(cmd, cmdConfig.ContextFiles, skipProjectContext)
+	if err != nil {
+		return fmt.Errorf("failed to process context: %w", err)
+	}
+
+	providerName, modelName, fallbacks, err := selectModelForCommand(cmd, cfg, cmdName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to select model: %w", err)
+	}
+	warnDroppedFeatures(cmd.ErrOrStderr(), cfg, providerName, modelName)
+	exitMode := getExitMode(cmd, &cmdConfig)
+	hideThinking, _ := cmd.Flags().GetBool("hide-thinking")
+	showThinking, _ := cmd.Flags().GetBool("show-thinking")
+	if cmd.Flags().Changed("show-thinking") && showThinking {
+		hideThinking = false
+	}
+
+	// records run concurrently, so no spinner and no interactive prompts
+	appInstance := app.NewApp(cfg, state.logger, false).
+		WithOutput(io.Discard).
+		WithQuiet(true).
+		WithToolConfirm(declineToolConfirm).
+		WithFallbacks(fallbacks).
+		WithContextWindow(contextWindowFor(cfg, providerName, modelName))
+	if contextResult != nil && len(contextResult.RetrievalFiles) > 0 {
+		retriever, err := newProjectRetriever(cfg, contextResult, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		appInstance.WithRetriever(retriever)
+	}
+
+	var contextFiles []slopContext.ContextFile
+	if contextResult != nil {
+		contextFiles = contextResult.ContextFileContents
+	}
+	answer := func(ctx context.Context, record batch.Record) batch.Result {
+		result := batch.Result{Index: record.Index, Record: record.Data}
+		prompt, err := template.ProcessRecord(cmdConfig.MessageTemplate, record.Data)
+		if err == nil {
+			input := &slopIO.StructuredInput{
+				CommandContext: cmdConfig.Context,
+				ContextFiles:   contextFiles,
+				CLIArgs:        prompt,
+			}
+			var run *app.Result
+			run, err = appInstance.RunInput(ctx, input, contextResult, providerName, modelName, "", exitMode, hideThinking, showThinking)
+			if err == nil {
+				recordUsage(run.Provider, run.Model, cmdName, run)
+				result.Response, result.ExitCode = run.Output, run.ExitCode
+			}
+		}
+		if err != nil {
+			result.Error, result.ExitCode = err.Error(), 1
+		}
+		return result
+	}
+
+	failed, err := writeBatch(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), pending, concurrency, checkpoint, answer)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		if checkpoint != nil {
+			return fmt.Errorf("%d of %d records failed; run again with --checkpoint %s to retry them", failed, len(pending), checkpoint.Path())
+		}
+		return fmt.Errorf("%d of %d records failed; use --checkpoint to retry only the failures", failed, len(pending))
+	}
+	return nil
+}
+
+// declineToolConfirm stands in for the terminal prompt in batch runs, where
+// concurrent records would race for /dev/tty
+func declineToolConfirm(name, _ string) (bool, error) {
+	return false, fmt.Errorf("batch runs can't confirm tools (set confirm = false on %s to allow it unattended)", name)
+}
+
+// readBatchRecords reads records from the named file, or from stdin
+func readBatchRecords(stdin io.Reader, files []string) ([]batch.Record, error) {
+	if len(files) == 0 {
+		return batch.Read(stdin)
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to open records: %w", err)
+	}
+	defer file.Close()
+
+	records, err := batch.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", files[0], err)
+	}
+	return records, nil
+}
+
+// writeBatch answers the records and writes a JSONL result for each, in
+// input order, noting answered records in the checkpoint and progress on
+// stderr. it returns how many records failed
+func writeBatch(ctx context.Context, w, progress io.Writer, records []batch.Record, concurrency int, checkpoint *batch.Checkpoint, answer batch.AnswerFunc) (int, error) {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	done, failed := 0, 0
+	emit := func(result batch.Result) error {
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to write result: %w", err)
+		}
+		done++
+		if result.Failed() {
+			failed++
+		} else if checkpoint != nil {
+			if err := checkpoint.Mark(batch.Record{Index: result.Index, Data: result.Record}); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(progress, "\rBatch: %d/%d records (%d failed)", done, len(records), failed)
+		return nil
+	}
+
+	fmt.Fprintf(progress, "Batch: 0/%d records", len(records))
+	err := batch.Run(ctx, records, concurrency, answer, emit)
+	fmt.Fprintln(progress)
+	return failed, err
+}
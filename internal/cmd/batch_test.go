@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/batch"
+)
+
+func TestReadBatchRecords(t *testing.T) {
+	records, err := readBatchRecords(strings.NewReader("{\"text\":\"a\"}\n\n{\"text\":\"b\"}\n"), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[1].Index != 1 {
+		t.Errorf("Expected 2 records from stdin, got %+v", records)
+	}
+
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	if err := os.WriteFile(path, []byte("{\"text\":\"a\"}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBatchRecords(strings.NewReader(""), []string{path}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected line 2 error, got %v", err)
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	records, err := batch.Read(strings.NewReader("{\"text\":\"good\"}\n{\"text\":\"bad\"}\n{\"text\":\"<ok>\"}\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkpoint, err := batch.OpenCheckpoint(filepath.Join(t.TempDir(), "checkpoint.jsonl"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	answer := func(ctx context.Context, record batch.Record) batch.Result {
+		result := batch.Result{Index: record.Index, Record: record.Data, Response: "answered"}
+		if strings.Contains(string(record.Data), "bad") {
+			result.Response, result.ExitCode, result.Error = "", 1, "provider unavailable"
+		}
+		return result
+	}
+
+	var out, progress bytes.Buffer
+	failed, err := writeBatch(context.Background(), &out, &progress, records, 2, checkpoint, answer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed record, got %d", failed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 result lines, got %q", out.String())
+	}
+	if !strings.Contains(lines[2], `"text":"<ok>"`) {
+		t.Errorf("Expected records written without HTML escaping, got %s", lines[2])
+	}
+	for i, line := range lines {
+		var result batch.Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("Result %d is not JSON: %v", i, err)
+		}
+		if result.Index != i {
+			t.Errorf("Expected result %d in input order, got index %d", i, result.Index)
+		}
+	}
+	if !strings.Contains(progress.String(), "3/3 records (1 failed)") {
+		t.Errorf("Expected progress on stderr, got %q", progress.String())
+	}
+
+	// only answered records are checkpointed
+	for i, want := range []bool{true, false, true} {
+		if got := checkpoint.Done(records[i]); got != want {
+			t.Errorf("Expected record %d done=%v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestDeclineToolConfirm(t *testing.T) {
+	approved, err := declineToolConfirm("deploy", `{}`)
+	if approved {
+		t.Error("Expected batch runs to decline tool calls")
+	}
+	if err == nil || !strings.Contains(err.Error(), "confirm = false on deploy") {
+		t.Errorf("Expected an error naming the confirm setting, got %v", err)
+	}
+}
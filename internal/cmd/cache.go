@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/cache"
+
+	"github.com/spf13/cobra"
+)
+
+// createCacheCommand creates the response cache command with subcommands
+func createCacheCommand() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the response cache",
+		Long: `Inspect or clear the on-disk response cache in ~/.slop/cache.
+
+Enable the cache with --cache or parameters.cache = true. Identical requests
+(same provider, model, messages and generation options) are then answered from
+disk until the entry is older than parameters.cache_ttl. The least recently
+used entries are evicted once the cache exceeds parameters.cache_max_mb.`,
+	}
+
+	cacheCmd.AddCommand(createCacheStatsCommand())
+	cacheCmd.AddCommand(createCacheClearCommand())
+
+	return cacheCmd
+}
+
+// createCacheStatsCommand creates the 'cache stats' subcommand
+func createCacheStatsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show response cache size and age",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			responseCache, err := configuredCache()
+			if err != nil {
+				return err
+			}
+			stats, err := responseCache.Stats()
+			if err != nil {
+				return err
+			}
+			printCacheStats(cmd.OutOrStdout(), stats)
+			return nil
+		},
+	}
+}
+
+// createCacheClearCommand creates the 'cache clear' subcommand
+func createCacheClearCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cached response",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			responseCache, err := configuredCache()
+			if err != nil {
+				return err
+			}
+			removed, err := responseCache.Clear()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached response(s)\n", removed)
+			return nil
+		},
+	}
+}
+
+// configuredCache opens the response cache with the configured TTL and size cap
+func configuredCache() (*cache.Cache, error) {
+	if state.manager == nil {
+		return nil, fmt.Errorf("config manager not initialized")
+	}
+	params := state.manager.Config().Parameters
+
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir, params.CacheTTLDuration(), int64(params.CacheMaxMB)*1024*1024), nil
+}
+
+// printCacheStats writes the cache stats as an aligned table
+func printCacheStats(w io.Writer, stats cache.Stats) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	limit := "none"
+	if stats.MaxBytes > 0 {
+		limit = formatBytes(stats.MaxBytes)
+	}
+	ttl := "never expires"
+	if stats.TTL > 0 {
+		ttl = stats.TTL.String()
+	}
+
+	fmt.Fprintf(tw, "Location:\t%s\n", stats.Dir)
+	fmt.Fprintf(tw, "Entries:\t%d (%d expired)\n", stats.Entries, stats.Expired)
+	fmt.Fprintf(tw, "Size:\t%s of %s\n", formatBytes(stats.Bytes), limit)
+	fmt.Fprintf(tw, "TTL:\t%s\n", ttl)
+	if stats.Entries > 0 && !stats.Oldest.IsZero() {
+		fmt.Fprintf(tw, "Oldest:\t%s\n", stats.Oldest.Local().Format(time.DateTime))
+		fmt.Fprintf(tw, "Newest:\t%s\n", stats.Newest.Local().Format(time.DateTime))
+	}
+	tw.Flush()
+}
+
+// formatBytes renders a byte count in the largest whole unit
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024*1024:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1024*1024*1024))
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
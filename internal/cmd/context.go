@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
 
+	"github.com/chriscorrea/slop/internal/app"
+	"github.com/chriscorrea/slop/internal/config"
 	slopContext "github.com/chriscorrea/slop/internal/context"
+	"github.com/chriscorrea/slop/internal/data"
+	"github.com/chriscorrea/slop/internal/llm/common"
 	"github.com/chriscorrea/slop/internal/manifest"
 	"github.com/chriscorrea/slop/internal/parser"
+	"github.com/chriscorrea/slop/internal/retrieval"
 
 	"github.com/spf13/cobra"
 )
@@ -50,6 +57,17 @@ func (c *DefaultContextManager) ProcessContextWithFlags(cmd *cobra.Command, addi
 		}
 	}
 
+	// with --retrieve the project files are held back; the app sends only
+	// the indexed chunks most relevant to the prompt
+	retrieveCount, err := getRetrieveCount(cmd)
+	if err != nil {
+		return nil, err
+	}
+	var retrievalFiles []slopContext.ContextFile
+	if retrieveCount > 0 {
+		retrievalFiles, projectContextFiles = projectContextFiles, nil
+	}
+
 	// merge CLI context files with command context files
 	allContextFiles := make([]string, 0, len(cliContextFiles)+len(additionalContextFiles))
 	allContextFiles = append(allContextFiles, cliContextFiles...)
@@ -63,6 +81,7 @@ func (c *DefaultContextManager) ProcessContextWithFlags(cmd *cobra.Command, addi
 	contextFileContents = append(contextFileContents, projectContextFiles...)
 	for _, contextFile := range projectContextFiles {
 		processedItem := c.processContextFile(contextFile.Path, contextFile.Content, state.logger)
+		processedItem.Project = true
 		processedItems = append(processedItems, processedItem)
 	}
 
@@ -72,6 +91,23 @@ func (c *DefaultContextManager) ProcessContextWithFlags(cmd *cobra.Command, addi
 			continue
 		}
 
+		// images become content parts rather than text
+		if common.IsImageFile(filePath) {
+			image, err := common.LoadImage(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read context file %q: %w", filePath, err)
+			}
+			if state.logger != nil {
+				state.logger.Debug("Context file detected as image", "file", filePath, "media_type", image.MediaType, "bytes", len(image.Data))
+			}
+			processedItems = append(processedItems, slopContext.ContextItem{
+				Path:  filePath,
+				Type:  "image",
+				Image: &image,
+			})
+			continue
+		}
+
 		content, err := os.ReadFile(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read context file %q: %w", filePath, err)
@@ -91,15 +127,93 @@ func (c *DefaultContextManager) ProcessContextWithFlags(cmd *cobra.Command, addi
 		}
 	}
 
+	// images attached to the prompt with --image
+	images, err := loadPromptImages(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return &slopContext.ContextResult{
 		AllContextFiles:     allContextFiles,
 		CLIContextFiles:     cliContextFiles,
 		CmdContextFiles:     additionalContextFiles,
 		ContextFileContents: contextFileContents,
 		ProcessedItems:      processedItems,
+		Images:              images,
+		RetrievalFiles:      retrievalFiles,
+		RetrieveCount:       retrieveCount,
+	}, nil
+}
+
+// getRetrieveCount reads --retrieve, the number of project context chunks
+// to send in place of the full files (0 sends the files)
+func getRetrieveCount(cmd *cobra.Command) (int, error) {
+	// commands built without the flag (e.g. in tests) send the files
+	if cmd.Flags().Lookup("retrieve") == nil {
+		return 0, nil
+	}
+	count, err := cmd.Flags().GetInt("retrieve")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get retrieve flag: %w", err)
+	}
+	if count < 0 {
+		return 0, fmt.Errorf("invalid --retrieve %d: must not be negative", count)
+	}
+	return count, nil
+}
+
+// newProjectRetriever loads the project's context index and returns the
+// app hook that embeds a prompt with the index's model and picks the
+// closest chunks. files changed since indexing are reported on w
+func newProjectRetriever(cfg *config.Config, contextResult *slopContext.ContextResult, w io.Writer) (app.RetrieveFunc, error) {
+	_, projectRoot, err := manifest.NewManifestManager("").FindManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find manifest: %w", err)
+	}
+	index, err := retrieval.Load(retrieval.DefaultPath(projectRoot))
+	if err != nil {
+		return nil, fmt.Errorf("--retrieve: %w", err)
+	}
+
+	if stale := index.Stale(contextResult.RetrievalFiles); len(stale) > 0 {
+		fmt.Fprintf(w, "Warning: %d project context file(s) changed since indexing; run 'slop context index' to include the changes\n", len(stale))
+	}
+
+	count := contextResult.RetrieveCount
+	return func(ctx context.Context, query string) ([]slopContext.Chunk, error) {
+		vectors, err := embedTexts(ctx, cfg, index.Provider, index.Model, []string{query}, common.EmbedQuery)
+		if err != nil {
+			return nil, err
+		}
+		return index.Search(vectors[0], count), nil
 	}, nil
 }
 
+// loadPromptImages reads the files passed with --image
+func loadPromptImages(cmd *cobra.Command) ([]common.Image, error) {
+	// commands built without the flag (e.g. in tests) simply have no images
+	if cmd.Flags().Lookup("image") == nil {
+		return nil, nil
+	}
+	paths, err := cmd.Flags().GetStringSlice("image")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image flag: %w", err)
+	}
+
+	var images []common.Image
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		image, err := common.LoadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
 // processContextFile intelligently processes a context file, detecting conversations vs regular files
 func (c *DefaultContextManager) processContextFile(path string, content string, logger *slog.Logger) slopContext.ContextItem {
 	// try JSON parsing first
@@ -140,3 +254,18 @@ func (c *DefaultContextManager) processContextFile(path string, content string,
 		Content: content,
 	}
 }
+
+// contextWindowFor returns the context window input is budgeted against:
+// parameters.context_window when set, else the window listed for the model.
+// 0, for models with no known window, skips budgeting
+func contextWindowFor(cfg *config.Config, providerName, modelName string) int {
+	if cfg.Parameters.ContextWindow > 0 {
+		return cfg.Parameters.ContextWindow
+	}
+	models := data.NewProviderRegistry()
+	if err := models.Load(); err != nil {
+		return 0
+	}
+	window, _ := models.GetContextWindow(providerName, modelName)
+	return window
+}
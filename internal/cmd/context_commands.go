@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/chriscorrea/slop/internal/llm/common"
 	"github.com/chriscorrea/slop/internal/manifest"
+	"github.com/chriscorrea/slop/internal/retrieval"
 
 	"github.com/spf13/cobra"
 )
@@ -22,6 +26,7 @@ func createContextCommand() *cobra.Command {
 	contextCmd.AddCommand(createContextAddCommand())
 	contextCmd.AddCommand(createContextListCommand())
 	contextCmd.AddCommand(createContextClearCommand())
+	contextCmd.AddCommand(createContextIndexCommand())
 
 	return contextCmd
 }
@@ -152,3 +157,79 @@ func createContextClearCommand() *cobra.Command {
 		},
 	}
 }
+
+// createContextIndexCommand creates the 'context index' subcommand
+func createContextIndexCommand() *cobra.Command {
+	indexCmd := &cobra.Command{
+		Use:   "index",
+		Short: "Chunk and embed the context files for --retrieve",
+		Long: `Split the files in the context manifest into chunks, embed them and store the
+vectors in .slop/index.json. With --retrieve k, a prompt is then sent only the
+k chunks most similar to it instead of every file in full.
+
+Run it again after the files change: unchanged files keep their chunks, so only
+changed files are embedded again. The model comes from models.remote.embed, or
+models.local.embed with --local; changing it rebuilds the whole index.
+
+Examples:
+  slop context index
+  slop --retrieve 5 "how do retries work?"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if state.manager == nil {
+				return fmt.Errorf("config manager not initialized")
+			}
+			cfg := state.manager.Config()
+
+			manager := manifest.NewManifestManager("")
+			manifestPath, projectRoot, err := manager.FindManifest()
+			if err != nil {
+				return fmt.Errorf("failed to find manifest: %w", err)
+			}
+			if manifestPath == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), "No context manifest found. Use 'slop context add' to create one in current directory.")
+				return nil
+			}
+			files, err := manager.LoadProjectContext()
+			if err != nil {
+				return fmt.Errorf("failed to load project context: %w", err)
+			}
+
+			providerName, modelName, err := selectEmbedModel(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			path := retrieval.DefaultPath(projectRoot)
+			index, err := retrieval.Load(path)
+			if err != nil && !errors.Is(err, retrieval.ErrNoIndex) {
+				return err
+			}
+			rebuild, _ := cmd.Flags().GetBool("rebuild")
+			if index == nil || rebuild || index.Provider != providerName || index.Model != modelName {
+				index = retrieval.New(path, providerName, modelName)
+			}
+
+			stats, err := index.Update(cmd.Context(), files, retrieval.DefaultChunkChars, func(ctx context.Context, texts []string) ([][]float64, error) {
+				return embedTexts(ctx, cfg, providerName, modelName, texts, common.EmbedDocument)
+			})
+			if err != nil {
+				return err
+			}
+			if err := index.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d file(s) as %d chunks with %s/%s (%d embedded", stats.Files, stats.Chunks, providerName, modelName, stats.Embedded)
+			if stats.Removed > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), ", %d file(s) removed", stats.Removed)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), ")\nUse --retrieve k to send the k most relevant chunks\n")
+			return nil
+		},
+	}
+
+	indexCmd.Flags().Bool("rebuild", false, "Embed every file again, even unchanged ones")
+
+	return indexCmd
+}
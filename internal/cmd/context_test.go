@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/chriscorrea/slop/internal/config"
 	slopContext "github.com/chriscorrea/slop/internal/context"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/manifest"
+	"github.com/chriscorrea/slop/internal/retrieval"
 
 	"github.com/spf13/cobra"
 )
@@ -243,3 +249,159 @@ func TestContextResult_HelperMethods(t *testing.T) {
 		})
 	}
 }
+
+// TestProcessContext_Images tests image detection in --context and the --image flag
+func TestProcessContext_Images(t *testing.T) {
+	tempDir := t.TempDir()
+	plans := filepath.Join(tempDir, "plans.png")
+	photo := filepath.Join(tempDir, "windmill.jpg")
+	notes := filepath.Join(tempDir, "notes.txt")
+	for path, content := range map[string]string{plans: "\x89PNG", photo: "\xff\xd8", notes: "four legs good"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", path, err)
+		}
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringSlice("context", []string{}, "context files")
+	cmd.Flags().StringSlice("image", []string{}, "image files")
+	if err := cmd.Flags().Set("context", plans+","+notes); err != nil {
+		t.Fatalf("Failed to set context flag: %v", err)
+	}
+	if err := cmd.Flags().Set("image", photo); err != nil {
+		t.Fatalf("Failed to set image flag: %v", err)
+	}
+
+	result, err := NewContextManager().ProcessContextWithFlags(cmd, nil, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.ProcessedItems) != 2 {
+		t.Fatalf("Expected 2 processed items, got %d", len(result.ProcessedItems))
+	}
+	image := result.ProcessedItems[0]
+	if image.Type != "image" || image.Image == nil || image.Image.MediaType != "image/png" {
+		t.Errorf("Expected plans.png to be processed as a png image, got %+v", image)
+	}
+	if result.ProcessedItems[1].Type != "file" {
+		t.Errorf("Expected notes.txt to be processed as a file, got %q", result.ProcessedItems[1].Type)
+	}
+
+	// images are not duplicated as text context
+	if len(result.ContextFileContents) != 1 || result.ContextFileContents[0].Path != notes {
+		t.Errorf("Expected only notes.txt as text context, got %+v", result.ContextFileContents)
+	}
+
+	if len(result.Images) != 1 || result.Images[0].MediaType != "image/jpeg" {
+		t.Errorf("Expected windmill.jpg from --image, got %+v", result.Images)
+	}
+}
+
+func TestProcessContext_Retrieve(t *testing.T) {
+	projectDir := t.TempDir()
+	t.Chdir(projectDir)
+	rules := filepath.Join(projectDir, "rules.md")
+	if err := os.WriteFile(rules, []byte("All animals are equal.\n\nNo animal shall sleep in a bed."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager := manifest.NewManifestManager(projectDir)
+	if err := manager.AddPaths(manager.GetManifestPath(), []string{"rules.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringSlice("context", []string{}, "context files")
+	cmd.Flags().Int("retrieve", 0, "chunks to retrieve")
+
+	// without --retrieve the files are sent, marked as project context
+	result, err := NewContextManager().ProcessContextWithFlags(cmd, nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ProcessedItems) != 1 || !result.ProcessedItems[0].Project {
+		t.Fatalf("Expected rules.md as a project item, got %+v", result.ProcessedItems)
+	}
+
+	if err := cmd.Flags().Set("retrieve", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = NewContextManager().ProcessContextWithFlags(cmd, nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.ProcessedItems) != 0 || len(result.ContextFileContents) != 0 {
+		t.Errorf("Expected project files held back from the prompt, got %+v", result.ProcessedItems)
+	}
+	if len(result.RetrievalFiles) != 1 || result.RetrievalFiles[0].Path != rules || result.RetrieveCount != 1 {
+		t.Fatalf("Expected rules.md held for retrieval, got %+v", result)
+	}
+
+	cfg := &config.Config{}
+	var warnings bytes.Buffer
+	if _, err := newProjectRetriever(cfg, result, &warnings); err == nil || !strings.Contains(err.Error(), "slop context index") {
+		t.Errorf("Expected a missing index to point at slop context index, got %v", err)
+	}
+
+	index := retrieval.New(retrieval.DefaultPath(projectDir), "mock", "test-embed")
+	embed := func(ctx context.Context, texts []string) ([][]float64, error) {
+		return embedTexts(ctx, cfg, "mock", "test-embed", texts, common.EmbedDocument)
+	}
+	if _, err := index.Update(context.Background(), result.RetrievalFiles, retrieval.DefaultChunkChars, embed); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	retrieve, err := newProjectRetriever(cfg, result, &warnings)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("Expected no stale warning, got %q", warnings.String())
+	}
+	chunks, err := retrieve(context.Background(), "may animals sleep in beds?")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Path != rules {
+		t.Errorf("Expected one chunk of rules.md, got %+v", chunks)
+	}
+
+	// a file edited after indexing is reported
+	result.RetrievalFiles[0].Content += "\nFour legs good."
+	if _, err := newProjectRetriever(cfg, result, &warnings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "1 project context file(s) changed since indexing") {
+		t.Errorf("Expected a stale warning, got %q", warnings.String())
+	}
+
+	// a negative count is rejected
+	if err := cmd.Flags().Set("retrieve", "-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContextManager().ProcessContextWithFlags(cmd, nil, false); err == nil {
+		t.Error("Expected a negative --retrieve to fail")
+	}
+}
+
+func TestContextWindowFor(t *testing.T) {
+	cfg := &config.Config{}
+	if window := contextWindowFor(cfg, "openai", "gpt-4o"); window != 128000 {
+		t.Errorf("Expected the listed 128000-token window, got %d", window)
+	}
+	if window := contextWindowFor(cfg, "ollama", "gemma3:4b"); window != 4096 {
+		t.Errorf("Expected Ollama's default 4096-token window, got %d", window)
+	}
+	if window := contextWindowFor(cfg, "lmstudio", "qwen2.5-7b-instruct"); window != 0 {
+		t.Errorf("Expected no window for an unlisted provider, got %d", window)
+	}
+
+	cfg.Parameters.ContextWindow = 8192
+	if window := contextWindowFor(cfg, "openai", "gpt-4o"); window != 8192 {
+		t.Errorf("Expected parameters.context_window to override, got %d", window)
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/registry"
+
+	"github.com/spf13/cobra"
+)
+
+// embedBatchSize is the most inputs sent per embedding request; Cohere, the
+// strictest provider, accepts 96
+const embedBatchSize = 96
+
+// embedInput is one text to embed and where it came from
+type embedInput struct {
+	source string // file path; empty for stdin lines
+	text   string
+}
+
+// embedRecord is one line of slop embed output
+type embedRecord struct {
+	Index  int       `json:"index"`
+	Source string    `json:"source,omitempty"`
+	Text   string    `json:"text,omitempty"`
+	Vector []float64 `json:"vector"`
+}
+
+// createEmbedCommand creates the embed command
+func createEmbedCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "embed [file...]",
+		Short: "Write embedding vectors as JSONL",
+		Long: `Turn text into embedding vectors for deduplication, clustering or search.
+
+Each line of stdin is embedded separately; each file argument is embedded as a
+whole. One JSON object is written per input, in input order:
+
+  {"index":0,"text":"first line","vector":[0.012,-0.034,...]}
+  {"index":1,"source":"notes.md","vector":[...]}
+
+The model comes from models.remote.embed, or models.local.embed with --local
+(or parameters.default_location = "local"). --model picks another one.
+
+Examples:
+  cat titles.txt | slop embed > vectors.jsonl
+  slop embed --local docs/*.md
+  slop embed -m openai/text-embedding-3-small < titles.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if state.manager == nil {
+				return fmt.Errorf("config manager not initialized")
+			}
+			cfg := state.manager.Config()
+
+			providerName, modelName, err := selectEmbedModel(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			var inputs []embedInput
+			if len(args) == 0 {
+				inputs, err = readEmbedLines(cmd.InOrStdin())
+			} else {
+				inputs, err = readEmbedFiles(args)
+			}
+			if err != nil {
+				return err
+			}
+			if len(inputs) == 0 {
+				return fmt.Errorf("nothing to embed: pipe text lines on stdin or name files")
+			}
+
+			return writeEmbeddings(cmd.Context(), cmd.OutOrStdout(), cfg, providerName, modelName, inputs)
+		},
+	}
+}
+
+// selectEmbedModel picks the embedding slot for the --local/--remote
+// preference, letting --model override it as it does for generation
+func selectEmbedModel(cmd *cobra.Command, cfg *config.Config) (providerName, modelName string, err error) {
+	if testFlag, _ := cmd.Flags().GetBool("test"); testFlag {
+		return "mock", "test-embed", nil
+	}
+
+	useLocal, _ := NewModelSelector().preferences(cmd, cfg)
+	slot, path := cfg.Models.Remote.Embed, "models.remote.embed"
+	if useLocal {
+		slot, path = cfg.Models.Local.Embed, "models.local.embed"
+	}
+
+	if model, _ := cmd.Flags().GetString("model"); model != "" {
+		providerName, modelName = splitModelFlag(model, slot.Provider)
+		if providerName == "" {
+			return "", "", fmt.Errorf("failed to select embedding model: --model %q names no provider and %s.provider is not set", model, path)
+		}
+		return providerName, modelName, nil
+	}
+
+	if slot.Provider == "" || slot.Name == "" {
+		return "", "", fmt.Errorf(`Embedding model configuration missing.
+
+Configure a model with:
+  slop config set %s.provider=PROVIDER_NAME
+  slop config set %s.name=MODEL_NAME`, path, path)
+	}
+	return slot.Provider, slot.Name, nil
+}
+
+// readEmbedLines returns each non-blank line of r as an input
+func readEmbedLines(r io.Reader) ([]embedInput, error) {
+	var inputs []embedInput
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		inputs = append(inputs, embedInput{text: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return inputs, nil
+}
+
+// readEmbedFiles returns each file's content as an input
+func readEmbedFiles(paths []string) ([]embedInput, error) {
+	inputs := make([]embedInput, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			return nil, fmt.Errorf("%s is empty", path)
+		}
+		inputs = append(inputs, embedInput{source: path, text: string(data)})
+	}
+	return inputs, nil
+}
+
+// writeEmbeddings embeds the inputs in batches and writes a record per input
+func writeEmbeddings(ctx context.Context, w io.Writer, cfg *config.Config, providerName, modelName string, inputs []embedInput) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	encoder := json.NewEncoder(w)
+	for start := 0; start < len(inputs); start += embedBatchSize {
+		batch := inputs[start:min(start+embedBatchSize, len(inputs))]
+		texts := make([]string, len(batch))
+		for i, input := range batch {
+			texts[i] = input.text
+		}
+
+		result, err := embedWithPull(ctx, cfg, providerName, modelName, texts, common.EmbedClustering)
+		if err != nil {
+			return err
+		}
+
+		for i, input := range batch {
+			record := embedRecord{Index: start + i, Source: input.source, Vector: result.Vectors[i]}
+			if input.source == "" {
+				record.Text = input.text
+			}
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("failed to write embedding: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// embedTexts embeds any number of texts in batches for the use inputType
+// names, returning a vector per text
+func embedTexts(ctx context.Context, cfg *config.Config, providerName, modelName string, texts []string, inputType common.EmbedInputType) ([][]float64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		result, err := embedWithPull(ctx, cfg, providerName, modelName, texts[start:min(start+embedBatchSize, len(texts))], inputType)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, result.Vectors...)
+	}
+	return vectors, nil
+}
+
+// embedWithPull embeds one batch. a model missing from the Ollama server may
+// be pulled, as for generation, and the batch retried
+func embedWithPull(ctx context.Context, cfg *config.Config, providerName, modelName string, texts []string, inputType common.EmbedInputType) (*common.EmbedResult, error) {
+	result, err := embedBatch(ctx, cfg, providerName, modelName, texts, inputType)
+	if errors.Is(err, common.ErrModelNotFound) {
+		if pulled, _ := promptPullModel(ctx, providerName, modelName); pulled {
+			result, err = embedBatch(ctx, cfg, providerName, modelName, texts, inputType)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, common.ErrEmbeddingUnsupported) {
+			return nil, fmt.Errorf("%w; choose a provider that embeds text with models.*.embed or --model", err)
+		}
+		return nil, fmt.Errorf("failed to embed with %s/%s: %w", providerName, modelName, err)
+	}
+	return result, nil
+}
+
+// embedBatch sends one embedding request, bounded by parameters.timeout
+func embedBatch(ctx context.Context, cfg *config.Config, providerName, modelName string, texts []string, inputType common.EmbedInputType) (*common.EmbedResult, error) {
+	if cfg.Parameters.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Parameters.Timeout)*time.Second)
+		defer cancel()
+	}
+	return registry.Embed(ctx, providerName, cfg, modelName, texts, inputType)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSelectEmbedModel(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+
+	newCmd := func(model string, flags ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		for _, name := range []string{"test", "local", "remote", "fast", "deep"} {
+			cmd.Flags().Bool(name, false, "")
+		}
+		cmd.Flags().String("model", model, "")
+		for _, flag := range flags {
+			if err := cmd.Flags().Set(flag, "true"); err != nil {
+				t.Fatalf("Failed to set --%s: %v", flag, err)
+			}
+		}
+		return cmd
+	}
+
+	tests := []struct {
+		name             string
+		cmd              *cobra.Command
+		expectedProvider string
+		expectedModel    string
+	}{
+		{"remote slot", newCmd(""), "mistral", "mistral-embed"},
+		{"local slot", newCmd("", "local"), "ollama", "nomic-embed-text"},
+		{"model on the slot provider", newCmd("mxbai-embed-large", "local"), "ollama", "mxbai-embed-large"},
+		{"provider and model", newCmd("openai/text-embedding-3-small"), "openai", "text-embedding-3-small"},
+		{"test mode", newCmd("", "test"), "mock", "test-embed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providerName, modelName, err := selectEmbedModel(tt.cmd, cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if providerName != tt.expectedProvider || modelName != tt.expectedModel {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.expectedProvider, tt.expectedModel, providerName, modelName)
+			}
+		})
+	}
+
+	empty := config.NewDefaultFromEmbedded()
+	empty.Models.Remote.Embed = config.Embed{}
+	if _, _, err := selectEmbedModel(newCmd(""), empty); err == nil || !strings.Contains(err.Error(), "models.remote.embed.provider") {
+		t.Errorf("Expected missing slot error, got %v", err)
+	}
+}
+
+func TestReadEmbedInputs(t *testing.T) {
+	lines, err := readEmbedLines(strings.NewReader("first\r\n\n  \nsecond\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 2 || lines[0].text != "first" || lines[1].text != "second" {
+		t.Errorf("Expected blank lines skipped, got %+v", lines)
+	}
+
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(notes, []byte("line one\nline two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := readEmbedFiles([]string{notes})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].source != notes || files[0].text != "line one\nline two\n" {
+		t.Errorf("Expected the whole file as one input, got %+v", files)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readEmbedFiles([]string{empty}); err == nil {
+		t.Error("Expected an empty file to be rejected")
+	}
+}
+
+func TestWriteEmbeddings(t *testing.T) {
+	t.Run("batches in input order", func(t *testing.T) {
+		inputs := make([]embedInput, embedBatchSize+2)
+		for i := range inputs {
+			inputs[i] = embedInput{text: strings.Repeat("x", i+1)}
+		}
+		inputs[0].source = "a.txt"
+
+		var out bytes.Buffer
+		if err := writeEmbeddings(context.Background(), &out, &config.Config{}, "mock", "test-embed", inputs); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != len(inputs) {
+			t.Fatalf("Expected %d records, got %d", len(inputs), len(lines))
+		}
+		for i, line := range lines {
+			var record embedRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("Record %d is not JSON: %v", i, err)
+			}
+			if record.Index != i || len(record.Vector) == 0 {
+				t.Errorf("Unexpected record %d: %+v", i, record)
+			}
+		}
+		if !strings.Contains(lines[0], `"source":"a.txt"`) || strings.Contains(lines[0], `"text"`) {
+			t.Errorf("Expected a file record to carry its source, not its text: %s", lines[0])
+		}
+		if !strings.Contains(lines[1], `"text":"xx"`) {
+			t.Errorf("Expected a line record to carry its text: %s", lines[1])
+		}
+	})
+
+	t.Run("provider without embeddings", func(t *testing.T) {
+		err := writeEmbeddings(context.Background(), &bytes.Buffer{}, &config.Config{}, "groq", "llama", []embedInput{{text: "a"}})
+		if err == nil || !strings.Contains(err.Error(), "embeddings are not supported") {
+			t.Errorf("Expected unsupported error, got %v", err)
+		}
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"input too long"}}`))
+		}))
+		defer server.Close()
+
+		cfg := &config.Config{}
+		cfg.Providers.OpenAI.APIKey = "test-key"
+		cfg.Providers.OpenAI.BaseUrl = server.URL
+		err := writeEmbeddings(context.Background(), &bytes.Buffer{}, cfg, "openai", "text-embedding-3-small", []embedInput{{text: "a"}})
+		if err == nil || !strings.Contains(err.Error(), "openai/text-embedding-3-small") {
+			t.Errorf("Expected the failing model in the error, got %v", err)
+		}
+	})
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/chriscorrea/slop/internal/catalog"
 	"github.com/chriscorrea/slop/internal/data"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -91,26 +93,26 @@ Your configuration will be saved to ~/.slop/config.toml`,
 			// set API key in config
 			viper.Set(fmt.Sprintf("providers.%s.api_key", providerKey), apiKey)
 
+			// offer the provider's current models when it will list them
+			models := discoverModels(cmd.Context(), viper, providerKey)
+			reportDiscoveredModels(cmd, providerInfo.Name, models)
+
 			// configure fast model
-			var fastModel string
-			fastPrompt := &survey.Input{
+			fastModel, err := askModel(&survey.Input{
 				Message: fmt.Sprintf("%s Default fast model for everyday tasks:", cyan("⚡")),
 				Default: providerInfo.Models.Fast,
 				Help:    "This model will be used for quick, everyday tasks",
-			}
-			err = survey.AskOne(fastPrompt, &fastModel)
+			}, providerKey, models)
 			if err != nil {
 				return fmt.Errorf("survey error: %w", err)
 			}
 
 			// configure deep model
-			var deepModel string
-			deepPrompt := &survey.Input{
+			deepModel, err := askModel(&survey.Input{
 				Message: fmt.Sprintf("%s Deep model for reasoning tasks:", cyan("🧠")),
 				Default: providerInfo.Models.Deep,
 				Help:    "This model will be used for complex analysis and reasoning tasks",
-			}
-			err = survey.AskOne(deepPrompt, &deepModel)
+			}, providerKey, models)
 			if err != nil {
 				return fmt.Errorf("survey error: %w", err)
 			}
@@ -154,32 +156,32 @@ Your configuration will be saved to ~/.slop/config.toml`,
 				return fmt.Errorf("survey error: %w", err)
 			}
 
+			// offer the models already pulled into the server
+			viper.Set("providers.ollama.base_url", ollamaURL)
+			models := discoverModels(cmd.Context(), viper, "ollama")
+			reportDiscoveredModels(cmd, "Ollama", models)
+
 			// configure local fast model
-			var localFastModel string
-			localFastPrompt := &survey.Input{
+			localFastModel, err := askModel(&survey.Input{
 				Message: fmt.Sprintf("%s Local fast model:", cyan("⚡")),
 				Default: ollamaInfo.Models.Fast,
 				Help:    "Local model for quick responses",
-			}
-			err = survey.AskOne(localFastPrompt, &localFastModel)
+			}, "ollama", models)
 			if err != nil {
 				return fmt.Errorf("survey error: %w", err)
 			}
 
 			// configure local deep model
-			var localDeepModel string
-			localDeepPrompt := &survey.Input{
+			localDeepModel, err := askModel(&survey.Input{
 				Message: fmt.Sprintf("%s Local deep model:", cyan("🧠")),
 				Default: ollamaInfo.Models.Deep,
 				Help:    "Local model for complex reasoning",
-			}
-			err = survey.AskOne(localDeepPrompt, &localDeepModel)
+			}, "ollama", models)
 			if err != nil {
 				return fmt.Errorf("survey error: %w", err)
 			}
 
 			// set local configurations
-			viper.Set("providers.ollama.base_url", ollamaURL)
 			viper.Set("models.local.fast.provider", "ollama")
 			viper.Set("models.local.fast.name", localFastModel)
 			viper.Set("models.local.deep.provider", "ollama")
@@ -216,6 +218,50 @@ Your configuration will be saved to ~/.slop/config.toml`,
 	},
 }
 
+// reportDiscoveredModels tells the user whether model names will be suggested
+func reportDiscoveredModels(cmd *cobra.Command, providerName string, models []string) {
+	if len(models) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Could not list %s models; enter model names manually\n", providerName)
+		return
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Found %d %s models (press Tab for suggestions)\n", len(models), providerName)
+}
+
+// askModel prompts for a model name. when the provider listed its models,
+// matching names are suggested and the answer must be one of them; a
+// default that is no longer listed gives way to the closest listed name
+func askModel(prompt *survey.Input, provider string, models []string) (string, error) {
+	var opts []survey.AskOpt
+	if len(models) > 0 {
+		listing := catalog.Listing{Models: models}
+		if !listing.Has(prompt.Default) {
+			if closest := catalog.Suggest(models, prompt.Default, 1); len(closest) > 0 {
+				prompt.Default = closest[0]
+			}
+		}
+		prompt.Suggest = func(toComplete string) []string {
+			var matches []string
+			for _, model := range models {
+				if strings.Contains(model, toComplete) {
+					matches = append(matches, model)
+				}
+			}
+			return matches
+		}
+		opts = append(opts, survey.WithValidator(func(ans interface{}) error {
+			name, _ := ans.(string)
+			if listing.Has(name) {
+				return nil
+			}
+			return unknownModelError(provider, name, models)
+		}))
+	}
+
+	var answer string
+	err := survey.AskOne(prompt, &answer, opts...)
+	return answer, err
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
 }
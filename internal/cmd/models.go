@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/catalog"
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/registry"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// modelListTimeout bounds each provider's model-listing request
+const modelListTimeout = 10 * time.Second
+
+// createModelsCommand creates the models command with subcommands
+func createModelsCommand() *cobra.Command {
+	modelsCmd := &cobra.Command{
+		Use:   "models",
+		Short: "Discover the models your providers serve",
+		Long: `Discover the models your providers serve.
+
+The model names slop ships with are fixed at release time. 'slop models list'
+asks each configured provider's model-listing endpoint (/v1/models, or
+/api/tags for Ollama) which models are available now and caches the answer in
+~/.slop/models.json. 'slop init' and 'slop config set models.*' use the
+cached list to suggest and check model names.`,
+	}
+
+	modelsCmd.AddCommand(createModelsListCommand())
+
+	return modelsCmd
+}
+
+// createModelsListCommand creates the 'models list' subcommand
+func createModelsListCommand() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list [provider]",
+		Short: "List models from provider endpoints and refresh the cache",
+		Long: `List the models available from a provider, or from every provider that is
+configured: those with an API key set, plus keyless providers such as Ollama
+and [providers.custom.<name>] servers. Each list is cached for 'slop init' and
+'slop config set'.
+
+Examples:
+  slop models list             # Query every configured provider
+  slop models list ollama      # Query one provider
+  slop models list --cached    # Show the cached lists without querying`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cached, _ := cmd.Flags().GetBool("cached")
+			cfg := state.manager.Config()
+
+			names := args
+			if len(args) == 1 && !registry.IsProviderRegistered(args[0]) {
+				return fmt.Errorf("unsupported provider '%s'. Available providers: %s", args[0], strings.Join(sortedProviders(), ", "))
+			}
+			if len(args) == 0 {
+				names = listableProviders(state.manager.Viper())
+			}
+
+			models, err := loadModelCatalog()
+			if err != nil {
+				return err
+			}
+
+			listings := make(map[string][]string)
+			if cached {
+				for name, listing := range models.Providers {
+					if len(args) == 0 || name == args[0] {
+						listings[name] = listing.Models
+					}
+				}
+				if len(listings) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "No cached models in %s; run 'slop models list' to fetch them\n", models.Path())
+					return nil
+				}
+				printModelTable(cmd.OutOrStdout(), listings)
+				return nil
+			}
+
+			listings, errs := fetchModelListings(cmd.Context(), cfg, names)
+			if len(args) == 1 {
+				if err := errs[args[0]]; err != nil {
+					return err
+				}
+			}
+			for _, name := range names {
+				err := errs[name]
+				if err == nil || errors.Is(err, common.ErrModelListingUnsupported) {
+					continue
+				}
+				message, _, _ := strings.Cut(err.Error(), "\n")
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipped %s: %s\n", name, message)
+			}
+
+			now := time.Now()
+			for name, list := range listings {
+				models.Set(name, list, now)
+			}
+			if len(listings) > 0 {
+				if err := models.Save(); err != nil {
+					return err
+				}
+			}
+
+			if len(listings) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No models found; configure a provider with 'slop init' or 'slop config set'")
+				return nil
+			}
+			printModelTable(cmd.OutOrStdout(), listings)
+			return nil
+		},
+	}
+
+	listCmd.Flags().Bool("cached", false, "Show the cached model lists without querying providers")
+
+	return listCmd
+}
+
+// listableProviders returns the registered providers that can list models
+// and have what they need to do so: an API key, unless they run without one
+func listableProviders(v *viper.Viper) []string {
+	var names []string
+	for _, name := range sortedProviders() {
+		provider := registry.AllProviders[name]
+		if _, ok := provider.(common.ModelLister); !ok {
+			continue
+		}
+		if provider.RequiresAPIKey() && v.GetString(fmt.Sprintf("providers.%s.api_key", name)) == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// sortedProviders returns the registered provider names in order
+func sortedProviders() []string {
+	names := registry.GetAvailableProviders()
+	sort.Strings(names)
+	return names
+}
+
+// fetchModelListings queries each provider's model-listing endpoint
+// concurrently, returning the model lists and the failures by provider
+func fetchModelListings(ctx context.Context, cfg *config.Config, names []string) (map[string][]string, map[string]error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	listings := make(map[string][]string)
+	errs := make(map[string]error)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, modelListTimeout)
+			defer cancel()
+
+			models, err := registry.ListModels(ctx, name, cfg)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[name] = err
+				return
+			}
+			listings[name] = models
+		}(name)
+	}
+	wg.Wait()
+	return listings, errs
+}
+
+// loadModelCatalog opens the model catalog at its default location
+func loadModelCatalog() (*catalog.Catalog, error) {
+	path, err := catalog.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Load(path)
+}
+
+// discoverModels fetches and caches one provider's models for the current
+// viper settings, as init does right after an API key or URL is entered.
+// any failure yields nil, leaving the caller without suggestions
+func discoverModels(ctx context.Context, v *viper.Viper, provider string) []string {
+	var cfg config.Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil
+	}
+	listings, _ := fetchModelListings(ctx, &cfg, []string{provider})
+	models := listings[provider]
+	if len(models) == 0 {
+		return nil
+	}
+
+	if cached, err := loadModelCatalog(); err == nil {
+		cached.Set(provider, models, time.Now())
+		_ = cached.Save()
+	}
+	return models
+}
+
+// checkModelName verifies a model name against the provider's cached list.
+// a provider without a cached list, or with an empty one, accepts any name
+func checkModelName(provider, name string) error {
+	models, err := loadModelCatalog()
+	if err != nil {
+		return nil
+	}
+	listing, ok := models.Get(provider)
+	if !ok || len(listing.Models) == 0 || listing.Has(name) {
+		return nil
+	}
+	return unknownModelError(provider, name, listing.Models)
+}
+
+// unknownModelError describes a model name missing from a provider's list,
+// with the closest listed names
+func unknownModelError(provider, name string, models []string) error {
+	message := fmt.Sprintf("%s does not list a model named %q", provider, name)
+	if suggestions := catalog.Suggest(models, name, 3); len(suggestions) > 0 {
+		message += fmt.Sprintf("; did you mean %s?", strings.Join(suggestions, ", "))
+	}
+	return fmt.Errorf("%s\nRun 'slop models list %s' to refresh the list", message, provider)
+}
+
+// printModelTable writes one row per provider and model
+func printModelTable(w io.Writer, listings map[string][]string) {
+	names := make([]string, 0, len(listings))
+	for name := range listings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "Provider\tModel")
+	for _, name := range names {
+		for _, model := range listings[name] {
+			fmt.Fprintf(tw, "%s\t%s\n", name, model)
+		}
+	}
+	tw.Flush()
+}
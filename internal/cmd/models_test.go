@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/catalog"
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/llm/custom"
+	"github.com/chriscorrea/slop/internal/registry"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheTestModels writes a model catalog under a temporary HOME
+func cacheTestModels(t *testing.T, provider string, models ...string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cached, err := catalog.Load(filepath.Join(home, ".slop", "models.json"))
+	if err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	cached.Set(provider, models, time.Now())
+	if err := cached.Save(); err != nil {
+		t.Fatalf("Failed to save catalog: %v", err)
+	}
+}
+
+func TestCheckModelName(t *testing.T) {
+	cacheTestModels(t, "openai", "gpt-4o", "gpt-4o-mini")
+
+	if err := checkModelName("openai", "gpt-4o-mini"); err != nil {
+		t.Errorf("Expected listed model to pass, got %v", err)
+	}
+	if err := checkModelName("mistral", "anything"); err != nil {
+		t.Errorf("Expected provider without a cached list to pass, got %v", err)
+	}
+
+	err := checkModelName("openai", "gpt-4")
+	if err == nil {
+		t.Fatal("Expected unlisted model to fail")
+	}
+	for _, want := range []string{`openai does not list a model named "gpt-4"`, "did you mean gpt-4o, gpt-4o-mini?", "slop models list openai"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestSetCommand_ModelNameValidation(t *testing.T) {
+	cacheTestModels(t, "openai", "gpt-4o", "gpt-4o-mini")
+
+	manager := config.NewManager()
+	if err := manager.Load(filepath.Join(t.TempDir(), "config.toml")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	manager.Viper().Set("models.remote.fast.provider", "openai")
+
+	originalState := state
+	state = &rootCmdState{manager: manager}
+	defer func() { state = originalState }()
+
+	run := func(force bool, arg string) error {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("force", force, "")
+		cmd.SetOut(&bytes.Buffer{})
+		return setCmd.RunE(cmd, []string{arg})
+	}
+
+	if err := run(false, "models.remote.fast.name=gpt-4o-mini"); err != nil {
+		t.Errorf("Expected listed model to be accepted, got %v", err)
+	}
+	err := run(false, "remote-fast-model=gpt-4o-turbo")
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected unlisted model to be rejected with a --force hint, got %v", err)
+	}
+	if err := run(true, "models.remote.fast.name=gpt-4o-turbo"); err != nil {
+		t.Errorf("Expected --force to skip the check, got %v", err)
+	}
+	if got := manager.Config().Models.Remote.Fast.Name; got != "gpt-4o-turbo" {
+		t.Errorf("Expected forced model to be saved, got %q", got)
+	}
+}
+
+func TestFetchModelListings(t *testing.T) {
+	originalProviders := registry.AllProviders
+	defer func() { registry.AllProviders = originalProviders }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"qwen2.5-7b-instruct"},{"id":"llama-3.1-8b"}]}`))
+	}))
+	defer server.Close()
+
+	registry.AllProviders = map[string]common.Provider{
+		"vllm":    custom.New("vllm", config.CustomProvider{BaseProvider: config.BaseProvider{BaseUrl: server.URL + "/v1"}}),
+		"offline": custom.New("offline", config.CustomProvider{BaseProvider: config.BaseProvider{BaseUrl: "http://127.0.0.1:1/v1"}}),
+	}
+
+	listings, errs := fetchModelListings(context.Background(), &config.Config{}, []string{"vllm", "offline"})
+	if got := strings.Join(listings["vllm"], ","); got != "llama-3.1-8b,qwen2.5-7b-instruct" {
+		t.Errorf("Unexpected vllm models: %s", got)
+	}
+	if errs["offline"] == nil {
+		t.Error("Expected an error for the offline server")
+	}
+	if _, ok := listings["offline"]; ok {
+		t.Error("Expected no listing for the offline server")
+	}
+}
+
+func TestPrintModelTable(t *testing.T) {
+	var buf bytes.Buffer
+	printModelTable(&buf, map[string][]string{
+		"openai": {"gpt-4o", "gpt-4o-mini"},
+		"ollama": {"llama3.2:latest"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected header and 3 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "Provider") || !strings.HasPrefix(lines[1], "ollama") || !strings.Contains(lines[3], "gpt-4o-mini") {
+		t.Errorf("Unexpected table:\n%s", buf.String())
+	}
+}
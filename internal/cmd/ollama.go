@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/ollama"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// progressBarWidth is the number of cells in the pull progress bar
+const progressBarWidth = 30
+
+// createOllamaCommand creates the ollama command with subcommands
+func createOllamaCommand() *cobra.Command {
+	ollamaCmd := &cobra.Command{
+		Use:   "ollama",
+		Short: "Manage the models on your Ollama server",
+		Long: `Manage the models on the Ollama server at providers.ollama.base_url.
+
+Examples:
+  slop ollama list              # Show pulled models and which are loaded
+  slop ollama pull gemma3:4b    # Download a model
+  slop ollama rm gemma3:4b      # Remove a model
+  slop ollama warm              # Load the configured local models now`,
+	}
+
+	ollamaCmd.AddCommand(createOllamaListCommand())
+	ollamaCmd.AddCommand(createOllamaPullCommand())
+	ollamaCmd.AddCommand(createOllamaRmCommand())
+	ollamaCmd.AddCommand(createOllamaWarmCommand())
+
+	return ollamaCmd
+}
+
+// createOllamaListCommand creates the 'ollama list' subcommand
+func createOllamaListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List pulled models and which are loaded",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := ollama.NewManager(state.manager.Config())
+			if err != nil {
+				return err
+			}
+			models, err := manager.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(models) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No models pulled; try 'slop ollama pull <model>'")
+				return nil
+			}
+
+			// the loaded column is best-effort; older servers lack /api/ps
+			running, _ := manager.Running(cmd.Context())
+			printOllamaModels(cmd.OutOrStdout(), models, running, time.Now())
+			return nil
+		},
+	}
+}
+
+// createOllamaPullCommand creates the 'ollama pull' subcommand
+func createOllamaPullCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull <model>...",
+		Short: "Download models with progress",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := ollama.NewManager(state.manager.Config())
+			if err != nil {
+				return err
+			}
+			for _, model := range args {
+				if err := pullModel(cmd.Context(), manager, model, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Pulled %s\n", model)
+			}
+			return nil
+		},
+	}
+}
+
+// createOllamaRmCommand creates the 'ollama rm' subcommand
+func createOllamaRmCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <model>...",
+		Short: "Remove pulled models",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := ollama.NewManager(state.manager.Config())
+			if err != nil {
+				return err
+			}
+			for _, model := range args {
+				if err := manager.Delete(cmd.Context(), model); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", model, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", model)
+			}
+			return nil
+		},
+	}
+}
+
+// createOllamaWarmCommand creates the 'ollama warm' subcommand
+func createOllamaWarmCommand() *cobra.Command {
+	warmCmd := &cobra.Command{
+		Use:   "warm [model]...",
+		Short: "Load models into memory so the first request is fast",
+		Long: `Load models into memory ahead of time so the first real request doesn't wait
+for them to load. Without arguments, every model slot served by Ollama is
+warmed. Models stay loaded for providers.ollama.keep_alive unless --keep-alive
+is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := state.manager.Config()
+			keepAlive := cfg.Providers.Ollama.KeepAlive
+			if cmd.Flags().Changed("keep-alive") {
+				keepAlive, _ = cmd.Flags().GetString("keep-alive")
+			}
+
+			models := args
+			if len(models) == 0 {
+				models = configuredOllamaModels(cfg)
+			}
+			if len(models) == 0 {
+				return fmt.Errorf("no models use the ollama provider; name one or set models.local.fast.name")
+			}
+
+			manager, err := ollama.NewManager(cfg)
+			if err != nil {
+				return err
+			}
+			for _, model := range models {
+				start := time.Now()
+				if err := manager.Warm(cmd.Context(), model, keepAlive); err != nil {
+					return fmt.Errorf("failed to warm %s: %w", model, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Warmed %s in %s\n", model, time.Since(start).Round(100*time.Millisecond))
+			}
+			return nil
+		},
+	}
+
+	warmCmd.Flags().String("keep-alive", "", "How long to keep models loaded, e.g. 30m or -1 for indefinitely")
+
+	return warmCmd
+}
+
+// configuredOllamaModels returns the distinct model names of the slots served by Ollama
+func configuredOllamaModels(cfg *config.Config) []string {
+	slots := []struct{ provider, name string }{
+		{cfg.Models.Local.Fast.Provider, cfg.Models.Local.Fast.Name},
+		{cfg.Models.Local.Deep.Provider, cfg.Models.Local.Deep.Name},
+		{cfg.Models.Remote.Fast.Provider, cfg.Models.Remote.Fast.Name},
+		{cfg.Models.Remote.Deep.Provider, cfg.Models.Remote.Deep.Name},
+	}
+
+	var models []string
+	seen := make(map[string]bool)
+	for _, slot := range slots {
+		if slot.provider != "ollama" || slot.name == "" || seen[slot.name] {
+			continue
+		}
+		seen[slot.name] = true
+		models = append(models, slot.name)
+	}
+	return models
+}
+
+// pullModel pulls a model, drawing progress on w
+func pullModel(ctx context.Context, manager *ollama.Manager, model string, w io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	progress := newPullProgress(w)
+	err := manager.Pull(ctx, model, progress.update)
+	progress.finish()
+	return err
+}
+
+// promptPullModel is the app's pull hook: it asks on the controlling terminal
+// whether to pull a model the Ollama server is missing, then pulls it. without
+// a terminal it declines, leaving the not-found error
+func promptPullModel(ctx context.Context, providerName, modelName string) (bool, error) {
+	if providerName != "ollama" {
+		return false, nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, nil
+	}
+	defer tty.Close()
+
+	pull := true
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("%s has not been pulled on the Ollama server. Pull it now?", modelName),
+		Default: true,
+	}
+	if err := survey.AskOne(prompt, &pull, survey.WithStdio(tty, tty, os.Stderr)); err != nil || !pull {
+		return false, err
+	}
+
+	manager, err := ollama.NewManager(state.manager.Config())
+	if err != nil {
+		return false, err
+	}
+	if err := pullModel(ctx, manager, modelName, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to pull %s: %v\n", modelName, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// pullProgress draws a bar for each downloading layer and a line for each
+// other status Ollama reports while pulling
+type pullProgress struct {
+	w       io.Writer
+	status  string
+	drawing bool // a bar is on the current line
+}
+
+func newPullProgress(w io.Writer) *pullProgress {
+	return &pullProgress{w: w}
+}
+
+// update renders one status update
+func (p *pullProgress) update(progress ollama.PullProgress) {
+	if progress.Status != p.status {
+		p.finish()
+	}
+
+	if progress.Total > 0 {
+		completed := min(progress.Completed, progress.Total)
+		filled := int(completed * progressBarWidth / progress.Total)
+		fmt.Fprintf(p.w, "\r%s [%s%s] %3d%% %s/%s", progress.Status,
+			strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled),
+			completed*100/progress.Total, formatBytes(completed), formatBytes(progress.Total))
+		p.drawing = true
+	} else if progress.Status != p.status {
+		fmt.Fprintln(p.w, progress.Status)
+	}
+	p.status = progress.Status
+}
+
+// finish ends a bar left on the current line
+func (p *pullProgress) finish() {
+	if p.drawing {
+		fmt.Fprintln(p.w)
+		p.drawing = false
+	}
+}
+
+// printOllamaModels writes the pulled models as a table, marking loaded ones
+// with how long they stay loaded
+func printOllamaModels(w io.Writer, models []ollama.LocalModel, running []ollama.RunningModel, now time.Time) {
+	loaded := make(map[string]time.Time, len(running))
+	for _, model := range running {
+		loaded[model.Name] = model.ExpiresAt
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "Model\tParameters\tSize\tModified\tLoaded")
+	for _, model := range models {
+		status := "-"
+		if expires, ok := loaded[model.Name]; ok {
+			status = "yes"
+			if expires.After(now) && expires.Year() < 2100 {
+				status = fmt.Sprintf("for %s", expires.Sub(now).Round(time.Second))
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", model.Name, model.Details.ParameterSize,
+			formatBytes(model.Size), model.ModifiedAt.Local().Format(time.DateOnly), status)
+	}
+	tw.Flush()
+}
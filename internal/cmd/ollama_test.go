@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/ollama"
+)
+
+func TestConfiguredOllamaModels(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Models.Local.Fast.Provider, cfg.Models.Local.Fast.Name = "ollama", "gemma3:4b"
+	cfg.Models.Local.Deep.Provider, cfg.Models.Local.Deep.Name = "ollama", "gemma3:4b"
+	cfg.Models.Remote.Fast.Provider, cfg.Models.Remote.Fast.Name = "mistral", "mistral-small-latest"
+	cfg.Models.Remote.Deep.Provider, cfg.Models.Remote.Deep.Name = "ollama", "qwen3:32b"
+
+	got := strings.Join(configuredOllamaModels(cfg), ",")
+	if got != "gemma3:4b,qwen3:32b" {
+		t.Errorf("Expected distinct Ollama models, got %s", got)
+	}
+}
+
+func TestPullProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newPullProgress(&buf)
+	for _, update := range []ollama.PullProgress{
+		{Status: "pulling manifest"},
+		{Status: "pulling aeda25e63ebd", Total: 2048, Completed: 1024},
+		{Status: "pulling aeda25e63ebd", Total: 2048, Completed: 2048},
+		{Status: "verifying sha256 digest"},
+		{Status: "success"},
+	} {
+		progress.update(update)
+	}
+	progress.finish()
+
+	output := buf.String()
+	for _, want := range []string{
+		"pulling manifest\n",
+		"\rpulling aeda25e63ebd [===============               ]  50% 1.0 KB/2.0 KB",
+		"\rpulling aeda25e63ebd [==============================] 100% 2.0 KB/2.0 KB\nverifying sha256 digest\n",
+		"success\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+func TestPrintOllamaModels(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	models := []ollama.LocalModel{
+		{Name: "qwen3:32b", Size: 20 * 1024 * 1024 * 1024, ModifiedAt: now},
+		{Name: "gemma3:4b", Size: 3 * 1024 * 1024 * 1024, ModifiedAt: now},
+	}
+	models[1].Details.ParameterSize = "4.3B"
+	running := []ollama.RunningModel{{Name: "gemma3:4b", ExpiresAt: now.Add(4 * time.Minute)}}
+
+	var buf bytes.Buffer
+	printOllamaModels(&buf, models, running, now)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "gemma3:4b") || !strings.Contains(lines[1], "4.3B") || !strings.Contains(lines[1], "3.0 GB") || !strings.HasSuffix(lines[1], "for 4m0s") {
+		t.Errorf("Unexpected loaded row: %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "qwen3:32b") || !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("Unexpected row: %q", lines[2])
+	}
+}
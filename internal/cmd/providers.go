@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/data"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/registry"
+
+	"github.com/spf13/cobra"
+)
+
+// featureFlags names the flag that requests each feature
+var featureFlags = map[common.Feature]string{
+	common.FeatureThinking: "--thinking",
+	common.FeatureSchema:   "--schema",
+	common.FeatureJSON:     "--json",
+	common.FeatureVision:   "--image",
+	common.FeatureTools:    "--tool",
+	common.FeatureSeed:     "--seed",
+}
+
+// createProvidersCommand creates the providers command with subcommands
+func createProvidersCommand() *cobra.Command {
+	providersCmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Show what each provider's models support",
+		Long: `Show what each provider's models support.
+
+Not every model honors every flag: a model without native reasoning ignores
+--thinking, and some APIs take no seed. slop leaves such a feature out of the
+request and prints a warning first. --json is the exception: without a native
+JSON mode it still applies through the prompt. 'slop providers describe' shows
+which model families honor what.`,
+	}
+
+	providersCmd.AddCommand(createProvidersDescribeCommand())
+
+	return providersCmd
+}
+
+// createProvidersDescribeCommand creates the 'providers describe' subcommand
+func createProvidersDescribeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe <provider> [model]",
+		Short: "Show the features a provider's model families support",
+		Long: `Show the features a provider's model families support: native thinking,
+schema-constrained output, JSON mode, image inputs, tool calls, a sampling
+seed and the context window. Give a model to see that model alone.
+
+Examples:
+  slop providers describe anthropic
+  slop providers describe openai o3-mini`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if !registry.IsProviderRegistered(name) {
+				return fmt.Errorf("unsupported provider '%s'. Available providers: %s", name, strings.Join(sortedProviders(), ", "))
+			}
+			families, err := registry.ModelFamilies(name)
+			if err != nil {
+				return err
+			}
+			model := ""
+			if len(args) == 2 {
+				model = args[1]
+			}
+
+			models := data.NewProviderRegistry()
+			if err := models.Load(); err != nil {
+				models = nil
+			}
+			describeProvider(cmd.OutOrStdout(), models, name, families, model)
+			return nil
+		},
+	}
+}
+
+// describeProvider prints the provider's heading and a capability row per
+// model family, or for model alone when it isn't empty. models may be nil
+// when the bundled model data can't be read
+func describeProvider(w io.Writer, models *data.ProviderRegistry, name string, families []common.ModelFamily, model string) {
+	heading := name
+	if models != nil {
+		if info, ok := models.GetProvider(name); ok && info.Description != "" {
+			heading = fmt.Sprintf("%s: %s", name, info.Description)
+		}
+	}
+	fmt.Fprintln(w, heading)
+	fmt.Fprintln(w)
+
+	if families == nil {
+		fmt.Fprintf(w, "%s doesn't describe its models; every flag is accepted as given\n", name)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if model != "" {
+		fmt.Fprintln(tw, "Model\tThinking\tSchema\tJSON\tVision\tTools\tSeed\tContext")
+		caps, _ := registry.ModelCapabilities(name, model)
+		fmt.Fprintf(tw, "%s\t%s\n", model, capabilityCells(caps, contextWindowOf(models, name, model)))
+		tw.Flush()
+		return
+	}
+
+	fmt.Fprintln(tw, "Family\tExample\tThinking\tSchema\tJSON\tVision\tTools\tSeed\tContext")
+	for _, family := range families {
+		example, window := "-", 0
+		if family.Example != "" {
+			example = family.Example
+			window = contextWindowOf(models, name, family.Example)
+		}
+		caps, _ := registry.ModelCapabilities(name, family.Example)
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", family.Name, example, capabilityCells(caps, window))
+	}
+	tw.Flush()
+}
+
+// capabilityCells formats a capability row's tab-separated yes/no cells and
+// context window; "-" stands for an unknown window
+func capabilityCells(caps common.Capabilities, window int) string {
+	cells := make([]string, 0, 7)
+	for _, supported := range []bool{caps.Thinking, caps.Schema, caps.JSON, caps.Vision, caps.Tools, caps.Seed} {
+		if supported {
+			cells = append(cells, "yes")
+		} else {
+			cells = append(cells, "no")
+		}
+	}
+	if window > 0 {
+		cells = append(cells, strconv.Itoa(window))
+	} else {
+		cells = append(cells, "-")
+	}
+	return strings.Join(cells, "\t")
+}
+
+// contextWindowOf looks up a model's context window in the bundled model
+// data; 0 when unknown
+func contextWindowOf(models *data.ProviderRegistry, name, model string) int {
+	if models == nil {
+		return 0
+	}
+	window, _ := models.GetContextWindow(name, model)
+	return window
+}
+
+// warnDroppedFeatures says which requested features the model will leave out
+// of the request, so an ignored --seed or --thinking doesn't pass unnoticed,
+// and which it only emulates. only the primary model is checked; fallbacks
+// answer only when it fails
+func warnDroppedFeatures(w io.Writer, cfg *config.Config, providerName, modelName string) {
+	var dropped []string
+	for _, feature := range registry.UnsupportedFeatures(providerName, modelName, cfg) {
+		if feature.Emulated() {
+			fmt.Fprintf(w, "Warning: %s/%s has no native JSON mode; --json relies on the prompt instruction alone\n", providerName, modelName)
+			continue
+		}
+		dropped = append(dropped, featureFlags[feature])
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	subject := "it is"
+	if len(dropped) > 1 {
+		subject = "they are"
+	}
+	fmt.Fprintf(w, "Warning: %s/%s doesn't support %s; %s left out of the request\n", providerName, modelName, strings.Join(dropped, ", "), subject)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/registry"
+)
+
+// describeOutput runs 'providers describe' with args and returns its output
+func describeOutput(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := createProvidersCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"describe"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestProvidersDescribe(t *testing.T) {
+	output, err := describeOutput(t, "anthropic")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+
+	lines := strings.Split(output, "\n")
+	if !strings.HasPrefix(lines[0], "anthropic: ") {
+		t.Errorf("Expected the provider description first, got %q", lines[0])
+	}
+	want := "Family Example Thinking Schema JSON Vision Tools Seed Context"
+	if got := strings.Join(strings.Fields(lines[2]), " "); got != want {
+		t.Errorf("Expected header %q, got %q", want, got)
+	}
+
+	// haiku is outside the extended-thinking allowlist and no Claude model takes a seed
+	want = "claude-haiku-4 claude-haiku-4-5 no yes no yes yes no 200000"
+	for _, line := range lines {
+		if strings.HasPrefix(line, "claude-haiku-4 ") {
+			if got := strings.Join(strings.Fields(line), " "); got != want {
+				t.Errorf("Expected row %q, got %q", want, got)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a haiku family row, got:\n%s", output)
+}
+
+func TestProvidersDescribe_Model(t *testing.T) {
+	output, err := describeOutput(t, "openai", "o3-mini")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+
+	lines := strings.Split(output, "\n")
+	want := "o3-mini yes yes yes no yes yes 200000"
+	if got := strings.Join(strings.Fields(lines[3]), " "); got != want {
+		t.Errorf("Expected row %q, got %q", want, got)
+	}
+}
+
+func TestProvidersDescribe_WithoutCapabilities(t *testing.T) {
+	output, err := describeOutput(t, "mock")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	if !strings.Contains(output, "mock doesn't describe its models") {
+		t.Errorf("Expected a note that mock has no capabilities, got:\n%s", output)
+	}
+}
+
+func TestProvidersDescribe_UnknownProvider(t *testing.T) {
+	_, err := describeOutput(t, "nonexistent")
+	if err == nil || !strings.Contains(err.Error(), "unsupported provider 'nonexistent'") {
+		t.Errorf("Expected an unsupported provider error, got %v", err)
+	}
+}
+
+func TestWarnDroppedFeatures(t *testing.T) {
+	seed := 7
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		setup    func(cfg *config.Config)
+		want     string
+	}{
+		{
+			name:     "several",
+			provider: "anthropic",
+			model:    "claude-haiku-4-5",
+			setup: func(cfg *config.Config) {
+				cfg.Parameters.Thinking = "high"
+				cfg.Parameters.Seed = &seed
+			},
+			want: "Warning: anthropic/claude-haiku-4-5 doesn't support --thinking, --seed; they are left out of the request\n",
+		},
+		{
+			name:     "one",
+			provider: "together",
+			model:    "meta-llama/Llama-3.3-70B-Instruct-Turbo",
+			setup:    func(cfg *config.Config) { cfg.Parameters.Seed = &seed },
+			want:     "Warning: together/meta-llama/Llama-3.3-70B-Instruct-Turbo doesn't support --seed; it is left out of the request\n",
+		},
+		{
+			name:     "json is emulated",
+			provider: "anthropic",
+			model:    "claude-haiku-4-5",
+			setup: func(cfg *config.Config) {
+				cfg.Format.JSON = true
+				cfg.Parameters.Seed = &seed
+			},
+			want: "Warning: anthropic/claude-haiku-4-5 has no native JSON mode; --json relies on the prompt instruction alone\n" +
+				"Warning: anthropic/claude-haiku-4-5 doesn't support --seed; it is left out of the request\n",
+		},
+		{
+			name:     "ollama model without thinking",
+			provider: "ollama",
+			model:    "llama3.2",
+			setup:    func(cfg *config.Config) { cfg.Parameters.Thinking = "medium" },
+			want:     "Warning: ollama/llama3.2 doesn't support --thinking; it is left out of the request\n",
+		},
+		{
+			name:     "honored",
+			provider: "openai",
+			model:    "o3",
+			setup: func(cfg *config.Config) {
+				cfg.Parameters.Thinking = "high"
+				cfg.Parameters.Seed = &seed
+			},
+			want: "",
+		},
+		{
+			name:     "mock",
+			provider: "mock",
+			model:    "test-model",
+			setup:    func(cfg *config.Config) { cfg.Parameters.Seed = &seed },
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !registry.IsProviderRegistered(tt.provider) {
+				t.Fatalf("provider %s is not registered", tt.provider)
+			}
+			cfg := &config.Config{}
+			tt.setup(cfg)
+
+			var out bytes.Buffer
+			warnDroppedFeatures(&out, cfg, tt.provider, tt.model)
+			if out.String() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+}
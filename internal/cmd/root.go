@@ -1,16 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/chriscorrea/slop/internal/app"
 	"github.com/chriscorrea/slop/internal/config"
 	slopContext "github.com/chriscorrea/slop/internal/context"
 	"github.com/chriscorrea/slop/internal/logger"
+	"github.com/chriscorrea/slop/internal/registry"
+	slopVerbose "github.com/chriscorrea/slop/internal/verbose"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -99,11 +106,12 @@ func showCustomHelp(cmd *cobra.Command) {
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:          "slop",
-	Version:      version,
-	Short:        "A CLI tool for interacting with LLMs",
-	Long:         `Slop brings large language models to your command line. It is inspired by the idea that language models work best as composable language operators`,
-	SilenceUsage: true, // don't show usage after errors
+	Use:           "slop",
+	Version:       version,
+	Short:         "A CLI tool for interacting with LLMs",
+	Long:          `Slop brings large language models to your command line. It is inspired by the idea that language models work best as composable language operators`,
+	SilenceUsage:  true, // don't show usage after errors
+	SilenceErrors: true, // Execute prints errors, except for a cancelled run
 
 	// accept any arguments and pass them to RunE
 	Args: cobra.ArbitraryArgs,
@@ -140,30 +148,38 @@ var rootCmd = &cobra.Command{
 
 		// binding map
 		flagBindings := map[string]string{
-			"system":         "parameters.system_prompt",
-			"context":        "context",
-			"ignore-context": "no_context",
-			"local":          "local",
-			"fast":           "fast",
-			"deep":           "deep",
-			"temperature":    "parameters.temperature",
-			"json":           "format.json",
-			"jsonl":          "format.jsonl",
-			"yaml":           "format.yaml",
-			"md":             "format.md",
-			"xml":            "format.xml",
-			"verbose":        "verbose",
-			"debug":          "debug",
-			"seed":           "parameters.seed",
-			"max-tokens":     "parameters.max_tokens",
-			"max-retries":    "parameters.max_retries",
-			"timeout":        "parameters.timeout",
-			"test":           "test",
-			"exit-code":      "exit_code_map",
-			"hide-thinking":  "hide_thinking",
-			"show-thinking":  "show_thinking",
-			"thinking":       "parameters.thinking",
-			"schema":         "parameters.response_schema",
+			"system":           "parameters.system_prompt",
+			"context":          "context",
+			"ignore-context":   "no_context",
+			"local":            "local",
+			"fast":             "fast",
+			"deep":             "deep",
+			"temperature":      "parameters.temperature",
+			"json":             "format.json",
+			"jsonl":            "format.jsonl",
+			"yaml":             "format.yaml",
+			"md":               "format.md",
+			"xml":              "format.xml",
+			"verbose":          "verbose",
+			"debug":            "debug",
+			"seed":             "parameters.seed",
+			"max-tokens":       "parameters.max_tokens",
+			"max-retries":      "parameters.max_retries",
+			"timeout":          "parameters.timeout",
+			"test":             "test",
+			"exit-code":        "exit_code_map",
+			"hide-thinking":    "hide_thinking",
+			"show-thinking":    "show_thinking",
+			"thinking":         "parameters.thinking",
+			"stream":           "parameters.stream",
+			"cache":            "parameters.cache",
+			"record":           "parameters.record",
+			"replay":           "parameters.replay",
+			"mock-rules":       "providers.mock.rules",
+			"context-overflow": "parameters.context_overflow",
+			"reduce-prompt":    "parameters.map_reduce.reduce_prompt",
+			"schema":           "parameters.response_schema",
+			"tool":             "parameters.tools",
 		}
 
 		// bind each flag to corresponding Viper key
@@ -179,6 +195,15 @@ var rootCmd = &cobra.Command{
 			return fmt.Errorf("failed to load configuration: %w", err)
 		}
 
+		// make [providers.custom.<name>] entries and plugins selectable like
+		// built-in providers
+		if err := registry.RegisterCustomProviders(state.manager.Config()); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := registry.RegisterPluginProviders(state.manager.Config()); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -205,23 +230,53 @@ var rootCmd = &cobra.Command{
 // execute adds all child commands to the root command and sets flags
 // this is called by main.main() – it only needs to happen once to the rootCmd
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	// Ctrl-C or SIGTERM cancels the run: the spinner is cleared, streamed
+	// output is kept, and slop exits 130 so scripts can tell a cancel from a
+	// failure. handling is then restored, so a second Ctrl-C kills a run stuck
+	// on something that doesn't heed cancellation, such as a prompt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if code := reportError(cmd, err); code != 0 {
+		os.Exit(code)
 	}
 }
 
+// reportError prints a failed command's error, as cobra would, and returns
+// the exit code. a cancelled run exits 130 without an error message
+func reportError(cmd *cobra.Command, err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, context.Canceled) {
+		return app.ExitInterrupted
+	}
+	cmd.PrintErrln(cmd.ErrPrefix(), err.Error())
+	return 1
+}
+
 func init() {
 	// define persistent flags
 	rootCmd.PersistentFlags().String("config", "", "Path to the config file")
 	rootCmd.PersistentFlags().String("system", "", "The system prompt")
 	rootCmd.PersistentFlags().StringSlice("context", []string{}, "Path to context file(s)")
+	rootCmd.PersistentFlags().StringSlice("image", []string{}, "Path to image file(s) to send with the prompt")
 	rootCmd.PersistentFlags().BoolP("ignore-context", "i", false, "Ignore project context for this command")
+	rootCmd.PersistentFlags().String("context-overflow", "truncate", "When input won't fit the model's context window: truncate, drop or fail")
+	rootCmd.PersistentFlags().Bool("map-reduce", false, "Answer the prompt for each part of input too large for one request, then combine the answers")
+	rootCmd.PersistentFlags().String("reduce-prompt", "", "Instructions for combining --map-reduce partial answers")
+	rootCmd.PersistentFlags().Int("retrieve", 0, "Send only the k project context chunks most relevant to the prompt (see 'slop context index')")
 	rootCmd.PersistentFlags().BoolP("local", "l", false, "Use local LLM")
 	rootCmd.PersistentFlags().BoolP("remote", "r", false, "Use remote LLM")
 	rootCmd.PersistentFlags().BoolP("fast", "f", false, "Use fast/lightweight model")
 	rootCmd.PersistentFlags().BoolP("deep", "d", false, "Use deep/reasoning model")
+	rootCmd.PersistentFlags().StringP("model", "m", "", "Use a specific model as provider/model, or a model on the selected provider")
 	rootCmd.PersistentFlags().Bool("test", false, "Use mock provider for testing")
+	rootCmd.PersistentFlags().String("mock-rules", "", "File of canned responses for the --test mock provider")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Display LLM parameters in formatted table")
 	rootCmd.PersistentFlags().BoolP("debug", "D", false, "Enable detailed debug logging")
 
@@ -229,7 +284,10 @@ func init() {
 	rootCmd.PersistentFlags().Int("max-tokens", 2048, "Maximum number of tokens for LLM responses")
 	rootCmd.PersistentFlags().Float64("top-p", 1.0, "Top P sampling for LLM responses")
 	rootCmd.PersistentFlags().StringSlice("stop-sequences", []string{"\n", "###"}, "Stop sequences for LLM responses")
-	rootCmd.PersistentFlags().Bool("stream", true, "Enable streaming responses from LLM") // TODO
+	rootCmd.PersistentFlags().Bool("stream", false, "Stream tokens to stdout as they arrive")
+	rootCmd.PersistentFlags().Bool("cache", false, "Reuse cached responses for identical requests")
+	rootCmd.PersistentFlags().String("record", "", "Save every provider request and response to a directory")
+	rootCmd.PersistentFlags().String("replay", "", "Answer requests from a --record directory, offline; unrecorded requests fail")
 	rootCmd.PersistentFlags().Int("seed", 0, "Random seed for deterministic LLM outputs (0 = no seed)")
 
 	rootCmd.PersistentFlags().Int("timeout", 60, "Timeout in seconds for LLM requests")
@@ -257,6 +315,9 @@ func init() {
 	// structured output schema — accepts a file path or inline JSON
 	rootCmd.PersistentFlags().String("schema", "", "JSON schema for structured output (file path or inline JSON)")
 
+	// tools the model may call (declared under [tools] in config)
+	rootCmd.PersistentFlags().StringSlice("tool", []string{}, "Enable a configured tool for the model to call (repeatable)")
+
 	// mark the mutually exclusive flags
 	rootCmd.MarkFlagsMutuallyExclusive("fast", "deep")
 	rootCmd.MarkFlagsMutuallyExclusive("local", "remote")
@@ -265,7 +326,7 @@ func init() {
 	rootCmd.MarkFlagsMutuallyExclusive("hide-thinking", "show-thinking")
 
 	// list of flags to hide for now
-	flagsToHide := []string{"test", "stream"}
+	flagsToHide := []string{"test"}
 
 	for _, flagName := range flagsToHide {
 		err := rootCmd.PersistentFlags().MarkHidden(flagName)
@@ -303,12 +364,19 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 	rootCmd.AddCommand(createVersionCommand())
 	rootCmd.AddCommand(createNamedHelpCommand())
 	rootCmd.AddCommand(createContextCommand())
+	rootCmd.AddCommand(createUsageCommand())
+	rootCmd.AddCommand(createCacheCommand())
+	rootCmd.AddCommand(createModelsCommand())
+	rootCmd.AddCommand(createProvidersCommand())
+	rootCmd.AddCommand(createOllamaCommand())
+	rootCmd.AddCommand(createEmbedCommand())
+	rootCmd.AddCommand(createBatchCommand())
 }
 
 // executeApp handles the common execution logic for both direct prompts and named commands
 func executeApp(cmd *cobra.Command, args []string, cfg *config.Config, contextResult *slopContext.ContextResult, commandContext string, showCommandInfo bool, commandName string, messageTemplate string, exitMode string) error {
 	// select model using the selector
-	providerName, modelName, err := selectModelForCommand(cmd, cfg, commandName, args)
+	providerName, modelName, fallbacks, err := selectModelForCommand(cmd, cfg, commandName, args)
 	if err != nil {
 		return fmt.Errorf("failed to select model: %w", err)
 	}
@@ -329,6 +397,11 @@ func executeApp(cmd *cobra.Command, args []string, cfg *config.Config, contextRe
 		return fmt.Errorf("failed to get show-thinking flag: %w", err)
 	}
 
+	mapReduce, err := cmd.Flags().GetBool("map-reduce")
+	if err != nil {
+		return fmt.Errorf("failed to get map-reduce flag: %w", err)
+	}
+
 	// handle mutual exclusivity with default values
 	// if show-thinking is explicitly set, override hide-thinking's default
 	if cmd.Flags().Changed("show-thinking") && showThinking {
@@ -343,10 +416,27 @@ func executeApp(cmd *cobra.Command, args []string, cfg *config.Config, contextRe
 	}
 
 	// create app with config, logger, and verbose setting
-	appInstance := app.NewApp(cfg, state.logger, verbose)
+	appInstance := app.NewApp(cfg, state.logger, verbose).
+		WithOutput(cmd.OutOrStdout()).
+		WithFallbacks(fallbacks).
+		WithModelPull(promptPullModel).
+		WithContextWindow(contextWindowFor(cfg, providerName, modelName)).
+		WithMapReduce(mapReduce)
+
+	// with --retrieve, project context comes from the index
+	if contextResult != nil && len(contextResult.RetrievalFiles) > 0 {
+		retriever, err := newProjectRetriever(cfg, contextResult, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		appInstance.WithRetriever(retriever)
+	}
+
+	// a feature the model can't honor is dropped, not rejected, so say so first
+	warnDroppedFeatures(cmd.ErrOrStderr(), cfg, providerName, modelName)
 
 	// run the app
-	output, exitCode, err := appInstance.Run(
+	result, err := appInstance.Run(
 		cmd.Context(),
 		args, // user prompt arguments
 		contextResult,
@@ -362,11 +452,32 @@ func executeApp(cmd *cobra.Command, args []string, cfg *config.Config, contextRe
 		return fmt.Errorf("failed to run app: %w", err)
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), output)
+	// say which model answered when the requested one was unavailable
+	printFallbacks(cmd.ErrOrStderr(), result)
+
+	// append the run to the usage ledger for `slop usage`
+	recordUsage(result.Provider, result.Model, commandName, result)
+
+	// a streamed response has already been written; just terminate the line
+	if appInstance.StreamsOutput() {
+		fmt.Fprintln(cmd.OutOrStdout())
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), result.Output)
+	}
+
+	// a cut-off answer is easy to miss in a pipeline, so always flag it
+	if result.Generation.Truncated() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: response was truncated at the max tokens limit (finish reason: %s); raise --max-tokens for a complete answer\n", result.Generation.FinishReason)
+	}
+
+	// report token usage and the resolved model when verbose
+	if verbose {
+		slopVerbose.PrintGenerationSummary(result.Generation, slopVerbose.DefaultOutputConfig(cmd.ErrOrStderr()))
+	}
 
 	// exit with determined code if not 0
-	if exitCode != 0 {
-		os.Exit(exitCode)
+	if result.ExitCode != 0 {
+		os.Exit(result.ExitCode)
 	}
 
 	return nil
@@ -402,15 +513,16 @@ func handleDirectPrompt(cmd *cobra.Command, args []string) error {
 }
 
 // selectModelForCommand uses the existing model selector logic
-func selectModelForCommand(cmd *cobra.Command, cfg *config.Config, cmdName string, args []string) (string, string, error) {
+func selectModelForCommand(cmd *cobra.Command, cfg *config.Config, cmdName string, args []string) (string, string, []config.ModelRef, error) {
 
 	// create a model selector and use it
 	selector := NewModelSelector()
 
 	providerName, modelName, err := selector.SelectModel(cmd, cfg, args)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
+	fallbacks := selector.SelectFallbacks(cmd, cfg)
 
 	// log model selection
 	if state.logger != nil {
@@ -421,7 +533,20 @@ func selectModelForCommand(cmd *cobra.Command, cfg *config.Config, cmdName strin
 		}
 	}
 
-	return providerName, modelName, nil
+	return providerName, modelName, fallbacks, nil
+}
+
+// printFallbacks reports each unavailable model and the fallback that answered
+func printFallbacks(w io.Writer, result *app.Result) {
+	if len(result.Fallbacks) == 0 {
+		return
+	}
+	for _, failed := range result.Fallbacks {
+		// provider errors can run to several lines of advice; the first says what went wrong
+		reason, _, _ := strings.Cut(failed.Err.Error(), "\n")
+		fmt.Fprintf(w, "Warning: %s/%s unavailable: %s\n", failed.Provider, failed.Model, reason)
+	}
+	fmt.Fprintf(w, "Answered by fallback model %s/%s\n", result.Provider, result.Model)
 }
 
 // getExitMode determines which exit mode is active based on flags and command config
@@ -500,6 +625,12 @@ func createListCommand() *cobra.Command {
 			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "config set", "Set a specific configuration value")
 			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "context", "Manage persistent context for the current directory")
 			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "list", "List all available commands")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "usage", "Summarize token usage and cost")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "cache", "Inspect or clear the response cache")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "models list", "List models from provider endpoints")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "ollama", "Pull, list, remove and warm Ollama models")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "embed", "Write embedding vectors for text as JSONL")
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "batch", "Run a named command over JSONL records")
 
 			fmt.Fprintln(cmd.OutOrStdout())
 			fmt.Fprintf(cmd.OutOrStdout(), "  %-12s %s\n", "init", "Configure a new slop installation")
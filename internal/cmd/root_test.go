@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func TestReportError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStderr string
+	}{
+		{"success", nil, 0, ""},
+		{"failure", errors.New("provider unavailable"), 1, "Error: provider unavailable\n"},
+		{"cancelled", fmt.Errorf("failed to run app: %w", context.Canceled), app.ExitInterrupted, ""},
+		{"deadline", fmt.Errorf("failed to run app: %w", context.DeadlineExceeded), 1, "Error: failed to run app: context deadline exceeded\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			cmd := &cobra.Command{Use: "slop"}
+			cmd.SetErr(&stderr)
+
+			if code := reportError(cmd, tt.err); code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("Expected stderr %q, got %q", tt.wantStderr, stderr.String())
+			}
+		})
+	}
+}
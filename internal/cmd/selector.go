@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/registry"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,7 @@ import (
 // ModelSelector handles model selection based on flags and command hints
 type ModelSelector interface {
 	SelectModel(cmd *cobra.Command, cfg *config.Config, originalArgs []string) (providerName, modelName string, err error)
+	SelectFallbacks(cmd *cobra.Command, cfg *config.Config) []config.ModelRef
 }
 
 // DefaultModelSelector implements ModelSelector
@@ -22,6 +24,14 @@ func NewModelSelector() *DefaultModelSelector {
 	return &DefaultModelSelector{}
 }
 
+// modelSlot is one models.<location>.<type> entry
+type modelSlot struct {
+	provider  string
+	name      string
+	fallbacks []config.ModelRef
+	path      string
+}
+
 // SelectModel determines which provider and model to use based on CLI flags / command hints
 // TODO: this doesn't need to be so tightly coupled to the *cobra.Command type
 func (s *DefaultModelSelector) SelectModel(cmd *cobra.Command, cfg *config.Config, originalArgs []string) (providerName, modelName string, err error) {
@@ -35,8 +45,57 @@ func (s *DefaultModelSelector) SelectModel(cmd *cobra.Command, cfg *config.Confi
 		return "mock", "test-model", nil
 	}
 
+	useLocal, useDeep := s.preferences(cmd, cfg)
+	slot := s.slot(cfg, useLocal, useDeep)
+
+	// an explicit --model overrides the slot's model, and its provider when named
+	if model, _ := cmd.Flags().GetString("model"); model != "" {
+		providerName, modelName = splitModelFlag(model, slot.provider)
+		if providerName == "" {
+			return "", "", fmt.Errorf("failed to select model: --model %q names no provider and %s.provider is not set", model, slot.path)
+		}
+		return providerName, modelName, nil
+	}
+
+	// validiate the selected model config
+	if slot.provider == "" || slot.name == "" {
+		return "", "", fmt.Errorf("failed to select model: %w", s.generateModelConfigError(slot.path, useLocal, useDeep))
+	}
+
+	return slot.provider, slot.name, nil
+}
+
+// SelectFallbacks returns the fallbacks of the slot SelectModel chose, in the
+// order they should be tried. call it after SelectModel so command hints apply
+func (s *DefaultModelSelector) SelectFallbacks(cmd *cobra.Command, cfg *config.Config) []config.ModelRef {
+	// the mock provider never fails over to a real one
+	if testFlag, _ := cmd.Flags().GetBool("test"); testFlag {
+		return nil
+	}
+
+	// a model picked by hand is used as given
+	if model, _ := cmd.Flags().GetString("model"); model != "" {
+		return nil
+	}
+
+	useLocal, useDeep := s.preferences(cmd, cfg)
+	return s.slot(cfg, useLocal, useDeep).fallbacks
+}
+
+// splitModelFlag parses --model. "provider/model" is split only when the
+// prefix is a registered provider, since model names such as
+// "meta-llama/Llama-3-8B" contain slashes too; anything else is a model on
+// the default provider
+func splitModelFlag(value, defaultProvider string) (providerName, modelName string) {
+	if prefix, rest, found := strings.Cut(value, "/"); found && rest != "" && registry.IsProviderRegistered(prefix) {
+		return prefix, rest
+	}
+	return defaultProvider, value
+}
+
+// preferences resolves the location and model type from flags, falling back to config defaults
+func (s *DefaultModelSelector) preferences(cmd *cobra.Command, cfg *config.Config) (useLocal, useDeep bool) {
 	// determine location pref
-	useLocal := false
 	if localFlag, _ := cmd.Flags().GetBool("local"); localFlag {
 		useLocal = true
 	} else if remoteFlag, _ := cmd.Flags().GetBool("remote"); remoteFlag {
@@ -47,7 +106,6 @@ func (s *DefaultModelSelector) SelectModel(cmd *cobra.Command, cfg *config.Confi
 	}
 
 	// determine deep/fast preference
-	useDeep := false
 	if deepFlag, _ := cmd.Flags().GetBool("deep"); deepFlag {
 		useDeep = true
 	} else if fastFlag, _ := cmd.Flags().GetBool("fast"); fastFlag {
@@ -57,38 +115,25 @@ func (s *DefaultModelSelector) SelectModel(cmd *cobra.Command, cfg *config.Confi
 		useDeep = cfg.Parameters.DefaultModelType == "deep"
 	}
 
-	// select appropriate model config and then validate it
-	var selectedProvider, selectedModel string
-	var modelPath string
+	return useLocal, useDeep
+}
 
+// slot returns the model config for a location and model type
+func (s *DefaultModelSelector) slot(cfg *config.Config, useLocal, useDeep bool) modelSlot {
 	if useLocal {
 		if useDeep {
-			selectedProvider = cfg.Models.Local.Deep.Provider
-			selectedModel = cfg.Models.Local.Deep.Name
-			modelPath = "models.local.deep"
-		} else {
-			selectedProvider = cfg.Models.Local.Fast.Provider
-			selectedModel = cfg.Models.Local.Fast.Name
-			modelPath = "models.local.fast"
-		}
-	} else {
-		if useDeep {
-			selectedProvider = cfg.Models.Remote.Deep.Provider
-			selectedModel = cfg.Models.Remote.Deep.Name
-			modelPath = "models.remote.deep"
-		} else {
-			selectedProvider = cfg.Models.Remote.Fast.Provider
-			selectedModel = cfg.Models.Remote.Fast.Name
-			modelPath = "models.remote.fast"
+			m := cfg.Models.Local.Deep
+			return modelSlot{m.Provider, m.Name, m.Fallbacks, "models.local.deep"}
 		}
+		m := cfg.Models.Local.Fast
+		return modelSlot{m.Provider, m.Name, m.Fallbacks, "models.local.fast"}
 	}
-
-	// validiate the selected model config
-	if selectedProvider == "" || selectedModel == "" {
-		return "", "", fmt.Errorf("failed to select model: %w", s.generateModelConfigError(modelPath, useLocal, useDeep))
+	if useDeep {
+		m := cfg.Models.Remote.Deep
+		return modelSlot{m.Provider, m.Name, m.Fallbacks, "models.remote.deep"}
 	}
-
-	return selectedProvider, selectedModel, nil
+	m := cfg.Models.Remote.Fast
+	return modelSlot{m.Provider, m.Name, m.Fallbacks, "models.remote.fast"}
 }
 
 // applyCommandHints applies command hints to flags not explicitly set
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
+	"github.com/chriscorrea/slop/internal/app"
 	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/registry"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -237,3 +241,136 @@ func TestDefaultModelSelector_SelectModel(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultModelSelector_SelectFallbacks(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Models.Remote.Fast.Fallbacks = []config.ModelRef{{Provider: "groq", Name: "llama-3.1-8b-instant"}}
+	cfg.Models.Local.Deep.Fallbacks = []config.ModelRef{{Provider: "ollama", Name: "qwen3:14b"}}
+	cfg.Commands = map[string]config.Command{
+		"review": {ModelType: "local-deep"},
+	}
+	selector := NewModelSelector()
+
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{}
+		for _, name := range []string{"test", "local", "remote", "fast", "deep"} {
+			cmd.Flags().Bool(name, false, "")
+		}
+		for flag, value := range flags {
+			assert.NoError(t, cmd.Flags().Set(flag, value))
+		}
+		return cmd
+	}
+
+	t.Run("default slot", func(t *testing.T) {
+		cmd := newCmd(nil)
+		_, _, err := selector.SelectModel(cmd, cfg, []string{})
+		assert.NoError(t, err)
+		assert.Equal(t, cfg.Models.Remote.Fast.Fallbacks, selector.SelectFallbacks(cmd, cfg))
+	})
+
+	t.Run("command hint slot", func(t *testing.T) {
+		cmd := newCmd(nil)
+		_, _, err := selector.SelectModel(cmd, cfg, []string{"review"})
+		assert.NoError(t, err)
+		assert.Equal(t, cfg.Models.Local.Deep.Fallbacks, selector.SelectFallbacks(cmd, cfg))
+	})
+
+	t.Run("slot without fallbacks", func(t *testing.T) {
+		cmd := newCmd(map[string]string{"deep": "true"})
+		assert.Empty(t, selector.SelectFallbacks(cmd, cfg))
+	})
+
+	t.Run("test mode", func(t *testing.T) {
+		cmd := newCmd(map[string]string{"test": "true"})
+		assert.Empty(t, selector.SelectFallbacks(cmd, cfg))
+	})
+}
+
+func TestDefaultModelSelector_ModelFlag(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Models.Remote.Fast.Provider = "together"
+	cfg.Models.Remote.Fast.Name = "deepseek-ai/DeepSeek-V3"
+	cfg.Models.Remote.Fast.Fallbacks = []config.ModelRef{{Provider: "groq", Name: "llama-3.1-8b-instant"}}
+	selector := NewModelSelector()
+
+	newCmd := func(model string, flags ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		for _, name := range []string{"test", "local", "remote", "fast", "deep"} {
+			cmd.Flags().Bool(name, false, "")
+		}
+		cmd.Flags().String("model", "", "")
+		assert.NoError(t, cmd.Flags().Set("model", model))
+		for _, flag := range flags {
+			assert.NoError(t, cmd.Flags().Set(flag, "true"))
+		}
+		return cmd
+	}
+
+	tests := []struct {
+		name             string
+		model            string
+		flags            []string
+		expectedProvider string
+		expectedModel    string
+	}{
+		{"provider and model", "openai/gpt-4o-mini", nil, "openai", "gpt-4o-mini"},
+		{"model with slashes on a provider", "together/meta-llama/Llama-3-8b-chat-hf", nil, "together", "meta-llama/Llama-3-8b-chat-hf"},
+		{"model on the selected provider", "mistral-large-latest", nil, "together", "mistral-large-latest"},
+		{"slashed model on the selected provider", "meta-llama/Llama-3-8b-chat-hf", nil, "together", "meta-llama/Llama-3-8b-chat-hf"},
+		{"model on the local provider", "qwen3:8b", []string{"local"}, "ollama", "qwen3:8b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCmd(tt.model, tt.flags...)
+			providerName, modelName, err := selector.SelectModel(cmd, cfg, []string{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedProvider, providerName)
+			assert.Equal(t, tt.expectedModel, modelName)
+			assert.Empty(t, selector.SelectFallbacks(cmd, cfg), "an explicit model has no fallbacks")
+		})
+	}
+
+	t.Run("custom provider", func(t *testing.T) {
+		registered := &config.Config{}
+		registered.Providers.Custom = map[string]config.CustomProvider{
+			"vllm": {BaseProvider: config.BaseProvider{BaseUrl: "http://localhost:8000/v1"}},
+		}
+		assert.NoError(t, registry.RegisterCustomProviders(registered))
+		defer func() { assert.NoError(t, registry.RegisterCustomProviders(&config.Config{})) }()
+
+		providerName, modelName, err := selector.SelectModel(newCmd("vllm/Qwen/Qwen2.5-7B-Instruct"), cfg, []string{})
+		assert.NoError(t, err)
+		assert.Equal(t, "vllm", providerName)
+		assert.Equal(t, "Qwen/Qwen2.5-7B-Instruct", modelName)
+	})
+
+	t.Run("test mode wins", func(t *testing.T) {
+		providerName, _, err := selector.SelectModel(newCmd("openai/gpt-4o", "test"), cfg, []string{})
+		assert.NoError(t, err)
+		assert.Equal(t, "mock", providerName)
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		empty := config.NewDefaultFromEmbedded()
+		empty.Models.Remote.Fast.Provider = ""
+		_, _, err := selector.SelectModel(newCmd("gpt-4o"), empty, []string{})
+		assert.ErrorContains(t, err, "models.remote.fast.provider")
+	})
+}
+
+func TestPrintFallbacks(t *testing.T) {
+	var buf bytes.Buffer
+	printFallbacks(&buf, &app.Result{Provider: "mistral", Model: "mistral-small-latest"})
+	assert.Empty(t, buf.String())
+
+	printFallbacks(&buf, &app.Result{
+		Provider: "ollama",
+		Model:    "gemma4:latest",
+		Fallbacks: []app.FailedAttempt{
+			{Provider: "mistral", Model: "mistral-small-latest", Err: errors.New("Mistral API rate limit exceeded.\n\nPlease try again later")},
+		},
+	})
+	assert.Equal(t, "Warning: mistral/mistral-small-latest unavailable: Mistral API rate limit exceeded.\n"+
+		"Answered by fallback model ollama/gemma4:latest\n", buf.String())
+}
@@ -29,7 +29,10 @@ Examples:
   slop config set parameters.system_prompt="You are helpful"
   slop config set parameters.max_tokens=1024
   slop config set models.remote.light.provider=mistral
-  slop config set anthropic_key=ak-65433210`,
+  slop config set models.remote.fast.name=gpt-4o-mini
+  slop config set anthropic_key=ak-65433210
+
+Model names are checked against the provider's list cached by 'slop models list'.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Parse the key=value argument
@@ -76,6 +79,14 @@ Examples:
 		manager := state.manager
 		viper := manager.Viper()
 
+		// check model names against the slot provider's cached model list
+		if force, _ := cmd.Flags().GetBool("force"); !force && strings.HasPrefix(canonicalKey, "models.") && strings.HasSuffix(canonicalKey, ".name") {
+			provider := viper.GetString(strings.TrimSuffix(canonicalKey, ".name") + ".provider")
+			if err := checkModelName(provider, fmt.Sprint(convertedValue)); err != nil {
+				return fmt.Errorf("%w\nUse --force to set it anyway", err)
+			}
+		}
+
 		// Set the value in Viper using the canonical key
 		viper.Set(canonicalKey, convertedValue)
 
@@ -138,5 +149,6 @@ func convertValueToType(value string, targetType reflect.Type) (interface{}, err
 }
 
 func init() {
+	setCmd.Flags().Bool("force", false, "Skip checking model names against the cached model list")
 	configCmd.AddCommand(setCmd)
 }
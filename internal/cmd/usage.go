@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/app"
+	"github.com/chriscorrea/slop/internal/data"
+	"github.com/chriscorrea/slop/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+// createUsageCommand creates the usage subcommand
+func createUsageCommand() *cobra.Command {
+	usageCmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Summarize token usage and cost",
+		Long: `Summarize the token usage and estimated cost recorded in ~/.slop/usage.jsonl.
+
+Every successful generation appends an entry with its provider, model, token
+counts, latency and named command. Costs use the list prices in slop's model
+table at the time of the run; runs of models without a known price are counted
+but not costed.
+
+Examples:
+  slop usage                    # Summarize by day
+  slop usage --by model         # Summarize by provider and model
+  slop usage --by command -n 7  # Summarize named commands over the last 7 days`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			groupBy, _ := cmd.Flags().GetString("by")
+			days, _ := cmd.Flags().GetInt("days")
+
+			path, err := usage.DefaultPath()
+			if err != nil {
+				return err
+			}
+			entries, err := usage.NewLedger(path).Entries()
+			if err != nil {
+				return err
+			}
+
+			if days > 0 {
+				now := time.Now()
+				midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+				entries = usage.Since(entries, midnight.AddDate(0, 0, -(days-1)))
+			}
+
+			summaries, total, err := usage.Summarize(entries, groupBy)
+			if err != nil {
+				return err
+			}
+
+			if total.Runs == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No usage recorded in %s\n", path)
+				return nil
+			}
+
+			printUsageTable(cmd.OutOrStdout(), groupBy, summaries, total)
+			return nil
+		},
+	}
+
+	usageCmd.Flags().String("by", "day", fmt.Sprintf("Group usage by %s", strings.Join(usage.GroupBy, "|")))
+	usageCmd.Flags().IntP("days", "n", 0, "Only include the last N days (0 for all)")
+
+	return usageCmd
+}
+
+// printUsageTable writes one row per group followed by a total row
+func printUsageTable(w io.Writer, groupBy string, summaries []usage.Summary, total usage.Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintf(tw, "%s\tRuns\tPrompt Tokens\tCompletion Tokens\tAvg Latency\tCost\n", strings.ToUpper(groupBy[:1])+groupBy[1:])
+	for _, summary := range append(summaries, total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n",
+			summary.Key,
+			summary.Runs,
+			summary.PromptTokens,
+			summary.CompletionTokens,
+			summary.AverageLatency().Round(time.Millisecond),
+			formatCost(summary))
+	}
+	tw.Flush()
+
+	if total.Unpriced > 0 {
+		fmt.Fprintf(w, "\n* excludes %d run(s) of models without a known price\n", total.Unpriced)
+	}
+}
+
+// formatCost renders a summary's cost, flagging groups with unpriced runs
+func formatCost(summary usage.Summary) string {
+	switch {
+	case summary.Unpriced == summary.Runs:
+		return "-"
+	case summary.Unpriced > 0:
+		return fmt.Sprintf("$%.4f*", summary.Cost)
+	default:
+		return fmt.Sprintf("$%.4f", summary.Cost)
+	}
+}
+
+// recordUsage appends a successful run to the usage ledger. the ledger is
+// bookkeeping, so failures are logged rather than failing the command
+func recordUsage(providerName, modelName, commandName string, result *app.Result) {
+	// cached responses and --test runs against the mock provider cost nothing
+	if providerName == "mock" || (result.Generation != nil && result.Generation.Cached) {
+		return
+	}
+
+	path, err := usage.DefaultPath()
+	if err != nil {
+		state.logger.Warn("Failed to record usage", "error", err)
+		return
+	}
+
+	prices := data.NewProviderRegistry()
+	if err := prices.Load(); err != nil {
+		state.logger.Warn("Failed to load model prices", "error", err)
+	}
+
+	entry := newUsageEntry(providerName, modelName, commandName, result, prices, time.Now())
+	if err := usage.NewLedger(path).Append(entry); err != nil {
+		state.logger.Warn("Failed to record usage", "error", err)
+	}
+}
+
+// newUsageEntry builds the ledger entry for a run, pricing the resolved model
+// and falling back to the requested one
+func newUsageEntry(providerName, modelName, commandName string, result *app.Result, prices *data.ProviderRegistry, now time.Time) usage.Entry {
+	entry := usage.Entry{
+		Time:      now.UTC(),
+		Provider:  providerName,
+		Model:     modelName,
+		Command:   commandName,
+		LatencyMS: result.Latency.Milliseconds(),
+	}
+
+	generation := result.Generation
+	if generation == nil {
+		return entry
+	}
+	if generation.Model != "" {
+		entry.Model = generation.Model
+	}
+	if generation.Usage != nil {
+		entry.PromptTokens = generation.Usage.PromptTokens
+		entry.CompletionTokens = generation.Usage.CompletionTokens
+	}
+
+	price, ok := prices.GetModelPrice(providerName, entry.Model)
+	if !ok {
+		price, ok = prices.GetModelPrice(providerName, modelName)
+	}
+	// a run without reported usage can't be costed
+	if ok && generation.Usage != nil {
+		cost := price.Cost(entry.PromptTokens, entry.CompletionTokens)
+		entry.Cost = &cost
+	}
+	return entry
+}
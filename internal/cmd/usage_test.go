@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/app"
+	"github.com/chriscorrea/slop/internal/data"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/usage"
+)
+
+func TestNewUsageEntry(t *testing.T) {
+	prices := data.NewProviderRegistry()
+	if err := prices.Load(); err != nil {
+		t.Fatalf("Failed to load prices: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("PricedResolvedModel", func(t *testing.T) {
+		result := &app.Result{
+			Latency: 1500 * time.Millisecond,
+			Generation: &common.GenerateResult{
+				Model: "claude-haiku-4-5-20251001",
+				Usage: &common.Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000, TotalTokens: 1_100_000},
+			},
+		}
+
+		entry := newUsageEntry("anthropic", "claude-haiku-4-5", "review", result, prices, now)
+
+		if entry.Model != "claude-haiku-4-5-20251001" {
+			t.Errorf("Expected resolved model, got %q", entry.Model)
+		}
+		if entry.Command != "review" || entry.LatencyMS != 1500 || entry.PromptTokens != 1_000_000 {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if entry.Cost == nil || *entry.Cost != 1.5 {
+			t.Errorf("Expected cost 1.5, got %v", entry.Cost)
+		}
+	})
+
+	t.Run("UnpricedModel", func(t *testing.T) {
+		result := &app.Result{Generation: &common.GenerateResult{
+			Model: "mystery-model",
+			Usage: &common.Usage{PromptTokens: 10, CompletionTokens: 5},
+		}}
+
+		entry := newUsageEntry("openai", "mystery-model", "", result, prices, now)
+		if entry.Cost != nil {
+			t.Errorf("Expected no cost for an unpriced model, got %v", *entry.Cost)
+		}
+	})
+
+	t.Run("NoReportedUsage", func(t *testing.T) {
+		result := &app.Result{Generation: &common.GenerateResult{Model: "gpt-4o"}}
+
+		entry := newUsageEntry("openai", "gpt-4o", "", result, prices, now)
+		if entry.Cost != nil {
+			t.Errorf("Expected no cost without usage, got %v", *entry.Cost)
+		}
+	})
+}
+
+func TestPrintUsageTable(t *testing.T) {
+	cost := 0.25
+	entries := []usage.Entry{
+		{Time: time.Now(), Provider: "anthropic", Model: "claude-haiku-4-5", PromptTokens: 100, CompletionTokens: 50, LatencyMS: 1000, Cost: &cost},
+		{Time: time.Now(), Provider: "openai", Model: "gpt-5.4", PromptTokens: 10, CompletionTokens: 5, LatencyMS: 3000},
+	}
+	summaries, total, err := usage.Summarize(entries, "provider")
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	printUsageTable(&buf, "provider", summaries, total)
+	output := buf.String()
+
+	expectedStrings := []string{
+		"Provider", "Runs", "Cost",
+		"anthropic", "$0.2500",
+		"openai", "-",
+		"total", "$0.2500*", "2s",
+		"excludes 1 run(s)",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got: %s", expected, output)
+		}
+	}
+}
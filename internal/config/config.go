@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -37,6 +39,8 @@ func NewManager() *Manager {
 	v.RegisterAlias("openai-key", "providers.openai.api_key")
 	v.RegisterAlias("groq-key", "providers.groq.api_key")
 	v.RegisterAlias("together-key", "providers.together.api_key")
+	v.RegisterAlias("gemini-key", "providers.gemini.api_key")
+	v.RegisterAlias("azure-key", "providers.azure.api_key")
 
 	// Bind provider API keys to intuitive environment variable names
 	_ = v.BindEnv("providers.mistral.api_key", "MISTRAL_API_KEY")
@@ -45,6 +49,14 @@ func NewManager() *Manager {
 	_ = v.BindEnv("providers.openai.api_key", "OPENAI_API_KEY")
 	_ = v.BindEnv("providers.groq.api_key", "GROQ_API_KEY")
 	_ = v.BindEnv("providers.together.api_key", "TOGETHER_API_KEY")
+	_ = v.BindEnv("providers.gemini.api_key", "GEMINI_API_KEY", "GOOGLE_API_KEY")
+	_ = v.BindEnv("providers.azure.api_key", "AZURE_OPENAI_API_KEY")
+	_ = v.BindEnv("providers.azure.base_url", "AZURE_OPENAI_ENDPOINT")
+
+	// cassettes switch every slop call in a pipeline at once
+	_ = v.BindEnv("parameters.record", "SLOP_RECORD")
+	_ = v.BindEnv("parameters.replay", "SLOP_REPLAY")
+	_ = v.BindEnv("providers.mock.rules", "SLOP_MOCK_RULES")
 
 	return &Manager{
 		v:   v,
@@ -165,12 +177,80 @@ func (m *Manager) Load(configPath string) error {
 		return err
 	}
 
+	// enabled tools must be declared and well-formed
+	if err := m.validateTools(); err != nil {
+		return err
+	}
+
+	if err := m.validateCache(); err != nil {
+		return err
+	}
+
+	if err := m.validateContextBudget(); err != nil {
+		return err
+	}
+
+	if err := m.validateMapReduce(); err != nil {
+		return err
+	}
+
+	if err := m.validateFallbacks(); err != nil {
+		return err
+	}
+
+	if err := m.validateRetry(); err != nil {
+		return err
+	}
+
+	if err := m.validateTransport(); err != nil {
+		return err
+	}
+
+	if err := m.validateCassette(); err != nil {
+		return err
+	}
+
+	if err := m.validateCustomProviders(); err != nil {
+		return err
+	}
+
+	if err := m.validatePluginProviders(); err != nil {
+		return err
+	}
+
 	// post-process configuration to handle special cases
 	m.postProcessConfig()
 
 	return nil
 }
 
+// validateTools checks every declared tool has a command and a valid
+// parameters schema, and that enabled tool names refer to declared tools
+func (m *Manager) validateTools() error {
+	for name, tool := range m.cfg.Tools {
+		if strings.TrimSpace(tool.Command) == "" {
+			return fmt.Errorf("tools.%s: command is required", name)
+		}
+		if params := strings.TrimSpace(tool.Parameters); params != "" {
+			var parsed map[string]interface{}
+			if err := json.Unmarshal([]byte(params), &parsed); err != nil {
+				return fmt.Errorf("tools.%s: parameters must be a JSON schema object: %w", name, err)
+			}
+		}
+	}
+
+	enabled := append([]string{}, m.cfg.Parameters.Tools...)
+	for _, cmd := range m.cfg.Commands {
+		enabled = append(enabled, cmd.Tools...)
+	}
+	for _, name := range enabled {
+		if _, ok := m.cfg.Tools[name]; !ok {
+			return fmt.Errorf("tool %q is enabled but not declared under [tools]", name)
+		}
+	}
+	return nil
+}
+
 // validateThinking rejects unknown values for parameters.thinking so the
 // user sees a clear error at load time instead of a silent no-op later.
 func (m *Manager) validateThinking() error {
@@ -182,6 +262,201 @@ func (m *Manager) validateThinking() error {
 	}
 }
 
+// validateCache checks the cache TTL parses and the size cap is usable
+func (m *Manager) validateCache() error {
+	p := m.cfg.Parameters
+	if p.CacheTTL != "" {
+		ttl, err := time.ParseDuration(p.CacheTTL)
+		if err != nil || ttl < 0 {
+			return fmt.Errorf("invalid parameters.cache_ttl %q: expected a duration such as 30m or 24h", p.CacheTTL)
+		}
+	}
+	if p.CacheMaxMB < 0 {
+		return fmt.Errorf("invalid parameters.cache_max_mb %d: must not be negative", p.CacheMaxMB)
+	}
+	return nil
+}
+
+// validateContextBudget checks the context window override and overflow strategy
+func (m *Manager) validateContextBudget() error {
+	p := m.cfg.Parameters
+	if p.ContextWindow < 0 {
+		return fmt.Errorf("invalid parameters.context_window %d: must not be negative", p.ContextWindow)
+	}
+	switch p.ContextOverflow {
+	case "", "truncate", "drop", "fail":
+		return nil
+	default:
+		return fmt.Errorf("invalid parameters.context_overflow %q: expected truncate|drop|fail", p.ContextOverflow)
+	}
+}
+
+// validateMapReduce checks the part sizes and concurrency are usable
+func (m *Manager) validateMapReduce() error {
+	mr := m.cfg.Parameters.MapReduce
+	switch {
+	case mr.ChunkTokens < 0:
+		return fmt.Errorf("invalid parameters.map_reduce.chunk_tokens %d: must not be negative", mr.ChunkTokens)
+	case mr.OverlapTokens < 0:
+		return fmt.Errorf("invalid parameters.map_reduce.overlap_tokens %d: must not be negative", mr.OverlapTokens)
+	case mr.ChunkTokens > 0 && mr.OverlapTokens*2 > mr.ChunkTokens:
+		return fmt.Errorf("invalid parameters.map_reduce.overlap_tokens %d: must be at most half of chunk_tokens %d", mr.OverlapTokens, mr.ChunkTokens)
+	case mr.Concurrency < 0:
+		return fmt.Errorf("invalid parameters.map_reduce.concurrency %d: must not be negative", mr.Concurrency)
+	}
+	return nil
+}
+
+// validateFallbacks checks every fallback model names both a provider and a model
+func (m *Manager) validateFallbacks() error {
+	slots := []struct {
+		path      string
+		fallbacks []ModelRef
+	}{
+		{"models.remote.fast", m.cfg.Models.Remote.Fast.Fallbacks},
+		{"models.remote.deep", m.cfg.Models.Remote.Deep.Fallbacks},
+		{"models.local.fast", m.cfg.Models.Local.Fast.Fallbacks},
+		{"models.local.deep", m.cfg.Models.Local.Deep.Fallbacks},
+	}
+	for _, slot := range slots {
+		for i, ref := range slot.fallbacks {
+			if strings.TrimSpace(ref.Provider) == "" || strings.TrimSpace(ref.Name) == "" {
+				return fmt.Errorf("%s.fallbacks[%d]: provider and name are required", slot.path, i)
+			}
+		}
+	}
+	return nil
+}
+
+// namedProvider is a provider's shared settings and its config path
+type namedProvider struct {
+	path     string
+	settings BaseProvider
+}
+
+// baseProviders lists the shared settings of every HTTP provider
+func (p Providers) baseProviders() []namedProvider {
+	providers := []namedProvider{
+		{"providers.anthropic", p.Anthropic.BaseProvider},
+		{"providers.openai", p.OpenAI.BaseProvider},
+		{"providers.cohere", p.Cohere.BaseProvider},
+		{"providers.ollama", p.Ollama.BaseProvider},
+		{"providers.mistral", p.Mistral.BaseProvider},
+		{"providers.groq", p.Groq.BaseProvider},
+		{"providers.together", p.Together.BaseProvider},
+		{"providers.gemini", p.Gemini.BaseProvider},
+		{"providers.azure", p.Azure.BaseProvider},
+	}
+	for name, custom := range p.Custom {
+		providers = append(providers, namedProvider{"providers.custom." + name, custom.BaseProvider})
+	}
+	return providers
+}
+
+// validateRetry checks the global and per-provider retry settings parse
+func (m *Manager) validateRetry() error {
+	type namedRetry struct {
+		path  string
+		retry Retry
+	}
+	policies := []namedRetry{{"parameters.retry", m.cfg.Parameters.Retry}}
+	for _, provider := range m.cfg.Providers.baseProviders() {
+		policies = append(policies, namedRetry{provider.path + ".retry", provider.settings.Retry})
+	}
+
+	for _, policy := range policies {
+		r := policy.retry
+		for key, value := range map[string]string{"base_delay": r.BaseDelay, "max_delay": r.MaxDelay} {
+			if value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("invalid %s.%s %q: expected a duration such as 500ms or 10s", policy.path, key, value)
+			}
+		}
+		if r.Jitter != nil && (*r.Jitter < 0 || *r.Jitter > 1) {
+			return fmt.Errorf("invalid %s.jitter %v: must be between 0 and 1", policy.path, *r.Jitter)
+		}
+		for _, code := range r.StatusCodes {
+			if code < 100 || code > 599 {
+				return fmt.Errorf("invalid %s.status_codes: %d is not an HTTP status code", policy.path, code)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTransport checks each provider's proxy URL and timeout. the CA
+// bundle is read when the provider's client is created
+func (m *Manager) validateTransport() error {
+	if m.cfg.Parameters.Timeout < 0 {
+		return fmt.Errorf("invalid parameters.timeout %d: must be 0 or more seconds", m.cfg.Parameters.Timeout)
+	}
+	for _, provider := range m.cfg.Providers.baseProviders() {
+		settings := provider.settings
+		if settings.ProxyURL != "" {
+			u, err := url.Parse(settings.ProxyURL)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid %s.proxy_url %q: expected a URL such as http://proxy.internal:3128", provider.path, settings.ProxyURL)
+			}
+		}
+		if settings.Timeout < 0 {
+			return fmt.Errorf("invalid %s.timeout %d: must be 0 or more seconds", provider.path, settings.Timeout)
+		}
+	}
+	return nil
+}
+
+// validateCassette checks that record and replay aren't both set and that a
+// replay directory exists, so a mistyped path fails before any request
+func (m *Manager) validateCassette() error {
+	p := m.cfg.Parameters
+	if p.Record != "" && p.Replay != "" {
+		return fmt.Errorf("parameters.record and parameters.replay can't both be set; record once, then replay")
+	}
+	if p.Replay != "" {
+		if info, err := os.Stat(p.Replay); err != nil || !info.IsDir() {
+			return fmt.Errorf("invalid parameters.replay %q: not a directory of recorded responses", p.Replay)
+		}
+	}
+	return nil
+}
+
+// validateCustomProviders checks every custom provider has a usable name and
+// an absolute base URL. clashes with built-in providers are caught at registration
+func (m *Manager) validateCustomProviders() error {
+	for name, custom := range m.cfg.Providers.Custom {
+		if strings.ContainsAny(name, "/ ") {
+			return fmt.Errorf("providers.custom.%s: name must not contain '/' or spaces", name)
+		}
+		if strings.TrimSpace(custom.BaseUrl) == "" {
+			return fmt.Errorf("providers.custom.%s: base_url is required", name)
+		}
+		u, err := url.Parse(custom.BaseUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("providers.custom.%s: invalid base_url %q: expected an http(s) URL such as http://localhost:8000/v1", name, custom.BaseUrl)
+		}
+		if custom.MaxRetries < 0 || custom.MaxRetries > 5 {
+			return fmt.Errorf("providers.custom.%s: max_retries must be between 0 and 5", name)
+		}
+	}
+	return nil
+}
+
+// validatePluginProviders checks every plugin provider has a usable name and a
+// command. clashes with other providers are caught at registration
+func (m *Manager) validatePluginProviders() error {
+	for name, plugin := range m.cfg.Providers.Plugin {
+		if strings.ContainsAny(name, "/ ") {
+			return fmt.Errorf("providers.plugin.%s: name must not contain '/' or spaces", name)
+		}
+		if strings.TrimSpace(plugin.Command) == "" {
+			return fmt.Errorf("providers.plugin.%s: command is required", name)
+		}
+	}
+	return nil
+}
+
 // resolveResponseSchema accepts either a file path or inline JSON on the
 // response_schema parameter. Values starting with "{" or "[" are treated
 // as inline JSON and anything else is read from disk. The resolved JSON is
@@ -353,6 +628,26 @@ func (m *Manager) postProcessConfig() {
 			m.cfg.Parameters.Seed = &seedValue
 		}
 	}
+
+	// replayed requests never reach a provider, so runs in CI need no API keys
+	if m.cfg.Parameters.Replay != "" {
+		m.cfg.Providers.fillReplayKeys()
+	}
+}
+
+// replayAPIKey stands in for missing API keys when replaying cassettes
+const replayAPIKey = "replay"
+
+// fillReplayKeys sets a placeholder key on every built-in provider without one
+func (p *Providers) fillReplayKeys() {
+	for _, key := range []*string{
+		&p.Anthropic.APIKey, &p.OpenAI.APIKey, &p.Cohere.APIKey, &p.Mistral.APIKey,
+		&p.Groq.APIKey, &p.Together.APIKey, &p.Gemini.APIKey, &p.Azure.APIKey,
+	} {
+		if *key == "" {
+			*key = replayAPIKey
+		}
+	}
 }
 
 // WithCommandOverrides creates a new Config with command overrides applied
@@ -371,6 +666,9 @@ func (c *Config) WithCommandOverrides(cmd Command) *Config {
 	if cmd.MaxTokens != nil {
 		newConfig.Parameters.MaxTokens = *cmd.MaxTokens
 	}
+	if len(cmd.Tools) > 0 {
+		newConfig.Parameters.Tools = cmd.Tools
+	}
 
 	return &newConfig
 }
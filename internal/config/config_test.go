@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -744,6 +745,27 @@ func TestCanonicalConfigPaths(t *testing.T) {
 			getAPIKey:     func(m *Manager) string { return m.cfg.Providers.Cohere.APIKey },
 			canonicalPath: "providers.cohere.api_key",
 		},
+		{
+			name:          "Gemini canonical path",
+			envVar:        "GEMINI_API_KEY",
+			envValue:      "test-gemini-key",
+			getAPIKey:     func(m *Manager) string { return m.cfg.Providers.Gemini.APIKey },
+			canonicalPath: "providers.gemini.api_key",
+		},
+		{
+			name:          "Gemini Google key fallback",
+			envVar:        "GOOGLE_API_KEY",
+			envValue:      "test-google-key",
+			getAPIKey:     func(m *Manager) string { return m.cfg.Providers.Gemini.APIKey },
+			canonicalPath: "providers.gemini.api_key",
+		},
+		{
+			name:          "Azure canonical path",
+			envVar:        "AZURE_OPENAI_API_KEY",
+			envValue:      "test-azure-key",
+			getAPIKey:     func(m *Manager) string { return m.cfg.Providers.Azure.APIKey },
+			canonicalPath: "providers.azure.api_key",
+		},
 	}
 
 	for _, tt := range tests {
@@ -804,6 +826,20 @@ func TestConfigAliases(t *testing.T) {
 			envVar:        "COHERE_API_KEY",
 			envValue:      "test-cohere-alias",
 		},
+		{
+			name:          "Gemini alias",
+			alias:         "gemini-key",
+			canonicalPath: "providers.gemini.api_key",
+			envVar:        "GEMINI_API_KEY",
+			envValue:      "test-gemini-alias",
+		},
+		{
+			name:          "Azure alias",
+			alias:         "azure-key",
+			canonicalPath: "providers.azure.api_key",
+			envVar:        "AZURE_OPENAI_API_KEY",
+			envValue:      "test-azure-alias",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1032,3 +1068,512 @@ func TestResolveResponseSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTools(t *testing.T) {
+	listFiles := Tool{
+		Command:    `ls "$SLOP_ARG_PATH"`,
+		Parameters: `{"type":"object","properties":{"path":{"type":"string"}}}`,
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *Config
+		errContains string
+	}{
+		{
+			name: "Declared and enabled tool is valid",
+			cfg: &Config{
+				Parameters: Parameters{Tools: []string{"list_files"}},
+				Tools:      map[string]Tool{"list_files": listFiles},
+			},
+		},
+		{
+			name: "Enabled tool must be declared",
+			cfg: &Config{
+				Parameters: Parameters{Tools: []string{"rm_rf"}},
+				Tools:      map[string]Tool{"list_files": listFiles},
+			},
+			errContains: `tool "rm_rf" is enabled but not declared`,
+		},
+		{
+			name: "Command tools must be declared",
+			cfg: &Config{
+				Commands: map[string]Command{"review": {Tools: []string{"missing"}}},
+			},
+			errContains: `tool "missing" is enabled but not declared`,
+		},
+		{
+			name: "Tool without a command is rejected",
+			cfg: &Config{
+				Tools: map[string]Tool{"empty": {Description: "nothing to run"}},
+			},
+			errContains: "tools.empty: command is required",
+		},
+		{
+			name: "Malformed parameters schema is rejected",
+			cfg: &Config{
+				Tools: map[string]Tool{"bad": {Command: "true", Parameters: `{"type": `}},
+			},
+			errContains: "tools.bad: parameters must be a JSON schema object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{cfg: tt.cfg}
+			err := m.validateTools()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestToolRequiresConfirmation(t *testing.T) {
+	no := false
+	if !(Tool{}).RequiresConfirmation() {
+		t.Error("expected confirmation by default")
+	}
+	if (Tool{Confirm: &no}).RequiresConfirmation() {
+		t.Error("expected confirm = false to skip confirmation")
+	}
+}
+
+func TestValidateCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      Parameters
+		errContains string
+	}{
+		{name: "Defaults are valid", params: Parameters{CacheTTL: "24h", CacheMaxMB: 100}},
+		{name: "Empty TTL is valid", params: Parameters{}},
+		{name: "Zero TTL never expires", params: Parameters{CacheTTL: "0"}},
+		{name: "Unparseable TTL is rejected", params: Parameters{CacheTTL: "1 day"}, errContains: "invalid parameters.cache_ttl"},
+		{name: "Negative TTL is rejected", params: Parameters{CacheTTL: "-5m"}, errContains: "invalid parameters.cache_ttl"},
+		{name: "Negative size cap is rejected", params: Parameters{CacheMaxMB: -1}, errContains: "invalid parameters.cache_max_mb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{cfg: &Config{Parameters: tt.params}}
+			err := m.validateCache()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+
+	if ttl := (Parameters{CacheTTL: "90m"}).CacheTTLDuration(); ttl != 90*time.Minute {
+		t.Errorf("expected 90m TTL, got %v", ttl)
+	}
+}
+
+func TestValidateContextBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      Parameters
+		errContains string
+	}{
+		{name: "Defaults are valid", params: Parameters{ContextOverflow: "truncate"}},
+		{name: "Empty strategy is valid", params: Parameters{}},
+		{name: "Override with fail is valid", params: Parameters{ContextWindow: 8192, ContextOverflow: "fail"}},
+		{name: "Unknown strategy is rejected", params: Parameters{ContextOverflow: "summarize"}, errContains: "invalid parameters.context_overflow"},
+		{name: "Negative window is rejected", params: Parameters{ContextWindow: -1}, errContains: "invalid parameters.context_window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{cfg: &Config{Parameters: tt.params}}
+			err := m.validateContextBudget()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestValidateMapReduce(t *testing.T) {
+	tests := []struct {
+		name        string
+		mapReduce   MapReduce
+		errContains string
+	}{
+		{name: "Defaults are valid", mapReduce: MapReduce{OverlapTokens: 200, Concurrency: 4}},
+		{name: "Fixed part size is valid", mapReduce: MapReduce{ChunkTokens: 4000, OverlapTokens: 200}},
+		{name: "Negative part size is rejected", mapReduce: MapReduce{ChunkTokens: -1}, errContains: "invalid parameters.map_reduce.chunk_tokens"},
+		{name: "Overlap over half a part is rejected", mapReduce: MapReduce{ChunkTokens: 300, OverlapTokens: 200}, errContains: "at most half of chunk_tokens"},
+		{name: "Negative concurrency is rejected", mapReduce: MapReduce{Concurrency: -2}, errContains: "invalid parameters.map_reduce.concurrency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{cfg: &Config{Parameters: Parameters{MapReduce: tt.mapReduce}}}
+			err := m.validateMapReduce()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestLoadModelFallbacks(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[models.remote.fast]
+provider = "mistral"
+name = "mistral-small-latest"
+fallbacks = [
+  { provider = "groq", name = "llama-3.1-8b-instant" },
+  { provider = "ollama", name = "gemma4:latest" },
+]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	manager := NewManager()
+	if err := manager.Load(configPath); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	want := []ModelRef{
+		{Provider: "groq", Name: "llama-3.1-8b-instant"},
+		{Provider: "ollama", Name: "gemma4:latest"},
+	}
+	if got := manager.cfg.Models.Remote.Fast.Fallbacks; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected fallbacks %v, got %v", want, got)
+	}
+
+	// other slots keep the default of no fallbacks
+	if got := manager.cfg.Models.Local.Deep.Fallbacks; len(got) != 0 {
+		t.Errorf("Expected no local deep fallbacks, got %v", got)
+	}
+}
+
+func TestValidateFallbacks(t *testing.T) {
+	valid := &Config{}
+	valid.Models.Remote.Deep.Fallbacks = []ModelRef{{Provider: "groq", Name: "llama-3.3-70b-versatile"}}
+	if err := (&Manager{cfg: valid}).validateFallbacks(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	missingName := &Config{}
+	missingName.Models.Local.Fast.Fallbacks = []ModelRef{{Provider: "ollama", Name: "llama3.2"}, {Provider: "ollama"}}
+	err := (&Manager{cfg: missingName}).validateFallbacks()
+	if err == nil || !strings.Contains(err.Error(), "models.local.fast.fallbacks[1]") {
+		t.Errorf("expected error naming models.local.fast.fallbacks[1], got %v", err)
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	jitter := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name        string
+		setup       func(cfg *Config)
+		errContains string
+	}{
+		{name: "Defaults are valid", setup: func(cfg *Config) {}},
+		{name: "Provider override is valid", setup: func(cfg *Config) {
+			cfg.Providers.Groq.Retry = Retry{BaseDelay: "500ms", MaxDelay: "0", Jitter: jitter(0), StatusCodes: []int{429}}
+		}},
+		{name: "Unparseable delay is rejected", setup: func(cfg *Config) {
+			cfg.Parameters.Retry.BaseDelay = "3 seconds"
+		}, errContains: "invalid parameters.retry.base_delay"},
+		{name: "Negative provider delay is rejected", setup: func(cfg *Config) {
+			cfg.Providers.OpenAI.Retry.MaxDelay = "-1s"
+		}, errContains: "invalid providers.openai.retry.max_delay"},
+		{name: "Jitter above 1 is rejected", setup: func(cfg *Config) {
+			cfg.Parameters.Retry.Jitter = jitter(1.5)
+		}, errContains: "invalid parameters.retry.jitter"},
+		{name: "Bad status code is rejected", setup: func(cfg *Config) {
+			cfg.Providers.Mistral.Retry.StatusCodes = []int{429, 42}
+		}, errContains: "invalid providers.mistral.retry.status_codes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultFromEmbedded()
+			tt.setup(cfg)
+			err := (&Manager{cfg: cfg}).validateRetry()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestValidateTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(cfg *Config)
+		errContains string
+	}{
+		{name: "Defaults are valid", setup: func(cfg *Config) {}},
+		{name: "Provider settings are valid", setup: func(cfg *Config) {
+			cfg.Providers.OpenAI.ProxyURL = "http://proxy.internal:3128"
+			cfg.Providers.OpenAI.Timeout = 300
+			cfg.Providers.OpenAI.Headers = map[string]string{"OpenAI-Organization": "org-123"}
+		}},
+		{name: "Proxy without a scheme is rejected", setup: func(cfg *Config) {
+			cfg.Providers.Anthropic.ProxyURL = "proxy.internal:3128"
+		}, errContains: "invalid providers.anthropic.proxy_url"},
+		{name: "Negative provider timeout is rejected", setup: func(cfg *Config) {
+			cfg.Providers.Groq.Timeout = -1
+		}, errContains: "invalid providers.groq.timeout"},
+		{name: "Custom provider proxy is checked", setup: func(cfg *Config) {
+			cfg.Providers.Custom = map[string]CustomProvider{"vllm": {BaseProvider: BaseProvider{ProxyURL: "::"}}}
+		}, errContains: "invalid providers.custom.vllm.proxy_url"},
+		{name: "Negative global timeout is rejected", setup: func(cfg *Config) {
+			cfg.Parameters.Timeout = -5
+		}, errContains: "invalid parameters.timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultFromEmbedded()
+			tt.setup(cfg)
+			err := (&Manager{cfg: cfg}).validateTransport()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestValidateCassette(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name        string
+		params      Parameters
+		errContains string
+	}{
+		{name: "Neither is valid", params: Parameters{}},
+		{name: "Record is valid", params: Parameters{Record: filepath.Join(dir, "new")}},
+		{name: "Replay of a directory is valid", params: Parameters{Replay: dir}},
+		{name: "Both are rejected", params: Parameters{Record: dir, Replay: dir}, errContains: "can't both be set"},
+		{name: "Missing replay directory is rejected", params: Parameters{Replay: filepath.Join(dir, "missing")}, errContains: "invalid parameters.replay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Manager{cfg: &Config{Parameters: tt.params}}).validateCassette()
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestFillReplayKeys(t *testing.T) {
+	providers := Providers{OpenAI: OpenAI{BaseProvider: BaseProvider{APIKey: "sk-real"}}}
+	providers.fillReplayKeys()
+	if providers.OpenAI.APIKey != "sk-real" {
+		t.Errorf("expected a configured key to be kept, got %q", providers.OpenAI.APIKey)
+	}
+	if providers.Anthropic.APIKey != replayAPIKey || providers.Azure.APIKey != replayAPIKey {
+		t.Errorf("expected placeholder keys, got %q and %q", providers.Anthropic.APIKey, providers.Azure.APIKey)
+	}
+}
+
+func TestRetryMerge(t *testing.T) {
+	honor := false
+	global := NewDefaultFromEmbedded().Parameters.Retry
+	if global.BaseDelay != "3s" || global.Jitter == nil || global.HonorHeaders == nil || !*global.HonorHeaders {
+		t.Fatalf("expected embedded retry defaults, got %+v", global)
+	}
+
+	merged := global.Merge(Retry{MaxDelay: "10s", HonorHeaders: &honor})
+	if merged.BaseDelay != "3s" || merged.MaxDelay != "10s" || *merged.HonorHeaders {
+		t.Errorf("expected overrides on top of the defaults, got %+v", merged)
+	}
+	if !reflect.DeepEqual(merged.StatusCodes, global.StatusCodes) {
+		t.Errorf("expected default status codes, got %v", merged.StatusCodes)
+	}
+}
+
+func TestLoadCustomProviders(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[models.local.fast]
+provider = "vllm"
+name = "meta-llama/Llama-3.1-8B-Instruct"
+
+[providers.custom.vllm]
+base_url = "http://gpu-box:8000/v1"
+api_key = "secret"
+supports_streaming = true
+supports_tools = true
+
+[providers.custom.vllm.headers]
+X-Team = "research"
+
+[providers.custom.lmstudio]
+base_url = "http://localhost:1234/v1"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	manager := NewManager()
+	if err := manager.Load(configPath); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	custom := manager.cfg.Providers.Custom
+	if len(custom) != 2 {
+		t.Fatalf("Expected 2 custom providers, got %v", custom)
+	}
+	vllm := custom["vllm"]
+	if vllm.BaseUrl != "http://gpu-box:8000/v1" || vllm.APIKey != "secret" {
+		t.Errorf("Unexpected vllm settings: %+v", vllm)
+	}
+	if !vllm.SupportsStreaming || !vllm.SupportsTools || vllm.SupportsImages || vllm.SupportsJSON {
+		t.Errorf("Unexpected vllm capabilities: %+v", vllm)
+	}
+	// header names are case-insensitive, and viper lowercases keys
+	if got := vllm.Headers["x-team"]; got != "research" {
+		t.Errorf("Expected x-team header 'research', got %q", got)
+	}
+	if manager.cfg.Models.Local.Fast.Provider != "vllm" {
+		t.Errorf("Expected local fast provider vllm, got %q", manager.cfg.Models.Local.Fast.Provider)
+	}
+}
+
+func TestValidateCustomProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		custom  CustomProvider
+		wantErr string
+	}{
+		{"valid", CustomProvider{BaseProvider: BaseProvider{BaseUrl: "http://localhost:8080/v1"}}, ""},
+		{"missing base_url", CustomProvider{}, "base_url is required"},
+		{"relative base_url", CustomProvider{BaseProvider: BaseProvider{BaseUrl: "localhost:8080"}}, "invalid base_url"},
+		{"max_retries too high", CustomProvider{BaseProvider: BaseProvider{BaseUrl: "http://localhost:8080", MaxRetries: 9}}, "max_retries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Providers: Providers{Custom: map[string]CustomProvider{"llamacpp": tt.custom}}}
+			err := (&Manager{cfg: cfg}).validateCustomProviders()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "providers.custom.llamacpp") {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	slashed := &Config{Providers: Providers{Custom: map[string]CustomProvider{
+		"team/vllm": {BaseProvider: BaseProvider{BaseUrl: "http://localhost:8000/v1"}},
+	}}}
+	if err := (&Manager{cfg: slashed}).validateCustomProviders(); err == nil {
+		t.Error("expected error for a name containing '/'")
+	}
+}
+
+func TestLoadAzureProvider(t *testing.T) {
+	os.Setenv("AZURE_OPENAI_ENDPOINT", "https://my-resource.openai.azure.com")
+	defer os.Unsetenv("AZURE_OPENAI_ENDPOINT")
+
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[providers.azure]
+api_key = "secret"
+
+[providers.azure.deployments]
+"GPT-4o" = "Prod-GPT4o"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	manager := NewManager()
+	if err := manager.Load(configPath); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	azure := manager.cfg.Providers.Azure
+	if azure.BaseUrl != "https://my-resource.openai.azure.com" {
+		t.Errorf("Expected base_url from AZURE_OPENAI_ENDPOINT, got '%s'", azure.BaseUrl)
+	}
+	if azure.APIVersion != "2024-10-21" {
+		t.Errorf("Expected default api_version '2024-10-21', got '%s'", azure.APIVersion)
+	}
+	// viper lowercases keys but keeps deployment names intact
+	if got := azure.Deployments["gpt-4o"]; got != "Prod-GPT4o" {
+		t.Errorf("Expected deployment 'Prod-GPT4o' for gpt-4o, got %v", azure.Deployments)
+	}
+}
+
+func TestValidatePluginProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins map[string]PluginProvider
+		wantErr string
+	}{
+		{"valid", map[string]PluginProvider{"gateway": {Command: "company-gateway", Args: []string{"--region", "eu"}}}, ""},
+		{"missing command", map[string]PluginProvider{"gateway": {}}, "providers.plugin.gateway: command is required"},
+		{"name with slash", map[string]PluginProvider{"team/gateway": {Command: "gateway"}}, "must not contain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Providers: Providers{Plugin: tt.plugins}}
+			err := (&Manager{cfg: cfg}).validatePluginProviders()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -103,6 +103,27 @@ func DefaultConfigSchema() *ConfigSchema {
 				Default:     2,
 				Validation:  validateIntRange(0, 5),
 			},
+			"parameters.retry.base_delay": {
+				Type:        reflect.TypeOf(""),
+				Description: "First wait before retrying a failed request; each retry waits 3x longer",
+				Default:     "3s",
+			},
+			"parameters.retry.max_delay": {
+				Type:        reflect.TypeOf(""),
+				Description: "Longest wait before a retry; servers asking for longer are not retried (0 disables)",
+				Default:     "60s",
+			},
+			"parameters.retry.jitter": {
+				Type:        reflect.TypeOf(float64(0)),
+				Description: "Random spread applied to each retry wait, as a fraction of the delay (0.0-1.0)",
+				Default:     0.1,
+				Validation:  validateFloat64Range(0.0, 1.0),
+			},
+			"parameters.retry.honor_headers": {
+				Type:        reflect.TypeOf(bool(false)),
+				Description: "Wait as long as Retry-After or x-ratelimit-reset-* headers ask before retrying",
+				Default:     true,
+			},
 			"parameters.top_p": {
 				Type:        reflect.TypeOf(float64(0)),
 				Description: "Top P sampling for LLM responses (0.0-1.0)",
@@ -125,6 +146,67 @@ func DefaultConfigSchema() *ConfigSchema {
 				Description: "Enable streaming responses from LLM",
 				Default:     false,
 			},
+			"parameters.cache": {
+				Type:        reflect.TypeOf(bool(false)),
+				Description: "Cache responses on disk and reuse them for identical requests",
+				Default:     false,
+			},
+			"parameters.cache_ttl": {
+				Type:        reflect.TypeOf(""),
+				Description: "How long cached responses stay valid (e.g. 30m, 24h; 0 = forever)",
+				Default:     "24h",
+			},
+			"parameters.cache_max_mb": {
+				Type:        reflect.TypeOf(int(0)),
+				Description: "Size cap for the response cache in MB; oldest entries are evicted (0 = no cap)",
+				Default:     100,
+				Validation:  validateIntRange(0, 100000),
+			},
+			"parameters.record": {
+				Type:        reflect.TypeOf(""),
+				Description: "Directory to record provider requests and responses to",
+				Default:     "",
+			},
+			"parameters.replay": {
+				Type:        reflect.TypeOf(""),
+				Description: "Directory of recorded responses to answer requests from, offline",
+				Default:     "",
+			},
+			"parameters.context_window": {
+				Type:        reflect.TypeOf(int(0)),
+				Description: "Context window of the selected model in tokens (0 = use the known window)",
+				Default:     0,
+				Validation:  validateIntRange(0, 10000000),
+			},
+			"parameters.context_overflow": {
+				Type:        reflect.TypeOf(""),
+				Description: "What to do when input overflows the context window (truncate/drop/fail)",
+				Default:     "truncate",
+				Validation:  validateEnum("truncate", "drop", "fail"),
+			},
+			"parameters.map_reduce.chunk_tokens": {
+				Type:        reflect.TypeOf(int(0)),
+				Description: "Tokens per part with --map-reduce (0 = as many as the context window allows)",
+				Default:     0,
+				Validation:  validateIntRange(0, 10000000),
+			},
+			"parameters.map_reduce.overlap_tokens": {
+				Type:        reflect.TypeOf(int(0)),
+				Description: "Tokens each --map-reduce part repeats from the end of the one before",
+				Default:     200,
+				Validation:  validateIntRange(0, 100000),
+			},
+			"parameters.map_reduce.concurrency": {
+				Type:        reflect.TypeOf(int(0)),
+				Description: "Parts answered at once with --map-reduce",
+				Default:     4,
+				Validation:  validateIntRange(1, 64),
+			},
+			"parameters.map_reduce.reduce_prompt": {
+				Type:        reflect.TypeOf(""),
+				Description: "Instructions for combining --map-reduce partial answers (empty = built-in)",
+				Default:     "",
+			},
 			"parameters.seed": {
 				Type:        reflect.TypeOf((*int)(nil)).Elem(),
 				Description: "Random seed for deterministic LLM outputs (optional)",
@@ -189,6 +271,11 @@ func DefaultConfigSchema() *ConfigSchema {
 				Description: "Ollama API base URL",
 				Default:     "http://127.0.0.1:11434",
 			},
+			"providers.mock.rules": {
+				Type:        reflect.TypeOf(""),
+				Description: "File of canned responses for the --test mock provider",
+				Default:     "",
+			},
 			"providers.ollama.keep_alive": {
 				Type:        reflect.TypeOf(""),
 				Description: "How long Ollama keeps models warm in RAM (e.g. 5m, 1h, 0)",
@@ -204,6 +291,31 @@ func DefaultConfigSchema() *ConfigSchema {
 				Description: "Mistral API base URL",
 				Default:     "https://api.mistral.ai/v1",
 			},
+			"providers.gemini.api_key": {
+				Type:        reflect.TypeOf(""),
+				Description: "Google Gemini API key",
+				Default:     "",
+			},
+			"providers.gemini.base_url": {
+				Type:        reflect.TypeOf(""),
+				Description: "Google Gemini API base URL",
+				Default:     "https://generativelanguage.googleapis.com/v1beta",
+			},
+			"providers.azure.api_key": {
+				Type:        reflect.TypeOf(""),
+				Description: "Azure OpenAI API key",
+				Default:     "",
+			},
+			"providers.azure.base_url": {
+				Type:        reflect.TypeOf(""),
+				Description: "Azure OpenAI resource endpoint (https://<resource>.openai.azure.com)",
+				Default:     "",
+			},
+			"providers.azure.api_version": {
+				Type:        reflect.TypeOf(""),
+				Description: "Azure OpenAI api-version query parameter",
+				Default:     "2024-10-21",
+			},
 
 			// Model configurations
 			"models.remote.fast.provider": {
@@ -226,6 +338,16 @@ func DefaultConfigSchema() *ConfigSchema {
 				Description: "Name of remote deep/reasoning model",
 				Default:     "magistral-medium-2509",
 			},
+			"models.remote.embed.provider": {
+				Type:        reflect.TypeOf(""),
+				Description: "Provider for remote embedding model",
+				Default:     "mistral",
+			},
+			"models.remote.embed.name": {
+				Type:        reflect.TypeOf(""),
+				Description: "Name of remote embedding model",
+				Default:     "mistral-embed",
+			},
 			"models.local.fast.provider": {
 				Type:        reflect.TypeOf(""),
 				Description: "Provider for local fast model",
@@ -246,6 +368,16 @@ func DefaultConfigSchema() *ConfigSchema {
 				Description: "Name of local deep/reasoning model",
 				Default:     "deepseek-r1:14b",
 			},
+			"models.local.embed.provider": {
+				Type:        reflect.TypeOf(""),
+				Description: "Provider for local embedding model",
+				Default:     "ollama",
+			},
+			"models.local.embed.name": {
+				Type:        reflect.TypeOf(""),
+				Description: "Name of local embedding model",
+				Default:     "nomic-embed-text",
+			},
 
 			// Format options
 			"format.json": {
@@ -277,14 +409,30 @@ func DefaultConfigSchema() *ConfigSchema {
 
 		Aliases: map[string]string{
 			// parameter aliases
-			"temperature":   "parameters.temperature",
-			"temp":          "parameters.temperature",
-			"max-tokens":    "parameters.max_tokens",
-			"max-retries":   "parameters.max_retries",
-			"top-p":         "parameters.top_p",
-			"system":        "parameters.system_prompt",
-			"system-prompt": "parameters.system_prompt",
-			"timeout":       "parameters.timeout",
+			"temperature":         "parameters.temperature",
+			"temp":                "parameters.temperature",
+			"max-tokens":          "parameters.max_tokens",
+			"max-retries":         "parameters.max_retries",
+			"retry-base-delay":    "parameters.retry.base_delay",
+			"retry-max-delay":     "parameters.retry.max_delay",
+			"retry-jitter":        "parameters.retry.jitter",
+			"retry-honor-headers": "parameters.retry.honor_headers",
+			"top-p":               "parameters.top_p",
+			"system":              "parameters.system_prompt",
+			"system-prompt":       "parameters.system_prompt",
+			"timeout":             "parameters.timeout",
+			"cache":               "parameters.cache",
+			"cache-ttl":           "parameters.cache_ttl",
+			"cache-max-mb":        "parameters.cache_max_mb",
+			"record":              "parameters.record",
+			"replay":              "parameters.replay",
+			"mock-rules":          "providers.mock.rules",
+			"context-window":      "parameters.context_window",
+			"context-overflow":    "parameters.context_overflow",
+			"chunk-tokens":        "parameters.map_reduce.chunk_tokens",
+			"overlap-tokens":      "parameters.map_reduce.overlap_tokens",
+			"concurrency":         "parameters.map_reduce.concurrency",
+			"reduce-prompt":       "parameters.map_reduce.reduce_prompt",
 			// "stream":             "parameters.stream",
 			"default-model-type": "parameters.default_model_type",
 			"default-location":   "parameters.default_location",
@@ -299,19 +447,25 @@ func DefaultConfigSchema() *ConfigSchema {
 			"openai-key":    "providers.openai.api_key",
 			"cohere-key":    "providers.cohere.api_key",
 			"mistral-key":   "providers.mistral.api_key",
+			"gemini-key":    "providers.gemini.api_key",
+			"azure-key":     "providers.azure.api_key",
 
 			// local provider endpoint
 			"ollama-url": "providers.ollama.base_url",
 
 			// quick set providers/models
-			"remote-fast-provider": "models.remote.fast.provider",
-			"remote-fast-model":    "models.remote.fast.name",
-			"remote-deep-provider": "models.remote.deep.provider",
-			"remote-deep-model":    "models.remote.deep.name",
-			"local-fast-provider":  "models.local.fast.provider",
-			"local-fast-model":     "models.local.fast.name",
-			"local-deep-provider":  "models.local.deep.provider",
-			"local-deep-model":     "models.local.deep.name",
+			"remote-fast-provider":  "models.remote.fast.provider",
+			"remote-fast-model":     "models.remote.fast.name",
+			"remote-deep-provider":  "models.remote.deep.provider",
+			"remote-deep-model":     "models.remote.deep.name",
+			"local-fast-provider":   "models.local.fast.provider",
+			"local-fast-model":      "models.local.fast.name",
+			"local-deep-provider":   "models.local.deep.provider",
+			"local-deep-model":      "models.local.deep.name",
+			"remote-embed-provider": "models.remote.embed.provider",
+			"remote-embed-model":    "models.remote.embed.name",
+			"local-embed-provider":  "models.local.embed.provider",
+			"local-embed-model":     "models.local.embed.name",
 
 			// format aliases
 			"json":     "format.json",
@@ -1,11 +1,14 @@
 package config
 
+import "time"
+
 // Config represents the complete configuration structure for slop
 type Config struct {
 	Parameters Parameters             `mapstructure:"parameters"`
 	Models     Models                 `mapstructure:"models"`
 	Providers  Providers              `mapstructure:"providers"`
 	Commands   map[string]Command     `mapstructure:"commands"`
+	Tools      map[string]Tool        `mapstructure:"tools"`
 	ExitCodes  map[string]ExitCodeMap `mapstructure:"exit_codes"`
 	Format     Format                 `mapstructure:"format"`
 }
@@ -33,9 +36,51 @@ type Parameters struct {
 	// or accepted inline on the --schema flag.
 	ResponseSchema string `mapstructure:"response_schema"`
 
+	// tools the model may call; names must be declared under [tools]
+	Tools []string `mapstructure:"tools"`
+
+	// response cache (opt-in); cache_ttl is a duration such as "24h"
+	Cache      bool   `mapstructure:"cache"`
+	CacheTTL   string `mapstructure:"cache_ttl"`
+	CacheMaxMB int    `mapstructure:"cache_max_mb"`
+
+	// HTTP cassettes: record saves every provider request and response to a
+	// directory, replay answers requests from one without touching the network
+	Record string `mapstructure:"record"`
+	Replay string `mapstructure:"replay"`
+
+	// context window budgeting; context_window overrides the model's known
+	// window (0 = use the model metadata), context_overflow picks what to do
+	// when input won't fit: "truncate", "drop" or "fail"
+	ContextWindow   int    `mapstructure:"context_window"`
+	ContextOverflow string `mapstructure:"context_overflow"`
+
+	// --map-reduce splits input too large for one request
+	MapReduce MapReduce `mapstructure:"map_reduce"`
+
 	// application behavior
-	Timeout    int `mapstructure:"timeout"`
-	MaxRetries int `mapstructure:"max_retries"`
+	Timeout    int   `mapstructure:"timeout"`
+	MaxRetries int   `mapstructure:"max_retries"`
+	Retry      Retry `mapstructure:"retry"`
+}
+
+// Retry configures the wait between retried requests. providers may override
+// any field under providers.<name>.retry; unset fields use parameters.retry
+type Retry struct {
+	BaseDelay    string   `mapstructure:"base_delay"`    // first backoff, such as "3s"; each retry waits 3x longer
+	MaxDelay     string   `mapstructure:"max_delay"`     // longest single wait; "0" is uncapped
+	Jitter       *float64 `mapstructure:"jitter"`        // random spread as a fraction of the delay (0-1)
+	StatusCodes  []int    `mapstructure:"status_codes"`  // responses worth retrying; empty means 429 and 5xx
+	HonorHeaders *bool    `mapstructure:"honor_headers"` // wait as long as Retry-After or x-ratelimit-reset-* ask
+}
+
+// MapReduce configures --map-reduce, which answers the prompt for each part
+// of a large input and then combines the partial answers
+type MapReduce struct {
+	ChunkTokens   int    `mapstructure:"chunk_tokens"`   // tokens per part; 0 sizes parts to the context window
+	OverlapTokens int    `mapstructure:"overlap_tokens"` // tokens each part repeats from the end of the one before
+	Concurrency   int    `mapstructure:"concurrency"`    // parts answered at once
+	ReducePrompt  string `mapstructure:"reduce_prompt"`  // instructions for combining the partial answers; empty uses the built-in
 }
 
 // Format contains output formatting options
@@ -68,24 +113,40 @@ type Models struct {
 
 // Remote contains remote model definitions
 type Remote struct {
-	Fast Fast `mapstructure:"fast"`
-	Deep Deep `mapstructure:"deep"`
+	Fast  Fast  `mapstructure:"fast"`
+	Deep  Deep  `mapstructure:"deep"`
+	Embed Embed `mapstructure:"embed"`
 }
 
 // Local contains local model definitions
 type Local struct {
-	Fast Fast `mapstructure:"fast"`
-	Deep Deep `mapstructure:"deep"`
+	Fast  Fast  `mapstructure:"fast"`
+	Deep  Deep  `mapstructure:"deep"`
+	Embed Embed `mapstructure:"embed"`
 }
 
 // Fast represents a fast/lightweight model configuration
 type Fast struct {
-	Provider string `mapstructure:"provider"`
-	Name     string `mapstructure:"name"`
+	Provider  string     `mapstructure:"provider"`
+	Name      string     `mapstructure:"name"`
+	Fallbacks []ModelRef `mapstructure:"fallbacks"` // tried in order when the model is unavailable
 }
 
 // Deep represents a deep/reasoning model configuration
 type Deep struct {
+	Provider  string     `mapstructure:"provider"`
+	Name      string     `mapstructure:"name"`
+	Fallbacks []ModelRef `mapstructure:"fallbacks"` // tried in order when the model is unavailable
+}
+
+// Embed represents an embedding model configuration, used by slop embed
+type Embed struct {
+	Provider string `mapstructure:"provider"`
+	Name     string `mapstructure:"name"`
+}
+
+// ModelRef names a model on a provider, such as a fallback candidate
+type ModelRef struct {
 	Provider string `mapstructure:"provider"`
 	Name     string `mapstructure:"name"`
 }
@@ -99,6 +160,17 @@ type Providers struct {
 	Mistral   Mistral   `mapstructure:"mistral"`
 	Groq      Groq      `mapstructure:"groq"`
 	Together  Together  `mapstructure:"together"`
+	Gemini    Gemini    `mapstructure:"gemini"`
+	Azure     Azure     `mapstructure:"azure"`
+	Mock      Mock      `mapstructure:"mock"`
+
+	// OpenAI-compatible servers (vLLM, LM Studio, llama.cpp, ...) keyed by the
+	// provider name used in models.*.provider and --model
+	Custom map[string]CustomProvider `mapstructure:"custom"`
+
+	// external executables speaking slop's JSON stdio plugin protocol, keyed
+	// by provider name. slop-provider-<name> executables on PATH are added too
+	Plugin map[string]PluginProvider `mapstructure:"plugin"`
 }
 
 // BaseProvider contains common fields shared across all providers
@@ -107,16 +179,35 @@ type BaseProvider struct {
 	BaseUrl    string `mapstructure:"base_url"`
 	APIVersion string `mapstructure:"api_version"`
 	MaxRetries int    `mapstructure:"max_retries"`
+	Retry      Retry  `mapstructure:"retry"`
+
+	// HTTP transport settings for reaching the provider
+	ProxyURL           string            `mapstructure:"proxy_url"`            // proxy for this provider; HTTPS_PROXY and friends apply otherwise
+	CAFile             string            `mapstructure:"ca_file"`              // PEM bundle trusted alongside the system roots
+	InsecureSkipVerify bool              `mapstructure:"insecure_skip_verify"` // skip TLS certificate checks; for testing only
+	Headers            map[string]string `mapstructure:"headers"`              // added to every request, such as OpenAI-Organization
+	Timeout            int               `mapstructure:"timeout"`              // seconds to wait for a response to start; 0 uses parameters.timeout
 }
 
 type Anthropic struct {
 	BaseProvider `mapstructure:",squash"`
 }
 
+type Azure struct {
+	BaseProvider `mapstructure:",squash"`
+	// Deployments maps model names to Azure deployment names. a model without
+	// an entry is used as the deployment name
+	Deployments map[string]string `mapstructure:"deployments"`
+}
+
 type Cohere struct {
 	BaseProvider `mapstructure:",squash"`
 }
 
+type Gemini struct {
+	BaseProvider `mapstructure:",squash"`
+}
+
 type Groq struct {
 	BaseProvider `mapstructure:",squash"`
 }
@@ -132,6 +223,13 @@ type Ollama struct {
 	KeepAlive string `mapstructure:"keep_alive"`
 }
 
+// Mock is the provider used with --test
+type Mock struct {
+	// Rules is a TOML, YAML or JSON file of canned responses; without one
+	// every prompt gets the same fixed response
+	Rules string `mapstructure:"rules"`
+}
+
 type OpenAI struct {
 	BaseProvider `mapstructure:",squash"`
 }
@@ -140,6 +238,26 @@ type Together struct {
 	BaseProvider `mapstructure:",squash"`
 }
 
+// CustomProvider is an OpenAI-compatible chat completions server. capabilities
+// default to off, so options the server may reject are only sent when enabled
+type CustomProvider struct {
+	BaseProvider `mapstructure:",squash"`
+
+	SupportsStreaming bool `mapstructure:"supports_streaming"`
+	SupportsTools     bool `mapstructure:"supports_tools"`
+	SupportsImages    bool `mapstructure:"supports_images"`
+	SupportsJSON      bool `mapstructure:"supports_json"` // response_format json_object and json_schema
+}
+
+// PluginProvider is an external executable that answers one JSON request on
+// stdin with one JSON response on stdout
+type PluginProvider struct {
+	Command string   `mapstructure:"command"` // path, or a name looked up on PATH
+	Args    []string `mapstructure:"args"`
+	// APIKey is passed to the plugin in the SLOP_PLUGIN_API_KEY environment variable
+	APIKey string `mapstructure:"api_key"`
+}
+
 // Command represents a named command with overrideable settings
 type Command struct {
 	Description     string `mapstructure:"description"`
@@ -158,6 +276,63 @@ type Command struct {
 
 	// exit code config
 	ExitCodeMap string `mapstructure:"exit_code_map"` // exit code map name
+
+	// tools enabled for this command (names declared under [tools])
+	Tools []string `mapstructure:"tools"`
+}
+
+// Tool declares a local shell command the model may call. arguments chosen by
+// the model are passed as JSON on stdin and as SLOP_ARG_<NAME> environment
+// variables; they are never interpolated into the command line
+type Tool struct {
+	Description string `mapstructure:"description"`
+	Command     string `mapstructure:"command"`
+	Parameters  string `mapstructure:"parameters"` // JSON schema for the arguments object
+	Confirm     *bool  `mapstructure:"confirm"`    // prompt before each run (default true)
+	Timeout     int    `mapstructure:"timeout"`    // seconds (default 30)
+}
+
+// RequiresConfirmation reports whether the user must approve each call
+func (t Tool) RequiresConfirmation() bool {
+	return t.Confirm == nil || *t.Confirm
+}
+
+// CacheTTLDuration returns the parsed cache TTL; zero means entries never expire
+func (p Parameters) CacheTTLDuration() time.Duration {
+	ttl, _ := time.ParseDuration(p.CacheTTL)
+	return ttl
+}
+
+// Cassette returns the cassette mode, "record" or "replay", and its
+// directory; both are empty when neither is set
+func (p Parameters) Cassette() (mode, dir string) {
+	if p.Replay != "" {
+		return "replay", p.Replay
+	}
+	if p.Record != "" {
+		return "record", p.Record
+	}
+	return "", ""
+}
+
+// Merge returns r with every field set in override replaced
+func (r Retry) Merge(override Retry) Retry {
+	if override.BaseDelay != "" {
+		r.BaseDelay = override.BaseDelay
+	}
+	if override.MaxDelay != "" {
+		r.MaxDelay = override.MaxDelay
+	}
+	if override.Jitter != nil {
+		r.Jitter = override.Jitter
+	}
+	if len(override.StatusCodes) > 0 {
+		r.StatusCodes = override.StatusCodes
+	}
+	if override.HonorHeaders != nil {
+		r.HonorHeaders = override.HonorHeaders
+	}
+	return r
 }
 
 // ReservedCommands are command names that cannot be overridden by users
@@ -11,9 +11,21 @@ type ContextFile struct {
 // ContextItem represents a processed context item with type information
 type ContextItem struct {
 	Path     string           // file path
-	Type     string           // "conversation" or "file"
+	Type     string           // "conversation", "file", "image" or "chunk"
 	Messages []common.Message // for conversations
 	Content  string           // for raw files
+	Image    *common.Image    // for images
+	Chunk    *Chunk           // for chunks retrieved from the project index
+	Project  bool             // from the project manifest; first to go when input overflows
+}
+
+// Chunk is a passage of a project context file retrieved for the prompt
+type Chunk struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Content   string
+	Score     float64 // similarity to the prompt, from -1 to 1
 }
 
 // ContextResult contains the result of context processing
@@ -25,6 +37,12 @@ type ContextResult struct {
 	ContextFileContents []ContextFile
 	// Enhanced structured context data with conversation detection
 	ProcessedItems []ContextItem
+	// Images attached to the prompt itself (--image)
+	Images []common.Image
+	// Project context files held back so only the chunks most relevant to
+	// the prompt are sent (--retrieve); RetrieveCount is how many chunks
+	RetrievalFiles []ContextFile
+	RetrieveCount  int
 }
 
 // HasContextFiles returns true if any context files are present
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // see https://pkg.go.dev/embed for more on embedding files
@@ -19,12 +20,63 @@ type ModelInfo struct {
 	Deep string `json:"deep"`
 }
 
+// ModelPrice is a model's list price in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Cost returns the USD cost of a request with the given token counts
+func (m ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*m.Input + float64(completionTokens)*m.Output) / 1_000_000
+}
+
 // ProviderInfo represents a provider's configuration information
 type ProviderInfo struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Reference   string    `json:"reference,omitempty"`
 	Models      ModelInfo `json:"models"`
+
+	// Pricing maps model ids to list prices; the "*" key prices every model
+	// of the provider (e.g. free local models)
+	Pricing map[string]ModelPrice `json:"pricing,omitempty"`
+
+	// ContextWindows maps model ids to the tokens they accept, input and
+	// output together; ids are matched as for Pricing
+	ContextWindows map[string]int `json:"context_windows,omitempty"`
+}
+
+// Price returns the list price of a model. a dated or versioned id such as
+// "gpt-4o-2024-08-06" falls back to the longest priced base id ("gpt-4o")
+func (p ProviderInfo) Price(model string) (ModelPrice, bool) {
+	return lookupModel(p.Pricing, model)
+}
+
+// ContextWindow returns the context window of a model in tokens
+func (p ProviderInfo) ContextWindow(model string) (int, bool) {
+	return lookupModel(p.ContextWindows, model)
+}
+
+// lookupModel finds a model's entry by exact id, then by the longest base id
+// it extends, then by the "*" wildcard
+func lookupModel[T any](entries map[string]T, model string) (T, bool) {
+	if entry, ok := entries[model]; ok {
+		return entry, true
+	}
+
+	best := ""
+	for id := range entries {
+		if strings.HasPrefix(model, id+"-") && len(id) > len(best) {
+			best = id
+		}
+	}
+	if best != "" {
+		return entries[best], true
+	}
+
+	entry, ok := entries["*"]
+	return entry, ok
 }
 
 // ProvidersData represents the structure of models.json
@@ -69,6 +121,24 @@ func (p *ProviderRegistry) GetProvider(key string) (ProviderInfo, bool) {
 	return provider, exists
 }
 
+// GetModelPrice returns the list price of a provider's model
+func (p *ProviderRegistry) GetModelPrice(providerKey, model string) (ModelPrice, bool) {
+	provider, exists := p.GetProvider(providerKey)
+	if !exists {
+		return ModelPrice{}, false
+	}
+	return provider.Price(model)
+}
+
+// GetContextWindow returns the context window of a provider's model in tokens
+func (p *ProviderRegistry) GetContextWindow(providerKey, model string) (int, bool) {
+	provider, exists := p.GetProvider(providerKey)
+	if !exists {
+		return 0, false
+	}
+	return provider.ContextWindow(model)
+}
+
 // GetRemoteProviders returns providers suitable for remote use (excludes local-only providers)
 func (p *ProviderRegistry) GetRemoteProviders() map[string]ProviderInfo {
 	if p.data == nil {
@@ -139,3 +139,95 @@ func TestProviderRegistry(t *testing.T) {
 		})
 	})
 }
+
+func TestProviderInfo_Price(t *testing.T) {
+	provider := ProviderInfo{Pricing: map[string]ModelPrice{
+		"gpt-4o":      {Input: 2.5, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+	}}
+
+	tests := []struct {
+		model    string
+		expected ModelPrice
+		found    bool
+	}{
+		{"gpt-4o", ModelPrice{Input: 2.5, Output: 10}, true},
+		{"gpt-4o-2024-08-06", ModelPrice{Input: 2.5, Output: 10}, true},
+		{"gpt-4o-mini-2024-07-18", ModelPrice{Input: 0.15, Output: 0.6}, true},
+		{"gpt-4o1", ModelPrice{}, false},
+		{"o3", ModelPrice{}, false},
+	}
+	for _, tt := range tests {
+		price, found := provider.Price(tt.model)
+		if found != tt.found || price != tt.expected {
+			t.Errorf("Price(%q) = %+v, %v; expected %+v, %v", tt.model, price, found, tt.expected, tt.found)
+		}
+	}
+
+	// the wildcard prices every model
+	local := ProviderInfo{Pricing: map[string]ModelPrice{"*": {}}}
+	if _, found := local.Price("llama3:8b"); !found {
+		t.Error("Expected wildcard price to match any model")
+	}
+}
+
+func TestModelPrice_Cost(t *testing.T) {
+	price := ModelPrice{Input: 3, Output: 15}
+	cost := price.Cost(1_000_000, 200_000)
+	if cost != 6 {
+		t.Errorf("Expected cost 6, got %v", cost)
+	}
+}
+
+func TestEmbeddedPricing(t *testing.T) {
+	registry := NewProviderRegistry()
+	if err := registry.Load(); err != nil {
+		t.Fatalf("Failed to load providers: %v", err)
+	}
+
+	if _, found := registry.GetModelPrice("anthropic", "claude-haiku-4-5-20251001"); !found {
+		t.Error("Expected a price for the dated anthropic fast model")
+	}
+	if price, found := registry.GetModelPrice("ollama", "gemma3:latest"); !found || price.Cost(1000, 1000) != 0 {
+		t.Errorf("Expected local models to be free, got %+v, %v", price, found)
+	}
+	if _, found := registry.GetModelPrice("unknown", "model"); found {
+		t.Error("Expected no price for an unknown provider")
+	}
+
+	// 'slop usage' must be able to cost runs of the shipped defaults
+	for key, info := range registry.GetProviders() {
+		for _, model := range []string{info.Models.Fast, info.Models.Deep} {
+			if _, found := info.Price(model); !found {
+				t.Errorf("Expected a price for %s default model %s", key, model)
+			}
+		}
+	}
+}
+
+func TestEmbeddedContextWindows(t *testing.T) {
+	registry := NewProviderRegistry()
+	if err := registry.Load(); err != nil {
+		t.Fatalf("Failed to load providers: %v", err)
+	}
+
+	if window, found := registry.GetContextWindow("openai", "gpt-4o-2024-08-06"); !found || window != 128000 {
+		t.Errorf("Expected a 128000-token window for a dated gpt-4o, got %d, %v", window, found)
+	}
+	if _, found := registry.GetContextWindow("anthropic", "claude-sonnet-4-6"); !found {
+		t.Error("Expected a context window for the anthropic deep model")
+	}
+	// slop sends no num_ctx, so local models get the server's default window
+	if window, found := registry.GetContextWindow("ollama", "gemma3:latest"); !found || window != 4096 {
+		t.Errorf("Expected Ollama's default 4096-token window, got %d, %v", window, found)
+	}
+
+	// the shipped defaults must be budgeted, or --map-reduce can't size parts
+	for key, info := range registry.GetProviders() {
+		for _, model := range []string{info.Models.Fast, info.Models.Deep} {
+			if _, found := info.ContextWindow(model); !found {
+				t.Errorf("Expected a context window for %s default model %s", key, model)
+			}
+		}
+	}
+}
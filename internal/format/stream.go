@@ -0,0 +1,125 @@
+package format
+
+import "strings"
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// StreamFilter applies thinking filtering incrementally to a streamed response.
+// It mirrors ApplyThinkingFilter for <think> tags and structured thinking deltas:
+// thinking is dropped unless showThinking is set, in which case it is emitted
+// under the same "Thinking:" / "Response:" headings as the buffered output
+type StreamFilter struct {
+	showThinking bool
+
+	inThink        bool   // currently inside an inline <think> block
+	pending        string // trailing bytes that may be the start of a tag
+	thinkingShown  bool   // "Thinking:" heading has been written
+	responseShown  bool   // "Response:" heading has been written
+	responseActive bool   // at least one non-whitespace response byte written
+}
+
+// NewStreamFilter creates a StreamFilter using the same flags as ApplyThinkingFilter
+func NewStreamFilter(hideThinking, showThinking bool) *StreamFilter {
+	return &StreamFilter{showThinking: showThinking && !hideThinking}
+}
+
+// Process consumes one streamed delta and returns the text that should be written.
+// thinking carries structured reasoning deltas; content may contain inline <think> tags
+func (f *StreamFilter) Process(thinking, content string) string {
+	var out strings.Builder
+
+	if thinking != "" {
+		out.WriteString(f.emitThinking(thinking))
+	}
+
+	data := f.pending + content
+	f.pending = ""
+
+	for data != "" {
+		tag := thinkOpenTag
+		if f.inThink {
+			tag = thinkCloseTag
+		}
+
+		idx := strings.Index(data, tag)
+		if idx >= 0 {
+			out.WriteString(f.emitSegment(data[:idx]))
+			f.inThink = !f.inThink
+			data = data[idx+len(tag):]
+			continue
+		}
+
+		// hold back a suffix that could be the beginning of the tag
+		keep := partialTagSuffix(data, tag)
+		out.WriteString(f.emitSegment(data[:len(data)-keep]))
+		f.pending = data[len(data)-keep:]
+		break
+	}
+
+	return out.String()
+}
+
+// Flush returns any held-back text once the stream has ended
+func (f *StreamFilter) Flush() string {
+	pending := f.pending
+	f.pending = ""
+	if pending == "" {
+		return ""
+	}
+	return f.emitSegment(pending)
+}
+
+// emitSegment routes a tag-free segment to thinking or response output
+func (f *StreamFilter) emitSegment(segment string) string {
+	if f.inThink {
+		return f.emitThinking(segment)
+	}
+	return f.emitResponse(segment)
+}
+
+// emitThinking formats a thinking delta, or drops it when thinking is hidden
+func (f *StreamFilter) emitThinking(text string) string {
+	if !f.showThinking || text == "" {
+		return ""
+	}
+	if !f.thinkingShown {
+		f.thinkingShown = true
+		return "Thinking:\n" + strings.TrimLeft(text, " \t\r\n")
+	}
+	return text
+}
+
+// emitResponse formats a response delta, trimming leading whitespace so the
+// output starts cleanly after a stripped thinking block
+func (f *StreamFilter) emitResponse(text string) string {
+	if !f.responseActive {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			return ""
+		}
+		f.responseActive = true
+		if f.thinkingShown && !f.responseShown {
+			f.responseShown = true
+			return "\n\nResponse:\n" + text
+		}
+	}
+	return text
+}
+
+// partialTagSuffix returns the length of the longest suffix of s that is a
+// proper prefix of tag
+func partialTagSuffix(s, tag string) int {
+	max := len(tag) - 1
+	if len(s) < max {
+		max = len(s)
+	}
+	for n := max; n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
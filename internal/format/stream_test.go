@@ -0,0 +1,75 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runStream feeds content deltas through a StreamFilter and returns the output
+func runStream(f *StreamFilter, deltas ...string) string {
+	out := ""
+	for _, d := range deltas {
+		out += f.Process("", d)
+	}
+	return out + f.Flush()
+}
+
+func TestStreamFilter_HidesInlineThinking(t *testing.T) {
+	tests := []struct {
+		name     string
+		deltas   []string
+		expected string
+	}{
+		{
+			name:     "no thinking",
+			deltas:   []string{"Hello", " world"},
+			expected: "Hello world",
+		},
+		{
+			name:     "think block in one chunk",
+			deltas:   []string{"<think>plan</think>\n\nAnswer"},
+			expected: "Answer",
+		},
+		{
+			name:     "tags split across chunks",
+			deltas:   []string{"<thi", "nk>pl", "an</th", "ink>", "\nAns", "wer"},
+			expected: "Answer",
+		},
+		{
+			name:     "partial tag that never completes is flushed",
+			deltas:   []string{"a <thi"},
+			expected: "a <thi",
+		},
+		{
+			name:     "angle bracket in normal text",
+			deltas:   []string{"1 < 2", " is true"},
+			expected: "1 < 2 is true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewStreamFilter(true, false)
+			assert.Equal(t, tt.expected, runStream(f, tt.deltas...))
+		})
+	}
+}
+
+func TestStreamFilter_ShowThinking(t *testing.T) {
+	f := NewStreamFilter(false, true)
+
+	out := runStream(f, "<think>", "step one", "</think>", "\n\nAnswer")
+
+	assert.Equal(t, "Thinking:\nstep one\n\nResponse:\nAnswer", out)
+}
+
+func TestStreamFilter_StructuredThinking(t *testing.T) {
+	hidden := NewStreamFilter(true, false)
+	out := hidden.Process("reasoning", "") + hidden.Process("", "Answer")
+	assert.Equal(t, "Answer", out)
+
+	shown := NewStreamFilter(false, true)
+	out = shown.Process("reasoning", "") + shown.Process("", "Answer")
+	assert.Equal(t, "Thinking:\nreasoning\n\nResponse:\nAnswer", out)
+}
@@ -1,22 +1,21 @@
 package anthropic
 
-import (
-	"encoding/json"
-
-	"github.com/chriscorrea/slop/internal/llm/common"
-)
+import "encoding/json"
 
 // MessagesRequest represents the request payload for Anthropic's Messages API
 type MessagesRequest struct {
-	Model         string           `json:"model"`
-	MaxTokens     int              `json:"max_tokens"`
-	Messages      []common.Message `json:"messages"`
-	System        string           `json:"system,omitempty"`
-	Temperature   *float64         `json:"temperature,omitempty"`
-	TopP          *float64         `json:"top_p,omitempty"`
-	TopK          *int             `json:"top_k,omitempty"`
-	StopSequences []string         `json:"stop_sequences,omitempty"`
-	Stream        *bool            `json:"stream,omitempty"`
+	Model         string    `json:"model"`
+	MaxTokens     int       `json:"max_tokens"`
+	Messages      []Message `json:"messages"`
+	System        string    `json:"system,omitempty"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	TopP          *float64  `json:"top_p,omitempty"`
+	TopK          *int      `json:"top_k,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	Stream        *bool     `json:"stream,omitempty"`
+
+	// Tools are the functions the model may call
+	Tools []Tool `json:"tools,omitempty"`
 
 	// Thinking carries the extended thinking config. When nil, the field
 	// is omitted and the model behaves as normal
@@ -27,6 +26,20 @@ type MessagesRequest struct {
 	OutputConfig *OutputConfig `json:"output_config,omitempty"`
 }
 
+// Message is a Messages API turn. Content is a plain string for ordinary
+// messages and a []ContentItem for tool_use / tool_result turns
+type Message struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// Tool is Anthropic's function definition; the JSON schema lives under input_schema
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
 // ThinkingConfig wires Anthropic's extended-thinking block. Type is
 // "enabled" with a BudgetTokens ceiling on 4.5 and earlier; Type is
 // "adaptive" (no budget) on 4.6+. Effort for adaptive routing lives on
@@ -66,13 +79,28 @@ type MessagesResponse struct {
 	Usage        AnthropicUsage `json:"usage"`
 }
 
-// ContentItem represents a content item in Anthropic's response.
+// ContentItem represents a content block in Anthropic's requests and responses.
 // Text carries the body of a "text" block; Thinking carries the body
-// of a "thinking" block emitted when extended thinking is enabled
+// of a "thinking" block emitted when extended thinking is enabled.
+// "tool_use" blocks set ID, Name and Input; "tool_result" blocks answer
+// one by ToolUseID with the tool output in Content; "image" blocks set Source
 type ContentItem struct {
-	Type     string `json:"type"`
-	Text     string `json:"text,omitempty"`
-	Thinking string `json:"thinking,omitempty"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Thinking  string          `json:"thinking,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	Source    *ImageSource    `json:"source,omitempty"`
+}
+
+// ImageSource carries an image block's data. slop always sends base64
+type ImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
 }
 
 // AnthropicUsage represents usage information in Anthropic's format
@@ -80,3 +108,28 @@ type AnthropicUsage struct {
 	InputTokens  int `json:"input_tokens"`
 	OutputTokens int `json:"output_tokens"`
 }
+
+// StreamEvent represents one server-sent event on Anthropic's streaming
+// Messages API. Only the fields slop consumes are decoded
+type StreamEvent struct {
+	Type    string            `json:"type"`
+	Message *MessagesResponse `json:"message,omitempty"`
+	Delta   *StreamDelta      `json:"delta,omitempty"`
+	Usage   *AnthropicUsage   `json:"usage,omitempty"`
+	Error   *StreamError      `json:"error,omitempty"`
+}
+
+// StreamDelta carries the incremental payload of content_block_delta and
+// message_delta events. Type is "text_delta" or "thinking_delta" for blocks
+type StreamDelta struct {
+	Type       string `json:"type,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Thinking   string `json:"thinking,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+// StreamError is the payload of an in-stream error event (e.g. overloaded_error)
+type StreamError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -1,12 +1,14 @@
 package anthropic
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains Anthropic-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// Anthropic-specific params
+// Extension holds Anthropic-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	TopK          *int     // integer for top-k sampling (only used by some Anthropic models)
 	System        string   // system prompt for Anthropic (separate from messages)
 	StopSequences []string // anthropic uses "stop_sequences" instead of "stop"
@@ -17,36 +19,64 @@ type GenerateOptions struct {
 	ThinkingBudget int
 }
 
-// GenerateOption configures Anthropic-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "anthropic"
+}
+
+// Validate checks the parameters against the values Anthropic accepts
+func (e *Extension) Validate() error {
+	if e.TopK != nil && *e.TopK <= 0 {
+		return fmt.Errorf("top_k must be positive, got %d", *e.TopK)
+	}
+	if e.ThinkingBudget < 0 || (e.ThinkingBudget > 0 && e.ThinkingBudget < 1024) {
+		return fmt.Errorf("thinking budget %d is below Anthropic's minimum of 1024 tokens", e.ThinkingBudget)
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Anthropic
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Anthropic parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // WithTopK sets top-k sampling parameter
 func WithTopK(topK int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.TopK = &topK
+	return func(c *common.GenerateRequest) {
+		extension(c).TopK = &topK
 	}
 }
 
 // WithSystem sets the system prompt for Anthropic
 func WithSystem(system string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.System = system
+	return func(c *common.GenerateRequest) {
+		extension(c).System = system
 	}
 }
 
 // WithStopSequences sets stop sequences (Anthropic uses different naming)
 func WithStopSequences(sequences []string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.StopSequences = sequences
+	return func(c *common.GenerateRequest) {
+		extension(c).StopSequences = sequences
 	}
 }
 
@@ -54,15 +84,15 @@ func WithStopSequences(sequences []string) GenerateOption {
 // when extended thinking is enabled. A value of zero lets the adapter
 // pick a default based on the cross-provider ThinkingLevel
 func WithThinkingBudget(budget int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.ThinkingBudget = budget
+	return func(c *common.GenerateRequest) {
+		extension(c).ThinkingBudget = budget
 	}
 }
 
 // WithThinking sets the cross-provider thinking level. Anthropic's adapter
 // translates this into a thinking block with an appropriate budget
 func WithThinking(level common.ThinkingLevel) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithThinking(level)(&c.GenerateOptions)
 	}
 }
@@ -71,7 +101,7 @@ func WithThinking(level common.ThinkingLevel) GenerateOption {
 // output_config envelope. The schema is forwarded to the common layer and
 // the adapter wires it onto the wire-level OutputConfig at request build
 func WithSchema(name string, schema []byte) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithSchema(name, schema)(&c.GenerateOptions)
 	}
 }
@@ -80,49 +110,58 @@ func WithSchema(name string, schema []byte) GenerateOption {
 
 // WithTemperature sets response randomness (0.0-1.0 for Anthropic)
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
 
 // WithTopP sets nucleus sampling threshold (0.0-1.0)
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets maximum tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences (maps to StopSequences for Anthropic)
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.StopSequences = stop
+	return func(c *common.GenerateRequest) {
+		extension(c).StopSequences = stop
 		// Also set the common stop field for consistency
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output (if supported)
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format (if supported)
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
@@ -5,12 +5,13 @@
 //
 // Example usage:
 //   client := anthropic.NewClient(apiKey)
-//   response, err := client.Generate(ctx, messages, anthropic.WithTemperature(0.7))
+//   response, err := client.Generate(ctx, messages, model, anthropic.NewGenerateOptions(anthropic.WithTemperature(0.7)))
 //
 
 package anthropic
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -29,6 +30,24 @@ type Provider struct{}
 // ensure Provider implements the common provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and stop reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
 // thinking budget defaults keyed on the cross-provider ThinkingLevel.
 // medium targets moderate reasoning; high gives the model room to explore
 const (
@@ -45,6 +64,9 @@ const (
 	maxTokensOpusFamily4   = 32768
 )
 
+// defaultBaseURL is used when providers.anthropic.base_url is unset
+const defaultBaseURL = "https://api.anthropic.com/v1"
+
 // New creates a new Anthropic provider instance
 func New() *Provider {
 	return &Provider{}
@@ -83,12 +105,25 @@ Get an API key from https://console.anthropic.com/settings/keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.Anthropic.APIKey, "https://api.anthropic.com/v1", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Anthropic.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Anthropic.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.anthropic: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Anthropic.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates Anthropic-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	// handle system prompt from config
@@ -111,6 +146,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// translate the cross-provider thinking level into Anthropic's native
 	// extended-thinking block at request build time. silent no-op for
@@ -127,7 +168,7 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 		functionalOpts = append(functionalOpts, WithSchema("response", []byte(schema)))
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // RequiresAPIKey returns true; Anthropic requires an API key
@@ -272,17 +313,14 @@ func effortForLevel(level common.ThinkingLevel, maxOK bool) string {
 }
 
 // BuildRequest creates an Anthropic-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to Anthropic-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if anthropicOpts, ok := options.(*GenerateOptions); ok {
-			config = anthropicOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Anthropic's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -291,6 +329,7 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	// separate system messages from user/assistant messages
 	var systemPrompt string
 	var filteredMessages []common.Message
+	continuesToolUse := false
 
 	for _, msg := range messages {
 		if msg.Role == "system" {
@@ -301,21 +340,24 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 			}
 		} else {
 			filteredMessages = append(filteredMessages, msg)
+			if msg.Role == "tool" {
+				continuesToolUse = true
+			}
 		}
 	}
 
 	// use system prompt from config if no system messages found
-	if systemPrompt == "" && config.System != "" {
-		systemPrompt = config.System
+	if systemPrompt == "" && ext.System != "" {
+		systemPrompt = ext.System
 	}
 
 	// create Anthropic-specific request payload. Anthropic requires
 	// max_tokens, so seed a per-model default the caller can override
 	requestBody := &MessagesRequest{
 		Model:     modelName,
-		Messages:  filteredMessages,
+		Messages:  convertMessages(filteredMessages),
 		MaxTokens: defaultMaxTokens(modelName),
-		Stream:    common.BoolPtr(false), // disable streaming for now
+		Stream:    common.BoolPtr(config.Stream),
 	}
 
 	// set system prompt if provided
@@ -335,11 +377,24 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map Anthropic-specific options
-	if config.TopK != nil {
-		requestBody.TopK = config.TopK
+	if ext.TopK != nil {
+		requestBody.TopK = ext.TopK
+	}
+	if len(ext.StopSequences) > 0 {
+		requestBody.StopSequences = ext.StopSequences
 	}
-	if len(config.StopSequences) > 0 {
-		requestBody.StopSequences = config.StopSequences
+
+	// map tool definitions; Anthropic names the schema input_schema
+	for _, tool := range config.Tools {
+		schema := tool.Function.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		requestBody.Tools = append(requestBody.Tools, Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
 	}
 
 	// extended thinking block. the shape depends on the model:
@@ -349,13 +404,15 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	//   4.5- — enabled+budget_tokens when the user asked for medium/high;
 	//          off stays literal (no block at all)
 	// unsupported models silently no-op so a default --thinking setting
-	// survives switching to something like haiku
-	if supportsThinking(modelName) {
+	// survives switching to something like haiku.
+	// a request answering tool_use must echo the previous turn's signed
+	// thinking blocks, which slop does not keep, so thinking stays off there
+	if supportsThinking(modelName) && !continuesToolUse {
 		if useAdaptiveThinking(modelName) {
 			requestBody.Thinking = &ThinkingConfig{Type: "adaptive"}
 			// adaptive auto-manages tokens; no max_tokens bump needed
 		} else if config.Thinking != common.ThinkingOff {
-			budget := config.ThinkingBudget
+			budget := ext.ThinkingBudget
 			if budget <= 0 {
 				budget = thinkingBudget(config.Thinking)
 			}
@@ -470,7 +527,8 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 		}
 	}
 
-	if len(textParts) == 0 {
+	// a tool_use turn may carry no text at all
+	if len(textParts) == 0 && anthropicResp.StopReason != "tool_use" {
 		return "", nil, fmt.Errorf("no text content in Anthropic response")
 	}
 
@@ -497,6 +555,171 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, usage, nil
 }
 
+// ParseMetadata extracts the resolved model and stop reason from an Anthropic response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	var anthropicResp MessagesResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return common.ResponseMetadata{}
+	}
+	return common.ResponseMetadata{Model: anthropicResp.Model, FinishReason: anthropicResp.StopReason}
+}
+
+// StreamFormat reports that Anthropic streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one Anthropic streaming event. input tokens and the
+// model arrive on message_start, output tokens and the stop reason on
+// message_delta; the common stream reader merges them into a single report
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	var event StreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(data))
+		return nil, fmt.Errorf("failed to unmarshal Anthropic stream event: %w", err)
+	}
+
+	switch event.Type {
+	case "message_start":
+		if event.Message == nil {
+			return nil, nil
+		}
+		chunk := &common.StreamChunk{Model: event.Message.Model}
+		if event.Message.Usage.InputTokens > 0 {
+			chunk.Usage = &common.Usage{
+				PromptTokens:     event.Message.Usage.InputTokens,
+				CompletionTokens: event.Message.Usage.OutputTokens,
+			}
+		}
+		return chunk, nil
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil, nil
+		}
+		switch event.Delta.Type {
+		case "text_delta":
+			return &common.StreamChunk{Content: event.Delta.Text}, nil
+		case "thinking_delta":
+			return &common.StreamChunk{Thinking: event.Delta.Thinking}, nil
+		}
+	case "message_delta":
+		chunk := &common.StreamChunk{}
+		if event.Delta != nil {
+			chunk.FinishReason = event.Delta.StopReason
+		}
+		if event.Usage != nil {
+			chunk.Usage = &common.Usage{
+				PromptTokens:     event.Usage.InputTokens,
+				CompletionTokens: event.Usage.OutputTokens,
+			}
+		}
+		return chunk, nil
+	case "message_stop":
+		return &common.StreamChunk{Done: true}, nil
+	case "error":
+		if event.Error != nil {
+			return nil, fmt.Errorf("Anthropic API error: %s", event.Error.Message)
+		}
+		return nil, fmt.Errorf("Anthropic API error during stream")
+	}
+
+	// ping, content_block_start/stop and unknown events carry nothing to emit
+	return nil, nil
+}
+
+// ParseToolCalls extracts tool_use blocks from an Anthropic response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	var anthropicResp MessagesResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal Anthropic response: %w", err)
+	}
+
+	var calls []common.ToolCall
+	for _, item := range anthropicResp.Content {
+		if item.Type != "tool_use" {
+			continue
+		}
+		calls = append(calls, common.ToolCall{
+			ID:   item.ID,
+			Type: "function",
+			Function: common.FunctionCall{
+				Name:      item.Name,
+				Arguments: string(item.Input),
+			},
+		})
+	}
+	return calls, nil
+}
+
+// convertMessages maps common messages onto Anthropic turns. assistant tool
+// calls become tool_use blocks, and consecutive tool results are merged into
+// a single user turn of tool_result blocks as the Messages API requires
+func convertMessages(messages []common.Message) []Message {
+	converted := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			var blocks []ContentItem
+			if msg.Content != "" {
+				blocks = append(blocks, ContentItem{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if len(input) == 0 {
+					input = json.RawMessage(`{}`)
+				}
+				blocks = append(blocks, ContentItem{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: input,
+				})
+			}
+			converted = append(converted, Message{Role: "assistant", Content: blocks})
+
+		case msg.Role == "tool":
+			result := ContentItem{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.Content}
+			if last := len(converted) - 1; last >= 0 && converted[last].Role == "user" {
+				if blocks, ok := converted[last].Content.([]ContentItem); ok && len(blocks) > 0 && blocks[0].Type == "tool_result" {
+					converted[last].Content = append(blocks, result)
+					continue
+				}
+			}
+			converted = append(converted, Message{Role: "user", Content: []ContentItem{result}})
+
+		case len(msg.Images) > 0:
+			// images come first; Anthropic recommends them ahead of the question
+			blocks := make([]ContentItem, 0, len(msg.Images)+1)
+			for _, image := range msg.Images {
+				blocks = append(blocks, ContentItem{
+					Type: "image",
+					Source: &ImageSource{
+						Type:      "base64",
+						MediaType: image.MediaType,
+						Data:      image.Base64(),
+					},
+				})
+			}
+			if msg.Content != "" {
+				blocks = append(blocks, ContentItem{Type: "text", Text: msg.Content})
+			}
+			converted = append(converted, Message{Role: msg.Role, Content: blocks})
+
+		default:
+			converted = append(converted, Message{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	return converted
+}
+
+// SupportsImages reports whether the model accepts image blocks.
+// every Claude 3 and later model has vision; legacy Claude 2 models do not
+func (p *Provider) SupportsImages(modelName string) bool {
+	id := strings.ToLower(modelName)
+	return !strings.HasPrefix(id, "claude-2") && !strings.HasPrefix(id, "claude-instant")
+}
+
 // HandleError creates Anthropic-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -568,3 +791,51 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 
 	return nil
 }
+
+// ListModels lists the models available to the API key via GET /v1/models
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Anthropic.APIKey == "" {
+		return nil, fmt.Errorf("Anthropic API key is required to list models")
+	}
+	baseURL := cfg.Providers.Anthropic.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+
+	client, err := common.NewAPIClient(cfg.Providers.Anthropic.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, baseURL+"/v1/models?limit=1000", map[string]string{
+		"x-api-key":         cfg.Providers.Anthropic.APIKey,
+		"anthropic-version": "2023-06-01",
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Capabilities reports the features a Claude model honors. JSON mode has no
+// Anthropic equivalent, so only --schema constrains output; no seed is accepted
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsThinking(modelName) || supportsEffort(modelName),
+		Schema:   true,
+		JSON:     false,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    true,
+		Seed:     false,
+	}
+}
+
+// ModelFamilies lists Claude's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "claude-opus-4", Example: "claude-opus-4-7"},
+		{Name: "claude-sonnet-4", Example: "claude-sonnet-4-6"},
+		{Name: "claude-haiku-4", Example: "claude-haiku-4-5"},
+		{Name: "claude-3-7-sonnet", Example: "claude-3-7-sonnet-20250219"},
+	}
+}
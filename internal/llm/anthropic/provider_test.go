@@ -109,9 +109,8 @@ func TestProvider_BuildOptions(t *testing.T) {
 	provider := New()
 
 	tests := []struct {
-		name     string
-		config   *config.Config
-		expected int // expected number of options
+		name   string
+		config *config.Config
 	}{
 		{
 			name: "minimal config",
@@ -119,7 +118,6 @@ func TestProvider_BuildOptions(t *testing.T) {
 				Parameters: config.Parameters{},
 				Format:     config.Format{},
 			},
-			expected: 1, // always returns at least one GenerateOptions object
 		},
 		{
 			name: "full config",
@@ -135,21 +133,13 @@ func TestProvider_BuildOptions(t *testing.T) {
 					JSON: true,
 				},
 			},
-			expected: 1, // still returns one GenerateOptions object, but with all options set
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			options := provider.BuildOptions(tt.config)
-			assert.Len(t, options, tt.expected)
-
-			// verify the first option is a GenerateOptions
-			if len(options) > 0 {
-				generateOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok)
-				assert.NotNil(t, generateOpts)
-			}
+			require.NotNil(t, options)
 		})
 	}
 }
@@ -164,7 +154,7 @@ func TestProvider_BuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		expected *MessagesRequest
 	}{
 		{
@@ -172,7 +162,7 @@ func TestProvider_BuildRequest(t *testing.T) {
 			options: nil,
 			expected: &MessagesRequest{
 				Model:     modelName,
-				Messages:  []common.Message{{Role: "user", Content: "Can you not understand that liberty is worth more than just ribbons?"}},
+				Messages:  []Message{{Role: "user", Content: "Can you not understand that liberty is worth more than just ribbons?"}},
 				System:    "You are a helpful assistant.",
 				MaxTokens: maxTokensDefault,
 				Stream:    common.BoolPtr(false),
@@ -188,7 +178,7 @@ func TestProvider_BuildRequest(t *testing.T) {
 			),
 			expected: &MessagesRequest{
 				Model:         modelName,
-				Messages:      []common.Message{{Role: "user", Content: "Can you not understand that liberty is worth more than just ribbons?"}},
+				Messages:      []Message{{Role: "user", Content: "Can you not understand that liberty is worth more than just ribbons?"}},
 				System:        "You are a helpful assistant.",
 				Temperature:   common.Float64Ptr(0.7),
 				MaxTokens:     1500,
@@ -197,17 +187,6 @@ func TestProvider_BuildRequest(t *testing.T) {
 				Stream:        common.BoolPtr(false),
 			},
 		},
-		{
-			name:    "invalid options type",
-			options: "invalid",
-			expected: &MessagesRequest{
-				Model:     modelName,
-				Messages:  []common.Message{{Role: "user", Content: "Can you not understand that liberty is worth more than just ribbons?"}},
-				System:    "You are a helpful assistant.",
-				MaxTokens: maxTokensDefault,
-				Stream:    common.BoolPtr(false),
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -638,9 +617,9 @@ func TestProvider_Integration(t *testing.T) {
 		{Role: "user", Content: "test message"},
 	}
 
-	content, err := client.Generate(context.Background(), messages, "claude-3-5-sonnet-latest")
+	result, err := client.Generate(context.Background(), messages, "claude-3-5-sonnet-latest", nil)
 	require.NoError(t, err)
-	assert.Equal(t, "test response", content)
+	assert.Equal(t, "test response", result.Content)
 }
 
 // TestSupportsThinking verifies the model id allowlist that drives
@@ -1152,10 +1131,8 @@ func TestBuildOptions_ThinkingAndSchema(t *testing.T) {
 		Format: config.Format{},
 	}
 
-	opts := provider.BuildOptions(cfg)
-	require.Len(t, opts, 1)
-	ga, ok := opts[0].(*GenerateOptions)
-	require.True(t, ok)
+	ga := provider.BuildOptions(cfg)
+	require.NotNil(t, ga)
 
 	assert.Equal(t, common.ThinkingHigh, ga.Thinking)
 	require.NotNil(t, ga.ResponseFormat)
@@ -1391,3 +1368,169 @@ func TestBuildRequest_TemperatureForcedWhenThinking(t *testing.T) {
 		assert.Equal(t, 0.85, *msgReq.TopP)
 	})
 }
+
+func TestProvider_ParseStreamEvent(t *testing.T) {
+	provider := New()
+	logger := slog.Default()
+
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude","usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"hmm"}}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Hi"}}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+		`{"type":"message_stop"}`,
+	}
+
+	var chunks []*common.StreamChunk
+	for _, event := range events {
+		chunk, err := provider.ParseStreamEvent([]byte(event), logger)
+		require.NoError(t, err)
+		if chunk != nil {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	require.Len(t, chunks, 5)
+	assert.Equal(t, 12, chunks[0].Usage.PromptTokens)
+	assert.Equal(t, "claude", chunks[0].Model)
+	assert.Equal(t, "hmm", chunks[1].Thinking)
+	assert.Equal(t, "Hi", chunks[2].Content)
+	assert.Equal(t, 7, chunks[3].Usage.CompletionTokens)
+	assert.Equal(t, "end_turn", chunks[3].FinishReason)
+	assert.True(t, chunks[4].Done)
+
+	_, err := provider.ParseStreamEvent([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`), logger)
+	assert.ErrorContains(t, err, "Overloaded")
+}
+
+func TestProvider_ParseMetadata(t *testing.T) {
+	provider := New()
+
+	meta := provider.ParseMetadata([]byte(`{"model":"claude-sonnet-4-20250514","stop_reason":"max_tokens","content":[{"type":"text","text":"Hi"}]}`))
+	assert.Equal(t, common.ResponseMetadata{Model: "claude-sonnet-4-20250514", FinishReason: "max_tokens"}, meta)
+
+	assert.Equal(t, common.ResponseMetadata{}, provider.ParseMetadata([]byte(`not json`)))
+}
+
+func TestProvider_BuildRequest_Tools(t *testing.T) {
+	provider := New()
+	opts := NewGenerateOptions(WithTools([]common.ToolConfig{{
+		Type:     "function",
+		Function: common.FunctionDefinition{Name: "date", Description: "Print the date"},
+	}}))
+
+	messages := []common.Message{
+		{Role: "user", Content: "what day and time is it?"},
+		{Role: "assistant", Content: "Checking.", ToolCalls: []common.ToolCall{
+			{ID: "toolu_1", Type: "function", Function: common.FunctionCall{Name: "date", Arguments: `{"part":"day"}`}},
+			{ID: "toolu_2", Type: "function", Function: common.FunctionCall{Name: "date", Arguments: ""}},
+		}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "Friday"},
+		{Role: "tool", ToolCallID: "toolu_2", Content: "noon"},
+	}
+
+	request, err := provider.BuildRequest(messages, "claude-sonnet-4-6", opts, slog.Default())
+	require.NoError(t, err)
+	msgReq := request.(*MessagesRequest)
+
+	require.Len(t, msgReq.Tools, 1)
+	assert.Equal(t, "date", msgReq.Tools[0].Name)
+	assert.JSONEq(t, `{"type":"object","properties":{}}`, string(msgReq.Tools[0].InputSchema))
+
+	// tool results continue a tool_use turn, so thinking is left off
+	assert.Nil(t, msgReq.Thinking)
+
+	require.Len(t, msgReq.Messages, 3)
+	assert.Equal(t, "what day and time is it?", msgReq.Messages[0].Content)
+
+	assistant := msgReq.Messages[1].Content.([]ContentItem)
+	require.Len(t, assistant, 3)
+	assert.Equal(t, ContentItem{Type: "text", Text: "Checking."}, assistant[0])
+	assert.Equal(t, "tool_use", assistant[1].Type)
+	assert.JSONEq(t, `{"part":"day"}`, string(assistant[1].Input))
+	assert.JSONEq(t, `{}`, string(assistant[2].Input))
+
+	// both results are merged into a single user turn
+	assert.Equal(t, "user", msgReq.Messages[2].Role)
+	results := msgReq.Messages[2].Content.([]ContentItem)
+	require.Len(t, results, 2)
+	assert.Equal(t, ContentItem{Type: "tool_result", ToolUseID: "toolu_1", Content: "Friday"}, results[0])
+	assert.Equal(t, "toolu_2", results[1].ToolUseID)
+}
+
+func TestProvider_ParseToolCalls(t *testing.T) {
+	provider := New()
+	body := []byte(`{
+		"id": "msg_1",
+		"type": "message",
+		"role": "assistant",
+		"content": [
+			{"type": "tool_use", "id": "toolu_1", "name": "date", "input": {"part": "day"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 20, "output_tokens": 10}
+	}`)
+
+	calls, err := provider.ParseToolCalls(body, slog.Default())
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "toolu_1", calls[0].ID)
+	assert.Equal(t, "date", calls[0].Function.Name)
+	assert.JSONEq(t, `{"part":"day"}`, calls[0].Function.Arguments)
+
+	// a tool_use turn without text is not a parse error
+	content, usage, err := provider.ParseResponse(body, slog.Default())
+	require.NoError(t, err)
+	assert.Empty(t, content)
+	assert.Equal(t, 30, usage.TotalTokens)
+}
+
+func TestProvider_Images(t *testing.T) {
+	provider := New()
+	messages := []common.Message{{
+		Role:    "user",
+		Content: "What is drawn on the barn wall?",
+		Images:  []common.Image{{MediaType: "image/png", Data: []byte("\x89PNG")}},
+	}}
+
+	request, err := provider.BuildRequest(messages, "claude-sonnet-4-6", nil, slog.Default())
+	require.NoError(t, err)
+	msgReq := request.(*MessagesRequest)
+
+	// images are sent as base64 blocks ahead of the text
+	require.Len(t, msgReq.Messages, 1)
+	blocks := msgReq.Messages[0].Content.([]ContentItem)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, ContentItem{
+		Type:   "image",
+		Source: &ImageSource{Type: "base64", MediaType: "image/png", Data: "iVBORw=="},
+	}, blocks[0])
+	assert.Equal(t, ContentItem{Type: "text", Text: "What is drawn on the barn wall?"}, blocks[1])
+
+	assert.True(t, provider.SupportsImages("claude-haiku-4-5"))
+	assert.False(t, provider.SupportsImages("claude-2.1"))
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, "2023-06-01", r.Header.Get("anthropic-version"))
+		_, _ = w.Write([]byte(`{"data":[{"type":"model","id":"claude-sonnet-4-5-20250929"},{"type":"model","id":"claude-haiku-4-5-20251001"}],"has_more":false}`))
+	}))
+	defer server.Close()
+
+	// the base URL works with or without the /v1 suffix
+	for _, baseURL := range []string{server.URL + "/v1", server.URL} {
+		cfg := &config.Config{}
+		cfg.Providers.Anthropic.APIKey = "test-key"
+		cfg.Providers.Anthropic.BaseUrl = baseURL
+
+		models, err := New().ListModels(context.Background(), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude-haiku-4-5-20251001", "claude-sonnet-4-5-20250929"}, models)
+	}
+}
@@ -0,0 +1,206 @@
+// Package azure provides a client implementation for Azure OpenAI.
+//
+// API Reference: https://learn.microsoft.com/azure/ai-services/openai/reference
+// Authentication: providers.azure.api_key or AZURE_OPENAI_API_KEY environment variable
+// Endpoint: providers.azure.base_url or AZURE_OPENAI_ENDPOINT environment variable
+//
+// Azure serves the OpenAI chat completions API per deployment, so the request
+// and response types are OpenAI's; only the URL and auth header differ:
+//   POST {endpoint}/openai/deployments/{deployment}/chat/completions?api-version=...
+//   api-key: <key>
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/llm/openai"
+)
+
+// defaultAPIVersion is the api-version sent when none is configured
+const defaultAPIVersion = "2024-10-21"
+
+// Provider implements the unified registry.Provider interface for Azure
+// OpenAI. request building and response parsing come from the OpenAI provider
+type Provider struct {
+	openai.Provider
+	settings config.Azure
+}
+
+// ensure Provider implements the common.Provider interface
+var _ common.Provider = (*Provider)(nil)
+
+// ensure Provider addresses the deployment's chat completions endpoint
+var _ common.EndpointProvider = (*Provider)(nil)
+
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider overrides the OpenAI model listing
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider describes what its models honor, as the OpenAI provider does
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// New creates a new Azure OpenAI provider instance
+func New() *Provider {
+	return &Provider{}
+}
+
+// CreateClient creates a new LLM client using the unified adapter pattern
+func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	settings := cfg.Providers.Azure
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf(`Azure OpenAI API key is required.
+
+You can set the API key using the environment variable AZURE_OPENAI_API_KEY or via slop config set azure-key=<your_api_key>
+Keys are listed under Keys and Endpoint for your resource in the Azure portal`)
+	}
+	if settings.BaseUrl == "" {
+		return nil, fmt.Errorf(`Azure OpenAI endpoint is required.
+
+Set providers.azure.base_url or the environment variable AZURE_OPENAI_ENDPOINT to your resource endpoint,
+for example https://my-resource.openai.azure.com`)
+	}
+
+	// create client options
+	var opts []common.ClientOption
+	if logger != nil {
+		opts = append(opts, common.WithLogger(logger))
+	}
+	// use provider-specific MaxRetries, fall back to global if not set
+	maxRetries := settings.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = cfg.Parameters.MaxRetries
+	}
+	if maxRetries > 5 {
+		maxRetries = 5 // enforce maximum limit
+	}
+	if maxRetries > 0 {
+		opts = append(opts, common.WithMaxRetries(maxRetries))
+	}
+
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(settings.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(settings.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.azure: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	// the client gets its own provider carrying the deployment map and api-version
+	adapterClient := common.NewAdapterClient(&Provider{settings: settings}, settings.APIKey, settings.BaseUrl, opts...)
+	return adapterClient, nil
+}
+
+// ProviderName returns the name of this provider
+func (p *Provider) ProviderName() string {
+	return "azure"
+}
+
+// ListModels is unsupported: deployment names are chosen per resource and
+// the data-plane API does not list them
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	return nil, fmt.Errorf("azure: %w; deployments are configured in providers.azure.deployments", common.ErrModelListingUnsupported)
+}
+
+// Embed is unsupported: the embedded OpenAI method would address the
+// public API rather than the resource's deployments
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	return nil, fmt.Errorf("azure: %w", common.ErrEmbeddingUnsupported)
+}
+
+// deployment returns the deployment serving a model. viper lowercases map
+// keys, so the lookup is case-insensitive
+func (p *Provider) deployment(modelName string) string {
+	if name, ok := p.settings.Deployments[strings.ToLower(modelName)]; ok && name != "" {
+		return name
+	}
+	return modelName
+}
+
+// RequestURL addresses the chat completions endpoint of the model's deployment
+func (p *Provider) RequestURL(baseURL, modelName string, stream bool) string {
+	apiVersion := p.settings.APIVersion
+	if apiVersion == "" {
+		apiVersion = defaultAPIVersion
+	}
+	// accept endpoints copied with or without the /openai suffix
+	base := strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/openai")
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		base, url.PathEscape(p.deployment(modelName)), url.QueryEscape(apiVersion))
+}
+
+// HandleError creates Azure-specific error messages from HTTP error responses
+func (p *Provider) HandleError(statusCode int, body []byte) error {
+	var errorResp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &errorResp)
+	message := errorResp.Error.Message
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf(`Azure OpenAI authentication failed.
+
+Check your API key and ensure it belongs to the resource in providers.azure.base_url.
+You can set the API key using the environment variable AZURE_OPENAI_API_KEY or via slop config set azure-key=<your_api_key>`)
+
+	case http.StatusNotFound:
+		if errorResp.Error.Code == "DeploymentNotFound" || message == "" {
+			return fmt.Errorf(`Azure OpenAI deployment not found.
+
+Requests go to the deployment named after the model. Map model names to your
+deployment names under [providers.azure.deployments], and check providers.azure.api_version`)
+		}
+		return fmt.Errorf("Azure OpenAI error: %s", message)
+
+	case http.StatusTooManyRequests:
+		return fmt.Errorf(`Azure OpenAI rate limit exceeded.
+
+Please try again later or raise the deployment's tokens-per-minute quota in the Azure portal`)
+	}
+
+	if message != "" {
+		return fmt.Errorf("Azure OpenAI error: %s", message)
+	}
+	return fmt.Errorf("Azure OpenAI request failed with status %d: %s", statusCode, string(body))
+}
+
+// CustomizeRequest replaces the bearer token with the api-key header Azure expects
+func (p *Provider) CustomizeRequest(req *http.Request) error {
+	if apiKey, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		req.Header.Del("Authorization")
+		req.Header.Set("api-key", apiKey)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/llm/openai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(baseURL string) *config.Config {
+	return &config.Config{
+		Providers: config.Providers{
+			Azure: config.Azure{
+				BaseProvider: config.BaseProvider{APIKey: "test-api-key", BaseUrl: baseURL, APIVersion: "2024-10-21"},
+				Deployments:  map[string]string{"gpt-4o": "prod-gpt4o"},
+			},
+		},
+	}
+}
+
+func TestProvider_Methods(t *testing.T) {
+	p := New()
+	assert.Equal(t, "azure", p.ProviderName())
+	assert.True(t, p.RequiresAPIKey())
+	assert.True(t, p.SupportsImages("gpt-4o"))
+	assert.Equal(t, common.StreamSSE, p.StreamFormat())
+}
+
+func TestProvider_CreateClient(t *testing.T) {
+	p := New()
+
+	_, err := p.CreateClient(nil, nil)
+	assert.ErrorContains(t, err, "config cannot be nil")
+
+	_, err = p.CreateClient(&config.Config{}, nil)
+	assert.ErrorContains(t, err, "AZURE_OPENAI_API_KEY")
+
+	cfg := testConfig("")
+	_, err = p.CreateClient(cfg, nil)
+	assert.ErrorContains(t, err, "AZURE_OPENAI_ENDPOINT")
+
+	client, err := p.CreateClient(testConfig("https://my-resource.openai.azure.com"), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestProvider_RequestURL(t *testing.T) {
+	p := &Provider{settings: config.Azure{Deployments: map[string]string{"gpt-4o": "prod-gpt4o"}}}
+	base := "https://my-resource.openai.azure.com"
+
+	tests := []struct {
+		name  string
+		base  string
+		model string
+		want  string
+	}{
+		{"mapped deployment", base, "gpt-4o", base + "/openai/deployments/prod-gpt4o/chat/completions?api-version=2024-10-21"},
+		{"mapping ignores case", base, "GPT-4o", base + "/openai/deployments/prod-gpt4o/chat/completions?api-version=2024-10-21"},
+		{"model as deployment", base, "gpt-4.1-mini", base + "/openai/deployments/gpt-4.1-mini/chat/completions?api-version=2024-10-21"},
+		{"trailing slash", base + "/", "o4-mini", base + "/openai/deployments/o4-mini/chat/completions?api-version=2024-10-21"},
+		{"openai suffix", base + "/openai/", "o4-mini", base + "/openai/deployments/o4-mini/chat/completions?api-version=2024-10-21"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.RequestURL(tt.base, tt.model, false))
+		})
+	}
+
+	p.settings.APIVersion = "2025-04-01-preview"
+	assert.Equal(t, base+"/openai/deployments/prod-gpt4o/chat/completions?api-version=2025-04-01-preview", p.RequestURL(base, "gpt-4o", true))
+}
+
+func TestProvider_HandleError(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"code":"401","message":"Access denied"}}`, "AZURE_OPENAI_API_KEY"},
+		{"deployment not found", http.StatusNotFound, `{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`, "providers.azure.deployments"},
+		{"not found", http.StatusNotFound, `{"error":{"code":"404","message":"Resource not found"}}`, "Resource not found"},
+		{"rate limit", http.StatusTooManyRequests, ``, "rate limit"},
+		{"content filter", http.StatusBadRequest, `{"error":{"code":"content_filter","message":"The response was filtered"}}`, "The response was filtered"},
+		{"unparsed body", http.StatusBadGateway, `bad gateway`, "status 502: bad gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, p.HandleError(tt.status, []byte(tt.body)), tt.want)
+		})
+	}
+}
+
+func TestProvider_CustomizeRequest(t *testing.T) {
+	req, err := common.CreateJSONRequest(context.Background(), "https://example.com", "secret", nil)
+	require.NoError(t, err)
+	require.NoError(t, New().CustomizeRequest(req))
+
+	assert.Equal(t, "secret", req.Header.Get("api-key"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestProvider_Integration(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/openai/deployments/prod-gpt4o/chat/completions", r.URL.Path)
+			assert.Equal(t, "2024-10-21", r.URL.Query().Get("api-version"))
+			assert.Equal(t, "test-api-key", r.Header.Get("api-key"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			var req openai.ChatRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "test message", req.Messages[0].Content)
+			require.NotNil(t, req.Temperature)
+			assert.Equal(t, 0.5, *req.Temperature)
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"model":"gpt-4o-2024-11-20","choices":[{"index":0,"message":{"role":"assistant","content":"test response"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`)
+		}))
+		defer server.Close()
+
+		client, err := New().CreateClient(testConfig(server.URL), nil)
+		require.NoError(t, err)
+
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "test message"}}, "gpt-4o", openai.NewGenerateOptions(openai.WithTemperature(0.5)))
+		require.NoError(t, err)
+		assert.Equal(t, "test response", result.Content)
+		assert.Equal(t, "gpt-4o-2024-11-20", result.Model)
+		assert.Equal(t, 5, result.Usage.TotalTokens)
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/openai/deployments/o4-mini/chat/completions", r.URL.Path)
+
+			// Azure opens the stream with a prompt filter event carrying no choices
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[],\"prompt_filter_results\":[{\"prompt_index\":0}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		client, err := New().CreateClient(testConfig(server.URL), nil)
+		require.NoError(t, err)
+
+		var chunks []string
+		handler := common.StreamHandler(func(chunk common.StreamChunk) {
+			if chunk.Content != "" {
+				chunks = append(chunks, chunk.Content)
+			}
+		})
+		req := openai.NewGenerateOptions(openai.WithStream())
+		req.OnChunk = handler
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "o4-mini", req)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result.Content)
+		assert.Equal(t, []string{"hel", "lo"}, chunks)
+	})
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	_, err := New().ListModels(context.Background(), &config.Config{})
+	assert.ErrorIs(t, err, common.ErrModelListingUnsupported)
+	assert.ErrorContains(t, err, "providers.azure.deployments")
+}
+
+func TestProvider_Embed(t *testing.T) {
+	_, err := New().Embed(context.Background(), &config.Config{}, "text-embedding-3-small", []string{"a"}, common.EmbedClustering)
+	assert.ErrorIs(t, err, common.ErrEmbeddingUnsupported)
+}
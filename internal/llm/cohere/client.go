@@ -23,8 +23,10 @@ type ChatRequest struct {
 	// Structured output support
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 
-	// Tools / function calling
-	StrictTools *bool `json:"strict_tools,omitempty"`
+	// Tools / function calling. Cohere v2 accepts OpenAI-compatible
+	// function definitions and returns tool_calls in the same shape
+	Tools       []common.ToolConfig `json:"tools,omitempty"`
+	StrictTools *bool               `json:"strict_tools,omitempty"`
 
 	// Grounded generation (RAG). When non-empty, Cohere requires
 	// safety_mode to be "CONTEXTUAL".
@@ -81,3 +83,22 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	Type    string `json:"type,omitempty"`
 }
+
+// StreamEvent represents one server-sent event on Cohere's v2 streaming chat
+// API. content-delta events carry text; message-end carries usage
+type StreamEvent struct {
+	Type  string       `json:"type"`
+	Delta *StreamDelta `json:"delta,omitempty"`
+}
+
+// StreamDelta is the delta payload of a Cohere stream event
+type StreamDelta struct {
+	Message *struct {
+		Content *struct {
+			Text     string `json:"text,omitempty"`
+			Thinking string `json:"thinking,omitempty"`
+		} `json:"content,omitempty"`
+	} `json:"message,omitempty"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Usage        *Usage `json:"usage,omitempty"`
+}
@@ -1,12 +1,14 @@
 package cohere
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains Cohere-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// Cohere-specific parameters
+// Extension holds Cohere-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	TopK        *int       // limits token selection top K candidates
 	Seed        *int       // random seed for deterministic generation
 	SafetyMode  *string    // controls safety instructions ("STRICT", "CONTEXTUAL", "NONE")
@@ -14,39 +16,71 @@ type GenerateOptions struct {
 	Documents   []Document // grounding documents for RAG-style generation
 }
 
-// GenerateOption configures Cohere-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "cohere"
+}
+
+// Validate checks the parameters against the values Cohere accepts
+func (e *Extension) Validate() error {
+	if e.TopK != nil && (*e.TopK < 0 || *e.TopK > 500) {
+		return fmt.Errorf("k %d is out of range (0-500)", *e.TopK)
+	}
+	if e.SafetyMode != nil {
+		switch *e.SafetyMode {
+		case "STRICT", "CONTEXTUAL", "NONE":
+		default:
+			return fmt.Errorf("unknown safety_mode %q: expected STRICT, CONTEXTUAL or NONE", *e.SafetyMode)
+		}
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Cohere
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Cohere parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // Cohere-specific option functions
 
 // WithTopK limits token selection to top K candidates
 func WithTopK(topK int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.TopK = &topK
+	return func(c *common.GenerateRequest) {
+		extension(c).TopK = &topK
 	}
 }
 
 // WithSeed enables deterministic generation
 func WithSeed(seed int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Seed = &seed
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
 	}
 }
 
 // WithSafetyMode sets the safety mode
 // valid values include "STRICT", "CONTEXTUAL", "NONE"
 func WithSafetyMode(mode string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.SafetyMode = &mode
+	return func(c *common.GenerateRequest) {
+		extension(c).SafetyMode = &mode
 	}
 }
 
@@ -54,8 +88,8 @@ func WithSafetyMode(mode string) GenerateOption {
 // Cohere constrains tool-call arguments to match the declared schema,
 // which noticeably reduces hallucinated fields.
 func WithStrictTools(b bool) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.StrictTools = &b
+	return func(c *common.GenerateRequest) {
+		extension(c).StrictTools = &b
 	}
 }
 
@@ -63,8 +97,8 @@ func WithStrictTools(b bool) GenerateOption {
 // Cohere requires safety_mode="CONTEXTUAL" whenever documents are set;
 // the provider's BuildRequest handles that pairing automatically.
 func WithDocuments(docs []Document) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Documents = docs
+	return func(c *common.GenerateRequest) {
+		extension(c).Documents = docs
 	}
 }
 
@@ -72,7 +106,7 @@ func WithDocuments(docs []Document) GenerateOption {
 
 // WithTemperature sets response randomness (0.0-1.0)
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
@@ -80,40 +114,49 @@ func WithTemperature(temp float64) GenerateOption {
 // WithTopP sets nucleus sampling threshold (0.0-1.0)
 // Note: Cohere uses 'p' parameter instead of 'top_p'
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets maximum tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences to halt generation
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
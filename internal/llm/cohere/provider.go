@@ -5,11 +5,12 @@
 //
 // Example usage:
 //   client := cohere.NewClient(apiKey)
-//   response, err := client.Generate(ctx, messages, cohere.WithTemperature(0.7))
+//   response, err := client.Generate(ctx, messages, model, cohere.NewGenerateOptions(cohere.WithTemperature(0.7)))
 
 package cohere
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -26,6 +27,27 @@ type Provider struct{}
 // esure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the stop reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider can embed text
+var _ common.Embedder = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// defaultBaseURL is used when providers.cohere.base_url is unset
+const defaultBaseURL = "https://api.cohere.com/v2"
+
 // creates a new Cohere provider instance
 func New() *Provider {
 	return &Provider{}
@@ -60,12 +82,25 @@ Get an API key from https://dashboard.cohere.com/api-keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.Cohere.APIKey, "https://api.cohere.com/v2", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Cohere.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Cohere.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.cohere: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Cohere.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates Cohere-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -86,11 +121,17 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// wire a pre-resolved response schema through the shared helper
 	if cfg.Parameters.ResponseSchema != "" {
 		schema := []byte(cfg.Parameters.ResponseSchema)
-		functionalOpts = append(functionalOpts, func(c *GenerateOptions) {
+		functionalOpts = append(functionalOpts, func(c *common.GenerateRequest) {
 			common.WithSchema("response", schema)(&c.GenerateOptions)
 		})
 	}
@@ -99,7 +140,7 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	// rather than a request-level parameter
 	// TODO: Keep an eye on this in case reasoning/effort params become available
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // RequiresAPIKey returns true since Cohere requires an API key
@@ -113,17 +154,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates a Cohere-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// connvert options to Cohere-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if cohereOpts, ok := options.(*GenerateOptions); ok {
-			config = cohereOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Cohere's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -133,7 +171,7 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	requestBody := &ChatRequest{
 		Model:    modelName,
 		Messages: messages,
-		Stream:   common.BoolPtr(false), // Disable streaming for now
+		Stream:   common.BoolPtr(config.Stream),
 	}
 
 	// map common generation options to Cohere's API format
@@ -151,20 +189,23 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map Cohere-specific options
-	if config.TopK != nil {
-		requestBody.K = config.TopK
+	if ext.TopK != nil {
+		requestBody.K = ext.TopK
+	}
+	if ext.Seed != nil {
+		requestBody.Seed = ext.Seed // Cohere: "determinism cannot be totally guaranteed"
 	}
-	if config.Seed != nil {
-		requestBody.Seed = config.Seed // Cohere: "determinism cannot be totally guaranteed"
+	if ext.SafetyMode != nil {
+		requestBody.SafetyMode = ext.SafetyMode
 	}
-	if config.SafetyMode != nil {
-		requestBody.SafetyMode = config.SafetyMode
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
 	}
-	if config.StrictTools != nil {
-		requestBody.StrictTools = config.StrictTools
+	if ext.StrictTools != nil {
+		requestBody.StrictTools = ext.StrictTools
 	}
-	if len(config.Documents) > 0 {
-		requestBody.Documents = config.Documents
+	if len(ext.Documents) > 0 {
+		requestBody.Documents = ext.Documents
 		// Cohere requires CONTEXTUAL safety mode when documents are present
 		// only auto-set when the caller hasn't picked a mode of their own
 		if requestBody.SafetyMode == nil {
@@ -196,8 +237,9 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	}
 
 	type cohereMessageArray struct {
-		Role    string              `json:"role"`
-		Content []cohereContentPart `json:"content"`
+		Role      string              `json:"role"`
+		Content   []cohereContentPart `json:"content"`
+		ToolCalls []common.ToolCall   `json:"tool_calls"`
 	}
 
 	type cohereMessageString struct {
@@ -219,11 +261,13 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	var chatRespArray cohereChatResponseArray
 	if err := json.Unmarshal(body, &chatRespArray); err == nil {
 		// extract text content from the content array
+		// tool-call turns carry a tool_plan and tool_calls instead of content
 		var content string
-		if len(chatRespArray.Message.Content) == 0 {
+		if len(chatRespArray.Message.Content) > 0 {
+			content = chatRespArray.Message.Content[0].Text
+		} else if len(chatRespArray.Message.ToolCalls) == 0 {
 			return "", nil, fmt.Errorf("Cohere response contained no content")
 		}
-		content = chatRespArray.Message.Content[0].Text
 
 		// log token usage if available
 		var usage *common.Usage
@@ -262,6 +306,73 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, usage, nil
 }
 
+// ParseToolCalls extracts the tool calls requested by a Cohere response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	var chatResp struct {
+		Message struct {
+			ToolCalls []common.ToolCall `json:"tool_calls"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal Cohere response: %w", err)
+	}
+	return chatResp.Message.ToolCalls, nil
+}
+
+// ParseMetadata extracts the finish reason from a Cohere response. Cohere
+// doesn't echo the model id, so the requested one stands
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	var cohereResp struct {
+		FinishReason string `json:"finish_reason"`
+	}
+	if err := json.Unmarshal(body, &cohereResp); err != nil {
+		return common.ResponseMetadata{}
+	}
+	return common.ResponseMetadata{FinishReason: cohereResp.FinishReason}
+}
+
+// StreamFormat reports that Cohere streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one Cohere v2 streaming event
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	var event StreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(data))
+		return nil, fmt.Errorf("failed to unmarshal Cohere stream event: %w", err)
+	}
+
+	switch event.Type {
+	case "content-delta":
+		if event.Delta != nil && event.Delta.Message != nil && event.Delta.Message.Content != nil {
+			return &common.StreamChunk{
+				Content:  event.Delta.Message.Content.Text,
+				Thinking: event.Delta.Message.Content.Thinking,
+			}, nil
+		}
+	case "message-end":
+		chunk := &common.StreamChunk{Done: true}
+		if event.Delta != nil {
+			chunk.FinishReason = event.Delta.FinishReason
+		}
+		if event.Delta != nil && event.Delta.Usage != nil && event.Delta.Usage.Tokens.InputTokens > 0 {
+			tokens := event.Delta.Usage.Tokens
+			chunk.Usage = &common.Usage{
+				PromptTokens:     tokens.InputTokens,
+				CompletionTokens: tokens.OutputTokens,
+				TotalTokens:      tokens.InputTokens + tokens.OutputTokens,
+			}
+		}
+		return chunk, nil
+	}
+
+	// message-start, content-start/end and other events carry nothing to emit
+	return nil, nil
+}
+
 // HandleError creates Cohere-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -314,3 +425,109 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 	// types are already set by CreateJSONRequest in the common package
 	return nil
 }
+
+// ListModels lists the chat models available to the API key. model listing
+// lives on the v1 API, so a v2 base URL is rewritten
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Cohere.APIKey == "" {
+		return nil, fmt.Errorf("Cohere API key is required to list models")
+	}
+	baseURL := cfg.Providers.Cohere.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v2")
+
+	client, err := common.NewAPIClient(cfg.Providers.Cohere.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, baseURL+"/v1/models?endpoint=chat&page_size=1000", map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Cohere.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// embedInputTypes maps what text is embedded for to Cohere's input_type
+var embedInputTypes = map[common.EmbedInputType]string{
+	common.EmbedClustering: "clustering",
+	common.EmbedDocument:   "search_document",
+	common.EmbedQuery:      "search_query",
+}
+
+// Embed returns a vector per input via POST /v2/embed, with the input_type
+// matching what the vectors are for; clustering when unset
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, inputType common.EmbedInputType) (*common.EmbedResult, error) {
+	if cfg.Providers.Cohere.APIKey == "" {
+		return nil, fmt.Errorf("Cohere API key is required to embed text")
+	}
+	baseURL := cfg.Providers.Cohere.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	cohereInputType, ok := embedInputTypes[inputType]
+	if !ok {
+		cohereInputType = embedInputTypes[common.EmbedClustering]
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Cohere.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.PostJSON(ctx, client, strings.TrimSuffix(baseURL, "/")+"/embed", map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Cohere.APIKey,
+	}, map[string]interface{}{
+		"model":           modelName,
+		"texts":           inputs,
+		"input_type":      cohereInputType,
+		"embedding_types": []string{"float"},
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Embeddings struct {
+			Float [][]float64 `json:"float"`
+		} `json:"embeddings"`
+		Meta struct {
+			BilledUnits struct {
+				InputTokens int `json:"input_tokens"`
+			} `json:"billed_units"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	}
+	tokens := resp.Meta.BilledUnits.InputTokens
+	return &common.EmbedResult{
+		Vectors: resp.Embeddings.Float,
+		Model:   modelName,
+		Usage:   &common.Usage{PromptTokens: tokens, TotalTokens: tokens},
+	}, nil
+}
+
+// Capabilities reports the features a Cohere model honors
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: false,
+		Schema:   true,
+		JSON:     true,
+		Vision:   false,
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists Cohere's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "command-a-reasoning", Example: "command-a-reasoning-08-2025"},
+		{Name: "command-a", Example: "command-a-03-2025"},
+		{Name: "command-r", Example: "command-r-08-2024"},
+	}
+}
@@ -1,9 +1,11 @@
 package cohere
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/chriscorrea/slop/internal/config"
@@ -96,7 +98,7 @@ func TestBuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		validate func(t *testing.T, request interface{})
 	}{
 		{
@@ -112,16 +114,18 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Build request with generation options",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					Temperature: common.Float64Ptr(0.8),
 					MaxTokens:   common.IntPtr(1000),
 					TopP:        common.Float64Ptr(0.9),
 					Stop:        []string{"STOP"},
 				},
-				TopK:       common.IntPtr(50),
-				Seed:       common.IntPtr(42),
-				SafetyMode: common.StringPtr("strict"),
+				Extension: &Extension{
+					TopK:       common.IntPtr(50),
+					Seed:       common.IntPtr(42),
+					SafetyMode: common.StringPtr("STRICT"),
+				},
 			},
 			validate: func(t *testing.T, request interface{}) {
 				chatReq, ok := request.(*ChatRequest)
@@ -136,12 +140,12 @@ func TestBuildRequest(t *testing.T) {
 				assert.Equal(t, []string{"STOP"}, chatReq.StopSequences)
 				assert.Equal(t, common.IntPtr(50), chatReq.K) // TopK -> K mapping
 				assert.Equal(t, common.IntPtr(42), chatReq.Seed)
-				assert.Equal(t, common.StringPtr("strict"), chatReq.SafetyMode)
+				assert.Equal(t, common.StringPtr("STRICT"), chatReq.SafetyMode)
 			},
 		},
 		{
 			name: "Build request with JSON format",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					ResponseFormat: &common.ResponseFormat{Type: "json_object"},
 				},
@@ -157,12 +161,14 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Documents non-empty auto-sets CONTEXTUAL safety mode",
-			options: &GenerateOptions{
-				Documents: []Document{
-					{ID: "windmill-1", Data: map[string]string{
-						"title": "Building the windmill",
-						"text":  "four legs good, two legs bad",
-					}},
+			options: &common.GenerateRequest{
+				Extension: &Extension{
+					Documents: []Document{
+						{ID: "windmill-1", Data: map[string]string{
+							"title": "Building the windmill",
+							"text":  "four legs good, two legs bad",
+						}},
+					},
 				},
 			},
 			validate: func(t *testing.T, request interface{}) {
@@ -176,7 +182,7 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name:    "Documents empty leaves SafetyMode unset",
-			options: &GenerateOptions{},
+			options: &common.GenerateRequest{},
 			validate: func(t *testing.T, request interface{}) {
 				chatReq := request.(*ChatRequest)
 				assert.Empty(t, chatReq.Documents)
@@ -185,10 +191,12 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Explicit SafetyMode preserved alongside Documents",
-			options: &GenerateOptions{
-				SafetyMode: common.StringPtr("STRICT"),
-				Documents: []Document{
-					{Data: map[string]string{"text": "Snowball's plans"}},
+			options: &common.GenerateRequest{
+				Extension: &Extension{
+					SafetyMode: common.StringPtr("STRICT"),
+					Documents: []Document{
+						{Data: map[string]string{"text": "Snowball's plans"}},
+					},
 				},
 			},
 			validate: func(t *testing.T, request interface{}) {
@@ -200,10 +208,8 @@ func TestBuildRequest(t *testing.T) {
 			},
 		},
 		{
-			name: "StrictTools populates the request field",
-			options: &GenerateOptions{
-				StrictTools: common.BoolPtr(true),
-			},
+			name:    "StrictTools populates the request field",
+			options: NewGenerateOptions(WithStrictTools(true)),
 			validate: func(t *testing.T, request interface{}) {
 				chatReq := request.(*ChatRequest)
 				if assert.NotNil(t, chatReq.StrictTools) {
@@ -213,7 +219,7 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Schema wiring round-trips through response_format",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					ResponseFormat: &common.ResponseFormat{
 						Type:   "json_schema",
@@ -235,7 +241,7 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "ThinkingLevel is ignored (Cohere selects by model)",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					Thinking: common.ThinkingHigh,
 				},
@@ -403,7 +409,7 @@ func TestBuildOptions(t *testing.T) {
 	tests := []struct {
 		name     string
 		config   *config.Config
-		validate func(t *testing.T, options []interface{})
+		validate func(t *testing.T, genOpts *common.GenerateRequest)
 	}{
 		{
 			name: "Default config with zero values",
@@ -419,18 +425,13 @@ func TestBuildOptions(t *testing.T) {
 					JSON: false, // false - should not create option
 				},
 			},
-			validate: func(t *testing.T, options []interface{}) {
-				assert.Len(t, options, 1, "Should return exactly one GenerateOptions object")
-
-				genOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok, "Should return *GenerateOptions")
-
+			validate: func(t *testing.T, genOpts *common.GenerateRequest) {
 				// All fields should be nil/zero since no options were set
 				assert.Nil(t, genOpts.Temperature, "Temperature should be nil for zero value")
 				assert.Nil(t, genOpts.MaxTokens, "MaxTokens should be nil for zero value")
 				assert.Nil(t, genOpts.TopP, "TopP should be nil for zero value")
 				assert.Nil(t, genOpts.Stop, "Stop should be nil for empty slice")
-				assert.Nil(t, genOpts.Seed, "Seed should be nil")
+				assert.Nil(t, genOpts.Extension, "Extension should be nil without a seed")
 				assert.Nil(t, genOpts.ResponseFormat, "ResponseFormat should be nil for JSON=false")
 			},
 		},
@@ -448,12 +449,7 @@ func TestBuildOptions(t *testing.T) {
 					JSON: false,
 				},
 			},
-			validate: func(t *testing.T, options []interface{}) {
-				assert.Len(t, options, 1, "Should return exactly one GenerateOptions object")
-
-				genOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok, "Should return *GenerateOptions")
-
+			validate: func(t *testing.T, genOpts *common.GenerateRequest) {
 				// Verify all parameters are correctly translated
 				assert.NotNil(t, genOpts.Temperature, "Temperature should be set")
 				assert.Equal(t, 0.8, *genOpts.Temperature, "Temperature should be 0.8")
@@ -467,8 +463,9 @@ func TestBuildOptions(t *testing.T) {
 				assert.NotNil(t, genOpts.Stop, "Stop should be set")
 				assert.Equal(t, []string{"STOP", "END"}, genOpts.Stop, "Stop sequences should match")
 
-				assert.NotNil(t, genOpts.Seed, "Seed should be set")
-				assert.Equal(t, 42, *genOpts.Seed, "Seed should be 42")
+				if ext, ok := genOpts.Extension.(*Extension); assert.True(t, ok, "Extension should be *Extension") {
+					assert.Equal(t, common.IntPtr(42), ext.Seed, "Seed should be 42")
+				}
 
 				assert.Nil(t, genOpts.ResponseFormat, "ResponseFormat should be nil for JSON=false")
 			},
@@ -483,12 +480,7 @@ func TestBuildOptions(t *testing.T) {
 					JSON: true,
 				},
 			},
-			validate: func(t *testing.T, options []interface{}) {
-				assert.Len(t, options, 1, "Should return exactly one GenerateOptions object")
-
-				genOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok, "Should return *GenerateOptions")
-
+			validate: func(t *testing.T, genOpts *common.GenerateRequest) {
 				// verify JSON format is set
 				assert.NotNil(t, genOpts.ResponseFormat, "ResponseFormat should be set for JSON=true")
 				assert.Equal(t, "json_object", genOpts.ResponseFormat.Type, "ResponseFormat type should be json_object")
@@ -505,8 +497,7 @@ func TestBuildOptions(t *testing.T) {
 					ResponseSchema: `{"type":"object","properties":{"animal":{"type":"string"}}}`,
 				},
 			},
-			validate: func(t *testing.T, options []interface{}) {
-				genOpts := options[0].(*GenerateOptions)
+			validate: func(t *testing.T, genOpts *common.GenerateRequest) {
 				if assert.NotNil(t, genOpts.ResponseFormat, "ResponseFormat should be set when ResponseSchema is present") {
 					assert.Equal(t, "json_schema", genOpts.ResponseFormat.Type)
 					assert.Equal(t, "response", genOpts.ResponseFormat.Name)
@@ -523,8 +514,7 @@ func TestBuildOptions(t *testing.T) {
 					Thinking: "high",
 				},
 			},
-			validate: func(t *testing.T, options []interface{}) {
-				genOpts := options[0].(*GenerateOptions)
+			validate: func(t *testing.T, genOpts *common.GenerateRequest) {
 				// Cohere routes reasoning via model selection, not request params
 				assert.Equal(t, common.ThinkingOff, genOpts.Thinking,
 					"ThinkingLevel must not leak into Cohere options")
@@ -540,3 +530,61 @@ func TestBuildOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// listing lives on v1 even though chat uses v2
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "chat", r.URL.Query().Get("endpoint"))
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"models":[{"name":"command-r7b-12-2024","endpoints":["chat"]},{"name":"command-a-03-2025","endpoints":["chat"]}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Cohere.APIKey = "test-key"
+	cfg.Providers.Cohere.BaseUrl = server.URL + "/v2"
+
+	models, err := New().ListModels(context.Background(), cfg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"command-a-03-2025", "command-r7b-12-2024"}, models)
+	}
+}
+
+func TestProvider_Embed(t *testing.T) {
+	tests := []struct {
+		inputType common.EmbedInputType
+		want      string
+	}{
+		{common.EmbedClustering, "clustering"},
+		{common.EmbedDocument, "search_document"},
+		{common.EmbedQuery, "search_query"},
+		{"", "clustering"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v2/embed", r.URL.Path)
+				var req map[string]interface{}
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				assert.Equal(t, []interface{}{"a", "b"}, req["texts"])
+				assert.Equal(t, tt.want, req["input_type"])
+				assert.Equal(t, []interface{}{"float"}, req["embedding_types"])
+				_, _ = w.Write([]byte(`{"id":"e1","embeddings":{"float":[[0.1],[0.2]]},"meta":{"billed_units":{"input_tokens":4}}}`))
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{}
+			cfg.Providers.Cohere.APIKey = "test-key"
+			cfg.Providers.Cohere.BaseUrl = server.URL + "/v2"
+
+			result, err := New().Embed(context.Background(), cfg, "embed-v4.0", []string{"a", "b"}, tt.inputType)
+			if assert.NoError(t, err) {
+				assert.Equal(t, [][]float64{{0.1}, {0.2}}, result.Vectors)
+				assert.Equal(t, "embed-v4.0", result.Model)
+				assert.Equal(t, 4, result.Usage.PromptTokens)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package common
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 )
 
 // ProviderAdapter defines the interface that each LLM provider MUST implement
@@ -19,9 +21,9 @@ type ProviderAdapter interface {
 	RequiresAPIKey() bool
 
 	// BuildRequest creates a provider-specific request payload from messages and options
-	// options parameter contains provider-specific generation options
+	// req holds the common options and the provider's own extension
 	// returns the request payload that will be JSON marshaled and sent via HTTP
-	BuildRequest(messages []Message, modelName string, options interface{}) (interface{}, error)
+	BuildRequest(messages []Message, modelName string, req *GenerateRequest) (interface{}, error)
 
 	// ParseResponse parses a provider-specific HTTP response body into content and usage stats
 	// returns the generated content, token usage information, and any parsing errors
@@ -63,82 +65,173 @@ func NewAdapterClient(adapter Provider, apiKey, baseURL string, opts ...ClientOp
 
 // Generate implements the unified generation logic for all providers
 // centralizes all common functionality while using the adapter for provider-specific details
-// TODO? ...interface() pushes type checking to runtime; consider using a more structured approach
-func (c *AdapterClient) Generate(ctx context.Context, messages []Message, modelName string, options ...interface{}) (string, error) {
-	// combine all interface{} options into a single options object
-	processedOptions, err := c.processOptions(options)
-	if err != nil {
-		return "", err
+func (c *AdapterClient) Generate(ctx context.Context, messages []Message, modelName string, req *GenerateRequest) (*GenerateResult, error) {
+	if req == nil {
+		req = &GenerateRequest{}
+	}
+
+	// reject bad options before anything is sent
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", c.adapter.ProviderName(), err)
+	}
+
+	// fail before the request when the model can't take attached images
+	if err := checkImageSupport(c.adapter, messages, modelName); err != nil {
+		return nil, err
 	}
 
 	// use adapter to build provider-specific request
-	request, err := c.adapter.BuildRequest(messages, modelName, processedOptions, c.Logger)
+	request, err := c.adapter.BuildRequest(messages, modelName, req, c.Logger)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// HTTP request with common retry logic
-	response, err := c.executeRequest(ctx, request)
+	response, err := c.executeRequest(ctx, request, c.buildRequestURL(modelName, req))
 	if err != nil {
+		// a missing recording is reported as is; it is not a connection failure
+		var miss *CassetteMissError
+		if errors.As(err, &miss) {
+			return nil, miss
+		}
+
 		// allow adapter to provide better error messages for connection failures
-		return "", c.adapter.HandleConnectionError(err)
+		handled := c.adapter.HandleConnectionError(err)
+		var urlErr *url.Error
+		if ctx.Err() == nil && errors.As(err, &urlErr) {
+			return nil, &ConnectionError{Err: handled}
+		}
+		return nil, handled
 	}
 	defer response.Body.Close()
 
+	// streamed responses are consumed incrementally; errors still arrive as a plain body
+	if streamer, ok := c.streamingAdapter(req); ok && response.StatusCode == http.StatusOK {
+		return c.readStreamedResponse(response, streamer, req, modelName)
+	}
+
 	// read the response body
 	body, err := c.readResponseBody(response)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// handle errors; should be provider-specific error handling
 	if response.StatusCode != http.StatusOK {
-		return "", c.adapter.HandleError(response.StatusCode, body)
+		return nil, &APIError{StatusCode: response.StatusCode, Err: c.adapter.HandleError(response.StatusCode, body)}
 	}
 
 	// yse adapter to parse provider-specific response
 	content, usage, err := c.adapter.ParseResponse(body, c.Logger)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	result := &GenerateResult{
+		Content: content,
+		Usage:   usage,
+		Model:   modelName,
+	}
+	result.Thinking, _ = SplitThinking(content)
+	if parser, ok := c.adapter.(MetadataProvider); ok {
+		meta := parser.ParseMetadata(body)
+		result.FinishReason = meta.FinishReason
+		if meta.Model != "" {
+			result.Model = meta.Model
+		}
+	}
+
+	// requested tool calls go back to the caller; the content is not a final answer
+	calls, err := c.parseToolCalls(body, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) > 0 {
+		LogToolCalls(c.Logger, calls)
+		result.ToolCalls = calls
+		c.logSuccess(result)
+		return result, nil
 	}
 
 	// validate JSON format if requested
-	if err := c.validateJSONResponse(content, processedOptions); err != nil {
-		return "", err
+	if err := c.validateJSONResponse(content, req); err != nil {
+		return nil, err
 	}
 
 	// log results
-	c.logSuccess(content, usage)
+	c.logSuccess(result)
+
+	return result, nil
+}
 
-	return content, nil
+// streamingAdapter reports whether this request should use the streaming path:
+// streaming must be requested in the options and supported by the adapter.
+// tool calls are parsed from complete responses, so requests with tools never stream
+func (c *AdapterClient) streamingAdapter(req *GenerateRequest) (StreamingProvider, bool) {
+	if !req.Stream || len(req.Tools) > 0 {
+		return nil, false
+	}
+	streamer, ok := c.adapter.(StreamingProvider)
+	return streamer, ok
 }
 
-// processOptions handles the processed configuration object from providers
-// Providers convert functional options into a single configuration object before calling AdapterClient
-func (c *AdapterClient) processOptions(options []interface{}) (interface{}, error) {
-	// providers should pass exactly one processed configuration object
-	// multiple functional options are processed at the provider level, not here
-	if len(options) > 1 {
-		// indicates a provider implementation bug - they should consolidate options
-		c.Logger.Warn("Multiple options passed to AdapterClient - only first will be used", "count", len(options))
+// readStreamedResponse consumes a streamed body, forwarding chunks to the handler
+// and returning the accumulated result in the same shape as a buffered response
+func (c *AdapterClient) readStreamedResponse(response *http.Response, streamer StreamingProvider, req *GenerateRequest, modelName string) (*GenerateResult, error) {
+	LogStreamResponse(c.Logger, response.StatusCode)
+
+	streamed, err := ReadStream(response.Body, streamer, req.OnChunk, c.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s stream: %w", c.adapter.ProviderName(), err)
 	}
 
-	if len(options) > 0 {
-		return options[0], nil
+	// re-inline streamed thinking as a <think> tag, matching ParseResponse
+	content := streamed.Content
+	if streamed.Thinking != "" {
+		content = "<think>" + streamed.Thinking + "</think>\n" + content
+	}
+
+	if err := c.validateJSONResponse(streamed.Content, req); err != nil {
+		return nil, err
 	}
-	return nil, nil
+
+	result := &GenerateResult{
+		Content:      content,
+		Thinking:     streamed.Thinking,
+		Usage:        streamed.Usage,
+		FinishReason: streamed.FinishReason,
+		Model:        modelName,
+	}
+	if streamed.Model != "" {
+		result.Model = streamed.Model
+	}
+
+	c.logSuccess(result)
+
+	return result, nil
+}
+
+// parseToolCalls extracts tool calls when tools were sent with the request
+// and the adapter supports function calling
+func (c *AdapterClient) parseToolCalls(body []byte, req *GenerateRequest) ([]ToolCall, error) {
+	if len(req.Tools) == 0 {
+		return nil, nil
+	}
+	parser, ok := c.adapter.(ToolCallingProvider)
+	if !ok {
+		return nil, nil
+	}
+	return parser.ParseToolCalls(body, c.Logger)
 }
 
 // executeRequest handles the common HTTP request execution with retry logic
-func (c *AdapterClient) executeRequest(ctx context.Context, request interface{}) (*http.Response, error) {
+func (c *AdapterClient) executeRequest(ctx context.Context, request interface{}, url string) (*http.Response, error) {
 	// marshal request to JSON
 	jsonData, err := c.marshalRequest(request)
 	if err != nil {
 		return nil, err
 	}
 
-	// build URL - most providers use /chat/completions, but adapters can customize
-	url := c.buildRequestURL()
 	LogRequestExecution(c.Logger, url, c.MaxRetries)
 
 	// create executor function for retry logic
@@ -158,7 +251,7 @@ func (c *AdapterClient) executeRequest(ctx context.Context, request interface{})
 	}
 
 	// execute with retry logic
-	resp, err := ExecuteWithRetry(ctx, executor, c.MaxRetries, c.Logger)
+	resp, err := ExecuteWithRetryPolicy(ctx, executor, c.MaxRetries, c.Retry, c.Logger)
 	if err != nil {
 		LogRequestFailure(c.Logger, err, c.MaxRetries)
 		return nil, err
@@ -177,7 +270,13 @@ func (c *AdapterClient) marshalRequest(request interface{}) ([]byte, error) {
 }
 
 // buildRequestURL constructs the API endpoint URL
-func (c *AdapterClient) buildRequestURL() string {
+func (c *AdapterClient) buildRequestURL(modelName string, req *GenerateRequest) string {
+	// endpoints that name the model or the streaming method come from the adapter
+	if endpoint, ok := c.adapter.(EndpointProvider); ok {
+		_, stream := c.streamingAdapter(req)
+		return endpoint.RequestURL(c.BaseURL, modelName, stream)
+	}
+
 	// most providers use the standard /chat/completions endpoint
 	// adapters can override this in CustomizeRequest if needed
 	return BuildChatCompletionsURL(c.BaseURL)
@@ -204,42 +303,14 @@ func (c *AdapterClient) readResponseBody(response *http.Response) ([]byte, error
 }
 
 // logSuccess logs successful completion with token usage
-func (c *AdapterClient) logSuccess(content string, usage *Usage) {
-	if usage != nil {
-		LogTokenUsage(c.Logger, "", *usage) // ID can be empty for unified logging
+func (c *AdapterClient) logSuccess(result *GenerateResult) {
+	if result.Usage != nil {
+		LogTokenUsage(c.Logger, "", *result.Usage) // ID can be empty for unified logging
 	}
-	LogRequestCompletion(c.Logger, len(content))
+	LogRequestCompletion(c.Logger, len(result.Content))
 }
 
 // validateJSONResponse validates JSON format if structured output was requested
-func (c *AdapterClient) validateJSONResponse(content string, options interface{}) error {
-	// extract GenerateOptions from the provider-specific options
-	// this isn't ideal, but necessary to access the common validation logic
-	if options != nil {
-		// use reflection or type assertion to extract GenerateOptions
-		// for now, we'll try to get the embedded GenerateOptions field
-		if genOpts := c.extractGenerateOptions(options); genOpts != nil {
-			return ValidateJSONResponse(content, genOpts, c.Logger)
-		}
-	}
-	return nil
-}
-
-// extractGenerateOptions tries to extract the common GenerateOptions from provider-specific options
-func (c *AdapterClient) extractGenerateOptions(options interface{}) *GenerateOptions {
-	if options == nil {
-		return nil
-	}
-
-	// use type assertion to handle different provider option types
-	// each provider options struct embeds common.GenerateOptions
-	switch opts := options.(type) {
-	case interface{ GetGenerateOptions() *GenerateOptions }:
-		// if the options implement a getter method
-		return opts.GetGenerateOptions()
-	default:
-		// for now, return nil - individual providers can override validation if needed
-		// this is a limitation of the current architecture and could be improved
-		return nil
-	}
+func (c *AdapterClient) validateJSONResponse(content string, req *GenerateRequest) error {
+	return ValidateJSONResponse(content, &req.GenerateOptions, c.Logger)
 }
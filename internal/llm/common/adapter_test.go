@@ -26,9 +26,9 @@ func (m *MockProvider) CreateClient(cfg *config.Config, logger *slog.Logger) (LL
 }
 
 // mock the BuildOptions method
-func (m *MockProvider) BuildOptions(cfg *config.Config) []interface{} {
+func (m *MockProvider) BuildOptions(cfg *config.Config) *GenerateRequest {
 	args := m.Called(cfg)
-	return args.Get(0).([]interface{})
+	return args.Get(0).(*GenerateRequest)
 }
 
 // mock the RequiresAPIKey method
@@ -44,7 +44,7 @@ func (m *MockProvider) ProviderName() string {
 }
 
 // mock the BuildRequest method
-func (m *MockProvider) BuildRequest(messages []Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
+func (m *MockProvider) BuildRequest(messages []Message, modelName string, options *GenerateRequest, logger *slog.Logger) (interface{}, error) {
 	args := m.Called(messages, modelName, options, logger)
 	return args.Get(0), args.Error(1)
 }
@@ -112,11 +112,11 @@ func TestAdapterClient_Generate_Success(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
 	assert.NoError(t, err)
-	assert.Equal(t, "hello", result)
+	assert.Equal(t, "hello", result.Content)
 
 	// verify all mock expectations were met
 	mockProvider.AssertExpectations(t)
@@ -146,12 +146,12 @@ func TestAdapterClient_Generate_BuildRequest_Error(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -196,12 +196,17 @@ func TestAdapterClient_Generate_HTTP_Error(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
-
-	// assert results
-	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
-	assert.Empty(t, result)
+	result, err := client.Generate(ctx, messages, "test-model", nil)
+
+	// assert results; the provider's message is kept alongside the status code
+	assert.ErrorIs(t, err, expectedError)
+	assert.EqualError(t, err, expectedError.Error())
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	}
+	assert.True(t, ShouldFallback(err))
+	assert.Nil(t, result)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -244,12 +249,12 @@ func TestAdapterClient_Generate_ParseResponse_Error(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 
 	// confirm mock expectations
 	mockProvider.AssertExpectations(t)
@@ -288,12 +293,14 @@ func TestAdapterClient_Generate_Connection_Error(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
-	assert.Error(t, err)
-	assert.Equal(t, enhancedError, err)
-	assert.Empty(t, result)
+	assert.ErrorIs(t, err, enhancedError)
+	var connErr *ConnectionError
+	assert.ErrorAs(t, err, &connErr)
+	assert.True(t, ShouldFallback(err))
+	assert.Nil(t, result)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -331,12 +338,12 @@ func TestAdapterClient_Generate_CustomizeRequest_Error(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to customize request")
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -355,17 +362,17 @@ func TestAdapterClient_Generate_With_Options(t *testing.T) {
 	mockProvider := &MockProvider{}
 
 	// test options
-	testOptions := []interface{}{"option1", "option2"}
+	testOptions := NewGenerateRequest(WithTemperature(0.5), WithMaxTokens(100))
 
 	// configure mock expectations in call order
 	mockProvider.On("BuildRequest",
 		[]Message{{Role: "user", Content: "test message"}},
 		"test-model",
-		"option1",
+		testOptions,
 		mock.Anything).Return(map[string]interface{}{
 		"model":    "test-model",
 		"messages": []Message{{Role: "user", Content: "test message"}},
-		"options":  testOptions,
+		"options":  testOptions.GenerateOptions,
 	}, nil)
 
 	// ProviderName called multiple times (allow unlimited calls for now)
@@ -381,11 +388,11 @@ func TestAdapterClient_Generate_With_Options(t *testing.T) {
 	// execute Generate with options
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model", testOptions...)
+	result, err := client.Generate(ctx, messages, "test-model", testOptions)
 
 	// assert results
 	assert.NoError(t, err)
-	assert.Equal(t, "response with options", result)
+	assert.Equal(t, "response with options", result.Content)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -421,7 +428,7 @@ func TestAdapterClient_Generate_Empty_Messages(t *testing.T) {
 
 	// exec client.Generate with empty messages
 	ctx := context.Background()
-	result, err := client.Generate(ctx, []Message{}, "test-model")
+	result, err := client.Generate(ctx, []Message{}, "test-model", nil)
 
 	// should still call BuildRequest even with empty messages
 	// the provider can decide how to handle empty messages
@@ -478,12 +485,12 @@ func TestAdapterClient_Generate_ContextCancellation(t *testing.T) {
 
 	// exec client.Generate with cancelled context
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results–should get context cancellation error
 	assert.Error(t, err)
 	assert.Equal(t, context.Canceled, err)
-	assert.Empty(t, result)
+	assert.Nil(t, result)
 
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
@@ -527,11 +534,11 @@ func TestAdapterClient_Generate_AuthorizationHeader(t *testing.T) {
 	// execute Generate
 	ctx := context.Background()
 	messages := []Message{{Role: "user", Content: "test message"}}
-	result, err := client.Generate(ctx, messages, "test-model")
+	result, err := client.Generate(ctx, messages, "test-model", nil)
 
 	// assert results
 	assert.NoError(t, err)
-	assert.Equal(t, "authorized response", result)
+	assert.Equal(t, "authorized response", result.Content)
 
 	// verify Authorization header was set correctly
 	assert.NotNil(t, capturedRequest, "Request should have been captured")
@@ -545,3 +552,37 @@ func TestAdapterClient_Generate_AuthorizationHeader(t *testing.T) {
 	// verify mock expectations
 	mockProvider.AssertExpectations(t)
 }
+
+// endpointProvider overrides the request URL through EndpointProvider
+type endpointProvider struct {
+	MockProvider
+}
+
+func (e *endpointProvider) RequestURL(baseURL, modelName string, stream bool) string {
+	return baseURL + "/models/" + modelName + ":generate"
+}
+
+func TestAdapterClient_Generate_EndpointProvider(t *testing.T) {
+	var requestPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"content": "routed"}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := &endpointProvider{}
+	provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+	provider.On("ParseResponse", mock.AnythingOfType("[]uint8"), mock.Anything).Return("routed", (*Usage)(nil), nil)
+
+	client := NewAdapterClient(provider, "test-key", server.URL)
+	result, err := client.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, "test-model", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "routed", result.Content)
+	assert.Equal(t, "/models/test-model:generate", requestPath)
+	provider.AssertExpectations(t)
+}
@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// defaultHTTPTimeout bounds a request when no timeout is configured; see
+// NewHTTPClient for what a provider's configured timeout bounds
+const defaultHTTPTimeout = 60 * time.Second
+
 // BaseClient contains common client configuration shared across all LLM providers
 type BaseClient struct {
 	APIKey     string
@@ -13,6 +17,10 @@ type BaseClient struct {
 	BaseURL    string
 	Logger     *slog.Logger
 	MaxRetries int
+	Retry      RetryPolicy
+
+	cassetteMode string // "record" or "replay"; see WithCassette
+	cassetteDir  string
 }
 
 // ClientOption configures a BaseClient using the functional options pattern
@@ -32,6 +40,13 @@ func WithMaxRetries(retries int) ClientOption {
 	}
 }
 
+// WithRetryPolicy sets how long any client waits between retries
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *BaseClient) {
+		c.Retry = policy
+	}
+}
+
 // WithHTTPClient sets the HTTP client for any client
 func WithHTTPClient(client *http.Client) ClientOption {
 	return func(c *BaseClient) {
@@ -50,14 +65,20 @@ func WithBaseURL(url string) ClientOption {
 func NewBaseClient(apiKey, defaultBaseURL string, opts ...ClientOption) *BaseClient {
 	c := &BaseClient{
 		APIKey:     apiKey,
-		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+		HTTPClient: &http.Client{Timeout: defaultHTTPTimeout},
 		BaseURL:    defaultBaseURL,
 		MaxRetries: 2,
+		Retry:      DefaultRetryPolicy(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	// recording or replaying wraps whichever HTTP client was chosen
+	if c.cassetteMode != "" {
+		c.HTTPClient = withCassetteTransport(c.HTTPClient, c.cassetteMode, c.cassetteDir)
+	}
+
 	return c
 }
@@ -0,0 +1,72 @@
+package common
+
+// Feature is a request feature a model may not honor
+type Feature string
+
+const (
+	FeatureThinking Feature = "thinking" // --thinking: native reasoning
+	FeatureSchema   Feature = "schema"   // --schema: structured output against a JSON schema
+	FeatureJSON     Feature = "json"     // --json: JSON mode
+	FeatureVision   Feature = "vision"   // --image: image inputs
+	FeatureTools    Feature = "tools"    // --tool: function calling
+	FeatureSeed     Feature = "seed"     // --seed: deterministic sampling
+)
+
+// Emulated reports whether the feature still takes effect without the
+// model's support: --json always adds a system-prompt instruction and cleans
+// the output, so only the guarantee of a native JSON mode is lost
+func (f Feature) Emulated() bool {
+	return f == FeatureJSON
+}
+
+// Capabilities are the features a model honors. a feature it doesn't honor
+// is left out of the request rather than rejected
+type Capabilities struct {
+	Thinking bool
+	Schema   bool
+	JSON     bool // a native JSON mode; see Feature.Emulated
+	Vision   bool
+	Tools    bool
+	Seed     bool
+
+	// ContextWindow is the tokens the model accepts, input and output
+	// together; 0 when unknown
+	ContextWindow int
+}
+
+// Supports reports whether the model honors a feature
+func (c Capabilities) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureThinking:
+		return c.Thinking
+	case FeatureSchema:
+		return c.Schema
+	case FeatureJSON:
+		return c.JSON
+	case FeatureVision:
+		return c.Vision
+	case FeatureTools:
+		return c.Tools
+	case FeatureSeed:
+		return c.Seed
+	}
+	return false
+}
+
+// ModelFamily is a group of a provider's models that share capabilities
+type ModelFamily struct {
+	Name    string // shown by 'slop providers describe', e.g. "o-series"
+	Example string // a model id in the family; empty when every model is alike
+}
+
+// CapabilityProvider is implemented by providers that know what their models
+// honor. the CLI warns before sending a request that would drop a feature,
+// and 'slop providers describe' lists the families
+type CapabilityProvider interface {
+	// Capabilities reports the features the model honors; ContextWindow is
+	// left for the caller to fill from the model data
+	Capabilities(modelName string) Capabilities
+
+	// ModelFamilies lists the provider's model families, newest first
+	ModelFamilies() []ModelFamily
+}
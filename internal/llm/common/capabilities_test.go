@@ -0,0 +1,23 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities_Supports(t *testing.T) {
+	caps := Capabilities{Thinking: true, Schema: true, Tools: true}
+
+	for feature, want := range map[Feature]bool{
+		FeatureThinking:  true,
+		FeatureSchema:    true,
+		FeatureJSON:      false,
+		FeatureVision:    false,
+		FeatureTools:     true,
+		FeatureSeed:      false,
+		Feature("audio"): false,
+	} {
+		assert.Equal(t, want, caps.Supports(feature), string(feature))
+	}
+}
@@ -0,0 +1,231 @@
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// redactedHeaders carry credentials, so they are never written to a cassette
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+	"Api-Key":             true,
+	"X-Goog-Api-Key":      true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactedParams carry credentials in the URL, as Gemini's key does
+var redactedParams = []string{"key", "api_key", "api-key"}
+
+// CassetteMissError is returned when replaying a request that was never recorded
+type CassetteMissError struct {
+	Method string
+	URL    string // with credentials redacted
+	Key    string
+	Dir    string
+}
+
+func (e *CassetteMissError) Error() string {
+	return fmt.Sprintf("no recorded response for %s %s (request %s) in %s; record it with --record %s",
+		e.Method, e.URL, e.Key[:12], e.Dir, e.Dir)
+}
+
+// cassette is one recorded exchange, stored as <dir>/<request key>.json
+type cassette struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// WithCassette records every request and response to dir ("record"), or
+// answers requests from dir without the network ("replay"). an empty mode
+// leaves the client as it is
+func WithCassette(mode, dir string) ClientOption {
+	return func(c *BaseClient) {
+		c.cassetteMode = mode
+		c.cassetteDir = dir
+	}
+}
+
+// withCassetteTransport wraps the client's transport for record or replay,
+// copying the client so one shared with other code is left alone
+func withCassetteTransport(client *http.Client, mode, dir string) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &cassetteTransport{replay: mode == "replay", dir: dir, next: next}
+	return &wrapped
+}
+
+// cassetteTransport records exchanges to disk or replays them from it
+type cassetteTransport struct {
+	replay bool
+	dir    string
+	next   http.RoundTripper
+}
+
+// RoundTrip answers the request from its recording when replaying; otherwise
+// it sends the request and records the exchange. a recorded response is read
+// in full before it is returned, so streamed responses arrive all at once
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	key := cassetteKey(req.Method, req.URL, body)
+	path := filepath.Join(t.dir, key+".json")
+	if t.replay {
+		return replayCassette(req, path, key, t.dir)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	recorded := cassette{
+		Request: recordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+			Body:    string(body),
+		},
+		Response: recordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    redactHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+	if err := writeCassette(path, recorded); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// replayCassette builds the response recorded at path
+func replayCassette(req *http.Request, path, key, dir string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &CassetteMissError{Method: req.Method, URL: redactURL(req.URL), Key: key, Dir: dir}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var recorded cassette
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	header := make(http.Header, len(recorded.Response.Headers))
+	for name, value := range recorded.Response.Headers {
+		header.Set(name, value)
+	}
+	status := recorded.Response.StatusCode
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Response.Body)),
+		ContentLength: int64(len(recorded.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// writeCassette saves an exchange, replacing an earlier recording of the
+// same request, such as a retried 429
+func writeCassette(path string, recorded cassette) error {
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// cassetteKey identifies a request by method, URL without credentials and
+// body, so a recording replays whatever key the run is given
+func cassetteKey(method string, u *url.URL, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + redactURL(u) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redactURL returns the URL with credential query parameters blanked
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	changed := false
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
+
+// redactHeaders flattens headers for a cassette, leaving out credentials
+func redactHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			flat[name] = "REDACTED"
+			continue
+		}
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
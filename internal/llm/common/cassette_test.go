@@ -0,0 +1,95 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// cassetteProvider is a mock provider answering one request, as the
+// adapter tests set it up
+func cassetteProvider(content string) *MockProvider {
+	provider := &MockProvider{}
+	provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).
+		Return(map[string]interface{}{"model": "test-model", "prompt": content}, nil)
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+	provider.On("ParseResponse", mock.AnythingOfType("[]uint8"), mock.Anything).
+		Return("hello", &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, nil).Maybe()
+	return provider
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":"hello"}`))
+	}))
+
+	messages := []Message{{Role: "user", Content: "test message"}}
+	recorder := NewAdapterClient(cassetteProvider("hi"), "sk-secret", server.URL+"/v1?key=google-secret", WithCassette("record", dir))
+	result, err := recorder.Generate(context.Background(), messages, "test-model", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result.Content)
+	assert.Equal(t, 1, hits)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-secret")
+	assert.NotContains(t, string(data), "google-secret")
+	assert.Contains(t, string(data), `"status_code": 200`)
+
+	// replay answers offline, whatever key the run has
+	server.Close()
+	player := NewAdapterClient(cassetteProvider("hi"), "replay", server.URL+"/v1?key=other", WithCassette("replay", dir))
+	result, err = player.Generate(context.Background(), messages, "test-model", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result.Content)
+
+	// a request that was never recorded fails without retries or fallback
+	player = NewAdapterClient(cassetteProvider("something else"), "replay", server.URL+"/v1", WithCassette("replay", dir), WithMaxRetries(3))
+	_, err = player.Generate(context.Background(), messages, "test-model", nil)
+	var miss *CassetteMissError
+	require.ErrorAs(t, err, &miss)
+	assert.Contains(t, err.Error(), "no recorded response for POST")
+	assert.Contains(t, err.Error(), "--record "+dir)
+	assert.False(t, ShouldFallback(err))
+}
+
+func TestCassette_ReplayMissNotRetried(t *testing.T) {
+	attempts := 0
+	_, err := ExecuteWithRetryPolicy(context.Background(), func(ctx context.Context) (*http.Response, error) {
+		attempts++
+		return nil, &CassetteMissError{Method: "POST", URL: "http://x", Key: "0123456789abcdef", Dir: "d"}
+	}, 3, DefaultRetryPolicy(), nil)
+	var miss *CassetteMissError
+	assert.True(t, errors.As(err, &miss))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestCassetteKey(t *testing.T) {
+	post := func(url string, body string) string {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		return cassetteKey(req.Method, req.URL, []byte(body))
+	}
+
+	base := post("https://api.example.test/v1/chat?key=one", `{"a":1}`)
+	assert.Equal(t, base, post("https://api.example.test/v1/chat?key=two", `{"a":1}`), "credentials don't change the key")
+	assert.NotEqual(t, base, post("https://api.example.test/v1/chat?key=one", `{"a":2}`))
+	assert.NotEqual(t, base, post("https://api.example.test/v2/chat?key=one", `{"a":1}`))
+}
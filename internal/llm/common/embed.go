@@ -0,0 +1,81 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/chriscorrea/slop/internal/config"
+)
+
+// ErrEmbeddingUnsupported is returned for providers that cannot embed text
+var ErrEmbeddingUnsupported = errors.New("embeddings are not supported")
+
+// EmbedInputType says what embedded text will be used for. most providers
+// ignore it; Cohere tunes its vectors to the use
+type EmbedInputType string
+
+const (
+	// EmbedClustering is text compared with other text, as by slop embed
+	EmbedClustering EmbedInputType = "clustering"
+	// EmbedDocument is text indexed for retrieval
+	EmbedDocument EmbedInputType = "document"
+	// EmbedQuery is a query searched against indexed documents
+	EmbedQuery EmbedInputType = "query"
+)
+
+// Embedder is implemented by providers that can turn text into vectors, such
+// as OpenAI's POST /v1/embeddings
+type Embedder interface {
+	// Embed returns one vector per input, in input order
+	Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, inputType EmbedInputType) (*EmbedResult, error)
+}
+
+// EmbedResult holds the vectors of an embedding request
+type EmbedResult struct {
+	Vectors [][]float64
+	Model   string
+	Usage   *Usage
+}
+
+// PostJSON sends payload as a JSON POST to url with the given headers and
+// returns the body. client and errors are as for FetchModels
+func PostJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}, handleError func(int, []byte) error) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(ctx, client, req, headers, handleError)
+}
+
+// ParseEmbeddings reads the OpenAI embeddings shape shared by Mistral and
+// Together: {"data":[{"index":0,"embedding":[...]}],"model":...,"usage":...}
+func ParseEmbeddings(body []byte) (*EmbedResult, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Model string `json:"model"`
+		Usage *Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	}
+
+	sort.SliceStable(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	result := &EmbedResult{Vectors: make([][]float64, len(resp.Data)), Model: resp.Model, Usage: resp.Usage}
+	for i, d := range resp.Data {
+		result.Vectors[i] = d.Embedding
+	}
+	return result, nil
+}
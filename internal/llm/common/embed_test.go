@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "yes", r.Header.Get("X-Test"))
+
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req["input"] == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"bad input"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	handleError := func(status int, body []byte) error {
+		return fmt.Errorf("status %d: %s", status, body)
+	}
+	headers := map[string]string{"X-Test": "yes"}
+
+	body, err := PostJSON(context.Background(), http.DefaultClient, server.URL, headers, map[string]string{"input": "good"}, handleError)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ok":true}`, string(body))
+
+	_, err = PostJSON(context.Background(), http.DefaultClient, server.URL, headers, map[string]string{"input": "bad"}, handleError)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.ErrorContains(t, err, "bad input")
+}
+
+func TestParseEmbeddings(t *testing.T) {
+	result, err := ParseEmbeddings([]byte(`{
+		"data": [{"index": 1, "embedding": [0.3]}, {"index": 0, "embedding": [0.1]}],
+		"model": "text-embedding-3-small",
+		"usage": {"prompt_tokens": 4, "total_tokens": 4}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1}, {0.3}}, result.Vectors)
+	assert.Equal(t, "text-embedding-3-small", result.Model)
+	assert.Equal(t, 4, result.Usage.PromptTokens)
+
+	_, err = ParseEmbeddings([]byte(`not json`))
+	assert.ErrorContains(t, err, "failed to parse embeddings")
+}
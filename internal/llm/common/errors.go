@@ -0,0 +1,61 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrModelNotFound is wrapped by errors for a model the provider doesn't
+// have, such as one an Ollama server has not pulled, so callers can offer to
+// pull it
+var ErrModelNotFound = errors.New("model not found")
+
+// APIError is a non-200 response from a provider. the message comes from the
+// provider's HandleError; the status code is kept so callers can classify it
+type APIError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.StatusCode)
+	}
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// ConnectionError is a request that never received a response, such as a
+// refused connection or a DNS failure
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// ShouldFallback reports whether err means the provider is unavailable rather
+// than the request being bad, so another model may be able to answer:
+// connection failures, 5xx, 429 and authentication errors
+func ShouldFallback(err error) bool {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return ShouldRetry(apiErr.StatusCode) ||
+			apiErr.StatusCode == http.StatusUnauthorized ||
+			apiErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldFallback(t *testing.T) {
+	cause := errors.New("provider message")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", &ConnectionError{Err: cause}, true},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway, Err: cause}, true},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests, Err: cause}, true},
+		{"bad api key", &APIError{StatusCode: http.StatusUnauthorized, Err: cause}, true},
+		{"forbidden", &APIError{StatusCode: http.StatusForbidden, Err: cause}, true},
+		{"wrapped", fmt.Errorf("failed to generate response: %w", &APIError{StatusCode: http.StatusServiceUnavailable, Err: cause}), true},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest, Err: cause}, false},
+		{"unknown model", &APIError{StatusCode: http.StatusNotFound, Err: cause}, false},
+		{"cancelled", context.Canceled, false},
+		{"plain error", cause, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ShouldFallback(tt.err))
+		})
+	}
+}
+
+func TestAPIError_Message(t *testing.T) {
+	cause := errors.New("Mistral API error: service overloaded")
+	err := &APIError{StatusCode: http.StatusServiceUnavailable, Err: cause}
+	assert.EqualError(t, err, cause.Error())
+	assert.ErrorIs(t, err, cause)
+
+	// a provider that returns no message still reports the status
+	assert.EqualError(t, &APIError{StatusCode: http.StatusServiceUnavailable}, "Service Unavailable")
+}
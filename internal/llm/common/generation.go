@@ -1,6 +1,9 @@
 package common
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // GenerateOptions contains near-universal generation parameters
 // this follows the interface segregation principle; providers only see relevant options
@@ -21,6 +24,10 @@ type GenerateOptions struct {
 	// Thinking / reasoning effort — translated by each provider adapter into
 	// its upstream native parameter
 	Thinking ThinkingLevel
+
+	// Stream asks the provider to send tokens incrementally; AdapterClient
+	// only honors it for providers implementing StreamingProvider
+	Stream bool
 }
 
 // ThinkingLevel expresses how much reasoning the model should do. Adapters
@@ -52,10 +59,18 @@ func ParseThinkingLevel(s string) (ThinkingLevel, error) {
 	}
 }
 
-// ToolConfig represents a tool/function definition for function calling
+// ToolConfig represents a tool/function definition for function calling.
+// it marshals to the OpenAI-compatible wire shape; other adapters translate it
 type ToolConfig struct {
-	Type     string      `json:"type"`     // e.g., "function"
-	Function interface{} `json:"function"` // Function definition
+	Type     string             `json:"type"`     // e.g., "function"
+	Function FunctionDefinition `json:"function"` // Function definition
+}
+
+// FunctionDefinition describes a callable function and its JSON-schema arguments
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
 }
 
 // GenerateOption configures generation parameters using the functional options pattern
@@ -149,6 +164,14 @@ func WithThinking(level ThinkingLevel) GenerateOption {
 	}
 }
 
+// WithStream requests incremental (streamed) delivery of the response
+// supported by: providers implementing StreamingProvider
+func WithStream() GenerateOption {
+	return func(c *GenerateOptions) {
+		c.Stream = true
+	}
+}
+
 // Function calling options
 
 // WithTools sets available tools/functions for function calling
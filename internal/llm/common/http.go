@@ -28,3 +28,13 @@ func CreateJSONRequest(ctx context.Context, url, apiKey string, jsonData []byte)
 func BuildChatCompletionsURL(baseURL string) string {
 	return fmt.Sprintf("%s/chat/completions", strings.TrimSuffix(baseURL, "/"))
 }
+
+// BuildModelsURL returns the model-listing endpoint used by OpenAI-compatible providers
+func BuildModelsURL(baseURL string) string {
+	return fmt.Sprintf("%s/models", strings.TrimSuffix(baseURL, "/"))
+}
+
+// BuildEmbeddingsURL returns the embeddings endpoint used by OpenAI-compatible providers
+func BuildEmbeddingsURL(baseURL string) string {
+	return fmt.Sprintf("%s/embeddings", strings.TrimSuffix(baseURL, "/"))
+}
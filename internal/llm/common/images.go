@@ -0,0 +1,132 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxImageBytes caps a single image; provider limits sit around 5-20MB
+const maxImageBytes = 20 * 1024 * 1024
+
+// imageMediaTypes maps the image extensions slop recognizes to media types
+var imageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// Image is an image content part attached to a message
+type Image struct {
+	Path      string // source file, for logging and display
+	MediaType string // e.g. "image/png"
+	Data      []byte
+}
+
+// Base64 returns the image data base64-encoded
+func (i Image) Base64() string {
+	return base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// DataURL returns the image as a data: URL
+func (i Image) DataURL() string {
+	return "data:" + i.MediaType + ";base64," + i.Base64()
+}
+
+// VisionProvider is implemented by providers that accept image inputs.
+// AdapterClient rejects image messages for providers and models without support
+type VisionProvider interface {
+	// SupportsImages reports whether the model accepts image content parts
+	SupportsImages(modelName string) bool
+}
+
+// IsImageFile reports whether a path has a supported image extension
+func IsImageFile(path string) bool {
+	_, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// LoadImage reads an image file into a content part
+func LoadImage(path string) (Image, error) {
+	mediaType, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return Image{}, fmt.Errorf("unsupported image type %q (supported: png, jpg, jpeg, gif, webp)", filepath.Ext(path))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image %q: %w", path, err)
+	}
+	if info.Size() > maxImageBytes {
+		return Image{}, fmt.Errorf("image %q is too large (%d bytes, max %d)", path, info.Size(), maxImageBytes)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image %q: %w", path, err)
+	}
+	return Image{Path: path, MediaType: mediaType, Data: data}, nil
+}
+
+// HasImages reports whether any message carries image content parts
+func HasImages(messages []Message) bool {
+	for _, msg := range messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImageSupport returns a clear error when messages carry images the
+// provider or model cannot accept
+func checkImageSupport(provider Provider, messages []Message, modelName string) error {
+	if !HasImages(messages) {
+		return nil
+	}
+	if vision, ok := provider.(VisionProvider); ok && vision.SupportsImages(modelName) {
+		return nil
+	}
+	return fmt.Errorf("model %q on provider %s does not accept image inputs; choose a vision-capable model", modelName, provider.ProviderName())
+}
+
+// ContentPart is an OpenAI-compatible content part: a "text" part sets Text,
+// an "image_url" part sets ImageURL
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL; slop always inlines a data: URL
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON encodes the message in the OpenAI-compatible wire shape. a
+// message with images sends its content as a list of parts instead of a string
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if len(m.Images) == 0 {
+		return json.Marshal(plain(m))
+	}
+
+	parts := make([]ContentPart, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, ContentPart{Type: "text", Text: m.Content})
+	}
+	for _, image := range m.Images {
+		parts = append(parts, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: image.DataURL()}})
+	}
+
+	// the outer Content field shadows the embedded string field
+	return json.Marshal(struct {
+		plain
+		Content []ContentPart `json:"content"`
+	}{plain: plain(m), Content: parts})
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// visionMockProvider adds VisionProvider support to MockProvider
+type visionMockProvider struct {
+	MockProvider
+	supported bool
+}
+
+func (m *visionMockProvider) SupportsImages(modelName string) bool {
+	return m.supported
+}
+
+func TestIsImageFile(t *testing.T) {
+	assert.True(t, IsImageFile("diagram.png"))
+	assert.True(t, IsImageFile("/tmp/Screenshot.JPEG"))
+	assert.True(t, IsImageFile("photo.webp"))
+	assert.False(t, IsImageFile("notes.txt"))
+	assert.False(t, IsImageFile("image.svg"))
+}
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "windmill.png")
+	require.NoError(t, os.WriteFile(path, []byte("\x89PNG"), 0644))
+
+	image, err := LoadImage(path)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", image.MediaType)
+	assert.Equal(t, "iVBORw==", image.Base64())
+	assert.Equal(t, "data:image/png;base64,iVBORw==", image.DataURL())
+
+	_, err = LoadImage(filepath.Join(dir, "notes.txt"))
+	assert.ErrorContains(t, err, "unsupported image type")
+
+	_, err = LoadImage(filepath.Join(dir, "missing.png"))
+	assert.ErrorContains(t, err, "failed to read image")
+}
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	// plain messages keep the string content shape
+	encoded, err := json.Marshal(Message{Role: "user", Content: "hello"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":"hello"}`, string(encoded))
+
+	// images switch the content to a list of parts
+	msg := Message{
+		Role:    "user",
+		Content: "What does this show?",
+		Images:  []Image{{MediaType: "image/png", Data: []byte("\x89PNG")}},
+	}
+	encoded, err = json.Marshal(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":[
+		{"type":"text","text":"What does this show?"},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw=="}}
+	]}`, string(encoded))
+}
+
+func TestCheckImageSupport(t *testing.T) {
+	text := []Message{{Role: "user", Content: "hello"}}
+	withImage := []Message{{Role: "user", Content: "look", Images: []Image{{MediaType: "image/png"}}}}
+
+	plain := &MockProvider{}
+	plain.On("ProviderName").Return("plain")
+
+	// text-only requests never need vision support
+	assert.NoError(t, checkImageSupport(plain, text, "text-model"))
+
+	err := checkImageSupport(plain, withImage, "text-model")
+	assert.ErrorContains(t, err, `model "text-model" on provider plain does not accept image inputs`)
+
+	vision := &visionMockProvider{supported: true}
+	assert.NoError(t, checkImageSupport(vision, withImage, "vision-model"))
+
+	blind := &visionMockProvider{supported: false}
+	blind.On("ProviderName").Return("blind")
+	assert.ErrorContains(t, checkImageSupport(blind, withImage, "old-model"), "does not accept image inputs")
+}
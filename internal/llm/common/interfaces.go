@@ -8,21 +8,30 @@ import (
 	"github.com/chriscorrea/slop/internal/config"
 )
 
-// LLM is the client interface; all provider clients must implement this
+// LLM is the client interface; all provider clients must implement this.
+// req may be nil for the provider's defaults; it is validated before any
+// request is sent
 type LLM interface {
-	Generate(ctx context.Context, messages []Message, modelName string, options ...interface{}) (string, error)
+	Generate(ctx context.Context, messages []Message, modelName string, req *GenerateRequest) (*GenerateResult, error)
 }
 
 // Provider is the unified interface that every provider must implement
 // this interface combines factory & adapter roles into a single contract
 type Provider interface {
 	CreateClient(cfg *config.Config, logger *slog.Logger) (LLM, error)
-	BuildOptions(cfg *config.Config) []interface{}
+	BuildOptions(cfg *config.Config) *GenerateRequest
 	RequiresAPIKey() bool
 	ProviderName() string
-	BuildRequest(messages []Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error)
+	BuildRequest(messages []Message, modelName string, req *GenerateRequest, logger *slog.Logger) (interface{}, error)
 	ParseResponse(body []byte, logger *slog.Logger) (content string, usage *Usage, err error)
 	HandleError(statusCode int, body []byte) error
 	CustomizeRequest(req *http.Request) error
 	HandleConnectionError(err error) error
 }
+
+// EndpointProvider is implemented by providers whose endpoint depends on the
+// model or on streaming, such as Gemini's models/{model}:generateContent.
+// AdapterClient posts to /chat/completions for every other provider
+type EndpointProvider interface {
+	RequestURL(baseURL, modelName string, stream bool) string
+}
@@ -42,6 +42,15 @@ func LogHTTPResponse(logger *slog.Logger, statusCode int, bodyLength int) {
 		"body_length", bodyLength)
 }
 
+// LogStreamResponse logs the start of a streamed HTTP response
+func LogStreamResponse(logger *slog.Logger, statusCode int) {
+	if logger == nil {
+		return
+	}
+	logger.Debug("Receiving streamed API response",
+		"status_code", statusCode)
+}
+
 // LogRawResponse logs the raw API response body for debugging
 func LogRawResponse(logger *slog.Logger, body string, statusCode int) {
 	if logger == nil {
@@ -102,3 +111,17 @@ func LogJSONUnmarshalError(logger *slog.Logger, err error, responseBody string)
 		"error", err,
 		"response_body", responseBody)
 }
+
+// LogToolCalls logs the tool calls requested by a response
+func LogToolCalls(logger *slog.Logger, calls []ToolCall) {
+	if logger == nil {
+		return
+	}
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		names = append(names, call.Function.Name)
+	}
+	logger.Debug("Model requested tool calls",
+		"count", len(calls),
+		"tools", names)
+}
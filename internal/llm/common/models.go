@@ -0,0 +1,101 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/chriscorrea/slop/internal/config"
+)
+
+// ErrModelListingUnsupported is returned for providers that cannot list models
+var ErrModelListingUnsupported = errors.New("model listing is not supported")
+
+// ModelLister is implemented by providers that can list the models available
+// to the configured account, such as OpenAI's GET /v1/models
+type ModelLister interface {
+	// ListModels returns the model names the provider currently serves
+	ListModels(ctx context.Context, cfg *config.Config) ([]string, error)
+}
+
+// FetchModels sends a GET to a model-listing url with the given headers and
+// returns the body. client comes from NewAPIClient. a non-200 status becomes
+// an APIError built by handleError, and a failed connection becomes a
+// ConnectionError
+func FetchModels(ctx context.Context, client *http.Client, url string, headers map[string]string, handleError func(int, []byte) error) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return doJSON(ctx, client, req, headers, handleError)
+}
+
+// doJSON sets the headers on req, sends it with client and returns the body
+// of a 200 response. other statuses become an APIError built by handleError, and a
+// failed connection becomes a ConnectionError
+func doJSON(ctx context.Context, client *http.Client, req *http.Request, headers map[string]string, handleError func(int, []byte) error) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Err: handleError(resp.StatusCode, body)}
+	}
+	return body, nil
+}
+
+// ParseModelIDs extracts sorted, de-duplicated model names from the common
+// listing shapes: {"data":[{"id":...}]} (OpenAI and compatibles), a bare
+// [{"id":...}] array (Together) and {"models":[{"name":...}]} (Ollama, Cohere)
+func ParseModelIDs(body []byte) ([]string, error) {
+	type entry struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var entries []entry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		var listing struct {
+			Data   []entry `json:"data"`
+			Models []entry `json:"models"`
+		}
+		if err := json.Unmarshal(body, &listing); err != nil {
+			return nil, fmt.Errorf("failed to parse model list: %w", err)
+		}
+		entries = append(listing.Data, listing.Models...)
+	}
+
+	seen := make(map[string]bool)
+	models := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.ID
+		if name == "" {
+			name = e.Name
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		models = append(models, name)
+	}
+	sort.Strings(models)
+	return models, nil
+}
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"bad key"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"b"},{"id":"a"}]}`))
+	}))
+	defer server.Close()
+
+	handleError := func(status int, body []byte) error {
+		return fmt.Errorf("status %d: %s", status, body)
+	}
+
+	body, err := FetchModels(context.Background(), http.DefaultClient, server.URL, map[string]string{"Authorization": "Bearer good"}, handleError)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":[{"id":"b"},{"id":"a"}]}`, string(body))
+
+	_, err = FetchModels(context.Background(), http.DefaultClient, server.URL, map[string]string{"Authorization": "Bearer bad"}, handleError)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.ErrorContains(t, err, "bad key")
+
+	server.Close()
+	_, err = FetchModels(context.Background(), http.DefaultClient, server.URL, nil, handleError)
+	var connErr *ConnectionError
+	assert.ErrorAs(t, err, &connErr)
+}
+
+func TestParseModelIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"openai data", `{"object":"list","data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"},{"id":"gpt-4o"}]}`, []string{"gpt-4o", "gpt-4o-mini"}},
+		{"bare array", `[{"id":"meta-llama/Llama-3.3-70B"},{"id":"Qwen/Qwen3"}]`, []string{"Qwen/Qwen3", "meta-llama/Llama-3.3-70B"}},
+		{"ollama tags", `{"models":[{"name":"llama3.2:latest","model":"llama3.2:latest"},{"name":"gemma3:4b"}]}`, []string{"gemma3:4b", "llama3.2:latest"}},
+		{"empty", `{"data":[]}`, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models, err := ParseModelIDs([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, models)
+		})
+	}
+
+	_, err := ParseModelIDs([]byte(`not json`))
+	assert.ErrorContains(t, err, "failed to parse model list")
+}
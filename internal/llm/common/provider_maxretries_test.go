@@ -3,10 +3,12 @@ package common_test
 import (
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/chriscorrea/slop/internal/config"
 	"github.com/chriscorrea/slop/internal/llm/anthropic"
 	"github.com/chriscorrea/slop/internal/llm/cohere"
+	"github.com/chriscorrea/slop/internal/llm/common"
 	"github.com/chriscorrea/slop/internal/llm/groq"
 	"github.com/chriscorrea/slop/internal/llm/mistral"
 	"github.com/chriscorrea/slop/internal/llm/ollama"
@@ -149,3 +151,33 @@ func TestProviderSpecificMaxRetries(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderRetryPolicy(t *testing.T) {
+	honor := false
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Providers.Groq.APIKey = "test-key"
+	cfg.Providers.Mistral.APIKey = "test-key"
+	cfg.Providers.Groq.Retry = config.Retry{BaseDelay: "1s", HonorHeaders: &honor}
+
+	groqClient, err := groq.New().CreateClient(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	policy := groqClient.(*common.AdapterClient).Retry
+
+	// provider overrides win; everything else comes from parameters.retry
+	if policy.BaseDelay != time.Second || policy.HonorHeaders {
+		t.Errorf("expected groq overrides, got %+v", policy)
+	}
+	if policy.MaxDelay != time.Minute || len(policy.StatusCodes) == 0 {
+		t.Errorf("expected global max delay and status codes, got %+v", policy)
+	}
+
+	mistralClient, err := mistral.New().CreateClient(cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if policy := mistralClient.(*common.AdapterClient).Retry; policy.BaseDelay != 3*time.Second || !policy.HonorHeaders {
+		t.Errorf("expected the global retry policy, got %+v", policy)
+	}
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateRequest is the typed options of one LLM.Generate call: a common
+// section every provider reads, plus one provider's own parameters. build
+// it with a provider's NewGenerateOptions, or directly:
+//
+//	req := &common.GenerateRequest{GenerateOptions: common.GenerateOptions{MaxTokens: common.IntPtr(500)}}
+//	req.Extension = &openai.Extension{Seed: common.IntPtr(7)}
+//
+// a nil request uses the provider's defaults
+type GenerateRequest struct {
+	GenerateOptions
+
+	// Extension holds parameters only one provider understands, such as
+	// *openai.Extension; nil when the request uses only the common section.
+	// a provider rejects another provider's extension
+	Extension Extension
+
+	// OnChunk receives chunks as they arrive when Stream is set
+	OnChunk StreamHandler
+}
+
+// Extension is a provider's typed section of a GenerateRequest
+type Extension interface {
+	// Provider names the provider the parameters are for
+	Provider() string
+
+	// Validate reports a value the provider would reject
+	Validate() error
+}
+
+// NewGenerateRequest creates a request with only the common section
+func NewGenerateRequest(opts ...GenerateOption) *GenerateRequest {
+	return &GenerateRequest{GenerateOptions: *NewGenerateOptions(opts...)}
+}
+
+// Validate checks the request before anything is sent: the common section,
+// then the extension
+func (r *GenerateRequest) Validate() error {
+	if err := r.GenerateOptions.Validate(); err != nil {
+		return err
+	}
+	if r.Extension != nil {
+		if err := r.Extension.Validate(); err != nil {
+			return fmt.Errorf("invalid %s options: %w", r.Extension.Provider(), err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the common parameters against the ranges providers accept
+func (o *GenerateOptions) Validate() error {
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return fmt.Errorf("temperature %g is out of range (0-2)", *o.Temperature)
+	}
+	if o.TopP != nil && (*o.TopP < 0 || *o.TopP > 1) {
+		return fmt.Errorf("top_p %g is out of range (0-1)", *o.TopP)
+	}
+	if o.MaxTokens != nil && *o.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive, got %d", *o.MaxTokens)
+	}
+	if o.Thinking < ThinkingOff || o.Thinking > ThinkingHigh {
+		return fmt.Errorf("unknown thinking level %d", o.Thinking)
+	}
+
+	if rf := o.ResponseFormat; rf != nil {
+		switch rf.Type {
+		case "text", "json_object":
+		case "json_schema":
+			if len(rf.Schema) == 0 {
+				return fmt.Errorf("response format json_schema needs a schema")
+			}
+			if err := ValidateJSONSchema(rf.Schema); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown response format %q: expected text, json_object or json_schema", rf.Type)
+		}
+	}
+
+	seen := make(map[string]bool, len(o.Tools))
+	for _, tool := range o.Tools {
+		name := tool.Function.Name
+		if tool.Type != "function" {
+			return fmt.Errorf("tool %q has unknown type %q: expected function", name, tool.Type)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("tool has no name")
+		}
+		if seen[name] {
+			return fmt.Errorf("tool %q is declared twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// ExtensionOf returns the request's extension as provider's type E, or an
+// empty E when the request has none. another provider's extension is an
+// error rather than being ignored
+func ExtensionOf[E any, P interface {
+	*E
+	Extension
+}](req *GenerateRequest, provider string) (P, error) {
+	switch ext := req.Extension.(type) {
+	case nil:
+		return P(new(E)), nil
+	case P:
+		if ext == nil {
+			return P(new(E)), nil
+		}
+		return ext, nil
+	default:
+		return nil, fmt.Errorf("%s options can't be sent to %s", ext.Provider(), provider)
+	}
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testExtension stands in for a provider's typed options
+type testExtension struct {
+	provider string
+	err      error
+}
+
+func (e *testExtension) Provider() string { return e.provider }
+func (e *testExtension) Validate() error  { return e.err }
+
+// otherExtension is a second extension type, so one can be foreign to the other
+type otherExtension struct{}
+
+func (e *otherExtension) Provider() string { return "openai" }
+func (e *otherExtension) Validate() error  { return nil }
+
+func TestGenerateOptions_Validate(t *testing.T) {
+	tool := func(name string) ToolConfig {
+		return ToolConfig{Type: "function", Function: FunctionDefinition{Name: name}}
+	}
+
+	tests := []struct {
+		name    string
+		opts    *GenerateOptions
+		wantErr string
+	}{
+		{"empty", NewGenerateOptions(), ""},
+		{"in range", NewGenerateOptions(WithTemperature(2), WithTopP(0), WithMaxTokens(1), WithThinking(ThinkingHigh)), ""},
+		{"temperature", NewGenerateOptions(WithTemperature(2.5)), "temperature 2.5 is out of range (0-2)"},
+		{"negative temperature", NewGenerateOptions(WithTemperature(-0.1)), "temperature -0.1 is out of range"},
+		{"top_p", NewGenerateOptions(WithTopP(1.5)), "top_p 1.5 is out of range (0-1)"},
+		{"max_tokens", NewGenerateOptions(WithMaxTokens(0)), "max_tokens must be positive, got 0"},
+		{"thinking", NewGenerateOptions(WithThinking(ThinkingLevel(9))), "unknown thinking level 9"},
+		{"json object", NewGenerateOptions(WithJSONFormat()), ""},
+		{"schema", NewGenerateOptions(WithSchema("r", []byte(`{"type":"object"}`))), ""},
+		{"schema missing", NewGenerateOptions(WithResponseFormat(&ResponseFormat{Type: "json_schema"})), "json_schema needs a schema"},
+		{"schema invalid", NewGenerateOptions(WithSchema("r", []byte(`{`))), "schema"},
+		{"response format type", NewGenerateOptions(WithResponseFormat(&ResponseFormat{Type: "yaml"})), `unknown response format "yaml"`},
+		{"tools", NewGenerateOptions(WithTools([]ToolConfig{tool("date"), tool("weather")})), ""},
+		{"tool type", NewGenerateOptions(WithTools([]ToolConfig{{Type: "retrieval", Function: FunctionDefinition{Name: "date"}}})), `tool "date" has unknown type "retrieval"`},
+		{"tool name", NewGenerateOptions(WithTools([]ToolConfig{tool(" ")})), "tool has no name"},
+		{"duplicate tool", NewGenerateOptions(WithTools([]ToolConfig{tool("date"), tool("date")})), `tool "date" is declared twice`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGenerateRequest_Validate(t *testing.T) {
+	t.Run("common section first", func(t *testing.T) {
+		req := NewGenerateRequest(WithTopP(2))
+		req.Extension = &testExtension{provider: "openai", err: errors.New("bad seed")}
+		assert.ErrorContains(t, req.Validate(), "top_p")
+	})
+
+	t.Run("extension", func(t *testing.T) {
+		req := NewGenerateRequest(WithTemperature(0.5))
+		req.Extension = &testExtension{provider: "openai", err: errors.New("bad seed")}
+		assert.EqualError(t, req.Validate(), "invalid openai options: bad seed")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		req := NewGenerateRequest(WithTemperature(0.5))
+		req.Extension = &testExtension{provider: "openai"}
+		assert.NoError(t, req.Validate())
+	})
+}
+
+func TestExtensionOf(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		ext, err := ExtensionOf[testExtension](&GenerateRequest{}, "openai")
+		require.NoError(t, err)
+		assert.NotNil(t, ext)
+	})
+
+	t.Run("typed nil", func(t *testing.T) {
+		ext, err := ExtensionOf[testExtension](&GenerateRequest{Extension: (*testExtension)(nil)}, "openai")
+		require.NoError(t, err)
+		assert.NotNil(t, ext)
+	})
+
+	t.Run("own", func(t *testing.T) {
+		own := &testExtension{provider: "openai"}
+		ext, err := ExtensionOf[testExtension](&GenerateRequest{Extension: own}, "openai")
+		require.NoError(t, err)
+		assert.Same(t, own, ext)
+	})
+
+	t.Run("another provider's", func(t *testing.T) {
+		_, err := ExtensionOf[otherExtension](&GenerateRequest{Extension: &testExtension{provider: "anthropic"}}, "openai")
+		assert.EqualError(t, err, "anthropic options can't be sent to openai")
+	})
+}
+
+func TestAdapterClient_Generate_InvalidRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("an invalid request must not be sent")
+	}))
+	defer server.Close()
+
+	provider := &MockProvider{}
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	client := NewAdapterClient(provider, "test-key", server.URL)
+
+	_, err := client.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, "test-model", NewGenerateRequest(WithTemperature(3)))
+	assert.EqualError(t, err, "test-provider: temperature 3 is out of range (0-2)")
+	provider.AssertNotCalled(t, "BuildRequest")
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// GenerateResult is everything a generation request produced
+type GenerateResult struct {
+	// Content is the response text. native thinking stays inlined as a
+	// leading <think> tag so the thinking filters treat every provider alike
+	Content string
+
+	// Thinking is the native thinking trace, when the provider returned one
+	Thinking string
+
+	// Usage is the token usage reported by the provider (nil if unreported)
+	Usage *Usage
+
+	// FinishReason is the provider's native stop reason (e.g. "stop",
+	// "length", "max_tokens", "end_turn"); see Truncated
+	FinishReason string
+
+	// Model is the model id the provider reports having used, falling back
+	// to the requested id when the response doesn't say
+	Model string
+
+	// ToolCalls are the tool calls requested by the model. when present, the
+	// content is not a final answer; see ToolCallingProvider
+	ToolCalls []ToolCall
+
+	// Cached is set when the result was served from the response cache
+	// rather than the provider
+	Cached bool
+}
+
+// truncatedFinishReasons are the stop reasons providers use when a response
+// was cut off by the max tokens limit
+var truncatedFinishReasons = map[string]bool{
+	"length":     true, // OpenAI-compatible, Ollama
+	"max_tokens": true, // Anthropic, Cohere
+	"MAX_TOKENS": true, // Cohere
+}
+
+// Truncated reports whether the response was cut off by the max tokens limit
+func (r *GenerateResult) Truncated() bool {
+	return r != nil && truncatedFinishReasons[r.FinishReason]
+}
+
+// ResponseMetadata is the descriptive part of a response that ParseResponse
+// doesn't return
+type ResponseMetadata struct {
+	Model        string
+	FinishReason string
+}
+
+// MetadataProvider is implemented by providers that report the resolved
+// model id and finish reason of a complete response
+type MetadataProvider interface {
+	// ParseMetadata extracts metadata from a response body. it is best
+	// effort: fields the body doesn't carry are left empty
+	ParseMetadata(body []byte) ResponseMetadata
+}
+
+// ParseChatCompletionMetadata extracts metadata from an OpenAI-compatible
+// chat completion response body
+func ParseChatCompletionMetadata(body []byte) ResponseMetadata {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return ResponseMetadata{}
+	}
+	meta := ResponseMetadata{Model: chatResp.Model}
+	if len(chatResp.Choices) > 0 {
+		meta.FinishReason = chatResp.Choices[0].FinishReason
+	}
+	return meta
+}
+
+// SplitThinking separates a leading inline <think> tag, as providers
+// re-inline native thinking, from the rest of the content
+func SplitThinking(content string) (thinking, rest string) {
+	after, ok := strings.CutPrefix(content, "<think>")
+	if !ok {
+		return "", content
+	}
+	thinking, rest, ok = strings.Cut(after, "</think>")
+	if !ok {
+		return "", content
+	}
+	return thinking, strings.TrimPrefix(rest, "\n")
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateResult_Truncated(t *testing.T) {
+	tests := []struct {
+		finishReason string
+		truncated    bool
+	}{
+		{"length", true},
+		{"max_tokens", true},
+		{"MAX_TOKENS", true},
+		{"stop", false},
+		{"end_turn", false},
+		{"tool_calls", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.finishReason, func(t *testing.T) {
+			result := &GenerateResult{FinishReason: tt.finishReason}
+			assert.Equal(t, tt.truncated, result.Truncated())
+		})
+	}
+
+	var nilResult *GenerateResult
+	assert.False(t, nilResult.Truncated())
+}
+
+func TestSplitThinking(t *testing.T) {
+	thinking, rest := SplitThinking("<think>step one</think>\nthe answer")
+	assert.Equal(t, "step one", thinking)
+	assert.Equal(t, "the answer", rest)
+
+	thinking, rest = SplitThinking("the answer")
+	assert.Empty(t, thinking)
+	assert.Equal(t, "the answer", rest)
+
+	// an unterminated tag is left in place
+	thinking, rest = SplitThinking("<think>still going")
+	assert.Empty(t, thinking)
+	assert.Equal(t, "<think>still going", rest)
+}
+
+func TestParseChatCompletionMetadata(t *testing.T) {
+	meta := ParseChatCompletionMetadata([]byte(`{"model":"gpt-4o-2024-08-06","choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"length"}]}`))
+	assert.Equal(t, "gpt-4o-2024-08-06", meta.Model)
+	assert.Equal(t, "length", meta.FinishReason)
+
+	assert.Equal(t, ResponseMetadata{}, ParseChatCompletionMetadata([]byte(`not json`)))
+}
+
+// metadataMockProvider adds response metadata to MockProvider
+type metadataMockProvider struct {
+	MockProvider
+	meta ResponseMetadata
+}
+
+func (m *metadataMockProvider) ParseMetadata(body []byte) ResponseMetadata {
+	return m.meta
+}
+
+func TestAdapterClient_Generate_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	newProvider := func(meta ResponseMetadata) *metadataMockProvider {
+		provider := &metadataMockProvider{meta: meta}
+		provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).
+			Return(map[string]interface{}{}, nil)
+		provider.On("ProviderName").Return("test-provider").Maybe()
+		provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+		provider.On("ParseResponse", mock.Anything, mock.Anything).
+			Return("<think>hmm</think>\npartial", &Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}, nil)
+		return provider
+	}
+	messages := []Message{{Role: "user", Content: "test message"}}
+
+	t.Run("reported model and finish reason", func(t *testing.T) {
+		client := NewAdapterClient(newProvider(ResponseMetadata{Model: "test-model-0613", FinishReason: "length"}), "", server.URL)
+		result, err := client.Generate(context.Background(), messages, "test-model", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "<think>hmm</think>\npartial", result.Content)
+		assert.Equal(t, "hmm", result.Thinking)
+		assert.Equal(t, "test-model-0613", result.Model)
+		assert.Equal(t, "length", result.FinishReason)
+		assert.Equal(t, 7, result.Usage.TotalTokens)
+		assert.True(t, result.Truncated())
+	})
+
+	t.Run("requested model when unreported", func(t *testing.T) {
+		client := NewAdapterClient(newProvider(ResponseMetadata{}), "", server.URL)
+		result, err := client.Generate(context.Background(), messages, "test-model", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "test-model", result.Model)
+		assert.Empty(t, result.FinishReason)
+		assert.False(t, result.Truncated())
+	})
+}
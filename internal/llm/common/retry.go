@@ -4,11 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"log/slog"
 	"math"
 	mathrand "math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
 )
 
 // ShouldRetry determines if an HTTP status code should trigger a retry
@@ -18,9 +23,67 @@ func ShouldRetry(statusCode int) bool {
 		(statusCode >= 500 && statusCode < 600) // 5xx server errors
 }
 
-// calculateBackoff calculates the delay for the given retry attempt
-// uses exponential backoff: 3^attempt seconds with ±10% jitter
-func calculateBackoff(attempt int) time.Duration {
+// RetryPolicy decides whether a failed request is retried and how long to wait
+type RetryPolicy struct {
+	// BaseDelay is the wait before the first retry; each later retry waits 3x longer
+	BaseDelay time.Duration
+
+	// MaxDelay caps each wait; zero leaves it uncapped. a server asking for a
+	// longer wait is not retried, so callers can move on instead of stalling
+	MaxDelay time.Duration
+
+	// Jitter spreads each backoff by up to ±Jitter of its length
+	Jitter float64
+
+	// StatusCodes lists the retryable response codes; empty means ShouldRetry
+	StatusCodes []int
+
+	// HonorHeaders waits as long as Retry-After or x-ratelimit-reset-* ask
+	HonorHeaders bool
+}
+
+// DefaultRetryPolicy returns the built-in policy: 3s, 9s, 27s... with ±10% jitter
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		BaseDelay: 3 * time.Second,
+		Jitter:    0.1,
+	}
+}
+
+// NewRetryPolicy builds a policy from config, keeping the defaults for unset fields
+func NewRetryPolicy(cfg config.Retry) RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if d, err := time.ParseDuration(cfg.BaseDelay); err == nil && d >= 0 {
+		policy.BaseDelay = d
+	}
+	if d, err := time.ParseDuration(cfg.MaxDelay); err == nil && d >= 0 {
+		policy.MaxDelay = d
+	}
+	if cfg.Jitter != nil {
+		policy.Jitter = *cfg.Jitter
+	}
+	policy.StatusCodes = cfg.StatusCodes
+	if cfg.HonorHeaders != nil {
+		policy.HonorHeaders = *cfg.HonorHeaders
+	}
+	return policy
+}
+
+// Retryable reports whether a response with this status code should be retried
+func (p RetryPolicy) Retryable(statusCode int) bool {
+	if len(p.StatusCodes) == 0 {
+		return ShouldRetry(statusCode)
+	}
+	for _, code := range p.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the jittered, capped exponential delay for a retry attempt
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
 	if attempt <= 0 {
 		return 0
 	}
@@ -33,56 +96,114 @@ func calculateBackoff(attempt int) time.Duration {
 	}
 	rng := mathrand.New(mathrand.NewSource(seed))
 
-	// base delay: 3^attempt seconds
-	baseDelay := math.Pow(3, float64(attempt))
+	// base delay grows 3x per attempt
+	delay := float64(p.BaseDelay) * math.Pow(3, float64(attempt-1))
+
+	// add/subtract up to Jitter (e.g. 0.1 gives a multiplier between 0.9 and 1.1)
+	delay *= 1 - p.Jitter + rng.Float64()*2*p.Jitter
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// calculateBackoff calculates the delay for the given retry attempt under the default policy
+// uses exponential backoff: 3^attempt seconds with ±10% jitter
+func calculateBackoff(attempt int) time.Duration {
+	return DefaultRetryPolicy().Backoff(attempt)
+}
+
+// rateLimitResets are the reset headers sent by OpenAI-compatible APIs, each
+// paired with the header saying how much of that limit remains
+var rateLimitResets = []struct{ reset, remaining string }{
+	{"x-ratelimit-reset-requests", "x-ratelimit-remaining-requests"},
+	{"x-ratelimit-reset-tokens", "x-ratelimit-remaining-tokens"},
+}
+
+// ServerDelay returns how long the response asks the client to wait, from
+// Retry-After (seconds or an HTTP date) or the x-ratelimit-reset-* headers,
+// and which header it came from
+func ServerDelay(header http.Header, now time.Time) (time.Duration, string, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), "Retry-After", true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), "Retry-After", true
+		}
+	}
+
+	// wait for the longest exhausted limit; when no remaining counts are
+	// sent, any reset could be the one that was hit
+	var delay time.Duration
+	source := ""
+	for _, limit := range rateLimitResets {
+		d, ok := parseResetDuration(header.Get(limit.reset))
+		if !ok {
+			continue
+		}
+		if remaining := strings.TrimSpace(header.Get(limit.remaining)); remaining != "" && remaining != "0" {
+			continue
+		}
+		if source == "" || d > delay {
+			delay, source = d, limit.reset
+		}
+	}
+	return delay, source, source != ""
+}
 
-	// add/subtract up to 10% jitter (multiplier between 0.9 and 1.1)
-	jitter := 0.9 + rng.Float64()*0.2
-	delaySeconds := baseDelay * jitter
+// parseResetDuration reads a reset header such as "1s", "6m0s", "250ms" or a bare number of seconds
+func parseResetDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	return 0, false
+}
 
-	return time.Duration(delaySeconds * float64(time.Second))
+// wait decides how long to wait before retrying after a response (nil when
+// no response was received). ok is false when the server asks for longer
+// than MaxDelay, so the response should be returned instead
+func (p RetryPolicy) wait(attempt int, resp *http.Response, now time.Time) (delay time.Duration, source string, ok bool) {
+	if p.HonorHeaders && resp != nil {
+		if d, header, found := ServerDelay(resp.Header, now); found {
+			if p.MaxDelay > 0 && d > p.MaxDelay {
+				return d, header, false
+			}
+			return d, header, true
+		}
+	}
+	return p.Backoff(attempt), "backoff", true
 }
 
 // HTTPExecutor is a function type that executes an HTTP request
 type HTTPExecutor func(ctx context.Context) (*http.Response, error)
 
-// ExecuteWithRetry executes an HTTP request with retry logic
+// ExecuteWithRetry executes an HTTP request with retry logic under the default policy
 // returns the response and error from the final attempt
 func ExecuteWithRetry(ctx context.Context, executor HTTPExecutor, maxRetries int, logger *slog.Logger) (*http.Response, error) {
+	return ExecuteWithRetryPolicy(ctx, executor, maxRetries, DefaultRetryPolicy(), logger)
+}
+
+// ExecuteWithRetryPolicy executes an HTTP request, retrying network errors and
+// retryable responses as the policy directs
+// returns the response and error from the final attempt
+func ExecuteWithRetryPolicy(ctx context.Context, executor HTTPExecutor, maxRetries int, policy RetryPolicy, logger *slog.Logger) (*http.Response, error) {
 	// Enforce maximum retry limit of 5
 	if maxRetries > 5 {
 		maxRetries = 5
 	}
 
-	var lastResp *http.Response
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		// add backoff delay for retry attempts (not for first attempt)
-		if attempt > 0 {
-			delay := calculateBackoff(attempt)
-			if logger != nil {
-				logger.Debug("Retrying HTTP request",
-					"attempt", attempt,
-					"max_retries", maxRetries,
-					"delay_seconds", delay.Seconds())
-			}
-
-			// use a timer that respects context cancellation
-			timer := time.NewTimer(delay)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return nil, ctx.Err()
-			case <-timer.C:
-				// Continue with retry
-			}
-		}
-
+	for attempt := 0; ; attempt++ {
 		// execute the HTTP request
 		resp, err := executor(ctx)
-		lastResp = resp
-		lastErr = err
 
 		// if context was cancelled, return immediately
 		if ctx.Err() != nil {
@@ -95,8 +216,12 @@ func ExecuteWithRetry(ctx context.Context, executor HTTPExecutor, maxRetries int
 		// determine if we should retry this attempt
 		shouldRetryThisAttempt := false
 
+		var miss *CassetteMissError
 		if err != nil {
-			if resp == nil {
+			if errors.As(err, &miss) {
+				// a request that was never recorded won't be on retry either
+				shouldRetryThisAttempt = false
+			} else if resp == nil {
 				// transient network error (no response received) - retry!
 				shouldRetryThisAttempt = true
 				if logger != nil {
@@ -104,7 +229,7 @@ func ExecuteWithRetry(ctx context.Context, executor HTTPExecutor, maxRetries int
 						"attempt", attempt,
 						"error", err.Error())
 				}
-			} else if ShouldRetry(resp.StatusCode) {
+			} else if policy.Retryable(resp.StatusCode) {
 				// HTTP error with retryable status code
 				shouldRetryThisAttempt = true
 				if logger != nil {
@@ -114,7 +239,7 @@ func ExecuteWithRetry(ctx context.Context, executor HTTPExecutor, maxRetries int
 						"error", err.Error())
 				}
 			}
-		} else if resp != nil && ShouldRetry(resp.StatusCode) {
+		} else if resp != nil && policy.Retryable(resp.StatusCode) {
 			// successful request but retryable status code
 			shouldRetryThisAttempt = true
 			if logger != nil {
@@ -133,12 +258,44 @@ func ExecuteWithRetry(ctx context.Context, executor HTTPExecutor, maxRetries int
 			return resp, err
 		}
 
+		// a server asking for a longer wait than the policy allows gets its
+		// response back now, so the caller can fall back elsewhere
+		delay, source, ok := policy.wait(attempt+1, resp, time.Now())
+		if !ok {
+			if logger != nil {
+				logger.Warn("Not retrying: server asked to wait longer than the max delay",
+					"attempt", attempt,
+					"source", source,
+					"requested_seconds", delay.Seconds(),
+					"max_delay_seconds", policy.MaxDelay.Seconds())
+			}
+			if err != nil && resp != nil && resp.Body != nil {
+				resp.Body.Close()
+			}
+			return resp, err
+		}
+
 		// clean up response body before retrying
 		if resp != nil && resp.Body != nil {
 			resp.Body.Close()
 		}
-	}
 
-	// return last response/error as fallback (shouldn't be needed)
-	return lastResp, lastErr
+		if logger != nil {
+			logger.Info("Retrying HTTP request",
+				"attempt", attempt+1,
+				"max_retries", maxRetries,
+				"delay_seconds", delay.Seconds(),
+				"source", source)
+		}
+
+		// use a timer that respects context cancellation
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+			// Continue with retry
+		}
+	}
 }
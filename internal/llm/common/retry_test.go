@@ -8,6 +8,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
 )
 
 func TestExecuteWithRetry(t *testing.T) {
@@ -217,3 +219,172 @@ func TestRetryContextCancellation(t *testing.T) {
 		t.Errorf("Expected quick failure due to context cancellation, but took %v", duration)
 	}
 }
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	// without jitter the delays are exact, and capped at the max delay
+	expected := []time.Duration{0, time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempt, want := range expected {
+		if got := policy.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %v, expected %v", attempt, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		if got := policy.Backoff(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Errorf("Backoff(1) with 50%% jitter = %v, expected between 500ms and 1.5s", got)
+		}
+	}
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	defaults := RetryPolicy{}
+	if !defaults.Retryable(http.StatusTooManyRequests) || !defaults.Retryable(http.StatusNotImplemented) {
+		t.Error("expected the default policy to retry 429 and any 5xx")
+	}
+
+	custom := RetryPolicy{StatusCodes: []int{http.StatusServiceUnavailable}}
+	if !custom.Retryable(http.StatusServiceUnavailable) {
+		t.Error("expected a listed status code to be retried")
+	}
+	if custom.Retryable(http.StatusTooManyRequests) {
+		t.Error("expected an unlisted status code not to be retried")
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	jitter := 0.25
+	honor := true
+	policy := NewRetryPolicy(config.Retry{
+		BaseDelay:    "500ms",
+		MaxDelay:     "20s",
+		Jitter:       &jitter,
+		StatusCodes:  []int{429},
+		HonorHeaders: &honor,
+	})
+
+	if policy.BaseDelay != 500*time.Millisecond || policy.MaxDelay != 20*time.Second {
+		t.Errorf("unexpected delays: base %v, max %v", policy.BaseDelay, policy.MaxDelay)
+	}
+	if policy.Jitter != 0.25 || !policy.HonorHeaders || len(policy.StatusCodes) != 1 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	// unset fields keep the defaults
+	if got := NewRetryPolicy(config.Retry{}); got.BaseDelay != 3*time.Second || got.Jitter != 0.1 || got.HonorHeaders {
+		t.Errorf("expected the default policy, got %+v", got)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		header     map[string]string
+		wantDelay  time.Duration
+		wantSource string
+		wantFound  bool
+	}{
+		{"no headers", nil, 0, "", false},
+		{"retry-after seconds", map[string]string{"Retry-After": "7"}, 7 * time.Second, "Retry-After", true},
+		{"retry-after date", map[string]string{"Retry-After": now.Add(90 * time.Second).Format(http.TimeFormat)}, 90 * time.Second, "Retry-After", true},
+		{"retry-after in the past", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, 0, "Retry-After", true},
+		{"retry-after wins over resets", map[string]string{"Retry-After": "2", "x-ratelimit-reset-requests": "30s"}, 2 * time.Second, "Retry-After", true},
+		{"openai reset duration", map[string]string{"x-ratelimit-reset-requests": "6m0s"}, 6 * time.Minute, "x-ratelimit-reset-requests", true},
+		{"groq fractional reset", map[string]string{"x-ratelimit-reset-tokens": "7.66s"}, 7660 * time.Millisecond, "x-ratelimit-reset-tokens", true},
+		{"bare seconds reset", map[string]string{"x-ratelimit-reset-requests": "1.5"}, 1500 * time.Millisecond, "x-ratelimit-reset-requests", true},
+		{"longest reset without remaining counts", map[string]string{"x-ratelimit-reset-requests": "2s", "x-ratelimit-reset-tokens": "20s"}, 20 * time.Second, "x-ratelimit-reset-tokens", true},
+		{
+			"exhausted limit only",
+			map[string]string{
+				"x-ratelimit-reset-requests": "2s", "x-ratelimit-remaining-requests": "0",
+				"x-ratelimit-reset-tokens": "20s", "x-ratelimit-remaining-tokens": "5000",
+			},
+			2 * time.Second, "x-ratelimit-reset-requests", true,
+		},
+		{"unparseable", map[string]string{"Retry-After": "soon", "x-ratelimit-reset-requests": "later"}, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			delay, source, found := ServerDelay(header, now)
+			if delay != tt.wantDelay || source != tt.wantSource || found != tt.wantFound {
+				t.Errorf("ServerDelay() = (%v, %q, %v), expected (%v, %q, %v)",
+					delay, source, found, tt.wantDelay, tt.wantSource, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestExecuteWithRetryPolicy_Headers(t *testing.T) {
+	rateLimited := func(retryAfter string) HTTPExecutor {
+		return func(ctx context.Context) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("Retry-After", retryAfter)
+			return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: http.NoBody}, nil
+		}
+	}
+
+	t.Run("waits as long as the server asks", func(t *testing.T) {
+		calls := 0
+		executor := func(ctx context.Context) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return rateLimited("0.05")(ctx)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}
+
+		// the 1 minute backoff would time the test out if Retry-After were ignored
+		policy := RetryPolicy{BaseDelay: time.Minute, HonorHeaders: true}
+		start := time.Now()
+		resp, err := ExecuteWithRetryPolicy(context.Background(), executor, 2, policy, nil)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected a successful retry, got %v, %v", resp, err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 5*time.Second {
+			t.Errorf("expected to wait about 50ms, waited %v", elapsed)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("gives up when the server asks for more than the max delay", func(t *testing.T) {
+		calls := 0
+		executor := func(ctx context.Context) (*http.Response, error) {
+			calls++
+			return rateLimited("120")(ctx)
+		}
+
+		policy := RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 30 * time.Second, HonorHeaders: true}
+		resp, err := ExecuteWithRetryPolicy(context.Background(), executor, 3, policy, nil)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected the 429 response back, got %v, %v", resp, err)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("ignores headers unless honored", func(t *testing.T) {
+		calls := 0
+		executor := func(ctx context.Context) (*http.Response, error) {
+			calls++
+			return rateLimited("120")(ctx)
+		}
+
+		policy := RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 30 * time.Second}
+		resp, _ := ExecuteWithRetryPolicy(context.Background(), executor, 2, policy, nil)
+		if resp.StatusCode != http.StatusTooManyRequests || calls != 3 {
+			t.Errorf("expected 3 calls ending in 429, got %d calls and status %d", calls, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// StreamFormat identifies the wire framing a provider uses for streamed responses
+type StreamFormat int
+
+const (
+	// StreamSSE is server-sent events: "data: {...}" lines separated by blank lines
+	// (OpenAI-compatible providers, Anthropic, Cohere)
+	StreamSSE StreamFormat = iota
+	// StreamNDJSON is newline-delimited JSON: one complete object per line (Ollama)
+	StreamNDJSON
+)
+
+// maxStreamLineSize bounds a single SSE/NDJSON line; final usage events can be large
+const maxStreamLineSize = 1024 * 1024
+
+// StreamChunk is one incremental piece of a streamed response.
+// Content and Thinking carry deltas (not the accumulated text so far)
+type StreamChunk struct {
+	Content      string
+	Thinking     string
+	Usage        *Usage // usually only present on the final event
+	Model        string // resolved model id, when the event reports it
+	FinishReason string // native stop reason, usually on the final event
+	Done         bool   // provider signalled the end of the stream
+}
+
+// StreamHandler receives chunks as they arrive. Set one as
+// GenerateRequest.OnChunk to have tokens delivered incrementally
+type StreamHandler func(chunk StreamChunk)
+
+// StreamingProvider is implemented by providers that can stream responses.
+// AdapterClient uses the streaming path only when the provider implements this
+// interface and streaming was requested through GenerateOptions.Stream
+type StreamingProvider interface {
+	// StreamFormat reports how stream events are framed on the wire
+	StreamFormat() StreamFormat
+
+	// ParseStreamEvent parses one event payload (an SSE data field or an
+	// NDJSON line). returning a nil chunk skips the event (e.g. pings)
+	ParseStreamEvent(data []byte, logger *slog.Logger) (*StreamChunk, error)
+}
+
+// StreamResult holds everything accumulated from a completed stream
+type StreamResult struct {
+	Content      string
+	Thinking     string
+	Usage        *Usage
+	Model        string
+	FinishReason string
+}
+
+// ReadStream consumes a streamed response body, parsing each event with the
+// provider and forwarding chunks to the handler (which may be nil)
+func ReadStream(body io.Reader, provider StreamingProvider, handler StreamHandler, logger *slog.Logger) (*StreamResult, error) {
+	var content, thinking strings.Builder
+	result := &StreamResult{}
+
+	// emit applies a parsed chunk to the result and reports whether the stream ended
+	emit := func(data []byte) (bool, error) {
+		chunk, err := provider.ParseStreamEvent(data, logger)
+		if err != nil {
+			return false, err
+		}
+		if chunk == nil {
+			return false, nil
+		}
+		content.WriteString(chunk.Content)
+		thinking.WriteString(chunk.Thinking)
+		if chunk.Usage != nil {
+			result.Usage = mergeUsage(result.Usage, chunk.Usage)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.FinishReason != "" {
+			result.FinishReason = chunk.FinishReason
+		}
+		if handler != nil && (chunk.Content != "" || chunk.Thinking != "") {
+			handler(*chunk)
+		}
+		return chunk.Done, nil
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	var event bytes.Buffer // pending SSE data lines for the current event
+	done := false
+
+	for !done && scanner.Scan() {
+		line := bytes.TrimRight(scanner.Bytes(), "\r")
+
+		if provider.StreamFormat() == StreamNDJSON {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			ended, err := emit(line)
+			if err != nil {
+				return nil, err
+			}
+			done = ended
+			continue
+		}
+
+		// SSE: a blank line dispatches the buffered event
+		if len(line) == 0 {
+			if event.Len() == 0 {
+				continue
+			}
+			data := event.Bytes()
+			if string(data) == "[DONE]" {
+				break
+			}
+			ended, err := emit(data)
+			if err != nil {
+				return nil, err
+			}
+			done = ended
+			event.Reset()
+			continue
+		}
+
+		// only data fields carry payloads; event/id/retry and comments are ignored
+		if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			if event.Len() > 0 {
+				event.WriteByte('\n')
+			}
+			event.Write(bytes.TrimPrefix(value, []byte(" ")))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	// servers may close the connection without a trailing blank line
+	if !done && event.Len() > 0 && string(event.Bytes()) != "[DONE]" {
+		if _, err := emit(event.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Content = content.String()
+	result.Thinking = thinking.String()
+	return result, nil
+}
+
+// mergeUsage folds a streamed usage report into the running total. some
+// providers split usage across events (e.g. Anthropic reports input tokens at
+// message start and output tokens at the end), so non-zero fields win
+func mergeUsage(current, update *Usage) *Usage {
+	merged := Usage{}
+	if current != nil {
+		merged = *current
+	}
+	if update.PromptTokens > 0 {
+		merged.PromptTokens = update.PromptTokens
+	}
+	if update.CompletionTokens > 0 {
+		merged.CompletionTokens = update.CompletionTokens
+	}
+	merged.TotalTokens = merged.PromptTokens + merged.CompletionTokens
+	if update.TotalTokens > merged.TotalTokens {
+		merged.TotalTokens = update.TotalTokens
+	}
+	return &merged
+}
+
+// ChatCompletionChunk is the OpenAI-compatible streamed chunk shape
+// shared by openai, groq, mistral and together
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// ChunkChoice is a single choice within a streamed chunk
+type ChunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        ChunkDelta `json:"delta"`
+	FinishReason string     `json:"finish_reason"`
+}
+
+// ChunkDelta carries the incremental message fields of a streamed choice
+type ChunkDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// ParseChatCompletionChunk parses one OpenAI-compatible SSE data payload
+func ParseChatCompletionChunk(data []byte, providerName string, logger *slog.Logger) (*StreamChunk, error) {
+	var chunk ChatCompletionChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		LogJSONUnmarshalError(logger, err, string(data))
+		return nil, fmt.Errorf("failed to unmarshal %s stream chunk: %w", providerName, err)
+	}
+
+	result := &StreamChunk{Usage: chunk.Usage, Model: chunk.Model}
+	if len(chunk.Choices) > 0 {
+		result.Content = chunk.Choices[0].Delta.Content
+		result.FinishReason = chunk.Choices[0].FinishReason
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkStreamer parses OpenAI-compatible chunks in the given framing
+type chunkStreamer struct {
+	format StreamFormat
+}
+
+func (s chunkStreamer) StreamFormat() StreamFormat {
+	return s.format
+}
+
+func (s chunkStreamer) ParseStreamEvent(data []byte, logger *slog.Logger) (*StreamChunk, error) {
+	return ParseChatCompletionChunk(data, "test", logger)
+}
+
+// streamingMockProvider adds StreamingProvider support to MockProvider
+type streamingMockProvider struct {
+	MockProvider
+	chunkStreamer
+}
+
+func TestReadStream_SSE(t *testing.T) {
+	body := strings.Join([]string{
+		`: keep-alive comment`,
+		``,
+		`data: {"choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+		``,
+		`event: message`,
+		`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+		``,
+		`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var deltas []string
+	result, err := ReadStream(strings.NewReader(body), chunkStreamer{format: StreamSSE}, func(chunk StreamChunk) {
+		deltas = append(deltas, chunk.Content)
+	}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", result.Content)
+	assert.Equal(t, []string{"Hel", "lo"}, deltas)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, 5, result.Usage.TotalTokens)
+}
+
+func TestReadStream_SSEWithoutTrailingBlankLine(t *testing.T) {
+	body := "data: {\"choices\":[{\"delta\":{\"content\":\"tail\"}}]}"
+
+	result, err := ReadStream(strings.NewReader(body), chunkStreamer{format: StreamSSE}, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "tail", result.Content)
+}
+
+func TestReadStream_NDJSON(t *testing.T) {
+	body := `{"choices":[{"delta":{"content":"a"}}]}` + "\n\n" +
+		`{"choices":[{"delta":{"content":"b"}}]}` + "\n"
+
+	result, err := ReadStream(strings.NewReader(body), chunkStreamer{format: StreamNDJSON}, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ab", result.Content)
+}
+
+func TestReadStream_ParseError(t *testing.T) {
+	body := "data: not-json\n\n"
+
+	_, err := ReadStream(strings.NewReader(body), chunkStreamer{format: StreamSSE}, nil, nil)
+
+	assert.Error(t, err)
+}
+
+func TestMergeUsage(t *testing.T) {
+	// input tokens at message start, output tokens at the end
+	usage := mergeUsage(nil, &Usage{PromptTokens: 10})
+	usage = mergeUsage(usage, &Usage{CompletionTokens: 4})
+
+	assert.Equal(t, Usage{PromptTokens: 10, CompletionTokens: 4, TotalTokens: 14}, *usage)
+}
+
+func TestAdapterClient_Generate_Streaming(t *testing.T) {
+	var requestBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"{\\\"a\\\":\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"1}\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider := &streamingMockProvider{chunkStreamer: chunkStreamer{format: StreamSSE}}
+	provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).
+		Return(map[string]interface{}{"stream": true}, nil)
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+
+	options := NewGenerateRequest(WithStream(), WithJSONFormat())
+
+	var streamed strings.Builder
+	handler := StreamHandler(func(chunk StreamChunk) {
+		streamed.WriteString(chunk.Content)
+	})
+
+	options.OnChunk = handler
+
+	client := NewAdapterClient(provider, "test-key", server.URL, WithLogger(slog.Default()))
+	result, err := client.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, "test-model", options)
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, result.Content)
+	assert.Equal(t, `{"a":1}`, streamed.String())
+	assert.Equal(t, true, requestBody["stream"])
+
+	// the buffered parser must not be used for streamed responses
+	provider.AssertNotCalled(t, "ParseResponse", mock.Anything, mock.Anything)
+}
+
+func TestAdapterClient_Generate_StreamingErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad"}}`))
+	}))
+	defer server.Close()
+
+	provider := &streamingMockProvider{chunkStreamer: chunkStreamer{format: StreamSSE}}
+	provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).
+		Return(map[string]interface{}{}, nil)
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+	provider.On("HandleError", http.StatusBadRequest, mock.Anything).Return(assert.AnError)
+
+	options := NewGenerateRequest(WithStream())
+
+	client := NewAdapterClient(provider, "test-key", server.URL, WithLogger(slog.Default()))
+	_, err := client.Generate(context.Background(), []Message{{Role: "user", Content: "hi"}}, "test-model", options)
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,47 @@
+package common
+
+import "unicode/utf8"
+
+// token estimates are deliberately rough: tokenizers differ by provider and
+// none is available offline. they only need to tell whether input is near a
+// context window, so they err on the high side
+const (
+	// charsPerToken is the usual ratio for English prose and code
+	charsPerToken = 4
+
+	// imageTokens approximates one image; providers charge roughly 250-1600
+	// tokens depending on size and detail
+	imageTokens = 1000
+
+	// messageOverheadTokens covers the role and framing of each message
+	messageOverheadTokens = 4
+)
+
+// EstimateTokens approximates the tokens in text
+func EstimateTokens(text string) int {
+	// count runes so multi-byte text isn't overcounted fourfold
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateMessageTokens approximates the tokens a message adds to a request
+func EstimateMessageTokens(msg Message) int {
+	tokens := messageOverheadTokens + EstimateTokens(msg.Content) + EstimateTokens(msg.Thinking)
+	for _, call := range msg.ToolCalls {
+		tokens += EstimateTokens(call.Function.Name) + EstimateTokens(call.Function.Arguments)
+	}
+	return tokens + len(msg.Images)*imageTokens
+}
+
+// EstimateRequestTokens approximates the prompt tokens of a conversation
+func EstimateRequestTokens(messages []Message) int {
+	tokens := 0
+	for _, msg := range messages {
+		tokens += EstimateMessageTokens(msg)
+	}
+	return tokens
+}
+
+// TokensToChars converts a token count back to roughly as many characters
+func TokensToChars(tokens int) int {
+	return tokens * charsPerToken
+}
@@ -0,0 +1,26 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("abc"))
+	assert.Equal(t, 250, EstimateTokens(strings.Repeat("a", 1000)))
+	// runes, not bytes
+	assert.Equal(t, 2, EstimateTokens(strings.Repeat("é", 8)))
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: strings.Repeat("a", 400)},
+		{Role: "user", Content: "look", Images: []Image{{Path: "a.png"}}},
+	}
+	assert.Equal(t, 4+100, EstimateMessageTokens(messages[0]))
+	assert.Equal(t, 4+1+imageTokens, EstimateMessageTokens(messages[1]))
+	assert.Equal(t, 104+5+imageTokens, EstimateRequestTokens(messages))
+}
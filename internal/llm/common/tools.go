@@ -0,0 +1,71 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/chriscorrea/slop/internal/config"
+)
+
+// ToolCallingProvider is implemented by providers that support function calling.
+// AdapterClient asks for tool calls only when tools were sent with the request,
+// and returns them on GenerateResult.ToolCalls (the content may then be empty)
+type ToolCallingProvider interface {
+	// ParseToolCalls extracts requested tool calls from a complete response body;
+	// it returns an empty slice when the model produced a final answer
+	ParseToolCalls(body []byte, logger *slog.Logger) ([]ToolCall, error)
+}
+
+// ToolsFromConfig builds tool definitions for the tools enabled in
+// parameters.tools. names are validated at config load, so unknown names are skipped
+func ToolsFromConfig(cfg *config.Config) []ToolConfig {
+	if cfg == nil || len(cfg.Parameters.Tools) == 0 {
+		return nil
+	}
+
+	names := append([]string{}, cfg.Parameters.Tools...)
+	sort.Strings(names)
+
+	var tools []ToolConfig
+	seen := make(map[string]bool)
+	for _, name := range names {
+		tool, ok := cfg.Tools[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		// the model must always be given an object schema, even for no arguments
+		params := json.RawMessage(`{"type":"object","properties":{}}`)
+		if raw := strings.TrimSpace(tool.Parameters); raw != "" {
+			params = json.RawMessage(raw)
+		}
+
+		tools = append(tools, ToolConfig{
+			Type: "function",
+			Function: FunctionDefinition{
+				Name:        name,
+				Description: tool.Description,
+				Parameters:  params,
+			},
+		})
+	}
+	return tools
+}
+
+// ParseChatCompletionToolCalls extracts tool calls from an OpenAI-compatible
+// chat completion response body
+func ParseChatCompletionToolCalls(body []byte, providerName string, logger *slog.Logger) ([]ToolCall, error) {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal %s response: %w", providerName, err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, nil
+	}
+	return chatResp.Choices[0].Message.ToolCalls, nil
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// toolCallingMockProvider adds ToolCallingProvider support to MockProvider
+type toolCallingMockProvider struct {
+	MockProvider
+}
+
+func (m *toolCallingMockProvider) ParseToolCalls(body []byte, logger *slog.Logger) ([]ToolCall, error) {
+	return ParseChatCompletionToolCalls(body, "test", logger)
+}
+
+func TestToolsFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		Parameters: config.Parameters{Tools: []string{"weather", "date", "weather"}},
+		Tools: map[string]config.Tool{
+			"weather": {
+				Description: "Look up the weather",
+				Command:     "curl wttr.in",
+				Parameters:  `{"type":"object","properties":{"city":{"type":"string"}}}`,
+			},
+			"date":   {Command: "date"},
+			"unused": {Command: "true"},
+		},
+	}
+
+	tools := ToolsFromConfig(cfg)
+
+	require.Len(t, tools, 2)
+	assert.Equal(t, "date", tools[0].Function.Name)
+	assert.JSONEq(t, `{"type":"object","properties":{}}`, string(tools[0].Function.Parameters))
+	assert.Equal(t, "weather", tools[1].Function.Name)
+	assert.Equal(t, "Look up the weather", tools[1].Function.Description)
+
+	// the definition marshals to the OpenAI-compatible wire shape
+	encoded, err := json.Marshal(tools[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"function","function":{"name":"weather","description":"Look up the weather","parameters":{"type":"object","properties":{"city":{"type":"string"}}}}}`, string(encoded))
+
+	assert.Nil(t, ToolsFromConfig(&config.Config{}))
+	assert.Nil(t, ToolsFromConfig(nil))
+}
+
+func TestAdapterClient_Generate_ToolCalls(t *testing.T) {
+	responseBody := `{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"date","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	provider := &toolCallingMockProvider{}
+	provider.On("BuildRequest", mock.Anything, "test-model", mock.Anything, mock.Anything).
+		Return(map[string]interface{}{}, nil)
+	provider.On("ProviderName").Return("test-provider").Maybe()
+	provider.On("CustomizeRequest", mock.AnythingOfType("*http.Request")).Return(nil)
+	provider.On("ParseResponse", []byte(responseBody), mock.Anything).Return("", &Usage{}, nil)
+
+	// JSON validation would reject the empty content; tool calls skip it
+	options := NewGenerateRequest(
+		WithJSONFormat(),
+		WithTools([]ToolConfig{{Type: "function", Function: FunctionDefinition{Name: "date"}}}),
+	)
+
+	client := NewAdapterClient(provider, "test-key", server.URL, WithLogger(slog.Default()))
+	result, err := client.Generate(context.Background(), []Message{{Role: "user", Content: "what day is it?"}}, "test-model", options)
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Content)
+	require.Len(t, result.ToolCalls, 1)
+	assert.Equal(t, "call_1", result.ToolCalls[0].ID)
+	assert.Equal(t, "date", result.ToolCalls[0].Function.Name)
+	assert.Equal(t, "{}", result.ToolCalls[0].Function.Arguments)
+}
+
+func TestAdapterClient_StreamingDisabledWithTools(t *testing.T) {
+	client := NewAdapterClient(&streamingMockProvider{chunkStreamer: chunkStreamer{format: StreamSSE}}, "", "")
+
+	streamOnly := NewGenerateRequest(WithStream())
+	_, ok := client.streamingAdapter(streamOnly)
+	assert.True(t, ok)
+
+	withTools := NewGenerateRequest(
+		WithStream(),
+		WithTools([]ToolConfig{{Type: "function", Function: FunctionDefinition{Name: "date"}}}),
+	)
+	_, ok = client.streamingAdapter(withTools)
+	assert.False(t, ok)
+}
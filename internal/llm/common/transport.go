@@ -0,0 +1,111 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+)
+
+// NewHTTPClient builds a provider's HTTP client from its transport settings:
+// proxy, CA bundle, TLS verification, extra headers and timeout. timeout is
+// parameters.timeout in seconds, used when the provider sets none. it bounds
+// connecting and waiting for the response to start, not reading the body, so
+// a long streamed answer isn't cut off; ctx still bounds the whole request
+func NewHTTPClient(settings config.BaseProvider, timeout int) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if settings.ProxyURL != "" {
+		proxy, err := url.Parse(settings.ProxyURL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q: expected a URL such as http://proxy.internal:3128", settings.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if settings.CAFile != "" || settings.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: settings.InsecureSkipVerify, // #nosec G402 -- opt-in for testing
+		}
+		if settings.CAFile != "" {
+			pool, err := loadCertPool(settings.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	seconds := settings.Timeout
+	if seconds <= 0 {
+		seconds = timeout
+	}
+	wait := defaultHTTPTimeout
+	if seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+	dialer := &net.Dialer{Timeout: wait, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = wait
+	transport.ResponseHeaderTimeout = wait
+
+	client := &http.Client{Transport: transport}
+	if len(settings.Headers) > 0 {
+		client.Transport = &headerTransport{headers: settings.Headers, next: transport}
+	}
+	return client, nil
+}
+
+// NewAPIClient builds the client for provider calls made outside Generate,
+// such as model listing and embeddings: the provider's transport settings and,
+// with --record or --replay, the same cassette adapters use
+func NewAPIClient(settings config.BaseProvider, params config.Parameters) (*http.Client, error) {
+	client, err := NewHTTPClient(settings, params.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if mode, dir := params.Cassette(); mode != "" {
+		client = withCassetteTransport(client, mode, dir)
+	}
+	return client, nil
+}
+
+// loadCertPool returns the system roots plus the certificates in a PEM file
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca_file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ca_file %s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// headerTransport sets configured headers on every request, replacing any
+// the adapter set, so a gateway can take its own credentials
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+// RoundTrip sends a copy of the request carrying the headers
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	// responseHeaderTimeout digs the transport out from under any header wrapper
+	responseHeaderTimeout := func(client *http.Client) time.Duration {
+		transport := client.Transport
+		if headers, ok := transport.(*headerTransport); ok {
+			transport = headers.next
+		}
+		return transport.(*http.Transport).ResponseHeaderTimeout
+	}
+
+	client, err := NewHTTPClient(config.BaseProvider{}, 0)
+	require.NoError(t, err)
+	assert.Zero(t, client.Timeout, "reading the body is left to ctx")
+	assert.Equal(t, defaultHTTPTimeout, responseHeaderTimeout(client))
+
+	client, err = NewHTTPClient(config.BaseProvider{}, 120)
+	require.NoError(t, err)
+	assert.Equal(t, 120*time.Second, responseHeaderTimeout(client), "parameters.timeout applies when the provider sets none")
+
+	client, err = NewHTTPClient(config.BaseProvider{Timeout: 5, Headers: map[string]string{"X-Gateway": "gateway"}}, 120)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, responseHeaderTimeout(client))
+}
+
+func TestNewHTTPClient_SlowResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-start" {
+			time.Sleep(1500 * time.Millisecond)
+			return
+		}
+		// a stream that starts at once and runs well past the timeout
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "data: chunk %d\n\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(600 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(config.BaseProvider{}, 1)
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL + "/stream")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err, "a streamed body may outlast the timeout")
+	assert.Equal(t, "data: chunk 0\n\ndata: chunk 1\n\ndata: chunk 2\n\n", string(body))
+
+	_, err = client.Get(server.URL + "/slow-start")
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+}
+
+func TestNewHTTPClient_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "org-123", r.Header.Get("OpenAI-Organization"))
+		assert.Equal(t, "Bearer gateway", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(config.BaseProvider{Headers: map[string]string{
+		"openai-organization": "org-123",
+		"Authorization":       "Bearer gateway",
+	}}, 0)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk-original")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer sk-original", req.Header.Get("Authorization"), "the caller's request is not modified")
+}
+
+func TestNewAPIClient(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gateway", r.Header.Get("X-Gateway"))
+		_, _ = w.Write([]byte(`{"data":[{"id":"m"}]}`))
+	}))
+	settings := config.BaseProvider{Headers: map[string]string{"X-Gateway": "gateway"}, Timeout: 5}
+	handleError := func(status int, body []byte) error { return nil }
+
+	client, err := NewAPIClient(settings, config.Parameters{Record: dir})
+	require.NoError(t, err)
+	_, err = FetchModels(context.Background(), client, server.URL+"/v1/models", nil, handleError)
+	require.NoError(t, err)
+
+	// the listing replays offline, as a Generate call would
+	server.Close()
+	client, err = NewAPIClient(settings, config.Parameters{Replay: dir})
+	require.NoError(t, err)
+	body, err := FetchModels(context.Background(), client, server.URL+"/v1/models", nil, handleError)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":[{"id":"m"}]}`, string(body))
+
+	_, err = NewAPIClient(config.BaseProvider{ProxyURL: "proxy.internal"}, config.Parameters{})
+	assert.ErrorContains(t, err, "invalid proxy_url")
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(config.BaseProvider{ProxyURL: proxy.URL}, 0)
+	require.NoError(t, err)
+	resp, err := client.Get("http://api.example.test/v1/models")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://api.example.test/v1/models", proxied)
+
+	_, err = NewHTTPClient(config.BaseProvider{ProxyURL: "proxy.internal"}, 0)
+	assert.ErrorContains(t, err, "invalid proxy_url")
+}
+
+func TestNewHTTPClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// the test server's certificate is self-signed, so it is untrusted by default
+	client, err := NewHTTPClient(config.BaseProvider{}, 0)
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0o600))
+
+	client, err = NewHTTPClient(config.BaseProvider{CAFile: caFile}, 0)
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	client, err = NewHTTPClient(config.BaseProvider{InsecureSkipVerify: true}, 0)
+	require.NoError(t, err)
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = NewHTTPClient(config.BaseProvider{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, 0)
+	assert.ErrorContains(t, err, "failed to read ca_file")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = NewHTTPClient(config.BaseProvider{CAFile: notPEM}, 0)
+	assert.ErrorContains(t, err, "no PEM certificates")
+}
@@ -25,6 +25,34 @@ type Message struct {
 	Role     string `json:"role"`
 	Content  string `json:"content"`
 	Thinking string `json:"thinking,omitempty"`
+
+	// ToolCalls are the function calls requested by an assistant message
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID links a "tool" role message to the call it answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// ToolName is the function a "tool" role message answers. it is not sent
+	// by OpenAI-compatible adapters; others (e.g. Ollama) address results by name
+	ToolName string `json:"-"`
+
+	// Images are image content parts sent alongside Content. adapters that
+	// accept images serialize them natively; see VisionProvider
+	Images []Image `json:"-"`
+}
+
+// ToolCall is a function call requested by the model. the shape matches the
+// OpenAI-compatible wire format so those providers can replay it unchanged
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall names the function to run and carries its JSON-encoded arguments
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // Usage represents token usage information
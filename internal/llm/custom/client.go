@@ -0,0 +1,51 @@
+package custom
+
+import (
+	"encoding/json"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// ChatRequest is the OpenAI chat completions payload. only fields that
+// OpenAI-compatible servers broadly accept are included
+type ChatRequest struct {
+	Model          string              `json:"model"`
+	Messages       []common.Message    `json:"messages"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+	MaxTokens      *int                `json:"max_tokens,omitempty"`
+	TopP           *float64            `json:"top_p,omitempty"`
+	Stop           []string            `json:"stop,omitempty"`
+	Seed           *int                `json:"seed,omitempty"`
+	Stream         *bool               `json:"stream,omitempty"`
+	ResponseFormat *ChatResponseFormat `json:"response_format,omitempty"`
+	Tools          []common.ToolConfig `json:"tools,omitempty"`
+	ToolChoice     interface{}         `json:"tool_choice,omitempty"`
+}
+
+// ChatResponseFormat is the OpenAI response_format wire shape:
+//   - legacy: {"type": "json_object"}
+//   - envelope: {"type": "json_schema", "json_schema": {name, schema, strict}}
+type ChatResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaConfig `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaConfig is the nested body of the json_schema envelope
+type JSONSchemaConfig struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict *bool           `json:"strict,omitempty"`
+}
+
+// ErrorResponse is the OpenAI-style error body. servers vary, so HandleError
+// also accepts a plain string in "error" or a top-level "message"
+type ErrorResponse struct {
+	Error   json.RawMessage `json:"error"`
+	Message string          `json:"message"`
+}
+
+// ErrorDetail contains the error details of an OpenAI-style error object
+type ErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,108 @@
+package custom
+
+import "github.com/chriscorrea/slop/internal/llm/common"
+
+// Extension holds custom provider-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
+	Seed *int // deterministic sampling; honored by vLLM and llama.cpp
+}
+
+var _ common.Extension = (*Extension)(nil)
+
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "custom"
+}
+
+// Validate checks the parameters against the values custom provider accepts
+func (e *Extension) Validate() error {
+	return nil
+}
+
+// GenerateOption configures a request for custom provider
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// extension returns the request's custom provider parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
+// WithSeed sets the sampling seed
+func WithSeed(seed int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
+	}
+}
+
+// Common options
+
+// WithTemperature sets response randomness
+func WithTemperature(temp float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTemperature(temp)(&c.GenerateOptions)
+	}
+}
+
+// WithTopP sets nucleus sampling threshold
+func WithTopP(topP float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTopP(topP)(&c.GenerateOptions)
+	}
+}
+
+// WithMaxTokens sets max tokens to generate
+func WithMaxTokens(maxTokens int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
+	}
+}
+
+// WithStop sets stop sequences to halt generation
+func WithStop(stop []string) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStop(stop)(&c.GenerateOptions)
+	}
+}
+
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
+// WithJSONFormat requests a JSON object response
+func WithJSONFormat() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithJSONFormat()(&c.GenerateOptions)
+	}
+}
+
+// WithSchema requests schema-constrained JSON output using the json_schema envelope
+func WithSchema(name string, schema []byte) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithSchema(name, schema)(&c.GenerateOptions)
+	}
+}
+
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
+}
@@ -0,0 +1,353 @@
+// Package custom provides a client for OpenAI-compatible chat completions
+// servers declared under [providers.custom.<name>], such as vLLM, LM Studio
+// and llama.cpp. one Provider is registered per entry at config load.
+//
+// Example config:
+//   [providers.custom.vllm]
+//   base_url = "http://gpu-box:8000/v1"
+//   api_key = "token"
+//   supports_streaming = true
+//   supports_tools = true
+//
+//   [providers.custom.vllm.headers]
+//   X-Team = "research"
+
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// Provider implements the unified registry.Provider interface for one custom
+// OpenAI-compatible server
+type Provider struct {
+	name     string
+	settings config.CustomProvider
+}
+
+// ensure Provider implements the common.Provider interface
+var _ common.Provider = (*Provider)(nil)
+
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider reports image support
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// New creates a provider for the named providers.custom entry
+func New(name string, settings config.CustomProvider) *Provider {
+	return &Provider{name: name, settings: settings}
+}
+
+// CreateClient creates a new LLM client using the unified adapter pattern
+func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if p.settings.BaseUrl == "" {
+		return nil, fmt.Errorf("providers.custom.%s.base_url is required", p.name)
+	}
+
+	// create client options
+	var opts []common.ClientOption
+	if logger != nil {
+		opts = append(opts, common.WithLogger(logger))
+	}
+	// use provider-specific MaxRetries, fall back to global if not set
+	maxRetries := p.settings.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = cfg.Parameters.MaxRetries
+	}
+	if maxRetries > 5 {
+		maxRetries = 5 // enforce maximum limit
+	}
+	if maxRetries > 0 {
+		opts = append(opts, common.WithMaxRetries(maxRetries))
+	}
+
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(p.settings.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(p.settings.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.custom.%s: %w", p.name, err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, p.settings.APIKey, p.settings.BaseUrl, opts...)
+	return adapterClient, nil
+}
+
+// BuildOptions creates generation options from configuration, leaving out
+// anything the server was not declared to support
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
+	var functionalOpts []GenerateOption
+
+	if cfg.Parameters.Temperature > 0 {
+		functionalOpts = append(functionalOpts, WithTemperature(cfg.Parameters.Temperature))
+	}
+	if cfg.Parameters.MaxTokens > 0 {
+		functionalOpts = append(functionalOpts, WithMaxTokens(cfg.Parameters.MaxTokens))
+	}
+	if cfg.Parameters.TopP > 0 {
+		functionalOpts = append(functionalOpts, WithTopP(cfg.Parameters.TopP))
+	}
+	if len(cfg.Parameters.StopSequences) > 0 {
+		functionalOpts = append(functionalOpts, WithStop(cfg.Parameters.StopSequences))
+	}
+	if cfg.Parameters.Seed != nil {
+		functionalOpts = append(functionalOpts, WithSeed(*cfg.Parameters.Seed))
+	}
+	if cfg.Parameters.Stream && p.settings.SupportsStreaming {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if p.settings.SupportsTools {
+		if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+			functionalOpts = append(functionalOpts, WithTools(tools))
+		}
+	}
+
+	// schema takes precedence over the plain json_object toggle
+	if p.settings.SupportsJSON {
+		if schema := cfg.Parameters.ResponseSchema; schema != "" {
+			functionalOpts = append(functionalOpts, WithSchema("response", []byte(schema)))
+		} else if cfg.Format.JSON {
+			functionalOpts = append(functionalOpts, WithJSONFormat())
+		}
+	}
+
+	return NewGenerateOptions(functionalOpts...)
+}
+
+// RequiresAPIKey returns false; local servers usually run without one
+func (p *Provider) RequiresAPIKey() bool {
+	return false
+}
+
+// ProviderName returns the name of the providers.custom entry
+func (p *Provider) ProviderName() string {
+	return p.name
+}
+
+// BuildRequest creates an OpenAI chat completions request from messages and options
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes the server's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	// log the API request
+	common.LogAPIRequest(logger, p.name, modelName, messages, &config.GenerateOptions)
+
+	requestBody := &ChatRequest{
+		Model:       modelName,
+		Messages:    messages,
+		Temperature: config.Temperature,
+		MaxTokens:   config.MaxTokens,
+		TopP:        config.TopP,
+		Stop:        config.Stop,
+		Seed:        ext.Seed,
+	}
+	if config.Stream {
+		requestBody.Stream = common.BoolPtr(true)
+	}
+	if config.ResponseFormat != nil {
+		requestBody.ResponseFormat = buildResponseFormat(config.ResponseFormat)
+	}
+
+	// tool definitions already use the OpenAI-compatible wire shape
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
+		requestBody.ToolChoice = config.ToolChoice
+	}
+
+	return requestBody, nil
+}
+
+// buildResponseFormat converts a canonical common.ResponseFormat into the
+// OpenAI wire shape, nesting json_schema requests in their envelope
+func buildResponseFormat(rf *common.ResponseFormat) *ChatResponseFormat {
+	if rf.Type == "json_schema" && len(rf.Schema) > 0 {
+		return &ChatResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchemaConfig{
+				Name:   rf.Name,
+				Schema: rf.Schema,
+				Strict: rf.Strict,
+			},
+		}
+	}
+	return &ChatResponseFormat{Type: rf.Type}
+}
+
+// ParseResponse parses a chat completions response and extracts content and usage
+func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *common.Usage, error) {
+	var chatResp common.ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return "", nil, fmt.Errorf("failed to unmarshal %s response: %w", p.name, err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", nil, fmt.Errorf("no choices in %s response", p.name)
+	}
+
+	return chatResp.Choices[0].Message.Content, &chatResp.Usage, nil
+}
+
+// ParseToolCalls extracts the tool calls requested by a chat completions response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	return common.ParseChatCompletionToolCalls(body, p.name, logger)
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	return common.ParseChatCompletionMetadata(body)
+}
+
+// StreamFormat reports that OpenAI-compatible servers stream server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed chat completion chunk
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	return common.ParseChatCompletionChunk(data, p.name, logger)
+}
+
+// SupportsImages reports whether the server was declared to accept image inputs
+func (p *Provider) SupportsImages(modelName string) bool {
+	return p.settings.SupportsImages
+}
+
+// HandleError creates error messages from HTTP error responses
+func (p *Provider) HandleError(statusCode int, body []byte) error {
+	message := errorMessage(body)
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf(`%s authentication failed (status %d).
+
+Check providers.custom.%s.api_key and any auth headers.`, p.name, statusCode, p.name)
+
+	case http.StatusNotFound:
+		if message == "" {
+			message = "not found"
+		}
+		return fmt.Errorf(`%s request failed: %s
+
+Check the model name and that providers.custom.%s.base_url ends in the API root (often /v1).`, p.name, message, p.name)
+
+	default:
+		if message != "" {
+			return fmt.Errorf("%s error (status %d): %s", p.name, statusCode, message)
+		}
+		return fmt.Errorf("%s request failed with status %d", p.name, statusCode)
+	}
+}
+
+// errorMessage extracts the message from an OpenAI-style error body, or from
+// the plain-string and top-level variants some servers return
+func errorMessage(body []byte) string {
+	var errorResp ErrorResponse
+	if len(body) == 0 || json.Unmarshal(body, &errorResp) != nil {
+		return ""
+	}
+
+	var detail ErrorDetail
+	if json.Unmarshal(errorResp.Error, &detail) == nil && detail.Message != "" {
+		return detail.Message
+	}
+	var plain string
+	if json.Unmarshal(errorResp.Error, &plain) == nil && plain != "" {
+		return plain
+	}
+	return errorResp.Message
+}
+
+// CustomizeRequest drops the empty bearer token when no API key is set, since
+// some servers reject it. configured headers are added by the HTTP client
+func (p *Provider) CustomizeRequest(req *http.Request) error {
+	if p.settings.APIKey == "" {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+// HandleConnectionError creates error messages for connection failures
+func (p *Provider) HandleConnectionError(err error) error {
+	return fmt.Errorf(`Failed to connect to %s at %s.
+
+Check that the server is running and providers.custom.%s.base_url is correct.
+
+Error: %w`, p.name, p.settings.BaseUrl, p.name, err)
+}
+
+// ListModels lists the server's models via GET /models, sending the API key
+// only when one is configured
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if p.settings.BaseUrl == "" {
+		return nil, fmt.Errorf("providers.custom.%s.base_url is required", p.name)
+	}
+
+	headers := map[string]string{}
+	if p.settings.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.settings.APIKey
+	}
+	client, err := common.NewAPIClient(p.settings.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(p.settings.BaseUrl), headers, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Capabilities reports the features declared in the server's settings;
+// every model on a server is alike
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: false,
+		Schema:   p.settings.SupportsJSON,
+		JSON:     p.settings.SupportsJSON,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    p.settings.SupportsTools,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies reports one family covering every model
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "all models"},
+	}
+}
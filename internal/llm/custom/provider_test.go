@@ -0,0 +1,241 @@
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func settings(baseURL string) config.CustomProvider {
+	return config.CustomProvider{BaseProvider: config.BaseProvider{BaseUrl: baseURL}}
+}
+
+func TestProvider_Methods(t *testing.T) {
+	p := New("vllm", settings("http://localhost:8000/v1"))
+	assert.Equal(t, "vllm", p.ProviderName())
+	assert.False(t, p.RequiresAPIKey())
+	assert.False(t, p.SupportsImages("llava"))
+
+	vision := New("lmstudio", config.CustomProvider{SupportsImages: true})
+	assert.True(t, vision.SupportsImages("llava"))
+}
+
+func TestProvider_CreateClient(t *testing.T) {
+	p := New("vllm", settings("http://localhost:8000/v1"))
+
+	client, err := p.CreateClient(&config.Config{}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+
+	_, err = p.CreateClient(nil, nil)
+	assert.ErrorContains(t, err, "config cannot be nil")
+
+	_, err = New("vllm", config.CustomProvider{}).CreateClient(&config.Config{}, nil)
+	assert.ErrorContains(t, err, "providers.custom.vllm.base_url")
+}
+
+func TestProvider_BuildOptions(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Parameters.Stream = true
+	cfg.Parameters.ResponseSchema = `{"type":"object"}`
+	cfg.Tools = map[string]config.Tool{"date": {Command: "date"}}
+	cfg.Parameters.Tools = []string{"date"}
+
+	optionsFor := func(s config.CustomProvider) *common.GenerateRequest {
+		opts := New("vllm", s).BuildOptions(cfg)
+		require.NotNil(t, opts)
+		return opts
+	}
+
+	t.Run("capabilities off", func(t *testing.T) {
+		opts := optionsFor(config.CustomProvider{})
+		require.NotNil(t, opts.Temperature)
+		assert.Equal(t, cfg.Parameters.Temperature, *opts.Temperature)
+		assert.False(t, opts.Stream)
+		assert.Empty(t, opts.Tools)
+		assert.Nil(t, opts.ResponseFormat)
+	})
+
+	t.Run("capabilities on", func(t *testing.T) {
+		opts := optionsFor(config.CustomProvider{SupportsStreaming: true, SupportsTools: true, SupportsJSON: true})
+		assert.True(t, opts.Stream)
+		require.Len(t, opts.Tools, 1)
+		assert.Equal(t, "date", opts.Tools[0].Function.Name)
+		require.NotNil(t, opts.ResponseFormat)
+		assert.Equal(t, "json_schema", opts.ResponseFormat.Type)
+	})
+}
+
+func TestProvider_BuildRequest(t *testing.T) {
+	p := New("vllm", settings("http://localhost:8000/v1"))
+	messages := []common.Message{{Role: "user", Content: "hi"}}
+
+	t.Run("options", func(t *testing.T) {
+		opts := NewGenerateOptions(WithTemperature(0.2), WithMaxTokens(64), WithSeed(7), WithStream(), WithSchema("response", []byte(`{"type":"object"}`)))
+		request, err := p.BuildRequest(messages, "meta-llama/Llama-3.1-8B-Instruct", opts, nil)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+		var wire map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &wire))
+
+		assert.Equal(t, "meta-llama/Llama-3.1-8B-Instruct", wire["model"])
+		assert.Equal(t, 0.2, wire["temperature"])
+		assert.Equal(t, float64(64), wire["max_tokens"])
+		assert.Equal(t, float64(7), wire["seed"])
+		assert.Equal(t, true, wire["stream"])
+		format := wire["response_format"].(map[string]interface{})
+		assert.Equal(t, "json_schema", format["type"])
+		assert.Equal(t, "response", format["json_schema"].(map[string]interface{})["name"])
+	})
+
+	t.Run("no options", func(t *testing.T) {
+		request, err := p.BuildRequest(messages, "llama", nil, nil)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"model":"llama","messages":[{"role":"user","content":"hi"}]}`, string(body))
+	})
+}
+
+func TestProvider_HandleError(t *testing.T) {
+	p := New("llamacpp", settings("http://localhost:8080/v1"))
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"openai error object", http.StatusBadRequest, `{"error":{"type":"invalid_request_error","message":"context length exceeded"}}`, "context length exceeded"},
+		{"plain string error", http.StatusInternalServerError, `{"error":"model not loaded"}`, "model not loaded"},
+		{"top-level message", http.StatusServiceUnavailable, `{"message":"Loading model"}`, "Loading model"},
+		{"no body", http.StatusBadGateway, ``, "status 502"},
+		{"unauthorized", http.StatusUnauthorized, ``, "providers.custom.llamacpp.api_key"},
+		{"not found", http.StatusNotFound, `{"error":{"message":"model 'x' not found"}}`, "model 'x' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.HandleError(tt.status, []byte(tt.body))
+			assert.ErrorContains(t, err, tt.want)
+			assert.True(t, strings.HasPrefix(err.Error(), "llamacpp"), err.Error())
+		})
+	}
+}
+
+func TestProvider_CustomizeRequest(t *testing.T) {
+	t.Run("key", func(t *testing.T) {
+		s := settings("http://localhost:8000/v1")
+		s.APIKey = "secret"
+
+		req, err := common.CreateJSONRequest(context.Background(), "http://localhost:8000/v1/chat/completions", s.APIKey, nil)
+		require.NoError(t, err)
+		require.NoError(t, New("vllm", s).CustomizeRequest(req))
+
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		req, err := common.CreateJSONRequest(context.Background(), "http://localhost:8000/v1/chat/completions", "", nil)
+		require.NoError(t, err)
+		require.NoError(t, New("vllm", settings("http://localhost:8000/v1")).CustomizeRequest(req))
+
+		assert.Empty(t, req.Header.Values("Authorization"))
+	})
+}
+
+func TestProvider_HandleConnectionError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := New("lmstudio", settings("http://localhost:1234/v1")).HandleConnectionError(cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorContains(t, err, "http://localhost:1234/v1")
+}
+
+func TestProvider_EndToEnd(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+			assert.Equal(t, "research", r.Header.Get("X-Team"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			var req ChatRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "qwen2.5-7b", req.Model)
+
+			fmt.Fprint(w, `{"model":"qwen2.5-7b-instruct","choices":[{"index":0,"message":{"role":"assistant","content":"hello from vllm"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`)
+		}))
+		defer server.Close()
+
+		s := settings(server.URL + "/v1")
+		s.Headers = map[string]string{"x-team": "research"}
+		client, err := New("vllm", s).CreateClient(&config.Config{}, nil)
+		require.NoError(t, err)
+
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "qwen2.5-7b", NewGenerateOptions())
+		require.NoError(t, err)
+		assert.Equal(t, "hello from vllm", result.Content)
+		assert.Equal(t, "qwen2.5-7b-instruct", result.Model)
+		assert.Equal(t, "stop", result.FinishReason)
+		assert.Equal(t, 7, result.Usage.TotalTokens)
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		s := settings(server.URL)
+		s.SupportsStreaming = true
+		client, err := New("llamacpp", s).CreateClient(&config.Config{}, nil)
+		require.NoError(t, err)
+
+		var chunks []string
+		req := NewGenerateOptions(WithStream())
+		req.OnChunk = func(chunk common.StreamChunk) { chunks = append(chunks, chunk.Content) }
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "local", req)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result.Content)
+		assert.Equal(t, []string{"hel", "lo"}, chunks)
+	})
+
+	t.Run("rejects images when unsupported", func(t *testing.T) {
+		client, err := New("vllm", settings("http://127.0.0.1:1")).CreateClient(&config.Config{}, nil)
+		require.NoError(t, err)
+
+		messages := []common.Message{{Role: "user", Content: "what is this?", Images: []common.Image{{MediaType: "image/png", Data: []byte{1}}}}}
+		_, err = client.Generate(context.Background(), messages, "llava", NewGenerateOptions())
+		assert.ErrorContains(t, err, "does not accept image inputs")
+	})
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data":[{"id":"qwen2.5-7b-instruct"}]}`))
+	}))
+	defer server.Close()
+
+	p := New("vllm", settings(server.URL+"/v1"))
+	models, err := p.ListModels(context.Background(), &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qwen2.5-7b-instruct"}, models)
+}
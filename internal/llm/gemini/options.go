@@ -0,0 +1,159 @@
+package gemini
+
+import (
+	"fmt"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// Extension holds Gemini-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
+	TopK   *int   // top-k sampling
+	Seed   *int   // deterministic sampling
+	System string // system instruction (separate from the conversation)
+
+	// ThinkingBudget overrides the thinkingBudget Gemini 2.5 models receive
+	// when thinking is requested. When zero, the adapter derives a budget
+	// from the cross-provider ThinkingLevel (medium: 8192, high: 24576)
+	ThinkingBudget int
+}
+
+var _ common.Extension = (*Extension)(nil)
+
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "gemini"
+}
+
+// Validate checks the parameters against the values Gemini accepts
+func (e *Extension) Validate() error {
+	if e.TopK != nil && *e.TopK <= 0 {
+		return fmt.Errorf("top_k must be positive, got %d", *e.TopK)
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Gemini
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// extension returns the request's Gemini parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
+// WithTopK sets top-k sampling parameter
+func WithTopK(topK int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).TopK = &topK
+	}
+}
+
+// WithSeed sets the sampling seed
+func WithSeed(seed int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
+	}
+}
+
+// WithSystem sets the system instruction
+func WithSystem(system string) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).System = system
+	}
+}
+
+// WithThinkingBudget overrides the thinkingBudget Gemini 2.5 models receive.
+// A value of zero lets the adapter pick a default from the ThinkingLevel
+func WithThinkingBudget(budget int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).ThinkingBudget = budget
+	}
+}
+
+// WithThinking sets the cross-provider thinking level. the adapter translates
+// it into a thinking budget or, for Gemini 3 models, a thinking level
+func WithThinking(level common.ThinkingLevel) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithThinking(level)(&c.GenerateOptions)
+	}
+}
+
+// WithSchema requests schema-constrained JSON output via responseSchema
+func WithSchema(name string, schema []byte) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithSchema(name, schema)(&c.GenerateOptions)
+	}
+}
+
+// Common options
+
+// WithTemperature sets response randomness
+func WithTemperature(temp float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTemperature(temp)(&c.GenerateOptions)
+	}
+}
+
+// WithTopP sets nucleus sampling threshold
+func WithTopP(topP float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTopP(topP)(&c.GenerateOptions)
+	}
+}
+
+// WithMaxTokens sets max tokens to generate
+func WithMaxTokens(maxTokens int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
+	}
+}
+
+// WithStop sets stop sequences to halt generation
+func WithStop(stop []string) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStop(stop)(&c.GenerateOptions)
+	}
+}
+
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
+// WithJSONFormat requests a JSON response (responseMimeType application/json)
+func WithJSONFormat() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithJSONFormat()(&c.GenerateOptions)
+	}
+}
+
+// WithResponseFormat sets structured output format
+func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithResponseFormat(format)(&c.GenerateOptions)
+	}
+}
+
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
+}
@@ -0,0 +1,668 @@
+// Package gemini provides a client implementation for the Google Gemini API.
+//
+// API Reference: https://ai.google.dev/api/generate-content
+// Authentication: providers.gemini.api_key or GEMINI_API_KEY (or GOOGLE_API_KEY) environment variable
+//
+// Example usage:
+//   client := gemini.NewClient(apiKey)
+//   response, err := client.Generate(ctx, messages, model, gemini.NewGenerateOptions(gemini.WithTemperature(0.7)))
+//
+// Gemini model documentation: https://ai.google.dev/gemini-api/docs/models
+
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// Provider implements the unified registry.Provider interface for Gemini
+type Provider struct{}
+
+// ensure Provider implements the common.Provider interface
+var _ common.Provider = (*Provider)(nil)
+
+// ensure Provider addresses the model's generateContent endpoint
+var _ common.EndpointProvider = (*Provider)(nil)
+
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// thinking budget defaults keyed on the cross-provider ThinkingLevel.
+// 24576 is the largest budget Gemini 2.5 Flash accepts
+const (
+	thinkingBudgetMedium = 8192
+	thinkingBudgetHigh   = 24576
+)
+
+// skipThoughtSignature stands in for the thought signature Gemini 3 expects on
+// replayed function calls; slop does not keep the original signatures
+const skipThoughtSignature = "skip_thought_signature_validator"
+
+// unsupportedSchemaKeys are JSON schema keywords responseSchema rejects
+var unsupportedSchemaKeys = []string{"$schema", "$id", "additionalProperties"}
+
+// defaultBaseURL is used when providers.gemini.base_url is unset
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// New creates a new Gemini provider instance
+func New() *Provider {
+	return &Provider{}
+}
+
+// CreateClient creates a new LLM client using the unified adapter pattern
+func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if cfg.Providers.Gemini.APIKey == "" {
+		return nil, fmt.Errorf(`Gemini API key is required.
+
+You can set the API key using the environment variable GEMINI_API_KEY or via slop config set gemini-key=<your_api_key>
+Get an API key from https://aistudio.google.com/apikey`)
+	}
+
+	// create client options
+	var opts []common.ClientOption
+	if cfg.Providers.Gemini.BaseUrl != "" {
+		opts = append(opts, common.WithBaseURL(cfg.Providers.Gemini.BaseUrl))
+	}
+	if logger != nil {
+		opts = append(opts, common.WithLogger(logger))
+	}
+	// use provider-specific MaxRetries, fall back to global if not set
+	maxRetries := cfg.Providers.Gemini.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = cfg.Parameters.MaxRetries
+	}
+	if maxRetries > 5 {
+		maxRetries = 5 // enforce maximum limit
+	}
+	if maxRetries > 0 {
+		opts = append(opts, common.WithMaxRetries(maxRetries))
+	}
+
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Gemini.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Gemini.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.gemini: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Gemini.APIKey, defaultBaseURL, opts...)
+	return adapterClient, nil
+}
+
+// BuildOptions creates Gemini-specific generation options from configuration
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
+	var functionalOpts []GenerateOption
+
+	if cfg.Parameters.SystemPrompt != "" {
+		functionalOpts = append(functionalOpts, WithSystem(cfg.Parameters.SystemPrompt))
+	}
+	if cfg.Parameters.Temperature > 0 {
+		functionalOpts = append(functionalOpts, WithTemperature(cfg.Parameters.Temperature))
+	}
+	if cfg.Parameters.MaxTokens > 0 {
+		functionalOpts = append(functionalOpts, WithMaxTokens(cfg.Parameters.MaxTokens))
+	}
+	if cfg.Parameters.TopP > 0 {
+		functionalOpts = append(functionalOpts, WithTopP(cfg.Parameters.TopP))
+	}
+	if len(cfg.Parameters.StopSequences) > 0 {
+		functionalOpts = append(functionalOpts, WithStop(cfg.Parameters.StopSequences))
+	}
+	if cfg.Parameters.Seed != nil {
+		functionalOpts = append(functionalOpts, WithSeed(*cfg.Parameters.Seed))
+	}
+	if cfg.Format.JSON {
+		functionalOpts = append(functionalOpts, WithJSONFormat())
+	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
+
+	// translated into a thinking budget or level at request build time
+	if level, err := common.ParseThinkingLevel(cfg.Parameters.Thinking); err == nil && level != common.ThinkingOff {
+		functionalOpts = append(functionalOpts, WithThinking(level))
+	}
+
+	// schema takes precedence over the plain JSON toggle
+	if schema := strings.TrimSpace(cfg.Parameters.ResponseSchema); schema != "" {
+		functionalOpts = append(functionalOpts, WithSchema("response", []byte(schema)))
+	}
+
+	return NewGenerateOptions(functionalOpts...)
+}
+
+// RequiresAPIKey returns true since Gemini requires an API key
+func (p *Provider) RequiresAPIKey() bool {
+	return true
+}
+
+// ProviderName returns the name of this provider
+func (p *Provider) ProviderName() string {
+	return "gemini"
+}
+
+// RequestURL addresses the model's generateContent method, or its
+// server-sent events stream when streaming
+func (p *Provider) RequestURL(baseURL, modelName string, stream bool) string {
+	model := strings.TrimPrefix(modelName, "models/")
+	base := strings.TrimSuffix(baseURL, "/")
+	if stream {
+		return fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", base, model)
+	}
+	return fmt.Sprintf("%s/models/%s:generateContent", base, model)
+}
+
+// supportsThinkingBudget reports whether the model takes a thinkingBudget
+func supportsThinkingBudget(modelID string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(modelID, "models/"), "gemini-2.5")
+}
+
+// supportsThinkingLevel reports whether the model takes a thinkingLevel
+func supportsThinkingLevel(modelID string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(modelID, "models/"), "gemini-3")
+}
+
+// thinkingBudget returns the default budget for a thinking level
+func thinkingBudget(level common.ThinkingLevel) int {
+	if level == common.ThinkingHigh {
+		return thinkingBudgetHigh
+	}
+	return thinkingBudgetMedium
+}
+
+// thinkingLevel maps the cross-provider level onto Gemini 3's low|high
+func thinkingLevel(level common.ThinkingLevel) string {
+	if level == common.ThinkingHigh {
+		return "high"
+	}
+	return "low"
+}
+
+// BuildRequest creates a Gemini generateContent request from messages and options
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Gemini's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
+	}
+
+	// log the API request using common utilities
+	common.LogAPIRequest(logger, "Gemini", modelName, messages, &config.GenerateOptions)
+
+	// system messages become the system instruction
+	var systemParts []string
+	var conversation []common.Message
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			systemParts = append(systemParts, msg.Content)
+			continue
+		}
+		conversation = append(conversation, msg)
+	}
+	if len(systemParts) == 0 && ext.System != "" {
+		systemParts = append(systemParts, ext.System)
+	}
+
+	requestBody := &GenerateContentRequest{
+		Contents: convertMessages(conversation),
+	}
+	if len(systemParts) > 0 {
+		requestBody.SystemInstruction = &Content{Parts: []Part{{Text: strings.Join(systemParts, "\n\n")}}}
+	}
+
+	// map common generation options onto generationConfig
+	genConfig := &GenerationConfig{
+		Temperature:     config.Temperature,
+		TopP:            config.TopP,
+		TopK:            ext.TopK,
+		MaxOutputTokens: config.MaxTokens,
+		StopSequences:   config.Stop,
+		Seed:            ext.Seed,
+	}
+
+	// structured output: json_object maps to the JSON mime type, and a
+	// schema additionally goes on responseSchema
+	jsonOutput := false
+	if rf := config.ResponseFormat; rf != nil {
+		jsonOutput = true
+		genConfig.ResponseMimeType = "application/json"
+		if rf.Type == "json_schema" && len(rf.Schema) > 0 {
+			schema, err := responseSchema(rf.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response schema for Gemini: %w", err)
+			}
+			genConfig.ResponseSchema = schema
+		}
+	}
+
+	// thinking: Gemini 2.5 takes a token budget, Gemini 3 a level. other
+	// models silently no-op so a default --thinking survives switching.
+	// thoughts are only returned when the answer need not parse as JSON
+	if config.Thinking != common.ThinkingOff {
+		switch {
+		case supportsThinkingLevel(modelName):
+			genConfig.ThinkingConfig = &ThinkingConfig{ThinkingLevel: thinkingLevel(config.Thinking), IncludeThoughts: !jsonOutput}
+		case supportsThinkingBudget(modelName):
+			budget := ext.ThinkingBudget
+			if budget <= 0 {
+				budget = thinkingBudget(config.Thinking)
+			}
+			genConfig.ThinkingConfig = &ThinkingConfig{ThinkingBudget: &budget, IncludeThoughts: !jsonOutput}
+		}
+	}
+
+	if !genConfig.isEmpty() {
+		requestBody.GenerationConfig = genConfig
+	}
+
+	// map tool definitions onto a single functionDeclarations group
+	if len(config.Tools) > 0 {
+		declarations := make([]FunctionDeclaration, 0, len(config.Tools))
+		for _, tool := range config.Tools {
+			declarations = append(declarations, FunctionDeclaration{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			})
+		}
+		requestBody.Tools = []Tool{{FunctionDeclarations: declarations}}
+	}
+
+	return requestBody, nil
+}
+
+// responseSchema strips the JSON schema keywords Gemini's OpenAPI-style
+// responseSchema rejects, at any depth
+func responseSchema(raw []byte) (json.RawMessage, error) {
+	var schema interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+
+	var strip func(node interface{})
+	strip = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			for _, key := range unsupportedSchemaKeys {
+				delete(v, key)
+			}
+			for _, child := range v {
+				strip(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				strip(child)
+			}
+		}
+	}
+	strip(schema)
+
+	return json.Marshal(schema)
+}
+
+// convertMessages maps common messages onto Gemini turns. assistant turns use
+// the "model" role, tool calls become functionCall parts, and consecutive tool
+// results are merged into one user turn of functionResponse parts
+func convertMessages(messages []common.Message) []Content {
+	converted := make([]Content, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.Role == "assistant":
+			var parts []Part
+			if msg.Content != "" {
+				parts = append(parts, Part{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				args := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(args) {
+					args = json.RawMessage(`{}`)
+				}
+				parts = append(parts, Part{
+					FunctionCall:     &FunctionCall{Name: call.Function.Name, Args: args},
+					ThoughtSignature: skipThoughtSignature,
+				})
+			}
+			if len(parts) == 0 {
+				parts = append(parts, Part{Text: ""})
+			}
+			converted = append(converted, Content{Role: "model", Parts: parts})
+
+		case msg.Role == "tool":
+			// functionResponse takes an object; tool output is plain text
+			response, _ := json.Marshal(map[string]string{"content": msg.Content})
+			part := Part{FunctionResponse: &FunctionResponse{Name: msg.ToolName, Response: response}}
+			if last := len(converted) - 1; last >= 0 && converted[last].Role == "user" && converted[last].Parts[0].FunctionResponse != nil {
+				converted[last].Parts = append(converted[last].Parts, part)
+				continue
+			}
+			converted = append(converted, Content{Role: "user", Parts: []Part{part}})
+
+		default:
+			parts := make([]Part, 0, len(msg.Images)+1)
+			for _, image := range msg.Images {
+				parts = append(parts, Part{InlineData: &InlineData{MimeType: image.MediaType, Data: image.Base64()}})
+			}
+			if msg.Content != "" || len(parts) == 0 {
+				parts = append(parts, Part{Text: msg.Content})
+			}
+			converted = append(converted, Content{Role: "user", Parts: parts})
+		}
+	}
+	return converted
+}
+
+// splitParts separates a candidate's answer text from its thought summaries
+func splitParts(parts []Part) (text, thinking string) {
+	var textParts, thoughtParts []string
+	for _, part := range parts {
+		if part.Text == "" {
+			continue
+		}
+		if part.Thought {
+			thoughtParts = append(thoughtParts, part.Text)
+		} else {
+			textParts = append(textParts, part.Text)
+		}
+	}
+	return strings.Join(textParts, ""), strings.Join(thoughtParts, "")
+}
+
+// convertUsage maps usageMetadata onto common usage; thoughts count as completion
+func convertUsage(meta *UsageMetadata) *common.Usage {
+	if meta == nil {
+		return nil
+	}
+	completion := meta.CandidatesTokenCount + meta.ThoughtsTokenCount
+	total := meta.TotalTokenCount
+	if total == 0 {
+		total = meta.PromptTokenCount + completion
+	}
+	return &common.Usage{
+		PromptTokens:     meta.PromptTokenCount,
+		CompletionTokens: completion,
+		TotalTokens:      total,
+	}
+}
+
+// ParseResponse parses a Gemini generateContent response and extracts content
+// and usage. thought summaries are re-inlined as a <think>...</think> prefix
+// so the thinking filter treats them like any other provider's
+func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *common.Usage, error) {
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return "", nil, fmt.Errorf("failed to unmarshal Gemini response: %w", err)
+	}
+
+	if len(geminiResp.Candidates) == 0 {
+		if feedback := geminiResp.PromptFeedback; feedback != nil && feedback.BlockReason != "" {
+			return "", nil, fmt.Errorf("Gemini blocked the prompt: %s", feedback.BlockReason)
+		}
+		return "", nil, fmt.Errorf("no candidates in Gemini response")
+	}
+
+	candidate := geminiResp.Candidates[0]
+	content, thinking := splitParts(candidate.Content.Parts)
+
+	// a function call turn may carry no text at all
+	hasCalls := false
+	for _, part := range candidate.Content.Parts {
+		hasCalls = hasCalls || part.FunctionCall != nil
+	}
+	if content == "" && !hasCalls && candidate.FinishReason != "" && candidate.FinishReason != "STOP" {
+		return "", nil, fmt.Errorf("Gemini returned no content (finish reason %s)", candidate.FinishReason)
+	}
+
+	if thinking != "" {
+		content = "<think>" + thinking + "</think>\n" + content
+	}
+
+	return content, convertUsage(geminiResp.UsageMetadata), nil
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a Gemini response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return common.ResponseMetadata{}
+	}
+	meta := common.ResponseMetadata{Model: geminiResp.ModelVersion}
+	if len(geminiResp.Candidates) > 0 {
+		meta.FinishReason = geminiResp.Candidates[0].FinishReason
+	}
+	return meta
+}
+
+// StreamFormat reports that Gemini streams server-sent events (alt=sse)
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed Gemini response. every event is a
+// partial GenerateContentResponse; usage is cumulative, so the latest wins
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	var event GenerateContentResponse
+	if err := json.Unmarshal(data, &event); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(data))
+		return nil, fmt.Errorf("failed to unmarshal Gemini stream event: %w", err)
+	}
+
+	chunk := &common.StreamChunk{
+		Model: event.ModelVersion,
+		Usage: convertUsage(event.UsageMetadata),
+	}
+	if len(event.Candidates) > 0 {
+		candidate := event.Candidates[0]
+		chunk.Content, chunk.Thinking = splitParts(candidate.Content.Parts)
+		chunk.FinishReason = candidate.FinishReason
+	}
+	return chunk, nil
+}
+
+// ParseToolCalls extracts functionCall parts from a Gemini response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal Gemini response: %w", err)
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return nil, nil
+	}
+
+	var calls []common.ToolCall
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		if part.FunctionCall == nil {
+			continue
+		}
+		// older models send no call ids; results are matched by name anyway
+		id := part.FunctionCall.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", len(calls))
+		}
+		args := string(part.FunctionCall.Args)
+		if args == "" {
+			args = "{}"
+		}
+		calls = append(calls, common.ToolCall{
+			ID:       id,
+			Type:     "function",
+			Function: common.FunctionCall{Name: part.FunctionCall.Name, Arguments: args},
+		})
+	}
+	return calls, nil
+}
+
+// SupportsImages reports true; every current Gemini model accepts images
+func (p *Provider) SupportsImages(modelName string) bool {
+	return true
+}
+
+// HandleError creates Gemini-specific error messages from HTTP error responses
+func (p *Provider) HandleError(statusCode int, body []byte) error {
+	var errorResp ErrorResponse
+	_ = json.Unmarshal(body, &errorResp)
+	message := errorResp.Error.Message
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf(`Gemini API authentication failed.
+
+Check your API key and ensure it is set correctly.
+You can set the API key using the environment variable GEMINI_API_KEY or via slop config set gemini-key=<your_api_key>
+Get an API key from https://aistudio.google.com/apikey`)
+
+	case http.StatusBadRequest:
+		// Gemini reports an invalid key as a 400 rather than a 401
+		if strings.Contains(message, "API key") {
+			return fmt.Errorf(`Gemini API authentication failed: %s
+
+You can set the API key using the environment variable GEMINI_API_KEY or via slop config set gemini-key=<your_api_key>`, message)
+		}
+		if message != "" {
+			return fmt.Errorf("Gemini request error: %s", message)
+		}
+		return fmt.Errorf("Gemini request error: invalid request parameters")
+
+	case http.StatusNotFound:
+		return fmt.Errorf(`Gemini model not found.
+
+Please ensure you are using a valid model name such as gemini-2.5-flash.
+Available models can be found at https://ai.google.dev/gemini-api/docs/models`)
+
+	case http.StatusTooManyRequests:
+		return fmt.Errorf(`Gemini API rate limit or quota exceeded.
+
+Please try again later or check your quota at https://aistudio.google.com/`)
+
+	case http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf(`Gemini server error (status %d).
+
+This is likely a temporary issue; please try again later.`, statusCode)
+
+	default:
+		if message != "" {
+			return fmt.Errorf("Gemini error (status %d): %s", statusCode, message)
+		}
+		return fmt.Errorf("Gemini request failed with status %d", statusCode)
+	}
+}
+
+// HandleConnectionError handles connection failures - for cloud services, return original error
+func (p *Provider) HandleConnectionError(err error) error {
+	return err
+}
+
+// CustomizeRequest moves the API key from the bearer token to the
+// x-goog-api-key header Gemini expects
+func (p *Provider) CustomizeRequest(req *http.Request) error {
+	if apiKey, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		req.Header.Del("Authorization")
+		req.Header.Set("x-goog-api-key", apiKey)
+	}
+	return nil
+}
+
+// ListModels lists the models that support generateContent, without the
+// "models/" prefix the API returns
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Gemini.APIKey == "" {
+		return nil, fmt.Errorf("Gemini API key is required to list models")
+	}
+	baseURL := cfg.Providers.Gemini.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Gemini.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(baseURL)+"?pageSize=1000", map[string]string{
+		"x-goog-api-key": cfg.Providers.Gemini.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+
+	var listing struct {
+		Models []struct {
+			Name                       string   `json:"name"`
+			SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+
+	var models []string
+	for _, model := range listing.Models {
+		if slices.Contains(model.SupportedGenerationMethods, "generateContent") {
+			models = append(models, strings.TrimPrefix(model.Name, "models/"))
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// Capabilities reports the features a Gemini model honors
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsThinkingLevel(modelName) || supportsThinkingBudget(modelName),
+		Schema:   true,
+		JSON:     true,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists Gemini's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "gemini-3", Example: "gemini-3-pro-preview"},
+		{Name: "gemini-2.5", Example: "gemini-2.5-flash"},
+		{Name: "gemini-2.0", Example: "gemini-2.0-flash"},
+	}
+}
@@ -0,0 +1,454 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(baseURL string) *config.Config {
+	return &config.Config{
+		Providers: config.Providers{
+			Gemini: config.Gemini{
+				BaseProvider: config.BaseProvider{APIKey: "test-api-key", BaseUrl: baseURL},
+			},
+		},
+	}
+}
+
+// wireRequest marshals a built request and decodes it generically so tests
+// assert on Gemini's JSON field names
+func wireRequest(t *testing.T, request interface{}) map[string]interface{} {
+	t.Helper()
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	var wire map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &wire))
+	return wire
+}
+
+func TestProvider_Methods(t *testing.T) {
+	p := New()
+	assert.Equal(t, "gemini", p.ProviderName())
+	assert.True(t, p.RequiresAPIKey())
+	assert.True(t, p.SupportsImages("gemini-2.5-flash"))
+	assert.Equal(t, common.StreamSSE, p.StreamFormat())
+}
+
+func TestProvider_CreateClient(t *testing.T) {
+	p := New()
+
+	_, err := p.CreateClient(nil, nil)
+	assert.ErrorContains(t, err, "config cannot be nil")
+
+	_, err = p.CreateClient(&config.Config{}, nil)
+	assert.ErrorContains(t, err, "GEMINI_API_KEY")
+
+	client, err := p.CreateClient(testConfig(""), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestProvider_RequestURL(t *testing.T) {
+	p := New()
+	base := "https://generativelanguage.googleapis.com/v1beta"
+
+	assert.Equal(t, base+"/models/gemini-2.5-flash:generateContent", p.RequestURL(base, "gemini-2.5-flash", false))
+	assert.Equal(t, base+"/models/gemini-2.5-flash:streamGenerateContent?alt=sse", p.RequestURL(base+"/", "gemini-2.5-flash", true))
+	assert.Equal(t, base+"/models/gemini-2.5-pro:generateContent", p.RequestURL(base, "models/gemini-2.5-pro", false))
+}
+
+func TestProvider_BuildOptions(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Parameters.SystemPrompt = "be brief"
+	cfg.Parameters.Stream = true
+	cfg.Parameters.Thinking = "high"
+	cfg.Parameters.ResponseSchema = `{"type":"object"}`
+
+	genOpts := New().BuildOptions(cfg)
+	require.NotNil(t, genOpts)
+
+	ext, ok := genOpts.Extension.(*Extension)
+	require.True(t, ok)
+	assert.Equal(t, "be brief", ext.System)
+	assert.True(t, genOpts.Stream)
+	assert.Equal(t, common.ThinkingHigh, genOpts.Thinking)
+	require.NotNil(t, genOpts.ResponseFormat)
+	assert.Equal(t, "json_schema", genOpts.ResponseFormat.Type)
+}
+
+func TestProvider_BuildRequest(t *testing.T) {
+	p := New()
+
+	t.Run("messages and generation config", func(t *testing.T) {
+		messages := []common.Message{
+			{Role: "system", Content: "you are terse"},
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+			{Role: "user", Content: "again"},
+		}
+		opts := NewGenerateOptions(WithTemperature(0.3), WithTopP(0.9), WithTopK(40), WithMaxTokens(128), WithStop([]string{"END"}), WithSeed(7))
+
+		request, err := p.BuildRequest(messages, "gemini-2.0-flash", opts, nil)
+		require.NoError(t, err)
+		wire := wireRequest(t, request)
+
+		assert.Equal(t, map[string]interface{}{"parts": []interface{}{map[string]interface{}{"text": "you are terse"}}}, wire["systemInstruction"])
+		contents := wire["contents"].([]interface{})
+		require.Len(t, contents, 3)
+		assert.Equal(t, "user", contents[0].(map[string]interface{})["role"])
+		assert.Equal(t, "model", contents[1].(map[string]interface{})["role"])
+
+		genConfig := wire["generationConfig"].(map[string]interface{})
+		assert.Equal(t, 0.3, genConfig["temperature"])
+		assert.Equal(t, 0.9, genConfig["topP"])
+		assert.Equal(t, float64(40), genConfig["topK"])
+		assert.Equal(t, float64(128), genConfig["maxOutputTokens"])
+		assert.Equal(t, []interface{}{"END"}, genConfig["stopSequences"])
+		assert.Equal(t, float64(7), genConfig["seed"])
+	})
+
+	t.Run("config system prompt", func(t *testing.T) {
+		request, err := p.BuildRequest([]common.Message{{Role: "user", Content: "hi"}}, "gemini-2.5-flash", NewGenerateOptions(WithSystem("from config")), nil)
+		require.NoError(t, err)
+		req := request.(*GenerateContentRequest)
+		require.NotNil(t, req.SystemInstruction)
+		assert.Equal(t, "from config", req.SystemInstruction.Parts[0].Text)
+		assert.Nil(t, req.GenerationConfig)
+	})
+
+	t.Run("no options", func(t *testing.T) {
+		request, err := p.BuildRequest([]common.Message{{Role: "user", Content: "hi"}}, "gemini-2.5-flash", nil, nil)
+		require.NoError(t, err)
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`, string(body))
+	})
+
+	t.Run("images", func(t *testing.T) {
+		messages := []common.Message{{Role: "user", Content: "what is this?", Images: []common.Image{{MediaType: "image/png", Data: []byte{0x89, 0x50}}}}}
+		request, err := p.BuildRequest(messages, "gemini-2.5-flash", nil, nil)
+		require.NoError(t, err)
+
+		parts := request.(*GenerateContentRequest).Contents[0].Parts
+		require.Len(t, parts, 2)
+		require.NotNil(t, parts[0].InlineData)
+		assert.Equal(t, "image/png", parts[0].InlineData.MimeType)
+		assert.Equal(t, "iVA=", parts[0].InlineData.Data)
+		assert.Equal(t, "what is this?", parts[1].Text)
+	})
+}
+
+func TestBuildRequest_JSON(t *testing.T) {
+	p := New()
+	messages := []common.Message{{Role: "user", Content: "list colors"}}
+
+	t.Run("json object", func(t *testing.T) {
+		request, err := p.BuildRequest(messages, "gemini-2.5-flash", NewGenerateOptions(WithJSONFormat()), nil)
+		require.NoError(t, err)
+		genConfig := request.(*GenerateContentRequest).GenerationConfig
+		require.NotNil(t, genConfig)
+		assert.Equal(t, "application/json", genConfig.ResponseMimeType)
+		assert.Empty(t, genConfig.ResponseSchema)
+	})
+
+	t.Run("schema strips unsupported keywords", func(t *testing.T) {
+		schema := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","additionalProperties":false,"properties":{"colors":{"type":"array","items":{"type":"object","additionalProperties":false,"properties":{"name":{"type":"string"}}}}}}`
+		request, err := p.BuildRequest(messages, "gemini-2.5-flash", NewGenerateOptions(WithSchema("response", []byte(schema))), nil)
+		require.NoError(t, err)
+
+		genConfig := request.(*GenerateContentRequest).GenerationConfig
+		require.NotNil(t, genConfig)
+		assert.Equal(t, "application/json", genConfig.ResponseMimeType)
+		assert.JSONEq(t, `{"type":"object","properties":{"colors":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"}}}}}}`, string(genConfig.ResponseSchema))
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := p.BuildRequest(messages, "gemini-2.5-flash", NewGenerateOptions(WithSchema("response", []byte(`{not json`))), nil)
+		assert.ErrorContains(t, err, "invalid response schema")
+	})
+}
+
+func TestBuildRequest_Thinking(t *testing.T) {
+	p := New()
+	messages := []common.Message{{Role: "user", Content: "why?"}}
+
+	tests := []struct {
+		name   string
+		model  string
+		opts   []GenerateOption
+		expect *ThinkingConfig
+	}{
+		{"2.5 medium budget", "gemini-2.5-flash", []GenerateOption{WithThinking(common.ThinkingMedium)}, &ThinkingConfig{ThinkingBudget: common.IntPtr(8192), IncludeThoughts: true}},
+		{"2.5 high budget", "gemini-2.5-pro", []GenerateOption{WithThinking(common.ThinkingHigh)}, &ThinkingConfig{ThinkingBudget: common.IntPtr(24576), IncludeThoughts: true}},
+		{"2.5 budget override", "gemini-2.5-flash", []GenerateOption{WithThinking(common.ThinkingHigh), WithThinkingBudget(2048)}, &ThinkingConfig{ThinkingBudget: common.IntPtr(2048), IncludeThoughts: true}},
+		{"3 level", "gemini-3-pro-preview", []GenerateOption{WithThinking(common.ThinkingMedium)}, &ThinkingConfig{ThinkingLevel: "low", IncludeThoughts: true}},
+		{"3 high level", "models/gemini-3-pro-preview", []GenerateOption{WithThinking(common.ThinkingHigh)}, &ThinkingConfig{ThinkingLevel: "high", IncludeThoughts: true}},
+		{"json hides thoughts", "gemini-2.5-flash", []GenerateOption{WithThinking(common.ThinkingMedium), WithJSONFormat()}, &ThinkingConfig{ThinkingBudget: common.IntPtr(8192)}},
+		{"older model no-op", "gemini-2.0-flash", []GenerateOption{WithThinking(common.ThinkingHigh)}, nil},
+		{"thinking off", "gemini-2.5-flash", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := p.BuildRequest(messages, tt.model, NewGenerateOptions(tt.opts...), nil)
+			require.NoError(t, err)
+
+			var got *ThinkingConfig
+			if genConfig := request.(*GenerateContentRequest).GenerationConfig; genConfig != nil {
+				got = genConfig.ThinkingConfig
+			}
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	p := New()
+	tools := []common.ToolConfig{{Type: "function", Function: common.FunctionDefinition{
+		Name:        "get_weather",
+		Description: "current weather",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+	}}}
+	messages := []common.Message{
+		{Role: "user", Content: "weather in Paris and Rome?"},
+		{Role: "assistant", ToolCalls: []common.ToolCall{
+			{ID: "call_0", Type: "function", Function: common.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "call_1", Type: "function", Function: common.FunctionCall{Name: "get_weather"}},
+		}},
+		{Role: "tool", ToolCallID: "call_0", ToolName: "get_weather", Content: "sunny"},
+		{Role: "tool", ToolCallID: "call_1", ToolName: "get_weather", Content: "rain"},
+	}
+
+	request, err := p.BuildRequest(messages, "gemini-2.5-flash", NewGenerateOptions(WithTools(tools)), nil)
+	require.NoError(t, err)
+	req := request.(*GenerateContentRequest)
+
+	require.Len(t, req.Tools, 1)
+	require.Len(t, req.Tools[0].FunctionDeclarations, 1)
+	assert.Equal(t, "get_weather", req.Tools[0].FunctionDeclarations[0].Name)
+
+	require.Len(t, req.Contents, 3)
+	calls := req.Contents[1]
+	assert.Equal(t, "model", calls.Role)
+	require.Len(t, calls.Parts, 2)
+	assert.Equal(t, "get_weather", calls.Parts[0].FunctionCall.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(calls.Parts[0].FunctionCall.Args))
+	assert.JSONEq(t, `{}`, string(calls.Parts[1].FunctionCall.Args))
+	assert.Equal(t, skipThoughtSignature, calls.Parts[0].ThoughtSignature)
+
+	// consecutive tool results share one user turn
+	results := req.Contents[2]
+	assert.Equal(t, "user", results.Role)
+	require.Len(t, results.Parts, 2)
+	assert.Equal(t, "get_weather", results.Parts[0].FunctionResponse.Name)
+	assert.JSONEq(t, `{"content":"sunny"}`, string(results.Parts[0].FunctionResponse.Response))
+	assert.JSONEq(t, `{"content":"rain"}`, string(results.Parts[1].FunctionResponse.Response))
+}
+
+func TestProvider_ParseResponse(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name      string
+		body      string
+		content   string
+		usage     *common.Usage
+		wantError string
+	}{
+		{
+			name:    "text",
+			body:    `{"candidates":[{"content":{"role":"model","parts":[{"text":"hello "},{"text":"world"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2,"totalTokenCount":6}}`,
+			content: "hello world",
+			usage:   &common.Usage{PromptTokens: 4, CompletionTokens: 2, TotalTokens: 6},
+		},
+		{
+			name:    "thoughts",
+			body:    `{"candidates":[{"content":{"parts":[{"text":"weighing it","thought":true},{"text":"42"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":1,"thoughtsTokenCount":9,"totalTokenCount":14}}`,
+			content: "<think>weighing it</think>\n42",
+			usage:   &common.Usage{PromptTokens: 4, CompletionTokens: 10, TotalTokens: 14},
+		},
+		{
+			name: "function call only",
+			body: `{"candidates":[{"content":{"parts":[{"functionCall":{"name":"get_weather","args":{}}}]},"finishReason":"STOP"}]}`,
+		},
+		{
+			name:      "blocked prompt",
+			body:      `{"promptFeedback":{"blockReason":"SAFETY"}}`,
+			wantError: "SAFETY",
+		},
+		{
+			name:      "no candidates",
+			body:      `{}`,
+			wantError: "no candidates",
+		},
+		{
+			name:      "empty candidate",
+			body:      `{"candidates":[{"content":{},"finishReason":"SAFETY"}]}`,
+			wantError: "finish reason SAFETY",
+		},
+		{
+			name:      "invalid json",
+			body:      `not json`,
+			wantError: "failed to unmarshal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, usage, err := p.ParseResponse([]byte(tt.body), nil)
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, content)
+			assert.Equal(t, tt.usage, usage)
+		})
+	}
+}
+
+func TestProvider_ParseMetadata(t *testing.T) {
+	meta := New().ParseMetadata([]byte(`{"candidates":[{"finishReason":"MAX_TOKENS"}],"modelVersion":"gemini-2.5-flash-001"}`))
+	assert.Equal(t, "gemini-2.5-flash-001", meta.Model)
+	assert.Equal(t, "MAX_TOKENS", meta.FinishReason)
+}
+
+func TestProvider_ParseToolCalls(t *testing.T) {
+	body := `{"candidates":[{"content":{"parts":[{"text":"checking"},{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}},{"functionCall":{"id":"fc_9","name":"get_time"}}]}}]}`
+
+	calls, err := New().ParseToolCalls([]byte(body), nil)
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "call_0", calls[0].ID)
+	assert.Equal(t, "function", calls[0].Type)
+	assert.Equal(t, "get_weather", calls[0].Function.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "fc_9", calls[1].ID)
+	assert.Equal(t, "{}", calls[1].Function.Arguments)
+}
+
+func TestProvider_HandleError(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"invalid key", http.StatusBadRequest, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`, "authentication failed"},
+		{"bad request", http.StatusBadRequest, `{"error":{"code":400,"message":"Invalid JSON payload","status":"INVALID_ARGUMENT"}}`, "Invalid JSON payload"},
+		{"forbidden", http.StatusForbidden, ``, "GEMINI_API_KEY"},
+		{"not found", http.StatusNotFound, ``, "model not found"},
+		{"rate limit", http.StatusTooManyRequests, ``, "rate limit"},
+		{"server error", http.StatusServiceUnavailable, ``, "status 503"},
+		{"other", http.StatusConflict, `{"error":{"message":"conflict"}}`, "conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, p.HandleError(tt.status, []byte(tt.body)), tt.want)
+		})
+	}
+}
+
+func TestProvider_CustomizeRequest(t *testing.T) {
+	req, err := common.CreateJSONRequest(context.Background(), "https://example.com", "secret", nil)
+	require.NoError(t, err)
+	require.NoError(t, New().CustomizeRequest(req))
+
+	assert.Equal(t, "secret", req.Header.Get("x-goog-api-key"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestProvider_Integration(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/models/gemini-2.5-flash:generateContent", r.URL.Path)
+			assert.Equal(t, "test-api-key", r.Header.Get("x-goog-api-key"))
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			var req GenerateContentRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "test message", req.Contents[0].Parts[0].Text)
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"test response"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5},"modelVersion":"gemini-2.5-flash"}`)
+		}))
+		defer server.Close()
+
+		client, err := New().CreateClient(testConfig(server.URL), nil)
+		require.NoError(t, err)
+
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "test message"}}, "gemini-2.5-flash", NewGenerateOptions())
+		require.NoError(t, err)
+		assert.Equal(t, "test response", result.Content)
+		assert.Equal(t, "gemini-2.5-flash", result.Model)
+		assert.Equal(t, "STOP", result.FinishReason)
+		assert.Equal(t, 5, result.Usage.TotalTokens)
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/models/gemini-2.5-flash:streamGenerateContent", r.URL.Path)
+			assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"mulling\",\"thought\":true}]}}]}\n\n")
+			fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"hel\"}]}}],\"usageMetadata\":{\"promptTokenCount\":3,\"totalTokenCount\":3}}\n\n")
+			fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":3,\"candidatesTokenCount\":2,\"totalTokenCount\":5}}\n\n")
+		}))
+		defer server.Close()
+
+		client, err := New().CreateClient(testConfig(server.URL), nil)
+		require.NoError(t, err)
+
+		var chunks, thoughts []string
+		handler := common.StreamHandler(func(chunk common.StreamChunk) {
+			if chunk.Content != "" {
+				chunks = append(chunks, chunk.Content)
+			}
+			if chunk.Thinking != "" {
+				thoughts = append(thoughts, chunk.Thinking)
+			}
+		})
+		req := NewGenerateOptions(WithStream())
+		req.OnChunk = handler
+		result, err := client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "gemini-2.5-flash", req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hel", "lo"}, chunks)
+		assert.Equal(t, []string{"mulling"}, thoughts)
+		assert.Contains(t, result.Content, "hello")
+		assert.Equal(t, "STOP", result.FinishReason)
+		assert.Equal(t, 5, result.Usage.TotalTokens)
+	})
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+		_, _ = w.Write([]byte(`{"models":[
+			{"name":"models/gemini-2.5-pro","supportedGenerationMethods":["generateContent","countTokens"]},
+			{"name":"models/text-embedding-004","supportedGenerationMethods":["embedContent"]},
+			{"name":"models/gemini-2.5-flash","supportedGenerationMethods":["generateContent"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Gemini.APIKey = "test-key"
+	cfg.Providers.Gemini.BaseUrl = server.URL + "/v1beta"
+
+	models, err := New().ListModels(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gemini-2.5-flash", "gemini-2.5-pro"}, models)
+}
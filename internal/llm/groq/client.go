@@ -21,6 +21,8 @@ type ChatRequest struct {
 	ResponseFormat   *chatResponseFormat `json:"response_format,omitempty"`
 	ReasoningFormat  *string             `json:"reasoning_format,omitempty"`
 	Seed             *int                `json:"seed,omitempty"`
+	Tools            []common.ToolConfig `json:"tools,omitempty"`
+	ToolChoice       interface{}         `json:"tool_choice,omitempty"`
 }
 
 // chatResponseFormat is Groq's OpenAI-compatible wire shape for the
@@ -1,12 +1,14 @@
 package groq
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains Groq-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// Groq-specific params (using OpenAI-compatible naming)
+// Extension holds Groq-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	FrequencyPenalty *float64 // Number between -2.0 and 2.0
 	PresencePenalty  *float64 // Number between -2.0 and 2.0
 	Seed             *int     // Integer seed for deterministic outputs
@@ -17,36 +19,71 @@ type GenerateOptions struct {
 	ReasoningFormat *string
 }
 
-// GenerateOption configures Groq-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "groq"
+}
+
+// Validate checks the parameters against the values Groq accepts
+func (e *Extension) Validate() error {
+	if e.FrequencyPenalty != nil && (*e.FrequencyPenalty < -2 || *e.FrequencyPenalty > 2) {
+		return fmt.Errorf("frequency_penalty %g is out of range (-2 to 2)", *e.FrequencyPenalty)
+	}
+	if e.PresencePenalty != nil && (*e.PresencePenalty < -2 || *e.PresencePenalty > 2) {
+		return fmt.Errorf("presence_penalty %g is out of range (-2 to 2)", *e.PresencePenalty)
+	}
+	if e.ReasoningFormat != nil {
+		switch *e.ReasoningFormat {
+		case "parsed", "raw", "hidden":
+		default:
+			return fmt.Errorf("unknown reasoning_format %q: expected parsed, raw or hidden", *e.ReasoningFormat)
+		}
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Groq
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Groq parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // WithFrequencyPenalty sets frequency penalty (-2.0 to 2.0)
 func WithFrequencyPenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.FrequencyPenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).FrequencyPenalty = &penalty
 	}
 }
 
 // WithPresencePenalty sets presence penalty (-2.0 to 2.0)
 func WithPresencePenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.PresencePenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).PresencePenalty = &penalty
 	}
 }
 
 // WithSeed enables deterministic generation using seed
 func WithSeed(seed int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Seed = &seed
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
 	}
 }
 
@@ -54,8 +91,8 @@ func WithSeed(seed int) GenerateOption {
 // Groq's API accepts "parsed" (reasoning returned as a separate field) or
 // "raw" (inlined into content). Only reasoning-capable models honor this
 func WithReasoningFormat(format string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.ReasoningFormat = &format
+	return func(c *common.GenerateRequest) {
+		extension(c).ReasoningFormat = &format
 	}
 }
 
@@ -63,47 +100,56 @@ func WithReasoningFormat(format string) GenerateOption {
 
 // WithTemperature sets response randomness (0.0-2.0)
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
 
 // WithTopP sets nucleus sampling threshold (0.0-1.0)
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets maximum tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences to halt generation
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
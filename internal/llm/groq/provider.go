@@ -5,7 +5,7 @@
 //
 // Example usage:
 //   client := groq.NewClient(apiKey)
-//   response, err := client.Generate(ctx, messages, groq.WithTemperature(0.7))
+//   response, err := client.Generate(ctx, messages, model, groq.NewGenerateOptions(groq.WithTemperature(0.7)))
 //
 // Groq models include:llama-3.3-70b-versatile, openai/gpt-oss-120b,
 // qwen-3-32b, and the agentic groq/compound.
@@ -13,6 +13,7 @@
 package groq
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -32,6 +33,24 @@ type Provider struct{}
 // ensure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// defaultBaseURL is used when providers.groq.base_url is unset
+const defaultBaseURL = "https://api.groq.com/openai/v1"
+
 // New creates a new Groq provider instance
 func New() *Provider {
 	return &Provider{}
@@ -102,12 +121,25 @@ Get an API key from https://console.groq.com/keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.Groq.APIKey, "https://api.groq.com/openai/v1", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Groq.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Groq.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.groq: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Groq.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates Groq-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -128,6 +160,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// translate the cross-provider thinking level into Groq's reasoning_format.
 	// BuildRequest gates the field by model ID so plain models and Compound
@@ -144,13 +182,13 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 		functionalOpts = append(functionalOpts, withCommonSchema("response", []byte(schema)))
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // withCommonSchema adapts the common WithSchema option into the Groq
 // GenerateOption signature so it can be applied alongside provider-specific options
 func withCommonSchema(name string, schema []byte) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithSchema(name, schema)(&c.GenerateOptions)
 	}
 }
@@ -166,17 +204,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates a Groq-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to Groq-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if groqOpts, ok := options.(*GenerateOptions); ok {
-			config = groqOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Groq's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -186,7 +221,7 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	requestBody := &ChatRequest{
 		Model:    modelName,
 		Messages: messages,
-		Stream:   common.BoolPtr(false), // Disable streaming for now
+		Stream:   common.BoolPtr(config.Stream),
 	}
 
 	// map common generation options to Groq's API format
@@ -204,20 +239,20 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map Groq-specific options
-	if config.FrequencyPenalty != nil {
-		requestBody.FrequencyPenalty = config.FrequencyPenalty
+	if ext.FrequencyPenalty != nil {
+		requestBody.FrequencyPenalty = ext.FrequencyPenalty
 	}
-	if config.PresencePenalty != nil {
-		requestBody.PresencePenalty = config.PresencePenalty
+	if ext.PresencePenalty != nil {
+		requestBody.PresencePenalty = ext.PresencePenalty
 	}
-	if config.Seed != nil {
-		requestBody.Seed = config.Seed
+	if ext.Seed != nil {
+		requestBody.Seed = ext.Seed
 	}
 
 	// only wire reasoning_format for models that support it; Compound
 	// reasons natively and plain chat models would reject the field
-	if config.ReasoningFormat != nil && supportsReasoning(modelName) {
-		requestBody.ReasoningFormat = config.ReasoningFormat
+	if ext.ReasoningFormat != nil && supportsReasoning(modelName) {
+		requestBody.ReasoningFormat = ext.ReasoningFormat
 	}
 
 	// handle structured output if requested. Groq accepts OpenAI's wire
@@ -243,6 +278,14 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 		}
 	}
 
+	// tool definitions already use the OpenAI-compatible wire shape
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
+	}
+	if config.ToolChoice != nil {
+		requestBody.ToolChoice = config.ToolChoice
+	}
+
 	return requestBody, nil
 }
 
@@ -266,6 +309,26 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, &chatResp.Usage, nil
 }
 
+// ParseToolCalls extracts the tool calls requested by a Groq response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	return common.ParseChatCompletionToolCalls(body, "Groq", logger)
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a Groq response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	return common.ParseChatCompletionMetadata(body)
+}
+
+// StreamFormat reports that Groq streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed Groq chat completion chunk
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	return common.ParseChatCompletionChunk(data, "Groq", logger)
+}
+
 // HandleError creates Groq-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -311,3 +374,47 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 	// this is implemented for completeness/future extensibility
 	return nil
 }
+
+// ListModels lists the models available to the API key via GET /models
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Groq.APIKey == "" {
+		return nil, fmt.Errorf("Groq API key is required to list models")
+	}
+	baseURL := cfg.Providers.Groq.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Groq.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Groq.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Capabilities reports the features a Groq model honors
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsReasoning(modelName),
+		Schema:   true,
+		JSON:     true,
+		Vision:   false,
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists Groq's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "qwen", Example: "qwen-3-32b"},
+		{Name: "llama", Example: "llama-3.3-70b-versatile"},
+		{Name: "compound", Example: "groq/compound"},
+	}
+}
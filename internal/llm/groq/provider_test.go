@@ -109,9 +109,8 @@ func TestProvider_BuildOptions(t *testing.T) {
 	provider := New()
 
 	tests := []struct {
-		name     string
-		config   *config.Config
-		expected int // expected number of options
+		name   string
+		config *config.Config
 	}{
 		{
 			name: "minimal config",
@@ -119,7 +118,6 @@ func TestProvider_BuildOptions(t *testing.T) {
 				Parameters: config.Parameters{},
 				Format:     config.Format{},
 			},
-			expected: 1, // always returns at least one GenerateOptions object
 		},
 		{
 			name: "full config",
@@ -135,21 +133,13 @@ func TestProvider_BuildOptions(t *testing.T) {
 					JSON: true,
 				},
 			},
-			expected: 1, // still returns one GenerateOptions object, but with all options set
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			options := provider.BuildOptions(tt.config)
-			assert.Len(t, options, tt.expected)
-
-			// verify the first option is a GenerateOptions
-			if len(options) > 0 {
-				generateOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok)
-				assert.NotNil(t, generateOpts)
-			}
+			require.NotNil(t, options)
 		})
 	}
 }
@@ -163,7 +153,7 @@ func TestProvider_BuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		expected *ChatRequest
 	}{
 		{
@@ -193,15 +183,6 @@ func TestProvider_BuildRequest(t *testing.T) {
 				Stream:           common.BoolPtr(false),
 			},
 		},
-		{
-			name:    "invalid options type",
-			options: "invalid",
-			expected: &ChatRequest{
-				Model:    modelName,
-				Messages: messages,
-				Stream:   common.BoolPtr(false),
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -440,9 +421,9 @@ func TestProvider_Integration(t *testing.T) {
 		{Role: "user", Content: "test message"},
 	}
 
-	content, err := client.Generate(context.Background(), messages, "llama-3.1-8b-instant")
+	result, err := client.Generate(context.Background(), messages, "llama-3.1-8b-instant", nil)
 	require.NoError(t, err)
-	assert.Equal(t, "test response", content)
+	assert.Equal(t, "test response", result.Content)
 }
 
 // TestSupportsReasoning covers the model-ID-aware gate for reasoning_format
@@ -491,14 +472,13 @@ func TestProvider_BuildOptions_ThinkingAndSchema(t *testing.T) {
 		},
 	}
 
-	options := provider.BuildOptions(cfg)
-	require.Len(t, options, 1)
+	groqOpts := provider.BuildOptions(cfg)
+	require.NotNil(t, groqOpts)
 
-	groqOpts, ok := options[0].(*GenerateOptions)
+	ext, ok := groqOpts.Extension.(*Extension)
 	require.True(t, ok)
-
-	require.NotNil(t, groqOpts.ReasoningFormat)
-	assert.Equal(t, "parsed", *groqOpts.ReasoningFormat)
+	require.NotNil(t, ext.ReasoningFormat)
+	assert.Equal(t, "parsed", *ext.ReasoningFormat)
 
 	require.NotNil(t, groqOpts.ResponseFormat)
 	assert.Equal(t, "json_schema", groqOpts.ResponseFormat.Type)
@@ -517,7 +497,7 @@ func TestProvider_BuildRequest_JSONSchemaEnvelope(t *testing.T) {
 	schema := []byte(`{"type":"object","properties":{"barn":{"type":"string"}}}`)
 
 	base := common.NewGenerateOptions(common.WithSchema("barn_schema", schema))
-	opts := &GenerateOptions{GenerateOptions: *base}
+	opts := &common.GenerateRequest{GenerateOptions: *base}
 
 	request, err := provider.BuildRequest(messages, "llama-3.3-70b-versatile", opts, slog.Default())
 	require.NoError(t, err)
@@ -19,6 +19,8 @@ type ChatRequest struct {
 	RandomSeed      *int                    `json:"random_seed,omitempty"`
 	ResponseFormat  *ResponseFormatEnvelope `json:"response_format,omitempty"`
 	ReasoningEffort *string                 `json:"reasoning_effort,omitempty"`
+	Tools           []common.ToolConfig     `json:"tools,omitempty"`
+	ToolChoice      interface{}             `json:"tool_choice,omitempty"`
 }
 
 // ResponseFormatEnvelope is Mistral's OpenAI-compatible response_format payload.
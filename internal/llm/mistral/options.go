@@ -1,32 +1,59 @@
 package mistral
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains Mistral-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// Mistral-specific params
+// Extension holds Mistral-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	RandomSeed      *int    // mistral uses "random_seed" instead of "seed"
 	ReasoningEffort *string // mistral's native reasoning_effort field (e.g. "medium", "high")
 }
 
-// GenerateOption configures Mistral-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "mistral"
+}
+
+// Validate checks the parameters against the values Mistral accepts
+func (e *Extension) Validate() error {
+	if e.ReasoningEffort != nil && *e.ReasoningEffort == "" {
+		return fmt.Errorf("reasoning_effort is empty")
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Mistral
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Mistral parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // WithRandomSeed enables deterministic generation using Mistral's random_seed field
 func WithRandomSeed(seed int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.RandomSeed = &seed
+	return func(c *common.GenerateRequest) {
+		extension(c).RandomSeed = &seed
 	}
 }
 
@@ -34,11 +61,11 @@ func WithRandomSeed(seed int) GenerateOption {
 // Valid upstream values include "medium" and "high". An empty string
 // leaves the field unset so callers can no-op without conditional logic.
 func WithReasoningEffort(effort string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		if effort == "" {
 			return
 		}
-		c.ReasoningEffort = &effort
+		extension(c).ReasoningEffort = &effort
 	}
 }
 
@@ -46,47 +73,56 @@ func WithReasoningEffort(effort string) GenerateOption {
 
 // WithTemperature sets response randomness (0.0-2.0)
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
 
 // WithTopP sets nucleus sampling threshold (0.0-1.0)
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets maximum tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences to halt generation
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
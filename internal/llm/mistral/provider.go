@@ -1,6 +1,7 @@
 package mistral
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -17,6 +18,27 @@ type Provider struct{}
 // ensure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider can embed text
+var _ common.Embedder = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// defaultBaseURL is used when providers.mistral.base_url is unset
+const defaultBaseURL = "https://api.mistral.ai/v1"
+
 // New creates a new Mistral provider instance
 func New() *Provider {
 	return &Provider{}
@@ -55,12 +77,25 @@ Get an API key from https://console.mistral.ai/api-keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.Mistral.APIKey, "https://api.mistral.ai/v1", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Mistral.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Mistral.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.mistral: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Mistral.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates Mistral-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -81,6 +116,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// translate the cross-provider thinking level into Mistral's native
 	// reasoning_effort string
@@ -94,12 +135,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	// WithSchema option. BuildRequest translates this into Mistral's
 	// OpenAI-compatible json_schema envelope.
 	if schema := strings.TrimSpace(cfg.Parameters.ResponseSchema); schema != "" {
-		functionalOpts = append(functionalOpts, func(c *GenerateOptions) {
+		functionalOpts = append(functionalOpts, func(c *common.GenerateRequest) {
 			common.WithSchema("response", []byte(schema))(&c.GenerateOptions)
 		})
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // supportsReasoningEffort reports whether the given model accepts Mistral's
@@ -164,17 +205,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates a Mistral-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to Mistral-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if mistralOpts, ok := options.(*GenerateOptions); ok {
-			config = mistralOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Mistral's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -184,7 +222,7 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	requestBody := &ChatRequest{
 		Model:    modelName,
 		Messages: messages,
-		Stream:   common.BoolPtr(false), // Disable streaming for now
+		Stream:   common.BoolPtr(config.Stream),
 	}
 
 	// map common generation options to Mistral's API format
@@ -202,14 +240,14 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// handle Mistral-specific seed field mapping
-	if config.RandomSeed != nil {
-		requestBody.RandomSeed = config.RandomSeed
+	if ext.RandomSeed != nil {
+		requestBody.RandomSeed = ext.RandomSeed
 	}
 
 	// wire Mistral's native reasoning_effort field, but only for model
 	// families that actually accept it
-	if config.ReasoningEffort != nil && supportsReasoningEffort(modelName) {
-		requestBody.ReasoningEffort = config.ReasoningEffort
+	if ext.ReasoningEffort != nil && supportsReasoningEffort(modelName) {
+		requestBody.ReasoningEffort = ext.ReasoningEffort
 	}
 
 	// translate common.ResponseFormat into Mistral response_format
@@ -231,6 +269,14 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 		}
 	}
 
+	// tool definitions already use the OpenAI-compatible wire shape
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
+	}
+	if config.ToolChoice != nil {
+		requestBody.ToolChoice = config.ToolChoice
+	}
+
 	return requestBody, nil
 }
 
@@ -259,6 +305,26 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, &chatResp.Usage, nil
 }
 
+// ParseToolCalls extracts the tool calls requested by a Mistral response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	return common.ParseChatCompletionToolCalls(body, "Mistral", logger)
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a Mistral response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	return common.ParseChatCompletionMetadata(body)
+}
+
+// StreamFormat reports that Mistral streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed Mistral chat completion chunk
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	return common.ParseChatCompletionChunk(data, "Mistral", logger)
+}
+
 // HandleError creates Mistral-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -305,3 +371,71 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 
 	return nil
 }
+
+// ListModels lists the models available to the API key via GET /models
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Mistral.APIKey == "" {
+		return nil, fmt.Errorf("Mistral API key is required to list models")
+	}
+	baseURL := cfg.Providers.Mistral.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Mistral.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Mistral.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Embed returns a vector per input via POST /embeddings
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	if cfg.Providers.Mistral.APIKey == "" {
+		return nil, fmt.Errorf("Mistral API key is required to embed text")
+	}
+	baseURL := cfg.Providers.Mistral.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Mistral.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.PostJSON(ctx, client, common.BuildEmbeddingsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Mistral.APIKey,
+	}, map[string]interface{}{"model": modelName, "input": inputs}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseEmbeddings(body)
+}
+
+// Capabilities reports the features a Mistral model honors
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsReasoningEffort(modelName),
+		Schema:   true,
+		JSON:     true,
+		Vision:   false,
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists Mistral's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "mistral-small", Example: "mistral-small-2603"},
+		{Name: "magistral", Example: "magistral-medium-2509"},
+		{Name: "mistral-medium", Example: "mistral-medium-2508"},
+		{Name: "mistral-large", Example: "mistral-large-2411"},
+	}
+}
@@ -1,9 +1,11 @@
 package mistral
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/chriscorrea/slop/internal/config"
@@ -102,7 +104,7 @@ func TestBuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		validate func(t *testing.T, request interface{})
 	}{
 		{
@@ -118,14 +120,14 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Build request with generation options",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					Temperature: common.Float64Ptr(0.8),
 					MaxTokens:   common.IntPtr(1000),
 					TopP:        common.Float64Ptr(0.9),
 					Stop:        []string{"STOP"},
 				},
-				RandomSeed: common.IntPtr(42),
+				Extension: &Extension{RandomSeed: common.IntPtr(42)},
 			},
 			validate: func(t *testing.T, request interface{}) {
 				chatReq, ok := request.(*ChatRequest)
@@ -143,7 +145,7 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Build request with JSON format",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					ResponseFormat: &common.ResponseFormat{Type: "json_object"},
 				},
@@ -379,7 +381,7 @@ func TestBuildRequest_ReasoningEffort(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			opts := &GenerateOptions{ReasoningEffort: tt.effort}
+			opts := &common.GenerateRequest{Extension: &Extension{ReasoningEffort: tt.effort}}
 			req, err := provider.BuildRequest(messages, tt.modelName, opts, slog.Default())
 			assert.NoError(t, err)
 
@@ -402,7 +404,7 @@ func TestBuildRequest_SchemaEnvelope(t *testing.T) {
 	schema := []byte(`{"type":"object","properties":{"character":{"type":"string"}},"required":["character"]}`)
 
 	commonOpts := common.NewGenerateOptions(common.WithSchema("snowball_schema", schema))
-	opts := &GenerateOptions{GenerateOptions: *commonOpts}
+	opts := &common.GenerateRequest{GenerateOptions: *commonOpts}
 
 	req, err := provider.BuildRequest(messages, "mistral-small-2603", opts, slog.Default())
 	assert.NoError(t, err)
@@ -461,3 +463,22 @@ func TestProviderInterface(t *testing.T) {
 		assert.Equal(t, "/v1/chat/completions", req.URL.Path)
 	})
 }
+
+func TestProvider_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data":[{"index":0,"embedding":[0.5,-0.5]}],"model":"mistral-embed","usage":{"prompt_tokens":3,"total_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Mistral.APIKey = "test-key"
+	cfg.Providers.Mistral.BaseUrl = server.URL + "/v1"
+
+	result, err := New().Embed(context.Background(), cfg, "mistral-embed", []string{"hello"}, common.EmbedClustering)
+	if assert.NoError(t, err) {
+		assert.Equal(t, [][]float64{{0.5, -0.5}}, result.Vectors)
+		assert.Equal(t, "mistral-embed", result.Model)
+	}
+}
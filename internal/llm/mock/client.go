@@ -2,16 +2,75 @@ package mock
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 
 	"github.com/chriscorrea/slop/internal/llm/common"
 )
 
 // Client implements the common.LLM interface for mock testing
-type Client struct{}
+type Client struct {
+	rules  []Rule // nil answers every prompt with a fixed response
+	logger *slog.Logger
+}
 
 var _ common.LLM = (*Client)(nil)
 
 // Generate implements common.LLM interface, returns a mock response
-func (c *Client) Generate(ctx context.Context, messages []common.Message, modelName string, options ...interface{}) (string, error) {
-	return "Mock LLM response", nil
+func (c *Client) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	// options are checked as a real provider would, then ignored
+	if req != nil {
+		if err := req.Validate(); err != nil {
+			return nil, fmt.Errorf("mock: %w", err)
+		}
+	}
+
+	if c.rules == nil {
+		return &common.GenerateResult{
+			Content:      "Mock LLM response",
+			Usage:        &common.Usage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+			FinishReason: "stop",
+			Model:        modelName,
+		}, nil
+	}
+
+	users, system := splitMessages(messages)
+	for i := range c.rules {
+		rule := &c.rules[i]
+		values, ok := rule.matches(users, system, modelName)
+		if !ok {
+			continue
+		}
+		if c.logger != nil {
+			c.logger.Debug("Mock rule matched", "rule", rule.label(i))
+		}
+		return rule.respond(ctx, values, messages, modelName)
+	}
+	prompt := ""
+	if len(users) > 0 {
+		prompt = users[len(users)-1]
+	}
+	return nil, fmt.Errorf("no mock rule matched the prompt %q; add a rule without matchers as a default", clip(prompt, 80))
+}
+
+// splitMessages returns the user messages in order and the system prompt
+func splitMessages(messages []common.Message) (users []string, system string) {
+	for _, message := range messages {
+		switch message.Role {
+		case "user":
+			users = append(users, message.Content)
+		case "system":
+			system = message.Content
+		}
+	}
+	return users, system
+}
+
+// clip shortens text for an error message
+func clip(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "..."
 }
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+	"hash/fnv"
 	"log/slog"
 	"net/http"
 
@@ -13,19 +15,33 @@ type Provider struct{}
 
 var _ common.Provider = (*Provider)(nil)
 
+var _ common.Embedder = (*Provider)(nil)
+
+// mockEmbedDimensions is the length of the mock vectors
+const mockEmbedDimensions = 8
+
 func New() *Provider {
 	return &Provider{}
 }
 
-// CreateClient creates a new mock LLM client
+// CreateClient creates a new mock LLM client, answering from
+// providers.mock.rules when set
 func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
-	return &Client{}, nil
+	client := &Client{logger: logger}
+	if cfg != nil && cfg.Providers.Mock.Rules != "" {
+		rules, err := LoadRules(cfg.Providers.Mock.Rules)
+		if err != nil {
+			return nil, err
+		}
+		client.rules = rules
+	}
+	return client, nil
 }
 
 // BuildOptions creates mock-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	// Mock provider doesn't need real options here
-	return []interface{}{}
+	return &common.GenerateRequest{}
 }
 
 // RequiresAPIKey returns false - no API key required!
@@ -39,7 +55,7 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates a mock request
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, req *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
 	return map[string]interface{}{
 		"model":    modelName,
 		"messages": messages,
@@ -72,3 +88,24 @@ func (p *Provider) HandleConnectionError(err error) error {
 func (p *Provider) CustomizeRequest(req *http.Request) error {
 	return nil
 }
+
+// Embed returns a deterministic vector per input, derived from a hash of the
+// text, so equal inputs always get equal vectors
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	vectors := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		vector := make([]float64, mockEmbedDimensions)
+		for d := range vector {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte{byte(d)})
+			_, _ = h.Write([]byte(input))
+			vector[d] = float64(h.Sum32())/float64(1<<31) - 1
+		}
+		vectors[i] = vector
+	}
+	return &common.EmbedResult{
+		Vectors: vectors,
+		Model:   modelName,
+		Usage:   &common.Usage{PromptTokens: len(inputs), TotalTokens: len(inputs)},
+	}, nil
+}
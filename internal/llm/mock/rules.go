@@ -0,0 +1,200 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/spf13/viper"
+)
+
+// placeholder matches {name} in a rule's response and thinking
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// Rule is one canned response in a rules file. rules are tried in order and
+// the first whose matchers all hold answers; a rule with no matchers answers
+// any prompt, so it makes a good last entry
+type Rule struct {
+	Name     string `mapstructure:"name"`     // shown in errors and debug logs
+	Match    string `mapstructure:"match"`    // regexp a user message must match
+	Contains string `mapstructure:"contains"` // text a user message must contain
+	System   string `mapstructure:"system"`   // regexp the system prompt must match
+	Model    string `mapstructure:"model"`    // model name the request must use
+
+	// Response is the content returned. {input} is the prompt (the last user
+	// message), {system} the system prompt, {model} the model, and {1}, {2}
+	// or {name} the groups captured by match
+	Response string `mapstructure:"response"`
+	Thinking string `mapstructure:"thinking"` // returned as a native thinking block
+
+	Latency      string `mapstructure:"latency"`       // wait before answering, such as "250ms"
+	FinishReason string `mapstructure:"finish_reason"` // "stop" when empty; "length" marks a truncated answer
+	Status       int    `mapstructure:"status"`        // answer with this HTTP error instead
+	Error        string `mapstructure:"error"`         // error message; without status, a connection failure
+
+	match   *regexp.Regexp
+	system  *regexp.Regexp
+	latency time.Duration
+}
+
+// LoadRules reads and checks a rules file: a list of [[rules]] in TOML, or
+// a rules array in YAML or JSON
+func LoadRules(path string) ([]Rule, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read mock rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := v.UnmarshalKey("rules", &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse mock rules %s: %w", path, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("mock rules %s: no [[rules]] defined", path)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("mock rules %s: rule %s: %w", path, rules[i].label(i), err)
+		}
+	}
+	return rules, nil
+}
+
+// compile parses the rule's patterns and latency
+func (r *Rule) compile() error {
+	var err error
+	if r.Match != "" {
+		if r.match, err = regexp.Compile(r.Match); err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+	}
+	if r.System != "" {
+		if r.system, err = regexp.Compile(r.System); err != nil {
+			return fmt.Errorf("invalid system: %w", err)
+		}
+	}
+	if r.Latency != "" {
+		if r.latency, err = time.ParseDuration(r.Latency); err != nil || r.latency < 0 {
+			return fmt.Errorf("invalid latency %q: expected a duration such as 250ms", r.Latency)
+		}
+	}
+	if r.Status != 0 && (r.Status < 400 || r.Status > 599) {
+		return fmt.Errorf("invalid status %d: expected an HTTP error status (400-599)", r.Status)
+	}
+	return nil
+}
+
+// label names a rule in errors: its name, else its position from 1
+func (r *Rule) label(i int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// matches reports whether the rule answers the request, returning the
+// placeholder values for its response. user messages are tried from the
+// last, so the prompt is preferred over piped input and context files
+func (r *Rule) matches(users []string, system, modelName string) (map[string]string, bool) {
+	if r.Model != "" && r.Model != modelName {
+		return nil, false
+	}
+	if r.system != nil && !r.system.MatchString(system) {
+		return nil, false
+	}
+
+	prompt := ""
+	if len(users) > 0 {
+		prompt = users[len(users)-1]
+	}
+	values := map[string]string{"input": prompt, "system": system, "model": modelName}
+
+	for i := len(users) - 1; i >= 0; i-- {
+		text := users[i]
+		if r.Contains != "" && !strings.Contains(text, r.Contains) {
+			continue
+		}
+		if r.match == nil {
+			return values, true
+		}
+		groups := r.match.FindStringSubmatch(text)
+		if groups == nil {
+			continue
+		}
+		for g, name := range r.match.SubexpNames() {
+			if g == 0 {
+				continue
+			}
+			values[fmt.Sprint(g)] = groups[g]
+			if name != "" {
+				values[name] = groups[g]
+			}
+		}
+		return values, true
+	}
+
+	// a rule without message matchers answers even an empty request
+	return values, r.Contains == "" && r.match == nil
+}
+
+// respond waits out the latency, then answers as the rule says
+func (r *Rule) respond(ctx context.Context, values map[string]string, messages []common.Message, modelName string) (*common.GenerateResult, error) {
+	if r.latency > 0 {
+		timer := time.NewTimer(r.latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if r.Status != 0 {
+		message := r.Error
+		if message == "" {
+			message = http.StatusText(r.Status)
+		}
+		return nil, &common.APIError{StatusCode: r.Status, Err: errors.New(message)}
+	}
+	if r.Error != "" {
+		return nil, &common.ConnectionError{Err: errors.New(r.Error)}
+	}
+
+	content := expand(r.Response, values)
+	thinking := expand(r.Thinking, values)
+	if thinking != "" {
+		// inlined like a real provider's native thinking
+		content = "<think>" + thinking + "</think>\n" + content
+	}
+	finishReason := r.FinishReason
+	if finishReason == "" {
+		finishReason = "stop"
+	}
+
+	promptTokens := common.EstimateRequestTokens(messages)
+	completionTokens := common.EstimateTokens(content)
+	return &common.GenerateResult{
+		Content:      content,
+		Thinking:     thinking,
+		Usage:        &common.Usage{PromptTokens: promptTokens, CompletionTokens: completionTokens, TotalTokens: promptTokens + completionTokens},
+		FinishReason: finishReason,
+		Model:        modelName,
+	}, nil
+}
+
+// expand fills {name} placeholders, leaving unknown ones as written
+func expand(text string, values map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := values[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
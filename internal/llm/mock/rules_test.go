@@ -0,0 +1,231 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRules writes a rules file to a temp dir and returns its path
+func writeRules(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+// rulesClient builds a client from a TOML rules file
+func rulesClient(t *testing.T, content string) *Client {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Providers.Mock.Rules = writeRules(t, "rules.toml", content)
+	llm, err := New().CreateClient(cfg, nil)
+	require.NoError(t, err)
+	return llm.(*Client)
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Run("toml", func(t *testing.T) {
+		rules, err := LoadRules(writeRules(t, "rules.toml", `
+[[rules]]
+name = "greeting"
+match = "^hello (\\w+)"
+response = "hi {1}"
+
+[[rules]]
+response = "default"
+`))
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, "greeting", rules[0].Name)
+		assert.NotNil(t, rules[0].match)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rules, err := LoadRules(writeRules(t, "rules.json", `{"rules":[{"contains":"x","response":"y","latency":"5ms"}]}`))
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, 5*time.Millisecond, rules[0].latency)
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no rules", "[other]\nkey = 1\n", "no [[rules]] defined"},
+		{"bad match", "[[rules]]\nmatch = \"(\"\n", `rule #1: invalid match`},
+		{"bad system", "[[rules]]\nname = \"sys\"\nsystem = \"[\"\n", `rule "sys": invalid system`},
+		{"bad latency", "[[rules]]\nlatency = \"soon\"\n", "invalid latency"},
+		{"negative latency", "[[rules]]\nlatency = \"-1s\"\n", "invalid latency"},
+		{"bad status", "[[rules]]\nstatus = 200\n", "invalid status 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRules(writeRules(t, "rules.toml", tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadRules(filepath.Join(t.TempDir(), "missing.toml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read mock rules")
+	})
+}
+
+func TestClient_Rules(t *testing.T) {
+	client := rulesClient(t, `
+[[rules]]
+model = "special"
+response = "special model"
+
+[[rules]]
+system = "pirate"
+response = "arr, {input}"
+
+[[rules]]
+match = "^translate (?P<word>\\w+) to (\\w+)$"
+response = "{word} in {2}"
+
+[[rules]]
+contains = "review:"
+thinking = "weighing {input}"
+response = "positive"
+
+[[rules]]
+match = "^long"
+response = "cut"
+finish_reason = "length"
+
+[[rules]]
+response = "echo: {input} {unknown}"
+`)
+	ctx := context.Background()
+	user := func(content string) []common.Message {
+		return []common.Message{{Role: "user", Content: content}}
+	}
+
+	t.Run("model", func(t *testing.T) {
+		result, err := client.Generate(ctx, user("anything"), "special", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "special model", result.Content)
+		assert.Equal(t, "special", result.Model)
+	})
+
+	t.Run("system", func(t *testing.T) {
+		messages := []common.Message{{Role: "system", Content: "talk like a pirate"}, {Role: "user", Content: "hello"}}
+		result, err := client.Generate(ctx, messages, "m", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "arr, hello", result.Content)
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		result, err := client.Generate(ctx, user("translate cat to french"), "m", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "cat in french", result.Content)
+		assert.Equal(t, "stop", result.FinishReason)
+	})
+
+	t.Run("earlier message and thinking", func(t *testing.T) {
+		messages := []common.Message{{Role: "user", Content: "review: great"}, {Role: "user", Content: "classify"}}
+		result, err := client.Generate(ctx, messages, "m", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "<think>weighing classify</think>\npositive", result.Content)
+		assert.Equal(t, "weighing classify", result.Thinking)
+		require.NotNil(t, result.Usage)
+		assert.Positive(t, result.Usage.PromptTokens)
+		assert.Equal(t, result.Usage.PromptTokens+result.Usage.CompletionTokens, result.Usage.TotalTokens)
+	})
+
+	t.Run("finish reason", func(t *testing.T) {
+		result, err := client.Generate(ctx, user("long answer"), "m", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "length", result.FinishReason)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		result, err := client.Generate(ctx, user("what?"), "m", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "echo: what? {unknown}", result.Content)
+	})
+}
+
+func TestClient_RuleErrors(t *testing.T) {
+	client := rulesClient(t, `
+[[rules]]
+match = "rate"
+status = 429
+error = "slow down"
+
+[[rules]]
+match = "server"
+status = 503
+
+[[rules]]
+match = "offline"
+error = "connection refused"
+
+[[rules]]
+match = "slow"
+latency = "1h"
+response = "late"
+`)
+	user := func(content string) []common.Message {
+		return []common.Message{{Role: "user", Content: content}}
+	}
+
+	t.Run("status", func(t *testing.T) {
+		_, err := client.Generate(context.Background(), user("rate limited"), "m", nil)
+		var apiErr *common.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 429, apiErr.StatusCode)
+		assert.Contains(t, err.Error(), "slow down")
+		assert.True(t, common.ShouldFallback(err))
+	})
+
+	t.Run("status text", func(t *testing.T) {
+		_, err := client.Generate(context.Background(), user("server down"), "m", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Service Unavailable")
+	})
+
+	t.Run("connection", func(t *testing.T) {
+		_, err := client.Generate(context.Background(), user("offline"), "m", nil)
+		var connErr *common.ConnectionError
+		require.True(t, errors.As(err, &connErr))
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+
+	t.Run("latency honors cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.Generate(ctx, user("slow"), "m", nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := client.Generate(context.Background(), user("unmatched"), "m", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no mock rule matched the prompt "unmatched"`)
+	})
+}
+
+func TestClient_WithoutRules(t *testing.T) {
+	llm, err := New().CreateClient(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	result, err := llm.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "m", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Mock LLM response", result.Content)
+}
@@ -8,9 +8,12 @@ import (
 
 // ChatRequest represents the request payload for Ollama's chat API
 type ChatRequest struct {
-	Model    string           `json:"model"`
-	Messages []common.Message `json:"messages"`
-	Stream   bool             `json:"stream"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+
+	// Tools are the functions the model may call (OpenAI-compatible shape)
+	Tools []common.ToolConfig `json:"tools,omitempty"`
 
 	// Generation parameters
 	Options map[string]interface{} `json:"options,omitempty"`
@@ -29,12 +32,39 @@ type ChatRequest struct {
 	KeepAlive *string `json:"keep_alive,omitempty"`
 }
 
+// Message is an Ollama chat message. it differs from common.Message in how
+// tools travel: call arguments are a JSON object rather than an encoded
+// string, tool results are addressed by tool_name, and images travel as a
+// list of base64 strings
+type Message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+// ToolCall is a function call in Ollama's wire shape
+type ToolCall struct {
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction names the function and carries its arguments object
+type ToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
 // ChatResponse represents the response from Ollama's chat API
 type ChatResponse struct {
-	Model     string         `json:"model"`
-	CreatedAt string         `json:"created_at"`
-	Message   common.Message `json:"message"`
-	Done      bool           `json:"done"`
+	Model     string  `json:"model"`
+	CreatedAt string  `json:"created_at"`
+	Message   Message `json:"message"`
+	Done      bool    `json:"done"`
+
+	// DoneReason is why generation stopped (e.g. "stop", "length") when done=true
+	DoneReason string `json:"done_reason,omitempty"`
 
 	// Token usage information (when done=true)
 	TotalDuration      int64 `json:"total_duration,omitempty"`
@@ -0,0 +1,217 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// ModelNotFoundError is returned when a request names a model the Ollama
+// server has not pulled, so callers can offer to pull it
+type ModelNotFoundError struct{}
+
+func (e *ModelNotFoundError) Error() string {
+	return `The requested model was not found. It may not be installed locally or available on the Ollama server.
+
+To see all models you have installed, run:
+    slop ollama list
+
+To download a model, use:
+    slop ollama pull [model_name]
+
+To see available models, visit: https://ollama.com/search`
+}
+
+// Unwrap lets callers match any provider's missing model with
+// common.ErrModelNotFound
+func (e *ModelNotFoundError) Unwrap() error {
+	return common.ErrModelNotFound
+}
+
+// LocalModel is a model pulled into the server, as /api/tags reports it
+type LocalModel struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Details    struct {
+		ParameterSize     string `json:"parameter_size"`
+		QuantizationLevel string `json:"quantization_level"`
+	} `json:"details"`
+}
+
+// RunningModel is a model loaded in memory, as /api/ps reports it
+type RunningModel struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PullProgress is one status update streamed by /api/pull. Total and
+// Completed are set while a layer downloads
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Manager administers the models on an Ollama server through its native API
+type Manager struct {
+	provider *Provider
+	baseURL  string
+	client   *http.Client
+}
+
+// NewManager creates a manager for the server at providers.ollama.base_url,
+// reaching it through the provider's transport settings
+func NewManager(cfg *config.Config) (*Manager, error) {
+	client, err := common.NewAPIClient(cfg.Providers.Ollama.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{provider: New(), baseURL: serverURL(cfg), client: client}, nil
+}
+
+// serverURL returns the server root, without the /v1 suffix used by the
+// OpenAI-compatible endpoints
+func serverURL(cfg *config.Config) string {
+	baseURL := cfg.Providers.Ollama.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+}
+
+// List returns the models pulled into the server
+func (m *Manager) List(ctx context.Context) ([]LocalModel, error) {
+	var resp struct {
+		Models []LocalModel `json:"models"`
+	}
+	if err := m.call(ctx, http.MethodGet, "/api/tags", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Models, nil
+}
+
+// Running returns the models currently loaded in memory
+func (m *Manager) Running(ctx context.Context) ([]RunningModel, error) {
+	var resp struct {
+		Models []RunningModel `json:"models"`
+	}
+	if err := m.call(ctx, http.MethodGet, "/api/ps", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Models, nil
+}
+
+// Pull downloads a model, reporting each streamed status update to progress
+func (m *Manager) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	// a download streams progress for as long as it takes; the client's
+	// timeout only bounds waiting for the stream to start
+	body, err := m.send(ctx, m.client, http.MethodPost, "/api/pull", map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var update PullProgress
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			continue
+		}
+		if update.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", model, update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	return fmt.Errorf("pull of %s ended before it completed", model)
+}
+
+// Delete removes a pulled model from the server
+func (m *Manager) Delete(ctx context.Context, model string) error {
+	return m.call(ctx, http.MethodDelete, "/api/delete", map[string]string{"model": model}, nil)
+}
+
+// Warm loads a model into memory without generating, keeping it resident
+// for keepAlive (the server default when empty)
+func (m *Manager) Warm(ctx context.Context, model, keepAlive string) error {
+	request := map[string]interface{}{"model": model, "stream": false}
+	if keepAlive != "" {
+		request["keep_alive"] = keepAlive
+	}
+	return m.call(ctx, http.MethodPost, "/api/generate", request, nil)
+}
+
+// call sends a request and decodes the JSON response into out when non-nil
+func (m *Manager) call(ctx context.Context, method, path string, payload, out interface{}) error {
+	body, err := m.send(ctx, m.client, method, path, payload)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, body)
+		return nil
+	}
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+	return nil
+}
+
+// send performs a request with client and returns the body of a successful response.
+// error statuses go through the provider's HandleError, so a missing model
+// is a ModelNotFoundError here too
+func (m *Manager) send(ctx context.Context, client *http.Client, method, path string, payload interface{}) (io.ReadCloser, error) {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Ollama request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &common.ConnectionError{Err: m.provider.HandleConnectionError(err)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return nil, &common.APIError{StatusCode: resp.StatusCode, Err: m.provider.HandleError(resp.StatusCode, data)}
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,165 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager returns a manager for a fake server answering with handler
+func newTestManager(t *testing.T, handler http.HandlerFunc) *Manager {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{}
+	cfg.Providers.Ollama.BaseUrl = server.URL + "/v1"
+	manager, err := NewManager(cfg)
+	require.NoError(t, err)
+	return manager
+}
+
+func TestManager_List(t *testing.T) {
+	manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"gemma3:4b","size":3338801804,"modified_at":"2026-05-01T10:00:00Z","details":{"parameter_size":"4.3B","quantization_level":"Q4_K_M"}}]}`))
+		case "/api/ps":
+			_, _ = w.Write([]byte(`{"models":[{"name":"gemma3:4b","size":6169209344,"expires_at":"2026-05-01T10:05:00Z"}]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	models, err := manager.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "gemma3:4b", models[0].Name)
+	assert.Equal(t, int64(3338801804), models[0].Size)
+	assert.Equal(t, "4.3B", models[0].Details.ParameterSize)
+
+	running, err := manager.Running(context.Background())
+	require.NoError(t, err)
+	require.Len(t, running, 1)
+	assert.Equal(t, 5, running[0].ExpiresAt.Minute())
+}
+
+func TestManager_Pull(t *testing.T) {
+	t.Run("streams progress until success", func(t *testing.T) {
+		manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/pull", r.URL.Path)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "gemma3:4b", body["model"])
+
+			_, _ = w.Write([]byte(`{"status":"pulling manifest"}
+{"status":"pulling aeda25e63ebd","digest":"sha256:aeda25e63ebd","total":100,"completed":40}
+{"status":"pulling aeda25e63ebd","digest":"sha256:aeda25e63ebd","total":100,"completed":100}
+{"status":"verifying sha256 digest"}
+{"status":"success"}
+`))
+		})
+
+		var updates []PullProgress
+		err := manager.Pull(context.Background(), "gemma3:4b", func(p PullProgress) { updates = append(updates, p) })
+		require.NoError(t, err)
+		require.Len(t, updates, 5)
+		assert.Equal(t, int64(40), updates[1].Completed)
+		assert.Equal(t, "success", updates[4].Status)
+	})
+
+	t.Run("streamed error", func(t *testing.T) {
+		manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"pulling manifest"}
+{"error":"pull model manifest: file does not exist"}
+`))
+		})
+		err := manager.Pull(context.Background(), "nope", nil)
+		assert.EqualError(t, err, "failed to pull nope: pull model manifest: file does not exist")
+	})
+
+	t.Run("interrupted stream", func(t *testing.T) {
+		manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"pulling manifest"}` + "\n"))
+		})
+		assert.ErrorContains(t, manager.Pull(context.Background(), "gemma3", nil), "ended before it completed")
+	})
+}
+
+func TestManager_Delete(t *testing.T) {
+	manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["model"] != "gemma3:4b" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model 'missing' not found"}`))
+		}
+	})
+
+	assert.NoError(t, manager.Delete(context.Background(), "gemma3:4b"))
+
+	err := manager.Delete(context.Background(), "missing")
+	var apiErr *common.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	var notFound *ModelNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestManager_Warm(t *testing.T) {
+	var body map[string]interface{}
+	manager := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/generate", r.URL.Path)
+		body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"model":"gemma3:4b","response":"","done":true,"done_reason":"load"}`))
+	})
+
+	require.NoError(t, manager.Warm(context.Background(), "gemma3:4b", "30m"))
+	assert.Equal(t, map[string]interface{}{"model": "gemma3:4b", "stream": false, "keep_alive": "30m"}, body)
+
+	require.NoError(t, manager.Warm(context.Background(), "gemma3:4b", ""))
+	assert.NotContains(t, body, "keep_alive")
+}
+
+func TestManager_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	cfg := &config.Config{}
+	cfg.Providers.Ollama.BaseUrl = server.URL
+
+	manager, err := NewManager(cfg)
+	require.NoError(t, err)
+	_, err = manager.List(context.Background())
+	var connErr *common.ConnectionError
+	assert.ErrorAs(t, err, &connErr)
+	assert.ErrorContains(t, err, "Cannot connect to Ollama server")
+}
+
+func TestManager_TransportSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer proxy", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Ollama.BaseUrl = server.URL
+	cfg.Providers.Ollama.Headers = map[string]string{"Authorization": "Bearer proxy"}
+	manager, err := NewManager(cfg)
+	require.NoError(t, err)
+	_, err = manager.List(context.Background())
+	require.NoError(t, err)
+
+	cfg.Providers.Ollama.ProxyURL = "proxy.internal"
+	_, err = NewManager(cfg)
+	assert.ErrorContains(t, err, "invalid proxy_url")
+}
@@ -1,12 +1,14 @@
 package ollama
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains Ollama-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// Ollama-specific parameters
+// Extension holds Ollama-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	TopK          *int     // Limits token selection to top K candidates
 	RepeatPenalty *float64 // Penalty for repeating tokens (default: 1.1)
 	Seed          *int     // Random seed for deterministic generation
@@ -14,38 +16,66 @@ type GenerateOptions struct {
 	KeepAlive     *string  // how long Ollama keeps the model warm in RAM
 }
 
-// GenerateOption configures Ollama-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "ollama"
+}
+
+// Validate checks the parameters against the values Ollama accepts
+func (e *Extension) Validate() error {
+	if e.TopK != nil && *e.TopK <= 0 {
+		return fmt.Errorf("top_k must be positive, got %d", *e.TopK)
+	}
+	if e.RepeatPenalty != nil && *e.RepeatPenalty < 0 {
+		return fmt.Errorf("repeat_penalty can't be negative, got %g", *e.RepeatPenalty)
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Ollama
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Ollama parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // Ollama-specific option functions
 
 // WithTopK limits token selection to top K candidates
 func WithTopK(topK int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.TopK = &topK
+	return func(c *common.GenerateRequest) {
+		extension(c).TopK = &topK
 	}
 }
 
 // WithRepeatPenalty sets penalty for repeating tokens (default: 1.1)
 func WithRepeatPenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.RepeatPenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).RepeatPenalty = &penalty
 	}
 }
 
 // WithSeed enables deterministic generation
 func WithSeed(seed int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Seed = &seed
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
 	}
 }
 
@@ -53,16 +83,16 @@ func WithSeed(seed int) GenerateOption {
 // return thinking content in a separate message.thinking field
 // the adapter routes to Message.Thinking
 func WithThink(think bool) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Think = &think
+	return func(c *common.GenerateRequest) {
+		extension(c).Think = &think
 	}
 }
 
 // WithKeepAlive tunes how long Ollama keeps the model warm in RAM after a
 // request. Accepts Go-style durations ("5m", "1h") or "0" to unload.
 func WithKeepAlive(s string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.KeepAlive = &s
+	return func(c *common.GenerateRequest) {
+		extension(c).KeepAlive = &s
 	}
 }
 
@@ -70,47 +100,56 @@ func WithKeepAlive(s string) GenerateOption {
 
 // WithTemperature sets response randomness (0.0-2.0)
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
 
 // WithTopP sets nucleus sampling threshold (0.0-1.0)
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets maximum tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences to halt generation
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
@@ -1,7 +1,9 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -17,11 +19,57 @@ type Provider struct{}
 // ensure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and stop reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider can embed text
+var _ common.Embedder = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// defaultBaseURL is used when providers.ollama.base_url is unset
+const defaultBaseURL = "http://localhost:11434"
+
 // New creates a new Ollama provider instance
 func New() *Provider {
 	return &Provider{}
 }
 
+// thinkingModels are the model families Ollama serves with a think toggle.
+// conservative allowlist, as the server rejects think for other models
+var thinkingModels = []string{
+	"deepseek-r1", "deepseek-v3.1", "qwen3", "qwq", "gpt-oss", "magistral",
+	"phi4-reasoning", "phi4-mini-reasoning", "cogito", "openthinker", "exaone-deep",
+}
+
+// supportsThinking reports whether a model accepts the think flag. the tag
+// and any namespace are ignored, so "qwen3:8b" and "library/qwen3" match
+func supportsThinking(modelName string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(modelName), ":")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, family := range thinkingModels {
+		if strings.HasPrefix(name, family) {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateClient creates a new LLM client using the unified adapter pattern
 func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
 	// create client options
@@ -29,7 +77,7 @@ func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common
 	if cfg.Providers.Ollama.BaseUrl != "" {
 		opts = append(opts, common.WithBaseURL(cfg.Providers.Ollama.BaseUrl))
 	} else {
-		opts = append(opts, common.WithBaseURL("http://localhost:11434"))
+		opts = append(opts, common.WithBaseURL(defaultBaseURL))
 	}
 	if logger != nil {
 		opts = append(opts, common.WithLogger(logger))
@@ -46,13 +94,26 @@ func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Ollama.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Ollama.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.ollama: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
 	// Ollama runs locally and doesn't require an API key
-	adapterClient := common.NewAdapterClient(p, "", "http://localhost:11434", opts...)
+	adapterClient := common.NewAdapterClient(p, "", defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates Ollama-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -73,13 +134,19 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// populate ResponseFormat with the user's schema when supplied.
 	// Parameters.ResponseSchema is resolved to inline JSON at config-load
 	// time, so the raw bytes are ready for direct passthrough.
 	if cfg.Parameters.ResponseSchema != "" {
 		schemaOpt := common.WithSchema("response", []byte(cfg.Parameters.ResponseSchema))
-		functionalOpts = append(functionalOpts, func(o *GenerateOptions) {
+		functionalOpts = append(functionalOpts, func(o *common.GenerateRequest) {
 			schemaOpt(&o.GenerateOptions)
 		})
 	}
@@ -96,7 +163,7 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 		functionalOpts = append(functionalOpts, WithKeepAlive(cfg.Providers.Ollama.KeepAlive))
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // RequiresAPIKey returns false (Ollama doesn't require an API key)
@@ -110,17 +177,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates an Ollama-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to Ollama-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if ollamaOpts, ok := options.(*GenerateOptions); ok {
-			config = ollamaOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Ollama's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -129,8 +193,11 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	// create Ollama-specific request payload
 	requestBody := &ChatRequest{
 		Model:    modelName,
-		Messages: messages,
-		Stream:   false, // disable streaming for now
+		Messages: convertMessages(messages),
+		Stream:   config.Stream,
+	}
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
 	}
 
 	// build options map for Ollama-specific parameters
@@ -151,14 +218,14 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map Ollama-specific options
-	if config.TopK != nil {
-		optionsMap["top_k"] = *config.TopK
+	if ext.TopK != nil {
+		optionsMap["top_k"] = *ext.TopK
 	}
-	if config.RepeatPenalty != nil {
-		optionsMap["repeat_penalty"] = *config.RepeatPenalty
+	if ext.RepeatPenalty != nil {
+		optionsMap["repeat_penalty"] = *ext.RepeatPenalty
 	}
-	if config.Seed != nil {
-		optionsMap["seed"] = *config.Seed
+	if ext.Seed != nil {
+		optionsMap["seed"] = *ext.Seed
 	}
 
 	// only set options if we have any
@@ -180,14 +247,15 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 		}
 	}
 
-	// wire the native think flag through to the API
-	if config.Think != nil {
-		requestBody.Think = config.Think
+	// wire the native think flag through to the API; other models get no
+	// think field, so a stray --thinking never breaks a request
+	if ext.Think != nil && (!*ext.Think || supportsThinking(modelName)) {
+		requestBody.Think = ext.Think
 	}
 
 	// wire keep_alive through so users can tune model residency in RAM
-	if config.KeepAlive != nil {
-		requestBody.KeepAlive = config.KeepAlive
+	if ext.KeepAlive != nil {
+		requestBody.KeepAlive = ext.KeepAlive
 	}
 
 	return requestBody, nil
@@ -231,20 +299,115 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, usage, nil
 }
 
+// ParseMetadata extracts the resolved model and done reason from an Ollama response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return common.ResponseMetadata{}
+	}
+	return common.ResponseMetadata{Model: chatResp.Model, FinishReason: chatResp.DoneReason}
+}
+
+// StreamFormat reports that Ollama streams newline-delimited JSON
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamNDJSON
+}
+
+// ParseStreamEvent parses one NDJSON line from Ollama's streaming chat API.
+// each line is a partial ChatResponse; the final line has done=true and
+// carries the token counts
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(data, &chatResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(data))
+		return nil, fmt.Errorf("failed to unmarshal Ollama stream chunk: %w", err)
+	}
+
+	// Ollama reports mid-stream failures as {"error": "..."}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(data, &errResp); err == nil && errResp.Error != "" {
+		return nil, fmt.Errorf("an ollama API error occurred: %s", errResp.Error)
+	}
+
+	chunk := &common.StreamChunk{
+		Content:      chatResp.Message.Content,
+		Thinking:     chatResp.Message.Thinking,
+		Model:        chatResp.Model,
+		FinishReason: chatResp.DoneReason,
+		Done:         chatResp.Done,
+	}
+	if chatResp.Done && chatResp.PromptEvalCount > 0 {
+		chunk.Usage = &common.Usage{
+			PromptTokens:     chatResp.PromptEvalCount,
+			CompletionTokens: chatResp.EvalCount,
+			TotalTokens:      chatResp.PromptEvalCount + chatResp.EvalCount,
+		}
+	}
+	return chunk, nil
+}
+
+// ParseToolCalls extracts the tool calls requested by an Ollama response.
+// Ollama does not assign call IDs, so positional IDs are generated
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("failed to unmarshal Ollama response: %w", err)
+	}
+
+	var calls []common.ToolCall
+	for i, call := range chatResp.Message.ToolCalls {
+		calls = append(calls, common.ToolCall{
+			ID:   fmt.Sprintf("call_%d", i),
+			Type: "function",
+			Function: common.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: string(call.Function.Arguments),
+			},
+		})
+	}
+	return calls, nil
+}
+
+// convertMessages maps common messages onto Ollama's message shape
+func convertMessages(messages []common.Message) []Message {
+	converted := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		m := Message{
+			Role:     msg.Role,
+			Content:  msg.Content,
+			Thinking: msg.Thinking,
+			ToolName: msg.ToolName,
+		}
+		for _, image := range msg.Images {
+			m.Images = append(m.Images, image.Base64())
+		}
+		for _, call := range msg.ToolCalls {
+			args := json.RawMessage(call.Function.Arguments)
+			if len(args) == 0 {
+				args = json.RawMessage(`{}`)
+			}
+			m.ToolCalls = append(m.ToolCalls, ToolCall{
+				Function: ToolCallFunction{Name: call.Function.Name, Arguments: args},
+			})
+		}
+		converted = append(converted, m)
+	}
+	return converted
+}
+
+// SupportsImages reports true for every model: local model capabilities
+// aren't known ahead of time, so Ollama itself rejects images a model can't use
+func (p *Provider) SupportsImages(modelName string) bool {
+	return true
+}
+
 // HandleError creates Ollama-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
 	// error message due to invalid model name or missing model
 	if strings.Contains(string(body), "try pulling") || strings.Contains(string(body), "not found") {
-		return fmt.Errorf(`The requested model was not found. It may not be installed locally or available on the Ollama server.
-
-To see all models you have installed, run:
-    ollama list
-
-To download a model, use:
-    ollama pull [model_name]
-
-To see available models, visit: https://ollama.com/search`)
+		return &ModelNotFoundError{}
 	}
 
 	// 413 status code / request is too large
@@ -295,3 +458,75 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 
 	return nil
 }
+
+// ListModels lists the models pulled into the local Ollama server via GET /api/tags
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	client, err := common.NewAPIClient(cfg.Providers.Ollama.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, serverURL(cfg)+"/api/tags", nil, p.HandleError)
+	if err != nil {
+		var connErr *common.ConnectionError
+		if errors.As(err, &connErr) {
+			return nil, &common.ConnectionError{Err: p.HandleConnectionError(connErr.Err)}
+		}
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Embed returns a vector per input via the native POST /api/embed
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	client, err := common.NewAPIClient(cfg.Providers.Ollama.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.PostJSON(ctx, client, serverURL(cfg)+"/api/embed", nil,
+		map[string]interface{}{"model": modelName, "input": inputs}, p.HandleError)
+	if err != nil {
+		var connErr *common.ConnectionError
+		if errors.As(err, &connErr) {
+			return nil, &common.ConnectionError{Err: p.HandleConnectionError(connErr.Err)}
+		}
+		return nil, err
+	}
+
+	var resp struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float64 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	}
+	return &common.EmbedResult{
+		Vectors: resp.Embeddings,
+		Model:   resp.Model,
+		Usage:   &common.Usage{PromptTokens: resp.PromptEvalCount, TotalTokens: resp.PromptEvalCount},
+	}, nil
+}
+
+// Capabilities reports the features an Ollama model honors. thinking follows
+// the model family; the rest are passed through, and Ollama rejects what a
+// model can't use
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsThinking(modelName),
+		Schema:   true,
+		JSON:     true,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists Ollama's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "qwen3", Example: "qwen3:8b"},
+		{Name: "gpt-oss", Example: "gpt-oss:20b"},
+		{Name: "deepseek-r1", Example: "deepseek-r1:8b"},
+		{Name: "other models", Example: "gemma4:latest"},
+	}
+}
@@ -1,10 +1,12 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/chriscorrea/slop/internal/config"
@@ -92,7 +94,7 @@ func TestBuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		validate func(t *testing.T, request interface{})
 	}{
 		{
@@ -102,27 +104,29 @@ func TestBuildRequest(t *testing.T) {
 				chatReq, ok := request.(*ChatRequest)
 				assert.True(t, ok, "Request should be *ChatRequest")
 				assert.Equal(t, modelName, chatReq.Model)
-				assert.Equal(t, messages, chatReq.Messages)
+				assert.Equal(t, []Message{{Role: "user", Content: "Oink"}}, chatReq.Messages)
 				assert.Equal(t, false, chatReq.Stream)
 			},
 		},
 		{
 			name: "Build request with generation options",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					Temperature: common.Float64Ptr(0.8),
 					MaxTokens:   common.IntPtr(1000),
 					TopP:        common.Float64Ptr(0.9),
 					Stop:        []string{"STOP"},
 				},
-				TopK: common.IntPtr(50),
-				Seed: common.IntPtr(42),
+				Extension: &Extension{
+					TopK: common.IntPtr(50),
+					Seed: common.IntPtr(42),
+				},
 			},
 			validate: func(t *testing.T, request interface{}) {
 				chatReq, ok := request.(*ChatRequest)
 				assert.True(t, ok, "Request should be *ChatRequest")
 				assert.Equal(t, modelName, chatReq.Model)
-				assert.Equal(t, messages, chatReq.Messages)
+				assert.Equal(t, []Message{{Role: "user", Content: "Oink"}}, chatReq.Messages)
 
 				// Verify options mapping
 				assert.NotNil(t, chatReq.Options)
@@ -137,7 +141,7 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Build request with JSON format",
-			options: &GenerateOptions{
+			options: &common.GenerateRequest{
 				GenerateOptions: common.GenerateOptions{
 					ResponseFormat: &common.ResponseFormat{Type: "json_object"},
 				},
@@ -150,9 +154,9 @@ func TestBuildRequest(t *testing.T) {
 		},
 		{
 			name: "Build request with JSON schema passthrough",
-			options: func() interface{} {
+			options: func() *common.GenerateRequest {
 				schema := []byte(`{"type":"object","properties":{"quote":{"type":"string"}},"required":["quote"]}`)
-				return NewGenerateOptions(func(o *GenerateOptions) {
+				return NewGenerateOptions(func(o *common.GenerateRequest) {
 					common.WithSchema("character_quote", schema)(&o.GenerateOptions)
 				})
 			}(),
@@ -393,9 +397,8 @@ func TestBuildOptions_Thinking(t *testing.T) {
 			}
 			opts := provider.BuildOptions(cfg)
 			require := assert.New(t)
-			require.Len(opts, 1)
-			ollamaOpts, ok := opts[0].(*GenerateOptions)
-			require.True(ok)
+			ollamaOpts, err := common.ExtensionOf[Extension](opts, "ollama")
+			require.NoError(err)
 
 			if tt.wantThink == nil {
 				require.Nil(ollamaOpts.Think)
@@ -407,6 +410,37 @@ func TestBuildOptions_Thinking(t *testing.T) {
 	}
 }
 
+// TestBuildRequest_ThinkingModels verifies the think flag reaches only
+// models that accept it
+func TestBuildRequest_ThinkingModels(t *testing.T) {
+	provider := New()
+	messages := []common.Message{{Role: "user", Content: "Oink"}}
+
+	tests := []struct {
+		model string
+		think *bool
+		want  *bool
+	}{
+		{"qwen3:8b", boolPtr(true), boolPtr(true)},
+		{"hf.co/unsloth/DeepSeek-R1-Distill-Qwen-7B-GGUF", boolPtr(true), boolPtr(true)},
+		{"gpt-oss:20b", boolPtr(true), boolPtr(true)},
+		{"gemma4:latest", boolPtr(true), nil},
+		{"llama3.2", boolPtr(true), nil},
+		{"llama3.2", boolPtr(false), boolPtr(false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			req := &common.GenerateRequest{Extension: &Extension{Think: tt.think}}
+			request, err := provider.BuildRequest(messages, tt.model, req, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, request.(*ChatRequest).Think)
+			assert.Equal(t, tt.want != nil && *tt.want, provider.Capabilities(tt.model).Thinking)
+		})
+	}
+}
+
 // TestParseResponse_StructuredThinking confirms that Ollama's native
 // message.thinking is re-inlined as a <think> tag
 func TestParseResponse_StructuredThinking(t *testing.T) {
@@ -475,9 +509,8 @@ func TestBuildOptions_KeepAlive(t *testing.T) {
 			}
 			opts := provider.BuildOptions(cfg)
 			require := assert.New(t)
-			require.Len(opts, 1)
-			ollamaOpts, ok := opts[0].(*GenerateOptions)
-			require.True(ok)
+			ollamaOpts, err := common.ExtensionOf[Extension](opts, "ollama")
+			require.NoError(err)
 
 			if tt.wantKeepAlive == nil {
 				require.Nil(ollamaOpts.KeepAlive)
@@ -487,7 +520,7 @@ func TestBuildOptions_KeepAlive(t *testing.T) {
 			}
 
 			// verify it also reaches the final request body
-			req, err := provider.BuildRequest(nil, "gemma4:latest", ollamaOpts, slog.Default())
+			req, err := provider.BuildRequest(nil, "gemma4:latest", opts, slog.Default())
 			require.NoError(err)
 			chatReq, ok := req.(*ChatRequest)
 			require.True(ok)
@@ -510,11 +543,9 @@ func TestBuildOptions_ResponseSchema(t *testing.T) {
 		Parameters: config.Parameters{ResponseSchema: schema},
 	}
 
-	opts := provider.BuildOptions(cfg)
+	ollamaOpts := provider.BuildOptions(cfg)
 	require := assert.New(t)
-	require.Len(opts, 1)
-	ollamaOpts, ok := opts[0].(*GenerateOptions)
-	require.True(ok)
+	require.NotNil(ollamaOpts)
 	require.NotNil(ollamaOpts.ResponseFormat)
 	require.Equal("json_schema", ollamaOpts.ResponseFormat.Type)
 	require.JSONEq(schema, string(ollamaOpts.ResponseFormat.Schema))
@@ -537,3 +568,131 @@ func boolPtr(b bool) *bool {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestProvider_ParseStreamEvent(t *testing.T) {
+	provider := New()
+	logger := slog.Default()
+
+	chunk, err := provider.ParseStreamEvent([]byte(`{"model":"llama3","message":{"role":"assistant","content":"Hel"},"done":false}`), logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hel", chunk.Content)
+	assert.False(t, chunk.Done)
+	assert.Nil(t, chunk.Usage)
+
+	chunk, err = provider.ParseStreamEvent([]byte(`{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"done_reason":"length","prompt_eval_count":9,"eval_count":3}`), logger)
+	assert.NoError(t, err)
+	assert.True(t, chunk.Done)
+	assert.Equal(t, "llama3", chunk.Model)
+	assert.Equal(t, "length", chunk.FinishReason)
+	assert.Equal(t, &common.Usage{PromptTokens: 9, CompletionTokens: 3, TotalTokens: 12}, chunk.Usage)
+
+	_, err = provider.ParseStreamEvent([]byte(`{"error":"model crashed"}`), logger)
+	assert.ErrorContains(t, err, "model crashed")
+}
+
+func TestProvider_ParseMetadata(t *testing.T) {
+	provider := New()
+
+	meta := provider.ParseMetadata([]byte(`{"model":"llama3:8b","message":{"role":"assistant","content":"Hi"},"done":true,"done_reason":"stop"}`))
+	assert.Equal(t, common.ResponseMetadata{Model: "llama3:8b", FinishReason: "stop"}, meta)
+}
+
+func TestProvider_Tools(t *testing.T) {
+	provider := New()
+	logger := slog.Default()
+
+	messages := []common.Message{
+		{Role: "user", Content: "what day is it?"},
+		{Role: "assistant", ToolCalls: []common.ToolCall{{ID: "call_0", Type: "function", Function: common.FunctionCall{Name: "date", Arguments: `{"tz":"UTC"}`}}}},
+		{Role: "tool", ToolCallID: "call_0", ToolName: "date", Content: "Friday"},
+	}
+	opts := NewGenerateOptions(WithTools([]common.ToolConfig{{Type: "function", Function: common.FunctionDefinition{Name: "date"}}}))
+
+	request, err := provider.BuildRequest(messages, "qwen3", opts, logger)
+	assert.NoError(t, err)
+	chatReq := request.(*ChatRequest)
+
+	assert.Len(t, chatReq.Tools, 1)
+	encoded, err := json.Marshal(chatReq.Messages)
+	assert.NoError(t, err)
+	// Ollama expects arguments as an object and results addressed by tool_name
+	assert.JSONEq(t, `[
+		{"role":"user","content":"what day is it?"},
+		{"role":"assistant","content":"","tool_calls":[{"function":{"name":"date","arguments":{"tz":"UTC"}}}]},
+		{"role":"tool","content":"Friday","tool_name":"date"}
+	]`, string(encoded))
+
+	body := []byte(`{"model":"qwen3","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"date","arguments":{"tz":"UTC"}}}]},"done":true}`)
+	calls, err := provider.ParseToolCalls(body, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.ToolCall{{
+		ID:       "call_0",
+		Type:     "function",
+		Function: common.FunctionCall{Name: "date", Arguments: `{"tz":"UTC"}`},
+	}}, calls)
+}
+
+func TestProvider_Images(t *testing.T) {
+	provider := New()
+	messages := []common.Message{{
+		Role:    "user",
+		Content: "describe this",
+		Images:  []common.Image{{MediaType: "image/png", Data: []byte("\x89PNG")}},
+	}}
+
+	request, err := provider.BuildRequest(messages, "llava", nil, slog.Default())
+	assert.NoError(t, err)
+
+	encoded, err := json.Marshal(request.(*ChatRequest).Messages)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"role":"user","content":"describe this","images":["iVBORw=="]}]`, string(encoded))
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		_, _ = w.Write([]byte(`{"models":[{"name":"llama3.2:latest","model":"llama3.2:latest","size":2019393189},{"name":"gemma3:4b","model":"gemma3:4b"}]}`))
+	}))
+
+	cfg := &config.Config{}
+	cfg.Providers.Ollama.BaseUrl = server.URL
+	models, err := New().ListModels(context.Background(), cfg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"gemma3:4b", "llama3.2:latest"}, models)
+	}
+
+	// a stopped server gets Ollama's connection advice
+	server.Close()
+	_, err = New().ListModels(context.Background(), cfg)
+	var connErr *common.ConnectionError
+	assert.ErrorAs(t, err, &connErr)
+	assert.ErrorContains(t, err, "Cannot connect to Ollama server")
+}
+
+func TestProvider_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["model"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2]],"prompt_eval_count":5}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Ollama.BaseUrl = server.URL + "/v1"
+	result, err := New().Embed(context.Background(), cfg, "nomic-embed-text", []string{"hello"}, common.EmbedClustering)
+	if assert.NoError(t, err) {
+		assert.Equal(t, [][]float64{{0.1, 0.2}}, result.Vectors)
+		assert.Equal(t, 5, result.Usage.PromptTokens)
+	}
+
+	_, err = New().Embed(context.Background(), cfg, "missing", []string{"hello"}, common.EmbedClustering)
+	var notFound *ModelNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	assert.ErrorIs(t, err, common.ErrModelNotFound)
+}
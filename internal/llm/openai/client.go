@@ -14,6 +14,7 @@ type ChatRequest struct {
 	TopP                *float64            `json:"top_p,omitempty"`
 	MaxCompletionTokens *int                `json:"max_completion_tokens,omitempty"`
 	Stream              *bool               `json:"stream,omitempty"`
+	StreamOptions       *StreamOptions      `json:"stream_options,omitempty"`
 	Stop                []string            `json:"stop,omitempty"`
 	FrequencyPenalty    *float64            `json:"frequency_penalty,omitempty"`
 	PresencePenalty     *float64            `json:"presence_penalty,omitempty"`
@@ -24,6 +25,12 @@ type ChatRequest struct {
 	ToolChoice          interface{}         `json:"tool_choice,omitempty"`
 }
 
+// StreamOptions configures streamed responses. IncludeUsage asks OpenAI to
+// append a final chunk carrying token usage
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
 // chatResponseFormat is the OpenAI wire-shape for the response_format field.
 // For json_object it serializes to {"type":"json_object"}.
 // For json_schema it nests the schema under a json_schema envelope.
@@ -5,7 +5,7 @@
 //
 // Example usage:
 //   client := openai.NewClient(apiKey)
-//   response, err := client.Generate(ctx, messages, openai.WithTemperature(0.7))
+//   response, err := client.Generate(ctx, messages, model, openai.NewGenerateOptions(openai.WithTemperature(0.7)))
 //
 // OpenAI models include: gpt-4.1-2025-04-14, o4-mini-2025-04-16, o3-2025-04-16
 // OpenAI model documentation: https://platform.openai.com/docs/models
@@ -13,6 +13,7 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -85,6 +86,33 @@ type Provider struct{}
 // ensure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider can embed text
+var _ common.Embedder = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// textOnlyModelPrefixes are OpenAI model families without vision input
+var textOnlyModelPrefixes = []string{"gpt-3.5", "o1-mini", "o3-mini"}
+
+// defaultBaseURL is used when providers.openai.base_url is unset
+const defaultBaseURL = "https://api.openai.com/v1"
+
 // New creates a new OpenAI provider instance
 func New() *Provider {
 	return &Provider{}
@@ -123,12 +151,25 @@ Get an API key from https://platform.openai.com/api-keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.OpenAI.APIKey, "https://api.openai.com/v1", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.OpenAI.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.OpenAI.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.openai: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.OpenAI.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates OpenAI-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -149,6 +190,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, withCommonTools(tools))
+	}
 
 	// translate thinking into reasoning_effort
 	if level, err := common.ParseThinkingLevel(cfg.Parameters.Thinking); err == nil {
@@ -163,17 +210,25 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 		functionalOpts = append(functionalOpts, withCommonSchema("response", []byte(schema)))
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // withCommonSchema adapts the common WithSchema option into the OpenAI
 // GenerateOption signature so it can be applied alongside provider-specific options
 func withCommonSchema(name string, schema []byte) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithSchema(name, schema)(&c.GenerateOptions)
 	}
 }
 
+// withCommonTools adapts the common WithTools option into the OpenAI
+// GenerateOption signature; BuildRequest converts them to OpenAI tools
+func withCommonTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
+}
+
 // RequiresAPIKey returns true since OpenAI requires an API key
 func (p *Provider) RequiresAPIKey() bool {
 	return true
@@ -185,17 +240,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates an OpenAI-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to OpenAI-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if openaiOpts, ok := options.(*GenerateOptions); ok {
-			config = openaiOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes OpenAI's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request using common utilities
@@ -205,7 +257,12 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	requestBody := &ChatRequest{
 		Model:    modelName,
 		Messages: messages,
-		Stream:   common.BoolPtr(false), // Disable streaming for now
+		Stream:   common.BoolPtr(config.Stream),
+	}
+
+	// streamed responses omit usage unless explicitly requested
+	if config.Stream {
+		requestBody.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 
 	// map common generation options to OpenAI's API format
@@ -223,19 +280,30 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map OpenAI-specific options
-	if config.FrequencyPenalty != nil {
-		requestBody.FrequencyPenalty = config.FrequencyPenalty
+	if ext.FrequencyPenalty != nil {
+		requestBody.FrequencyPenalty = ext.FrequencyPenalty
 	}
-	if config.PresencePenalty != nil {
-		requestBody.PresencePenalty = config.PresencePenalty
+	if ext.PresencePenalty != nil {
+		requestBody.PresencePenalty = ext.PresencePenalty
 	}
-	if config.Seed != nil {
-		requestBody.Seed = config.Seed
+	if ext.Seed != nil {
+		requestBody.Seed = ext.Seed
 	}
-	if len(config.Tools) > 0 {
+
+	// tools declared in config arrive as common tool definitions; copy so
+	// repeated requests with the same options don't accumulate them
+	tools := append([]Tool{}, ext.Tools...)
+	for _, tool := range config.Tools {
+		function := Function{Name: tool.Function.Name, Description: tool.Function.Description}
+		if len(tool.Function.Parameters) > 0 {
+			function.Parameters = tool.Function.Parameters
+		}
+		tools = append(tools, Tool{Type: "function", Function: function})
+	}
+	if len(tools) > 0 {
 		// validate every tool before sending — the API rejects requests with
 		// unnamed tools or malformed parameter schemas, so fail fast if invalid
-		for i, tool := range config.Tools {
+		for i, tool := range tools {
 			if strings.TrimSpace(tool.Function.Name) == "" {
 				return nil, fmt.Errorf("tool at index %d is missing a function name", i)
 			}
@@ -258,17 +326,19 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 				}
 			}
 		}
-		requestBody.Tools = config.Tools
+		requestBody.Tools = tools
 	}
-	if config.ToolChoice != nil {
+	if ext.ToolChoice != nil {
+		requestBody.ToolChoice = ext.ToolChoice
+	} else if config.ToolChoice != nil {
 		requestBody.ToolChoice = config.ToolChoice
 	}
 
 	// reasoning_effort is only accepted by GPT-5 thinking variants and the
 	// o-series. For other models silently no-op so users can keep a global
 	// thinking default without errors when switching providers/models
-	if config.ReasoningEffort != nil && supportsThinking(modelName) {
-		requestBody.ReasoningEffort = config.ReasoningEffort
+	if ext.ReasoningEffort != nil && supportsThinking(modelName) {
+		requestBody.ReasoningEffort = ext.ReasoningEffort
 	}
 
 	// map structured output into OpenAI's wire shape
@@ -314,6 +384,37 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, &chatResp.Usage, nil
 }
 
+// ParseToolCalls extracts the tool calls requested by an OpenAI response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	return common.ParseChatCompletionToolCalls(body, "OpenAI", logger)
+}
+
+// SupportsImages reports whether the model accepts image_url content parts
+func (p *Provider) SupportsImages(modelName string) bool {
+	id := strings.ToLower(modelName)
+	for _, prefix := range textOnlyModelPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a OpenAI response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	return common.ParseChatCompletionMetadata(body)
+}
+
+// StreamFormat reports that OpenAI streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed OpenAI chat completion chunk
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	return common.ParseChatCompletionChunk(data, "OpenAI", logger)
+}
+
 // HandleError creates OpenAI-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -359,3 +460,73 @@ func (p *Provider) CustomizeRequest(req *http.Request) error {
 	// this is implemented for completeness/future extensibility
 	return nil
 }
+
+// ListModels lists the models available to the API key via GET /models
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.OpenAI.APIKey == "" {
+		return nil, fmt.Errorf("OpenAI API key is required to list models")
+	}
+	baseURL := cfg.Providers.OpenAI.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.OpenAI.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.OpenAI.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Embed returns a vector per input via POST /embeddings
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	if cfg.Providers.OpenAI.APIKey == "" {
+		return nil, fmt.Errorf("OpenAI API key is required to embed text")
+	}
+	baseURL := cfg.Providers.OpenAI.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.OpenAI.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.PostJSON(ctx, client, common.BuildEmbeddingsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.OpenAI.APIKey,
+	}, map[string]interface{}{"model": modelName, "input": inputs}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseEmbeddings(body)
+}
+
+// Capabilities reports the features an OpenAI model honors
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: supportsThinking(modelName),
+		Schema:   true,
+		JSON:     true,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies lists OpenAI's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "gpt-5", Example: "gpt-5"},
+		{Name: "gpt-4.1", Example: "gpt-4.1"},
+		{Name: "gpt-4o", Example: "gpt-4o"},
+		{Name: "o-series", Example: "o3"},
+		{Name: "o3-mini", Example: "o3-mini"},
+		{Name: "gpt-3.5", Example: "gpt-3.5-turbo"},
+	}
+}
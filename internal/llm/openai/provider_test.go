@@ -111,7 +111,7 @@ func TestProvider_BuildOptions(t *testing.T) {
 	tests := []struct {
 		name     string
 		config   *config.Config
-		expected int // expected number of options
+		wantSeed *int // expected seed in the openai extension
 	}{
 		{
 			name: "minimal config",
@@ -119,7 +119,6 @@ func TestProvider_BuildOptions(t *testing.T) {
 				Parameters: config.Parameters{},
 				Format:     config.Format{},
 			},
-			expected: 1, // always returns at least one GenerateOptions object
 		},
 		{
 			name: "full config",
@@ -135,21 +134,18 @@ func TestProvider_BuildOptions(t *testing.T) {
 					JSON: true,
 				},
 			},
-			expected: 1, // still returns one GenerateOptions object, but with all options set
+			wantSeed: common.IntPtr(42),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			options := provider.BuildOptions(tt.config)
-			assert.Len(t, options, tt.expected)
+			require.NotNil(t, options)
 
-			// verify the first option is a GenerateOptions
-			if len(options) > 0 {
-				generateOpts, ok := options[0].(*GenerateOptions)
-				assert.True(t, ok)
-				assert.NotNil(t, generateOpts)
-			}
+			ext, err := common.ExtensionOf[Extension](options, "openai")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSeed, ext.Seed)
 		})
 	}
 }
@@ -163,7 +159,7 @@ func TestProvider_BuildRequest(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		options  interface{}
+		options  *common.GenerateRequest
 		expected *ChatRequest
 	}{
 		{
@@ -211,15 +207,6 @@ func TestProvider_BuildRequest(t *testing.T) {
 				Stream: common.BoolPtr(false),
 			},
 		},
-		{
-			name:    "invalid options type",
-			options: "invalid",
-			expected: &ChatRequest{
-				Model:    modelName,
-				Messages: messages,
-				Stream:   common.BoolPtr(false),
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -259,6 +246,46 @@ func TestProvider_BuildRequest(t *testing.T) {
 	}
 }
 
+// otherExtension stands in for another provider's options
+type otherExtension struct{}
+
+func (otherExtension) Provider() string { return "anthropic" }
+func (otherExtension) Validate() error  { return nil }
+
+func TestProvider_BuildRequest_ForeignExtension(t *testing.T) {
+	provider := New()
+	messages := []common.Message{{Role: "user", Content: "hi"}}
+
+	req := common.NewGenerateRequest(common.WithTemperature(0.5))
+	req.Extension = otherExtension{}
+
+	_, err := provider.BuildRequest(messages, "gpt-4o", req, slog.Default())
+	assert.EqualError(t, err, "anthropic options can't be sent to openai")
+}
+
+func TestExtension_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *common.GenerateRequest
+		wantErr string
+	}{
+		{"valid", NewGenerateOptions(WithFrequencyPenalty(1.5), WithReasoningEffort("low")), ""},
+		{"penalty out of range", NewGenerateOptions(WithPresencePenalty(2.5)), "presence_penalty"},
+		{"unknown reasoning effort", NewGenerateOptions(WithReasoningEffort("extreme")), "reasoning_effort"},
+		{"common section", NewGenerateOptions(WithTemperature(3)), "temperature 3 is out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
 func TestProvider_ParseResponse(t *testing.T) {
 	provider := New()
 
@@ -526,14 +553,13 @@ func TestProvider_BuildOptions_ThinkingAndSchema(t *testing.T) {
 		},
 	}
 
-	out := provider.BuildOptions(cfg)
-	require.Len(t, out, 1)
+	genOpts := provider.BuildOptions(cfg)
+	require.NotNil(t, genOpts)
 
-	genOpts, ok := out[0].(*GenerateOptions)
+	ext, ok := genOpts.Extension.(*Extension)
 	require.True(t, ok)
-
-	require.NotNil(t, genOpts.ReasoningEffort)
-	assert.Equal(t, "high", *genOpts.ReasoningEffort)
+	require.NotNil(t, ext.ReasoningEffort)
+	assert.Equal(t, "high", *ext.ReasoningEffort)
 
 	require.NotNil(t, genOpts.ResponseFormat)
 	assert.Equal(t, "json_schema", genOpts.ResponseFormat.Type)
@@ -743,7 +769,135 @@ func TestProvider_Integration(t *testing.T) {
 		{Role: "user", Content: "test message"},
 	}
 
-	content, err := client.Generate(context.Background(), messages, "gpt-4")
+	result, err := client.Generate(context.Background(), messages, "gpt-4", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "test response", result.Content)
+}
+
+func TestProvider_ConfiguredTools(t *testing.T) {
+	provider := New()
+	cfg := &config.Config{
+		Parameters: config.Parameters{Tools: []string{"date"}},
+		Tools:      map[string]config.Tool{"date": {Description: "Print the date", Command: "date"}},
+	}
+
+	opts := provider.BuildOptions(cfg)
+	require.NotNil(t, opts)
+
+	messages := []common.Message{
+		{Role: "user", Content: "what day is it?"},
+		{Role: "assistant", ToolCalls: []common.ToolCall{{ID: "call_1", Type: "function", Function: common.FunctionCall{Name: "date", Arguments: "{}"}}}},
+		{Role: "tool", ToolCallID: "call_1", ToolName: "date", Content: "Friday"},
+	}
+
+	// build twice to make sure config tools don't accumulate on the options
+	_, err := provider.BuildRequest(messages, "gpt-4o", opts, slog.Default())
+	require.NoError(t, err)
+	request, err := provider.BuildRequest(messages, "gpt-4o", opts, slog.Default())
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	var wire struct {
+		Messages []map[string]interface{} `json:"messages"`
+		Tools    []Tool                   `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &wire))
+
+	require.Len(t, wire.Tools, 1)
+	assert.Equal(t, "date", wire.Tools[0].Function.Name)
+	assert.Equal(t, "Print the date", wire.Tools[0].Function.Description)
+
+	// tool turns replay in OpenAI's native shape; the tool name stays local
+	assert.NotNil(t, wire.Messages[1]["tool_calls"])
+	assert.Equal(t, "call_1", wire.Messages[2]["tool_call_id"])
+	assert.NotContains(t, wire.Messages[2], "name")
+}
+
+func TestProvider_ParseToolCalls(t *testing.T) {
+	provider := New()
+	body := []byte(`{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_abc","type":"function","function":{"name":"date","arguments":"{\"tz\":\"UTC\"}"}}]},"finish_reason":"tool_calls"}]}`)
+
+	calls, err := provider.ParseToolCalls(body, slog.Default())
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "call_abc", calls[0].ID)
+	assert.Equal(t, `{"tz":"UTC"}`, calls[0].Function.Arguments)
+
+	// tool-call responses have null content, which must still parse
+	content, _, err := provider.ParseResponse(body, slog.Default())
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
+
+func TestProvider_Images(t *testing.T) {
+	provider := New()
+	messages := []common.Message{{
+		Role:    "user",
+		Content: "describe this",
+		Images:  []common.Image{{MediaType: "image/jpeg", Data: []byte("\xff\xd8")}},
+	}}
+
+	request, err := provider.BuildRequest(messages, "gpt-4o", nil, slog.Default())
 	require.NoError(t, err)
-	assert.Equal(t, "test response", content)
+
+	encoded, err := json.Marshal(request.(*ChatRequest).Messages)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"role":"user","content":[
+		{"type":"text","text":"describe this"},
+		{"type":"image_url","image_url":{"url":"data:image/jpeg;base64,/9g="}}
+	]}]`, string(encoded))
+
+	assert.True(t, provider.SupportsImages("gpt-5.4-mini"))
+	assert.False(t, provider.SupportsImages("gpt-3.5-turbo"))
+	assert.False(t, provider.SupportsImages("o3-mini"))
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o-mini"},{"id":"gpt-4o"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.OpenAI.APIKey = "test-key"
+	cfg.Providers.OpenAI.BaseUrl = server.URL + "/v1"
+
+	models, err := New().ListModels(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, models)
+
+	_, err = New().ListModels(context.Background(), &config.Config{})
+	assert.ErrorContains(t, err, "API key is required")
+}
+
+func TestProvider_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "text-embedding-3-small", req["model"])
+		assert.Equal(t, []interface{}{"a", "b"}, req["input"])
+		// entries may arrive out of order; index decides
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}],
+			"model":"text-embedding-3-small","usage":{"prompt_tokens":2,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.OpenAI.APIKey = "test-key"
+	cfg.Providers.OpenAI.BaseUrl = server.URL + "/v1"
+
+	result, err := New().Embed(context.Background(), cfg, "text-embedding-3-small", []string{"a", "b"}, common.EmbedClustering)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, result.Vectors)
+	assert.Equal(t, "text-embedding-3-small", result.Model)
+	assert.Equal(t, 2, result.Usage.TotalTokens)
+
+	_, err = New().Embed(context.Background(), &config.Config{}, "text-embedding-3-small", []string{"a"}, common.EmbedClustering)
+	assert.ErrorContains(t, err, "API key is required")
 }
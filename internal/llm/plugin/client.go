@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// apiKeyEnv is the environment variable a plugin's api_key is passed in
+const apiKeyEnv = "SLOP_PLUGIN_API_KEY"
+
+// waitDelay bounds how long a timed-out or cancelled plugin's output is waited
+// for after it is killed, in case a child process still holds stdout open
+const waitDelay = 2 * time.Second
+
+// Client implements the common.LLM interface by running a plugin executable
+type Client struct {
+	provider *Provider
+	logger   *slog.Logger
+	timeout  time.Duration // per run; 0 waits until ctx is done
+}
+
+var _ common.LLM = (*Client)(nil)
+
+// Generate runs the plugin once with the request on stdin and parses its stdout.
+// plugins answer in a single response, so stream handlers receive no chunks
+func (c *Client) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	if req == nil {
+		req = &common.GenerateRequest{}
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", c.provider.name, err)
+	}
+
+	request, err := c.provider.BuildRequest(messages, modelName, req, c.logger)
+	if err != nil {
+		return nil, err
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin request: %w", err)
+	}
+
+	output, err := c.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	content, usage, err := c.provider.ParseResponse(output, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &common.GenerateResult{
+		Content: content,
+		Usage:   usage,
+		Model:   modelName,
+	}
+	result.Thinking, _ = common.SplitThinking(content)
+	meta := c.provider.ParseMetadata(output)
+	result.FinishReason = meta.FinishReason
+	if meta.Model != "" {
+		result.Model = meta.Model
+	}
+
+	calls, err := c.provider.ParseToolCalls(output, c.logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) > 0 {
+		common.LogToolCalls(c.logger, calls)
+		result.ToolCalls = calls
+		return result, nil
+	}
+
+	if err := common.ValidateJSONResponse(content, &req.GenerateOptions, c.logger); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// run starts the plugin, writes input to its stdin and returns its stdout. a
+// plugin that exits non-zero after writing an error response fails with
+// that error; otherwise its stderr is the message. a plugin still running
+// after parameters.timeout is killed
+func (c *Client) run(ctx context.Context, input []byte) ([]byte, error) {
+	runCtx := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	settings := c.provider.settings
+	cmd := exec.CommandContext(runCtx, settings.Command, settings.Args...)
+	cmd.WaitDelay = waitDelay
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if settings.APIKey != "" {
+		cmd.Env = append(cmd.Env, apiKeyEnv+"="+settings.APIKey)
+	}
+
+	c.logger.Debug("Running plugin", "provider", c.provider.name, "command", settings.Command)
+	err := cmd.Run()
+	if stderr.Len() > 0 {
+		c.logger.Debug("Plugin stderr", "provider", c.provider.name, "output", stderr.String())
+	}
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if runCtx.Err() != nil {
+		// a hung plugin is as unavailable as an unreachable API, so fallbacks apply
+		return nil, &common.ConnectionError{Err: fmt.Errorf("plugin %s did not answer within %s; raise parameters.timeout for slower plugins: %w", c.provider.name, c.timeout, runCtx.Err())}
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// the executable could not be started at all
+		return nil, &common.ConnectionError{Err: c.provider.HandleConnectionError(err)}
+	}
+	if json.Valid(stdout.Bytes()) {
+		if _, parseErr := c.provider.parse(stdout.Bytes(), c.logger); parseErr != nil {
+			return nil, parseErr
+		}
+	}
+
+	message := strings.TrimSpace(stderr.String())
+	if message == "" {
+		message = "no output on stderr"
+	}
+	return nil, fmt.Errorf("plugin %s failed (%v): %s", c.provider.name, exitErr, message)
+}
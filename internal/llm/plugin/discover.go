@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ExecutablePrefix names plugins found on PATH: slop-provider-<name>
+const ExecutablePrefix = "slop-provider-"
+
+// Discover returns the plugin executables on PATH keyed by provider name.
+// when several directories hold the same name the first wins, as with exec.LookPath
+func Discover() map[string]string {
+	found := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), ExecutablePrefix)
+			if !ok || entry.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if name == "" || strings.ContainsAny(name, "/ ") {
+				continue
+			}
+			if _, seen := found[name]; seen {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// isExecutable reports whether path is a regular file the user may run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	write(first, "slop-provider-gateway", 0755)
+	write(second, "slop-provider-gateway", 0755)
+	write(second, "slop-provider-script", 0755)
+	write(second, "slop-provider-notes", 0644)
+	write(second, "other-tool", 0755)
+	require.NoError(t, os.Mkdir(filepath.Join(second, "slop-provider-dir"), 0755))
+
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+	found := Discover()
+
+	assert.Equal(t, map[string]string{
+		"gateway": filepath.Join(first, "slop-provider-gateway"),
+		"script":  filepath.Join(second, "slop-provider-script"),
+	}, found)
+}
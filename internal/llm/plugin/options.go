@@ -0,0 +1,108 @@
+package plugin
+
+import "github.com/chriscorrea/slop/internal/llm/common"
+
+// Extension holds plugin-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
+	Seed *int // deterministic sampling
+}
+
+var _ common.Extension = (*Extension)(nil)
+
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "plugin"
+}
+
+// Validate checks the parameters against the values plugin accepts
+func (e *Extension) Validate() error {
+	return nil
+}
+
+// GenerateOption configures a request for plugin
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// extension returns the request's plugin parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
+// WithSeed sets the sampling seed
+func WithSeed(seed int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		extension(c).Seed = &seed
+	}
+}
+
+// Common options
+
+// WithTemperature sets response randomness
+func WithTemperature(temp float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTemperature(temp)(&c.GenerateOptions)
+	}
+}
+
+// WithTopP sets nucleus sampling threshold
+func WithTopP(topP float64) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTopP(topP)(&c.GenerateOptions)
+	}
+}
+
+// WithMaxTokens sets max tokens to generate
+func WithMaxTokens(maxTokens int) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
+	}
+}
+
+// WithStop sets stop sequences to halt generation
+func WithStop(stop []string) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStop(stop)(&c.GenerateOptions)
+	}
+}
+
+// WithJSONFormat requests a JSON object response
+func WithJSONFormat() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithJSONFormat()(&c.GenerateOptions)
+	}
+}
+
+// WithSchema requests JSON output matching a schema
+func WithSchema(name string, schema []byte) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithSchema(name, schema)(&c.GenerateOptions)
+	}
+}
+
+// WithThinking sets the cross-provider thinking level
+func WithThinking(level common.ThinkingLevel) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithThinking(level)(&c.GenerateOptions)
+	}
+}
+
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
+}
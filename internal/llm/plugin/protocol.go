@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// ProtocolVersion is sent with every request so plugins can reject requests
+// they do not understand
+const ProtocolVersion = 1
+
+// Request is the JSON document written to the plugin's stdin
+type Request struct {
+	Version  int       `json:"version"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Options  Options   `json:"options"`
+}
+
+// Message is a conversation message. unlike common.Message it carries images
+// and tool names, which HTTP adapters serialize in their own shapes
+type Message struct {
+	Role       string            `json:"role"`
+	Content    string            `json:"content"`
+	Images     []Image           `json:"images,omitempty"`
+	ToolCalls  []common.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+	ToolName   string            `json:"tool_name,omitempty"`
+}
+
+// Image is a base64-encoded image attached to a message
+type Image struct {
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// Options are the generation parameters; unset parameters are omitted
+type Options struct {
+	Temperature *float64            `json:"temperature,omitempty"`
+	TopP        *float64            `json:"top_p,omitempty"`
+	MaxTokens   *int                `json:"max_tokens,omitempty"`
+	Stop        []string            `json:"stop,omitempty"`
+	Seed        *int                `json:"seed,omitempty"`
+	Thinking    string              `json:"thinking,omitempty"` // medium or high
+	JSON        bool                `json:"json,omitempty"`     // the answer must be JSON
+	Schema      json.RawMessage     `json:"schema,omitempty"`   // JSON schema the answer must match
+	Tools       []common.ToolConfig `json:"tools,omitempty"`
+}
+
+// Response is the JSON document the plugin writes to stdout. a non-empty
+// Error fails the request; Status optionally gives it an HTTP status, so
+// 429s, 5xxs and auth failures move on to fallback models
+type Response struct {
+	Content      string            `json:"content"`
+	Usage        *common.Usage     `json:"usage,omitempty"`
+	Model        string            `json:"model,omitempty"`
+	FinishReason string            `json:"finish_reason,omitempty"`
+	ToolCalls    []common.ToolCall `json:"tool_calls,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Status       int               `json:"status,omitempty"`
+}
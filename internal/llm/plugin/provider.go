@@ -0,0 +1,272 @@
+// Package plugin runs external executables as providers. each request starts
+// the executable, writes one JSON Request to its stdin and reads one JSON
+// Response from its stdout, so a gateway wrapper or a local script can back a
+// provider without rebuilding slop.
+//
+// Plugins come from [providers.plugin.<name>] entries or from executables on
+// PATH named slop-provider-<name>.
+//
+// Example exchange:
+//   stdin:  {"version":1,"provider":"gateway","model":"fast","messages":[{"role":"user","content":"hi"}],"options":{"temperature":0.7}}
+//   stdout: {"content":"hello","usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
+
+// Provider implements the unified registry.Provider interface for one plugin
+// executable
+type Provider struct {
+	name     string
+	settings config.PluginProvider
+}
+
+// ensure Provider implements the common.Provider interface
+var _ common.Provider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider accepts image inputs
+var _ common.VisionProvider = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// New creates a provider for the named plugin
+func New(name string, settings config.PluginProvider) *Provider {
+	return &Provider{name: name, settings: settings}
+}
+
+// CreateClient creates a client that runs the plugin executable per request
+func (p *Provider) CreateClient(cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if strings.TrimSpace(p.settings.Command) == "" {
+		return nil, fmt.Errorf("providers.plugin.%s.command is required", p.name)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Client{
+		provider: p,
+		logger:   logger,
+		timeout:  time.Duration(cfg.Parameters.Timeout) * time.Second,
+	}, nil
+}
+
+// BuildOptions creates plugin generation options from configuration
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
+	var functionalOpts []GenerateOption
+
+	if cfg.Parameters.Temperature > 0 {
+		functionalOpts = append(functionalOpts, WithTemperature(cfg.Parameters.Temperature))
+	}
+	if cfg.Parameters.MaxTokens > 0 {
+		functionalOpts = append(functionalOpts, WithMaxTokens(cfg.Parameters.MaxTokens))
+	}
+	if cfg.Parameters.TopP > 0 {
+		functionalOpts = append(functionalOpts, WithTopP(cfg.Parameters.TopP))
+	}
+	if len(cfg.Parameters.StopSequences) > 0 {
+		functionalOpts = append(functionalOpts, WithStop(cfg.Parameters.StopSequences))
+	}
+	if cfg.Parameters.Seed != nil {
+		functionalOpts = append(functionalOpts, WithSeed(*cfg.Parameters.Seed))
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
+	if level, err := common.ParseThinkingLevel(cfg.Parameters.Thinking); err == nil && level != common.ThinkingOff {
+		functionalOpts = append(functionalOpts, WithThinking(level))
+	}
+
+	// schema takes precedence over the plain JSON toggle
+	if schema := strings.TrimSpace(cfg.Parameters.ResponseSchema); schema != "" {
+		functionalOpts = append(functionalOpts, WithSchema("response", []byte(schema)))
+	} else if cfg.Format.JSON {
+		functionalOpts = append(functionalOpts, WithJSONFormat())
+	}
+
+	return NewGenerateOptions(functionalOpts...)
+}
+
+// RequiresAPIKey returns false; a plugin handles its own credentials
+func (p *Provider) RequiresAPIKey() bool {
+	return false
+}
+
+// ProviderName returns the plugin's provider name
+func (p *Provider) ProviderName() string {
+	return p.name
+}
+
+// BuildRequest creates the plugin Request from messages and options
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes the plugin's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	// log the request using common utilities
+	common.LogAPIRequest(logger, p.name, modelName, messages, &config.GenerateOptions)
+
+	request := &Request{
+		Version:  ProtocolVersion,
+		Provider: p.name,
+		Model:    modelName,
+		Messages: make([]Message, 0, len(messages)),
+		Options: Options{
+			Temperature: config.Temperature,
+			TopP:        config.TopP,
+			MaxTokens:   config.MaxTokens,
+			Stop:        config.Stop,
+			Seed:        ext.Seed,
+			Tools:       config.Tools,
+		},
+	}
+
+	switch config.Thinking {
+	case common.ThinkingMedium:
+		request.Options.Thinking = "medium"
+	case common.ThinkingHigh:
+		request.Options.Thinking = "high"
+	}
+	if rf := config.ResponseFormat; rf != nil {
+		request.Options.JSON = true
+		request.Options.Schema = rf.Schema
+	}
+
+	for _, msg := range messages {
+		wire := Message{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+			ToolName:   msg.ToolName,
+		}
+		for _, image := range msg.Images {
+			wire.Images = append(wire.Images, Image{MediaType: image.MediaType, Data: image.Base64()})
+		}
+		request.Messages = append(request.Messages, wire)
+	}
+
+	return request, nil
+}
+
+// parse decodes a plugin Response and turns a reported error into a Go error
+func (p *Provider) parse(body []byte, logger *slog.Logger) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		common.LogJSONUnmarshalError(logger, err, string(body))
+		return nil, fmt.Errorf("plugin %s wrote an invalid response: %w", p.name, err)
+	}
+	if resp.Error != "" {
+		if resp.Status > 0 {
+			return nil, &common.APIError{StatusCode: resp.Status, Err: p.HandleError(resp.Status, []byte(resp.Error))}
+		}
+		return nil, fmt.Errorf("plugin %s error: %s", p.name, resp.Error)
+	}
+	return &resp, nil
+}
+
+// ParseResponse parses a plugin Response and extracts content and usage
+func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *common.Usage, error) {
+	resp, err := p.parse(body, logger)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Content, resp.Usage, nil
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a plugin Response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return common.ResponseMetadata{}
+	}
+	return common.ResponseMetadata{Model: resp.Model, FinishReason: resp.FinishReason}
+}
+
+// ParseToolCalls extracts the tool calls requested in a plugin Response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	resp, err := p.parse(body, logger)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.ToolCalls {
+		if resp.ToolCalls[i].ID == "" {
+			resp.ToolCalls[i].ID = fmt.Sprintf("call_%d", i)
+		}
+		if resp.ToolCalls[i].Type == "" {
+			resp.ToolCalls[i].Type = "function"
+		}
+	}
+	return resp.ToolCalls, nil
+}
+
+// SupportsImages reports true; images are passed through and the plugin
+// decides what to do with them
+func (p *Provider) SupportsImages(modelName string) bool {
+	return true
+}
+
+// HandleError creates an error message from a status and message the plugin reported
+func (p *Provider) HandleError(statusCode int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return fmt.Errorf("plugin %s error (status %d): %s", p.name, statusCode, message)
+}
+
+// CustomizeRequest is a no-op; plugins are not called over HTTP
+func (p *Provider) CustomizeRequest(req *http.Request) error {
+	return nil
+}
+
+// HandleConnectionError creates error messages for a plugin that could not be started
+func (p *Provider) HandleConnectionError(err error) error {
+	return fmt.Errorf(`Failed to run plugin %s (%s).
+
+Check that providers.plugin.%s.command points at an executable.
+
+Error: %w`, p.name, p.settings.Command, p.name, err)
+}
+
+// Capabilities reports every feature; the plugin decides what to honor
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: true,
+		Schema:   true,
+		JSON:     true,
+		Vision:   p.SupportsImages(modelName),
+		Tools:    true,
+		Seed:     true,
+	}
+}
+
+// ModelFamilies reports one family covering every model
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "all models"},
+	}
+}
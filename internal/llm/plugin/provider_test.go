@@ -0,0 +1,231 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperEnv switches the test binary into a fake plugin; see TestHelperPlugin
+const helperEnv = "SLOP_TEST_PLUGIN_MODE"
+
+// TestHelperPlugin is not a real test: it is the plugin executable the client
+// tests run, answering according to the mode in helperEnv
+func TestHelperPlugin(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "echo":
+		last := req.Messages[len(req.Messages)-1]
+		_ = json.NewEncoder(os.Stdout).Encode(Response{
+			Content:      fmt.Sprintf("%s said %q (key %s)", req.Model, last.Content, os.Getenv(apiKeyEnv)),
+			Usage:        &common.Usage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5},
+			Model:        req.Model + "-v1",
+			FinishReason: "stop",
+		})
+	case "tools":
+		_ = json.NewEncoder(os.Stdout).Encode(Response{ToolCalls: []common.ToolCall{
+			{Function: common.FunctionCall{Name: req.Options.Tools[0].Function.Name, Arguments: `{}`}},
+		}})
+	case "rate-limited":
+		_ = json.NewEncoder(os.Stdout).Encode(Response{Error: "slow down", Status: http.StatusTooManyRequests})
+		os.Exit(1)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "gateway unreachable")
+		os.Exit(3)
+	case "not-json":
+		fmt.Fprint(os.Stdout, "plain text")
+	case "hang":
+		// a child sharing stdout keeps the pipe open after this process is killed
+		child := exec.Command(os.Args[0], os.Args[1:]...)
+		child.Env = append(os.Environ(), helperEnv+"=sleep")
+		child.Stdin = strings.NewReader(`{}`)
+		child.Stdout = os.Stdout
+		_ = child.Start()
+		time.Sleep(20 * time.Second)
+	case "sleep":
+		time.Sleep(20 * time.Second)
+	}
+	os.Exit(0)
+}
+
+// helperProvider returns a provider running this test binary as a plugin
+func helperProvider(t *testing.T, mode string) *Provider {
+	t.Setenv(helperEnv, mode)
+	return New("helper", config.PluginProvider{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestHelperPlugin$"},
+		APIKey:  "secret",
+	})
+}
+
+func generate(t *testing.T, p *Provider, opts ...GenerateOption) (*common.GenerateResult, error) {
+	t.Helper()
+	client, err := p.CreateClient(&config.Config{}, nil)
+	require.NoError(t, err)
+	return client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "fast", NewGenerateOptions(opts...))
+}
+
+func TestProvider_Methods(t *testing.T) {
+	p := New("gateway", config.PluginProvider{Command: "gateway"})
+	assert.Equal(t, "gateway", p.ProviderName())
+	assert.False(t, p.RequiresAPIKey())
+	assert.True(t, p.SupportsImages("any"))
+
+	_, err := p.CreateClient(nil, nil)
+	assert.ErrorContains(t, err, "config cannot be nil")
+	_, err = New("gateway", config.PluginProvider{}).CreateClient(&config.Config{}, nil)
+	assert.ErrorContains(t, err, "providers.plugin.gateway.command")
+}
+
+func TestProvider_BuildOptions(t *testing.T) {
+	cfg := config.NewDefaultFromEmbedded()
+	cfg.Parameters.Thinking = "medium"
+	cfg.Format.JSON = true
+
+	genOpts := New("gateway", config.PluginProvider{}).BuildOptions(cfg)
+	require.NotNil(t, genOpts)
+	require.NotNil(t, genOpts.Temperature)
+	assert.Equal(t, common.ThinkingMedium, genOpts.Thinking)
+	require.NotNil(t, genOpts.ResponseFormat)
+	assert.Equal(t, "json_object", genOpts.ResponseFormat.Type)
+}
+
+func TestProvider_BuildRequest(t *testing.T) {
+	p := New("gateway", config.PluginProvider{})
+	messages := []common.Message{
+		{Role: "user", Content: "what is this?", Images: []common.Image{{MediaType: "image/png", Data: []byte{0x89, 0x50}}}},
+		{Role: "tool", Content: "sunny", ToolCallID: "call_0", ToolName: "get_weather"},
+	}
+	opts := NewGenerateOptions(WithTemperature(0.2), WithMaxTokens(64), WithSeed(7), WithThinking(common.ThinkingHigh), WithSchema("response", []byte(`{"type":"object"}`)))
+
+	request, err := p.BuildRequest(messages, "fast", opts, nil)
+	require.NoError(t, err)
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"version": 1,
+		"provider": "gateway",
+		"model": "fast",
+		"messages": [
+			{"role": "user", "content": "what is this?", "images": [{"media_type": "image/png", "data": "iVA="}]},
+			{"role": "tool", "content": "sunny", "tool_call_id": "call_0", "tool_name": "get_weather"}
+		],
+		"options": {"temperature": 0.2, "max_tokens": 64, "seed": 7, "thinking": "high", "json": true, "schema": {"type": "object"}}
+	}`, string(body))
+}
+
+func TestProvider_ParseResponse(t *testing.T) {
+	p := New("gateway", config.PluginProvider{})
+
+	content, usage, err := p.ParseResponse([]byte(`{"content":"hello","usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", content)
+	assert.Equal(t, 3, usage.TotalTokens)
+
+	_, _, err = p.ParseResponse([]byte(`{"error":"model offline","status":503}`), nil)
+	var apiErr *common.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.ErrorContains(t, err, "model offline")
+	assert.True(t, common.ShouldFallback(err))
+
+	_, _, err = p.ParseResponse([]byte(`{"error":"bad prompt"}`), nil)
+	assert.EqualError(t, err, "plugin gateway error: bad prompt")
+
+	_, _, err = p.ParseResponse([]byte(`nope`), nil)
+	assert.ErrorContains(t, err, "invalid response")
+}
+
+func TestClient_Generate(t *testing.T) {
+	t.Run("response", func(t *testing.T) {
+		result, err := generate(t, helperProvider(t, "echo"))
+		require.NoError(t, err)
+		assert.Equal(t, `fast said "hi" (key secret)`, result.Content)
+		assert.Equal(t, "fast-v1", result.Model)
+		assert.Equal(t, "stop", result.FinishReason)
+		assert.Equal(t, 5, result.Usage.TotalTokens)
+	})
+
+	t.Run("tool calls", func(t *testing.T) {
+		tools := []common.ToolConfig{{Type: "function", Function: common.FunctionDefinition{Name: "get_weather"}}}
+		result, err := generate(t, helperProvider(t, "tools"), WithTools(tools))
+		require.NoError(t, err)
+		require.Len(t, result.ToolCalls, 1)
+		assert.Equal(t, "call_0", result.ToolCalls[0].ID)
+		assert.Equal(t, "function", result.ToolCalls[0].Type)
+		assert.Equal(t, "get_weather", result.ToolCalls[0].Function.Name)
+	})
+
+	t.Run("reported error", func(t *testing.T) {
+		_, err := generate(t, helperProvider(t, "rate-limited"))
+		var apiErr *common.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.ErrorContains(t, err, "slow down")
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		_, err := generate(t, helperProvider(t, "crash"))
+		assert.ErrorContains(t, err, "gateway unreachable")
+		assert.ErrorContains(t, err, "exit status 3")
+	})
+
+	t.Run("invalid JSON answer", func(t *testing.T) {
+		_, err := generate(t, helperProvider(t, "not-json"))
+		assert.ErrorContains(t, err, "invalid response")
+	})
+
+	t.Run("missing executable", func(t *testing.T) {
+		p := New("gateway", config.PluginProvider{Command: filepath.Join(t.TempDir(), "missing")})
+		_, err := generate(t, p)
+		var connErr *common.ConnectionError
+		assert.ErrorAs(t, err, &connErr)
+		assert.ErrorContains(t, err, "providers.plugin.gateway.command")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		client, err := helperProvider(t, "hang").CreateClient(&config.Config{Parameters: config.Parameters{Timeout: 1}}, nil)
+		require.NoError(t, err)
+		start := time.Now()
+		_, err = client.Generate(context.Background(), []common.Message{{Role: "user", Content: "hi"}}, "fast", nil)
+		assert.Less(t, time.Since(start), 10*time.Second)
+		var connErr *common.ConnectionError
+		require.ErrorAs(t, err, &connErr)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+		assert.ErrorContains(t, err, "parameters.timeout")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		client, err := helperProvider(t, "echo").CreateClient(&config.Config{}, nil)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = client.Generate(ctx, []common.Message{{Role: "user", Content: "hi"}}, "fast", nil)
+		assert.True(t, errors.Is(err, context.Canceled), err)
+	})
+}
@@ -26,6 +26,8 @@ type ChatRequest struct {
 	N                 *int                `json:"n,omitempty"`
 	MinP              *float64            `json:"min_p,omitempty"`
 	SafetyModel       *string             `json:"safety_model,omitempty"`
+	Tools             []common.ToolConfig `json:"tools,omitempty"`
+	ToolChoice        interface{}         `json:"tool_choice,omitempty"`
 }
 
 // ChatResponseFormat is Together.AI's response_format wire shape. Together is
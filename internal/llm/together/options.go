@@ -1,12 +1,14 @@
 package together
 
-import "github.com/chriscorrea/slop/internal/llm/common"
+import (
+	"fmt"
 
-// GenerateOptions contains TogetherAI-specific generation parameters
-type GenerateOptions struct {
-	common.GenerateOptions
+	"github.com/chriscorrea/slop/internal/llm/common"
+)
 
-	// TogetherAI-specific params
+// Extension holds Together.AI-specific generation parameters, the
+// provider's section of a common.GenerateRequest
+type Extension struct {
 	FrequencyPenalty  *float64
 	PresencePenalty   *float64
 	RepetitionPenalty *float64
@@ -18,78 +20,115 @@ type GenerateOptions struct {
 	SafetyModel       *string // Safety model to use for content filtering
 }
 
-// GenerateOption configures Together.AI-specific generation parameters
-type GenerateOption func(*GenerateOptions)
+var _ common.Extension = (*Extension)(nil)
 
-// NewGenerateOptions creates new GenerateOptions with functional options applied
-func NewGenerateOptions(opts ...GenerateOption) *GenerateOptions {
-	config := &GenerateOptions{}
+// Provider names the provider these parameters are for
+func (e *Extension) Provider() string {
+	return "together"
+}
+
+// Validate checks the parameters against the values Together.AI accepts
+func (e *Extension) Validate() error {
+	if e.FrequencyPenalty != nil && (*e.FrequencyPenalty < -2 || *e.FrequencyPenalty > 2) {
+		return fmt.Errorf("frequency_penalty %g is out of range (-2 to 2)", *e.FrequencyPenalty)
+	}
+	if e.PresencePenalty != nil && (*e.PresencePenalty < -2 || *e.PresencePenalty > 2) {
+		return fmt.Errorf("presence_penalty %g is out of range (-2 to 2)", *e.PresencePenalty)
+	}
+	if e.MinP != nil && (*e.MinP < 0 || *e.MinP > 1) {
+		return fmt.Errorf("min_p %g is out of range (0-1)", *e.MinP)
+	}
+	if e.TopLogProbs != nil && *e.TopLogProbs < 0 {
+		return fmt.Errorf("top_logprobs can't be negative, got %d", *e.TopLogProbs)
+	}
+	if e.N != nil && *e.N < 1 {
+		return fmt.Errorf("n must be at least 1, got %d", *e.N)
+	}
+	return nil
+}
+
+// GenerateOption configures a request for Together.AI
+type GenerateOption func(*common.GenerateRequest)
+
+// NewGenerateOptions creates a request with functional options applied
+func NewGenerateOptions(opts ...GenerateOption) *common.GenerateRequest {
+	config := &common.GenerateRequest{}
 	for _, opt := range opts {
 		opt(config)
 	}
 	return config
 }
 
+// extension returns the request's Together.AI parameters, adding them when missing
+func extension(c *common.GenerateRequest) *Extension {
+	ext, ok := c.Extension.(*Extension)
+	if !ok || ext == nil {
+		ext = &Extension{}
+		c.Extension = ext
+	}
+	return ext
+}
+
 // WithFrequencyPenalty sets frequency penalty (-2.0 to 2.0)
 func WithFrequencyPenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.FrequencyPenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).FrequencyPenalty = &penalty
 	}
 }
 
 // WithPresencePenalty sets presence penalty (-2.0 to 2.0)
 func WithPresencePenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.PresencePenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).PresencePenalty = &penalty
 	}
 }
 
 // WithRepetitionPenalty sets repetition penalty (0.0 to 2.0)
 func WithRepetitionPenalty(penalty float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.RepetitionPenalty = &penalty
+	return func(c *common.GenerateRequest) {
+		extension(c).RepetitionPenalty = &penalty
 	}
 }
 
 // WithMinP sets minimum probability for token sampling
 func WithMinP(minP float64) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.MinP = &minP
+	return func(c *common.GenerateRequest) {
+		extension(c).MinP = &minP
 	}
 }
 
 // WithLogProbs enables log probabilities in response
 func WithLogProbs(logProbs bool) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.LogProbs = &logProbs
+	return func(c *common.GenerateRequest) {
+		extension(c).LogProbs = &logProbs
 	}
 }
 
 // WithTopLogProbs sets number of top log probabilities to return
 func WithTopLogProbs(topLogProbs int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.TopLogProbs = &topLogProbs
+	return func(c *common.GenerateRequest) {
+		extension(c).TopLogProbs = &topLogProbs
 	}
 }
 
 // WithEcho enables echoing the prompt in response
 func WithEcho(echo bool) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.Echo = &echo
+	return func(c *common.GenerateRequest) {
+		extension(c).Echo = &echo
 	}
 }
 
 // WithN sets number of completions to generate
 func WithN(n int) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.N = &n
+	return func(c *common.GenerateRequest) {
+		extension(c).N = &n
 	}
 }
 
 // WithSafetyModel sets the safety model for content filtering
 func WithSafetyModel(model string) GenerateOption {
-	return func(c *GenerateOptions) {
-		c.SafetyModel = &model
+	return func(c *common.GenerateRequest) {
+		extension(c).SafetyModel = &model
 	}
 }
 
@@ -97,42 +136,49 @@ func WithSafetyModel(model string) GenerateOption {
 
 // WithTemperature sets response randomness
 func WithTemperature(temp float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTemperature(temp)(&c.GenerateOptions)
 	}
 }
 
 // WithTopP sets nucleus sampling threshold
 func WithTopP(topP float64) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithTopP(topP)(&c.GenerateOptions)
 	}
 }
 
 // WithMaxTokens sets max tokens to generate
 func WithMaxTokens(maxTokens int) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithMaxTokens(maxTokens)(&c.GenerateOptions)
 	}
 }
 
 // WithStop sets stop sequences to halt generation
 func WithStop(stop []string) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithStop(stop)(&c.GenerateOptions)
 	}
 }
 
+// WithStream requests a streamed response
+func WithStream() GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithStream()(&c.GenerateOptions)
+	}
+}
+
 // WithJSONFormat enables JSON structured output (available for some models)
 func WithJSONFormat() GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithJSONFormat()(&c.GenerateOptions)
 	}
 }
 
 // WithResponseFormat sets structured output format
 func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithResponseFormat(format)(&c.GenerateOptions)
 	}
 }
@@ -140,12 +186,14 @@ func WithResponseFormat(format *common.ResponseFormat) GenerateOption {
 // WithSchema requests schema-constrained JSON output using the json_schema
 // envelope. The shared common.WithSchema populates ResponseFormat canonically.
 func WithSchema(name string, schema []byte) GenerateOption {
-	return func(c *GenerateOptions) {
+	return func(c *common.GenerateRequest) {
 		common.WithSchema(name, schema)(&c.GenerateOptions)
 	}
 }
 
-// GetGenerateOptions returns the embedded common GenerateOptions for validation
-func (c *GenerateOptions) GetGenerateOptions() *common.GenerateOptions {
-	return &c.GenerateOptions
+// WithTools sets the functions the model may call
+func WithTools(tools []common.ToolConfig) GenerateOption {
+	return func(c *common.GenerateRequest) {
+		common.WithTools(tools)(&c.GenerateOptions)
+	}
 }
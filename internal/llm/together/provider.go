@@ -5,13 +5,14 @@
 //
 // Example usage:
 //   client := together.NewClient(apiKey)
-//   response, err := client.Generate(ctx, messages, together.WithTemperature(0.7))
+//   response, err := client.Generate(ctx, messages, model, together.NewGenerateOptions(together.WithTemperature(0.7)))
 //
 // Together model documentation: https://api.together.ai/models and https://docs.together.ai/docs/models
 
 package together
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -27,6 +28,27 @@ type Provider struct{}
 // ensure Provider implements the common.Provider interface
 var _ common.Provider = (*Provider)(nil)
 
+// ensure Provider supports streamed responses
+var _ common.StreamingProvider = (*Provider)(nil)
+
+// ensure Provider supports function calling
+var _ common.ToolCallingProvider = (*Provider)(nil)
+
+// ensure Provider reports the resolved model and finish reason
+var _ common.MetadataProvider = (*Provider)(nil)
+
+// ensure Provider can list available models
+var _ common.ModelLister = (*Provider)(nil)
+
+// ensure Provider can embed text
+var _ common.Embedder = (*Provider)(nil)
+
+// ensure Provider describes what its models honor
+var _ common.CapabilityProvider = (*Provider)(nil)
+
+// defaultBaseURL is used when providers.together.base_url is unset
+const defaultBaseURL = "https://api.together.xyz/v1"
+
 // New creates a new Together.AI provider instance
 func New() *Provider {
 	return &Provider{}
@@ -61,12 +83,25 @@ Get an API key from https://api.together.ai/settings/api-keys`)
 		opts = append(opts, common.WithMaxRetries(maxRetries))
 	}
 
-	adapterClient := common.NewAdapterClient(p, cfg.Providers.Together.APIKey, "https://api.together.xyz/v1", opts...)
+	// global retry policy with any provider-specific overrides
+	opts = append(opts, common.WithRetryPolicy(common.NewRetryPolicy(cfg.Parameters.Retry.Merge(cfg.Providers.Together.Retry))))
+
+	// proxy, CA bundle, extra headers and timeout
+	httpClient, err := common.NewHTTPClient(cfg.Providers.Together.BaseProvider, cfg.Parameters.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("providers.together: %w", err)
+	}
+	opts = append(opts, common.WithHTTPClient(httpClient))
+
+	// --record and --replay save or serve exchanges on disk
+	opts = append(opts, common.WithCassette(cfg.Parameters.Cassette()))
+
+	adapterClient := common.NewAdapterClient(p, cfg.Providers.Together.APIKey, defaultBaseURL, opts...)
 	return adapterClient, nil
 }
 
 // BuildOptions creates TogetherAI-specific generation options from configuration
-func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
+func (p *Provider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
 	var functionalOpts []GenerateOption
 
 	if cfg.Parameters.Temperature > 0 {
@@ -84,6 +119,12 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 	if cfg.Format.JSON {
 		functionalOpts = append(functionalOpts, WithJSONFormat())
 	}
+	if cfg.Parameters.Stream {
+		functionalOpts = append(functionalOpts, WithStream())
+	}
+	if tools := common.ToolsFromConfig(cfg); len(tools) > 0 {
+		functionalOpts = append(functionalOpts, WithTools(tools))
+	}
 
 	// if a response schema is provided, wrap it in the json_schema envelope
 	// schema takes precedence over the plain json_object toggle
@@ -91,7 +132,7 @@ func (p *Provider) BuildOptions(cfg *config.Config) []interface{} {
 		functionalOpts = append(functionalOpts, WithSchema("response", []byte(schema)))
 	}
 
-	return []interface{}{NewGenerateOptions(functionalOpts...)}
+	return NewGenerateOptions(functionalOpts...)
 }
 
 // RequiresAPIKey returns true since TogetherAI requires an API key
@@ -105,17 +146,14 @@ func (p *Provider) ProviderName() string {
 }
 
 // BuildRequest creates a Together.AI-specific request from messages and options
-func (p *Provider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
-	// convert options to Together-specific options
-	var config *GenerateOptions
-	if options != nil {
-		if togetherOpts, ok := options.(*GenerateOptions); ok {
-			config = togetherOpts
-		} else {
-			config = &GenerateOptions{}
-		}
-	} else {
-		config = &GenerateOptions{}
+func (p *Provider) BuildRequest(messages []common.Message, modelName string, config *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
+	// an empty request takes Together.AI's defaults
+	if config == nil {
+		config = &common.GenerateRequest{}
+	}
+	ext, err := common.ExtensionOf[Extension](config, p.ProviderName())
+	if err != nil {
+		return nil, err
 	}
 
 	// log the API request
@@ -125,7 +163,7 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	requestBody := &ChatRequest{
 		Model:    modelName,
 		Messages: messages,
-		Stream:   common.BoolPtr(false), // Disable streaming for now
+		Stream:   common.BoolPtr(config.Stream),
 	}
 
 	// map common generation options to Together's API format
@@ -143,32 +181,32 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 	}
 
 	// map Together-specific options
-	if config.FrequencyPenalty != nil {
-		requestBody.FrequencyPenalty = config.FrequencyPenalty
+	if ext.FrequencyPenalty != nil {
+		requestBody.FrequencyPenalty = ext.FrequencyPenalty
 	}
-	if config.PresencePenalty != nil {
-		requestBody.PresencePenalty = config.PresencePenalty
+	if ext.PresencePenalty != nil {
+		requestBody.PresencePenalty = ext.PresencePenalty
 	}
-	if config.RepetitionPenalty != nil {
-		requestBody.RepetitionPenalty = config.RepetitionPenalty
+	if ext.RepetitionPenalty != nil {
+		requestBody.RepetitionPenalty = ext.RepetitionPenalty
 	}
-	if config.MinP != nil {
-		requestBody.MinP = config.MinP
+	if ext.MinP != nil {
+		requestBody.MinP = ext.MinP
 	}
-	if config.LogProbs != nil {
-		requestBody.LogProbs = config.LogProbs
+	if ext.LogProbs != nil {
+		requestBody.LogProbs = ext.LogProbs
 	}
-	if config.TopLogProbs != nil {
-		requestBody.TopLogProbs = config.TopLogProbs
+	if ext.TopLogProbs != nil {
+		requestBody.TopLogProbs = ext.TopLogProbs
 	}
-	if config.Echo != nil {
-		requestBody.Echo = config.Echo
+	if ext.Echo != nil {
+		requestBody.Echo = ext.Echo
 	}
-	if config.N != nil {
-		requestBody.N = config.N
+	if ext.N != nil {
+		requestBody.N = ext.N
 	}
-	if config.SafetyModel != nil {
-		requestBody.SafetyModel = config.SafetyModel
+	if ext.SafetyModel != nil {
+		requestBody.SafetyModel = ext.SafetyModel
 	}
 
 	// handle structured output if requested. Together is OpenAI-compatible and
@@ -177,6 +215,14 @@ func (p *Provider) BuildRequest(messages []common.Message, modelName string, opt
 		requestBody.ResponseFormat = buildResponseFormat(config.ResponseFormat)
 	}
 
+	// tool definitions already use the OpenAI-compatible wire shape
+	if len(config.Tools) > 0 {
+		requestBody.Tools = config.Tools
+	}
+	if config.ToolChoice != nil {
+		requestBody.ToolChoice = config.ToolChoice
+	}
+
 	return requestBody, nil
 }
 
@@ -220,6 +266,26 @@ func (p *Provider) ParseResponse(body []byte, logger *slog.Logger) (string, *com
 	return content, &chatResp.Usage, nil
 }
 
+// ParseToolCalls extracts the tool calls requested by a Together.AI response
+func (p *Provider) ParseToolCalls(body []byte, logger *slog.Logger) ([]common.ToolCall, error) {
+	return common.ParseChatCompletionToolCalls(body, "Together.AI", logger)
+}
+
+// ParseMetadata extracts the resolved model and finish reason from a Together.AI response
+func (p *Provider) ParseMetadata(body []byte) common.ResponseMetadata {
+	return common.ParseChatCompletionMetadata(body)
+}
+
+// StreamFormat reports that Together.AI streams server-sent events
+func (p *Provider) StreamFormat() common.StreamFormat {
+	return common.StreamSSE
+}
+
+// ParseStreamEvent parses one streamed Together.AI chat completion chunk
+func (p *Provider) ParseStreamEvent(data []byte, logger *slog.Logger) (*common.StreamChunk, error) {
+	return common.ParseChatCompletionChunk(data, "Together.AI", logger)
+}
+
 // HandleError creates Together.AI-specific error messages from HTTP error responses
 func (p *Provider) HandleError(statusCode int, body []byte) error {
 
@@ -292,3 +358,70 @@ func (p *Provider) HandleConnectionError(err error) error {
 
 Error: %w`, err)
 }
+
+// ListModels lists the models available to the API key via GET /models
+func (p *Provider) ListModels(ctx context.Context, cfg *config.Config) ([]string, error) {
+	if cfg.Providers.Together.APIKey == "" {
+		return nil, fmt.Errorf("Together API key is required to list models")
+	}
+	baseURL := cfg.Providers.Together.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Together.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.FetchModels(ctx, client, common.BuildModelsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Together.APIKey,
+	}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseModelIDs(body)
+}
+
+// Embed returns a vector per input via POST /embeddings
+func (p *Provider) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	if cfg.Providers.Together.APIKey == "" {
+		return nil, fmt.Errorf("Together API key is required to embed text")
+	}
+	baseURL := cfg.Providers.Together.BaseUrl
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	client, err := common.NewAPIClient(cfg.Providers.Together.BaseProvider, cfg.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	body, err := common.PostJSON(ctx, client, common.BuildEmbeddingsURL(baseURL), map[string]string{
+		"Authorization": "Bearer " + cfg.Providers.Together.APIKey,
+	}, map[string]interface{}{"model": modelName, "input": inputs}, p.HandleError)
+	if err != nil {
+		return nil, err
+	}
+	return common.ParseEmbeddings(body)
+}
+
+// Capabilities reports the features a Together model honors; no seed
+// or reasoning parameter is sent
+func (p *Provider) Capabilities(modelName string) common.Capabilities {
+	return common.Capabilities{
+		Thinking: false,
+		Schema:   true,
+		JSON:     true,
+		Vision:   false,
+		Tools:    true,
+		Seed:     false,
+	}
+}
+
+// ModelFamilies lists Together's model families
+func (p *Provider) ModelFamilies() []common.ModelFamily {
+	return []common.ModelFamily{
+		{Name: "llama", Example: "meta-llama/Llama-3.3-70B-Instruct-Turbo"},
+		{Name: "deepseek-r1", Example: "deepseek-ai/DeepSeek-R1-Distill-Llama-70B"},
+	}
+}
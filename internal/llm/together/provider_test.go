@@ -106,14 +106,9 @@ func TestProvider_BuildOptions(t *testing.T) {
 			},
 		}
 
-		options := p.BuildOptions(cfg)
-		if len(options) != 1 {
-			t.Errorf("Expected 1 option, got %d", len(options))
-		}
-
-		opts, ok := options[0].(*GenerateOptions)
-		if !ok {
-			t.Error("Expected GenerateOptions type")
+		opts := p.BuildOptions(cfg)
+		if opts == nil {
+			t.Fatal("Expected options")
 		}
 		if opts.Temperature == nil || *opts.Temperature != 0.7 {
 			t.Errorf("Expected temperature 0.7, got %v", opts.Temperature)
@@ -134,13 +129,9 @@ func TestProvider_BuildOptions(t *testing.T) {
 
 	t.Run("Empty config returns empty options", func(t *testing.T) {
 		cfg := &config.Config{}
-		options := p.BuildOptions(cfg)
-		if len(options) != 1 {
-			t.Errorf("Expected 1 option, got %d", len(options))
-		}
-		opts, ok := options[0].(*GenerateOptions)
-		if !ok {
-			t.Error("Expected GenerateOptions type")
+		opts := p.BuildOptions(cfg)
+		if opts == nil {
+			t.Fatal("Expected options")
 		}
 		if opts.Temperature != nil {
 			t.Errorf("Expected no temperature, got %v", opts.Temperature)
@@ -316,12 +307,12 @@ func TestProvider_BuildRequest(t *testing.T) {
 		}
 
 		builtOpts := p.BuildOptions(cfg)
-		if len(builtOpts) != 1 {
-			t.Fatalf("Expected 1 option, got %d", len(builtOpts))
+		if builtOpts == nil {
+			t.Fatal("Expected options")
 		}
 
 		messages := []common.Message{{Role: "user", Content: "four legs good two legs bad"}}
-		req, err := p.BuildRequest(messages, "test-model", builtOpts[0], nil)
+		req, err := p.BuildRequest(messages, "test-model", builtOpts, nil)
 		if err != nil {
 			t.Fatalf("Expected no error, got: %v", err)
 		}
@@ -574,8 +565,8 @@ func TestProvider_EndToEnd(t *testing.T) {
 			t.Fatalf("Generate failed: %v", err)
 		}
 
-		if result != "Hello from Together.AI!" {
-			t.Errorf("Expected correct response, got %s", result)
+		if result.Content != "Hello from Together.AI!" {
+			t.Errorf("Expected correct response, got %s", result.Content)
 		}
 	})
 
@@ -635,8 +626,8 @@ func TestProvider_EndToEnd(t *testing.T) {
 			t.Fatalf("Generate failed: %v", err)
 		}
 
-		if result != `{"result": "success"}` {
-			t.Errorf("Expected JSON response, got %s", result)
+		if result.Content != `{"result": "success"}` {
+			t.Errorf("Expected JSON response, got %s", result.Content)
 		}
 	})
 
@@ -671,7 +662,7 @@ func TestProvider_EndToEnd(t *testing.T) {
 
 		messages := []common.Message{{Role: "user", Content: "Hello"}}
 
-		_, err = client.Generate(context.Background(), messages, "together-model")
+		_, err = client.Generate(context.Background(), messages, "together-model", nil)
 		if err == nil {
 			t.Error("Expected error for server error")
 		}
@@ -680,3 +671,27 @@ func TestProvider_EndToEnd(t *testing.T) {
 		}
 	})
 }
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("Expected /v1/models, got %s", r.URL.Path)
+		}
+		// Together answers with a bare array rather than {"data": [...]}
+		w.Write([]byte(`[{"id":"meta-llama/Llama-3.3-70B-Instruct-Turbo","type":"chat"},{"id":"Qwen/Qwen3-235B-A22B-fp8-tput","type":"chat"}]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Providers.Together.APIKey = "test-key"
+	cfg.Providers.Together.BaseUrl = server.URL + "/v1"
+
+	models, err := New().ListModels(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	expected := []string{"Qwen/Qwen3-235B-A22B-fp8-tput", "meta-llama/Llama-3.3-70B-Instruct-Turbo"}
+	if strings.Join(models, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, models)
+	}
+}
@@ -1,18 +1,23 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
 	"github.com/chriscorrea/slop/internal/config"
 	"github.com/chriscorrea/slop/internal/llm/anthropic"
+	"github.com/chriscorrea/slop/internal/llm/azure"
 	"github.com/chriscorrea/slop/internal/llm/cohere"
 	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/llm/custom"
+	"github.com/chriscorrea/slop/internal/llm/gemini"
 	"github.com/chriscorrea/slop/internal/llm/groq"
 	"github.com/chriscorrea/slop/internal/llm/mistral"
 	"github.com/chriscorrea/slop/internal/llm/mock"
 	"github.com/chriscorrea/slop/internal/llm/ollama"
 	"github.com/chriscorrea/slop/internal/llm/openai"
+	"github.com/chriscorrea/slop/internal/llm/plugin"
 	"github.com/chriscorrea/slop/internal/llm/together"
 )
 
@@ -20,7 +25,9 @@ import (
 // TODO this is manually updated for now
 var AllProviders = map[string]common.Provider{
 	"anthropic": anthropic.New(),
+	"azure":     azure.New(),
 	"cohere":    cohere.New(),
+	"gemini":    gemini.New(),
 	"groq":      groq.New(),
 	"mistral":   mistral.New(),
 	"mock":      mock.New(),
@@ -29,6 +36,50 @@ var AllProviders = map[string]common.Provider{
 	"together":  together.New(),
 }
 
+// RegisterCustomProviders registers each [providers.custom.<name>] entry as an
+// OpenAI-compatible provider, replacing custom providers from an earlier load.
+// a name already taken by a built-in provider is an error
+func RegisterCustomProviders(cfg *config.Config) error {
+	for name, provider := range AllProviders {
+		if _, ok := provider.(*custom.Provider); ok {
+			delete(AllProviders, name)
+		}
+	}
+
+	for name, settings := range cfg.Providers.Custom {
+		if _, exists := AllProviders[name]; exists {
+			return fmt.Errorf("providers.custom.%s: %q is a built-in provider; choose another name", name, name)
+		}
+		AllProviders[name] = custom.New(name, settings)
+	}
+	return nil
+}
+
+// RegisterPluginProviders registers each [providers.plugin.<name>] entry and
+// every slop-provider-<name> executable on PATH, replacing plugins from an
+// earlier load. a configured name already taken by another provider is an
+// error; a discovered one is skipped, so configuration wins over PATH
+func RegisterPluginProviders(cfg *config.Config) error {
+	for name, provider := range AllProviders {
+		if _, ok := provider.(*plugin.Provider); ok {
+			delete(AllProviders, name)
+		}
+	}
+
+	for name, settings := range cfg.Providers.Plugin {
+		if _, exists := AllProviders[name]; exists {
+			return fmt.Errorf("providers.plugin.%s: %q is already a provider; choose another name", name, name)
+		}
+		AllProviders[name] = plugin.New(name, settings)
+	}
+	for name, path := range plugin.Discover() {
+		if _, exists := AllProviders[name]; !exists {
+			AllProviders[name] = plugin.New(name, config.PluginProvider{Command: path})
+		}
+	}
+	return nil
+}
+
 // CreateProvider creates a provider instance using the central registry
 // this will return an error if provider is not registered or creation fails
 func CreateProvider(name string, cfg *config.Config, logger *slog.Logger) (common.LLM, error) {
@@ -42,7 +93,7 @@ func CreateProvider(name string, cfg *config.Config, logger *slog.Logger) (commo
 
 // BuildProviderOptions builds provider-specific options using the central registry
 // returns nil if the provider is not registered
-func BuildProviderOptions(name string, cfg *config.Config) []interface{} {
+func BuildProviderOptions(name string, cfg *config.Config) *common.GenerateRequest {
 	provider, exists := AllProviders[name]
 	if !exists {
 		return nil
@@ -51,6 +102,108 @@ func BuildProviderOptions(name string, cfg *config.Config) []interface{} {
 	return provider.BuildOptions(cfg)
 }
 
+// ListModels asks a provider for the models it currently serves. providers
+// without a listing endpoint return common.ErrModelListingUnsupported
+func ListModels(ctx context.Context, name string, cfg *config.Config) ([]string, error) {
+	provider, exists := AllProviders[name]
+	if !exists {
+		return nil, fmt.Errorf("unsupported provider '%s'. Available providers: %s", name, getAvailableProviders())
+	}
+
+	lister, ok := provider.(common.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, common.ErrModelListingUnsupported)
+	}
+	return lister.ListModels(ctx, cfg)
+}
+
+// Embed asks a provider for one vector per input, for the use inputType
+// names. providers without an embeddings endpoint return
+// common.ErrEmbeddingUnsupported
+func Embed(ctx context.Context, name string, cfg *config.Config, modelName string, inputs []string, inputType common.EmbedInputType) (*common.EmbedResult, error) {
+	provider, exists := AllProviders[name]
+	if !exists {
+		return nil, fmt.Errorf("unsupported provider '%s'. Available providers: %s", name, getAvailableProviders())
+	}
+
+	embedder, ok := provider.(common.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, common.ErrEmbeddingUnsupported)
+	}
+	result, err := embedder.Embed(ctx, cfg, modelName, inputs, inputType)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Vectors) != len(inputs) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d inputs", name, len(result.Vectors), len(inputs))
+	}
+	return result, nil
+}
+
+// ModelCapabilities reports the features a provider's model honors. ok is
+// false for providers that don't describe their models, such as mock
+func ModelCapabilities(name, modelName string) (caps common.Capabilities, ok bool) {
+	describer, ok := AllProviders[name].(common.CapabilityProvider)
+	if !ok {
+		return common.Capabilities{}, false
+	}
+	return describer.Capabilities(modelName), true
+}
+
+// ModelFamilies lists a provider's model families, newest first; nil for
+// providers that don't describe their models
+func ModelFamilies(name string) ([]common.ModelFamily, error) {
+	provider, exists := AllProviders[name]
+	if !exists {
+		return nil, fmt.Errorf("unsupported provider '%s'. Available providers: %s", name, getAvailableProviders())
+	}
+
+	describer, ok := provider.(common.CapabilityProvider)
+	if !ok {
+		return nil, nil
+	}
+	return describer.ModelFamilies(), nil
+}
+
+// UnsupportedFeatures lists the features cfg requests that the model would
+// leave out of the request. images are not checked here: the adapter rejects
+// them outright rather than dropping them
+func UnsupportedFeatures(name, modelName string, cfg *config.Config) []common.Feature {
+	caps, ok := ModelCapabilities(name, modelName)
+	if !ok {
+		return nil
+	}
+
+	var dropped []common.Feature
+	for _, feature := range requestedFeatures(cfg) {
+		if !caps.Supports(feature) {
+			dropped = append(dropped, feature)
+		}
+	}
+	return dropped
+}
+
+// requestedFeatures lists the features cfg asks for, in flag order. a schema
+// supersedes the plain JSON toggle, so JSON mode only counts on its own
+func requestedFeatures(cfg *config.Config) []common.Feature {
+	var features []common.Feature
+	if level, err := common.ParseThinkingLevel(cfg.Parameters.Thinking); err == nil && level != common.ThinkingOff {
+		features = append(features, common.FeatureThinking)
+	}
+	if cfg.Parameters.ResponseSchema != "" {
+		features = append(features, common.FeatureSchema)
+	} else if cfg.Format.JSON {
+		features = append(features, common.FeatureJSON)
+	}
+	if len(common.ToolsFromConfig(cfg)) > 0 {
+		features = append(features, common.FeatureTools)
+	}
+	if cfg.Parameters.Seed != nil {
+		features = append(features, common.FeatureSeed)
+	}
+	return features
+}
+
 // GetAvailableProviders returns a list of registered provider names
 func GetAvailableProviders() []string {
 	providers := make([]string, 0, len(AllProviders))
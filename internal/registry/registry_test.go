@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
 	"github.com/chriscorrea/slop/internal/config"
 	"github.com/chriscorrea/slop/internal/llm/common"
+	"github.com/chriscorrea/slop/internal/llm/mock"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockProvider implements the registry.Provider interface for testing
@@ -33,9 +38,9 @@ func (m *mockProvider) CreateClient(cfg *config.Config, logger *slog.Logger) (co
 	return &mockLLMClient{}, nil
 }
 
-// BuildOptions returns sample options for testing
-func (m *mockProvider) BuildOptions(cfg *config.Config) []interface{} {
-	return []interface{}{"option1", "option2"}
+// BuildOptions returns the configured temperature for testing
+func (m *mockProvider) BuildOptions(cfg *config.Config) *common.GenerateRequest {
+	return common.NewGenerateRequest(common.WithTemperature(cfg.Parameters.Temperature))
 }
 
 // RequiresAPIKey returns the configured API key requirement
@@ -49,7 +54,7 @@ func (m *mockProvider) ProviderName() string {
 }
 
 // BuildRequest creates a mock request
-func (m *mockProvider) BuildRequest(messages []common.Message, modelName string, options interface{}, logger *slog.Logger) (interface{}, error) {
+func (m *mockProvider) BuildRequest(messages []common.Message, modelName string, options *common.GenerateRequest, logger *slog.Logger) (interface{}, error) {
 	return map[string]interface{}{
 		"model":    modelName,
 		"messages": messages,
@@ -84,8 +89,8 @@ func (m *mockProvider) HandleConnectionError(err error) error {
 type mockLLMClient struct{}
 
 // Generate implements the common.LLM interface
-func (m *mockLLMClient) Generate(ctx context.Context, messages []common.Message, modelName string, options ...interface{}) (string, error) {
-	return "mock LLM response", nil
+func (m *mockLLMClient) Generate(ctx context.Context, messages []common.Message, modelName string, req *common.GenerateRequest) (*common.GenerateResult, error) {
+	return &common.GenerateResult{Content: "mock LLM response", Model: modelName}, nil
 }
 
 func TestAllProvidersInitialization(t *testing.T) {
@@ -95,7 +100,7 @@ func TestAllProvidersInitialization(t *testing.T) {
 	})
 
 	t.Run("Contains expected provider keys", func(t *testing.T) {
-		expectedProviders := []string{"anthropic", "cohere", "groq", "mistral", "ollama", "openai"}
+		expectedProviders := []string{"anthropic", "azure", "cohere", "gemini", "groq", "mistral", "ollama", "openai"}
 
 		for _, providerName := range expectedProviders {
 			assert.Contains(t, AllProviders, providerName, "AllProviders should contain %s", providerName)
@@ -238,6 +243,225 @@ func TestCreateProvider_RegistryLookup(t *testing.T) {
 	assert.IsType(t, &mockLLMClient{}, client)
 }
 
+func TestRegisterCustomProviders(t *testing.T) {
+	// save original providers and restore after test
+	originalProviders := AllProviders
+	defer func() { AllProviders = originalProviders }()
+
+	AllProviders = map[string]common.Provider{"openai": &mockProvider{name: "openai"}}
+
+	cfg := &config.Config{}
+	cfg.Providers.Custom = map[string]config.CustomProvider{
+		"vllm":     {BaseProvider: config.BaseProvider{BaseUrl: "http://localhost:8000/v1"}},
+		"lmstudio": {BaseProvider: config.BaseProvider{BaseUrl: "http://localhost:1234/v1"}},
+	}
+
+	t.Run("registers each entry", func(t *testing.T) {
+		assert.NoError(t, RegisterCustomProviders(cfg))
+		assert.True(t, IsProviderRegistered("vllm"))
+		assert.True(t, IsProviderRegistered("lmstudio"))
+		assert.False(t, ProviderRequiresAPIKey("vllm"))
+		assert.Equal(t, "vllm", AllProviders["vllm"].ProviderName())
+
+		client, err := CreateProvider("vllm", cfg, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.NotNil(t, BuildProviderOptions("lmstudio", cfg))
+	})
+
+	t.Run("reloading replaces earlier entries", func(t *testing.T) {
+		reloaded := &config.Config{}
+		reloaded.Providers.Custom = map[string]config.CustomProvider{
+			"llamacpp": {BaseProvider: config.BaseProvider{BaseUrl: "http://localhost:8080/v1"}},
+		}
+		assert.NoError(t, RegisterCustomProviders(reloaded))
+		assert.True(t, IsProviderRegistered("llamacpp"))
+		assert.False(t, IsProviderRegistered("vllm"))
+		assert.True(t, IsProviderRegistered("openai"))
+	})
+
+	t.Run("rejects built-in names", func(t *testing.T) {
+		clash := &config.Config{}
+		clash.Providers.Custom = map[string]config.CustomProvider{
+			"openai": {BaseProvider: config.BaseProvider{BaseUrl: "http://localhost:8000/v1"}},
+		}
+		err := RegisterCustomProviders(clash)
+		assert.ErrorContains(t, err, "built-in provider")
+		assert.IsType(t, &mockProvider{}, AllProviders["openai"])
+	})
+}
+
+func TestRegisterPluginProviders(t *testing.T) {
+	// save original providers and restore after test
+	originalProviders := AllProviders
+	defer func() { AllProviders = originalProviders }()
+
+	// a PATH holding one plugin that clashes with a built-in and one that doesn't
+	dir := t.TempDir()
+	for _, name := range []string{"slop-provider-echo", "slop-provider-openai", "slop-provider-gateway"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	t.Setenv("PATH", dir)
+
+	AllProviders = map[string]common.Provider{"openai": &mockProvider{name: "openai"}}
+
+	cfg := &config.Config{}
+	cfg.Providers.Plugin = map[string]config.PluginProvider{
+		"gateway": {Command: "/opt/gateway/bin/slop-gateway"},
+	}
+
+	t.Run("registers configured and discovered plugins", func(t *testing.T) {
+		require.NoError(t, RegisterPluginProviders(cfg))
+		assert.True(t, IsProviderRegistered("gateway"))
+		assert.True(t, IsProviderRegistered("echo"))
+		assert.False(t, ProviderRequiresAPIKey("echo"))
+		assert.IsType(t, &mockProvider{}, AllProviders["openai"])
+		assert.NotNil(t, BuildProviderOptions("echo", cfg))
+	})
+
+	t.Run("reloading replaces earlier entries", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		require.NoError(t, RegisterPluginProviders(&config.Config{}))
+		assert.False(t, IsProviderRegistered("gateway"))
+		assert.False(t, IsProviderRegistered("echo"))
+		assert.True(t, IsProviderRegistered("openai"))
+	})
+
+	t.Run("rejects configured names already taken", func(t *testing.T) {
+		clash := &config.Config{}
+		clash.Providers.Plugin = map[string]config.PluginProvider{"openai": {Command: "gateway"}}
+		assert.ErrorContains(t, RegisterPluginProviders(clash), "already a provider")
+	})
+}
+
+func TestListModels(t *testing.T) {
+	// save original providers and restore after test
+	originalProviders := AllProviders
+	defer func() { AllProviders = originalProviders }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"id":"qwen2.5-7b-instruct"}]}`))
+	}))
+	defer server.Close()
+
+	AllProviders = map[string]common.Provider{"openai": &mockProvider{name: "openai"}}
+	cfg := &config.Config{}
+	cfg.Providers.Custom = map[string]config.CustomProvider{
+		"vllm": {BaseProvider: config.BaseProvider{BaseUrl: server.URL + "/v1"}},
+	}
+	require.NoError(t, RegisterCustomProviders(cfg))
+
+	models, err := ListModels(context.Background(), "vllm", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qwen2.5-7b-instruct"}, models)
+
+	_, err = ListModels(context.Background(), "openai", cfg)
+	assert.ErrorIs(t, err, common.ErrModelListingUnsupported)
+
+	_, err = ListModels(context.Background(), "nonexistent", cfg)
+	assert.ErrorContains(t, err, "unsupported provider")
+}
+
+func TestEmbed(t *testing.T) {
+	// save original providers and restore after test
+	originalProviders := AllProviders
+	defer func() { AllProviders = originalProviders }()
+
+	AllProviders = map[string]common.Provider{
+		"mock":   mock.New(),
+		"openai": &mockProvider{name: "openai"},
+		"short":  &shortEmbedder{mockProvider{name: "short"}},
+	}
+
+	result, err := Embed(context.Background(), "mock", &config.Config{}, "test-embed", []string{"a", "b", "a"}, common.EmbedClustering)
+	require.NoError(t, err)
+	require.Len(t, result.Vectors, 3)
+	assert.Equal(t, result.Vectors[0], result.Vectors[2], "equal inputs get equal mock vectors")
+	assert.NotEqual(t, result.Vectors[0], result.Vectors[1])
+
+	_, err = Embed(context.Background(), "openai", &config.Config{}, "text-embedding-3-small", []string{"a"}, common.EmbedClustering)
+	assert.ErrorIs(t, err, common.ErrEmbeddingUnsupported)
+
+	_, err = Embed(context.Background(), "short", &config.Config{}, "m", []string{"a", "b"}, common.EmbedClustering)
+	assert.ErrorContains(t, err, "returned 1 embeddings for 2 inputs")
+
+	_, err = Embed(context.Background(), "nonexistent", &config.Config{}, "m", []string{"a"}, common.EmbedClustering)
+	assert.ErrorContains(t, err, "unsupported provider")
+}
+
+func TestModelCapabilities(t *testing.T) {
+	caps, ok := ModelCapabilities("anthropic", "claude-opus-4-7")
+	require.True(t, ok)
+	assert.True(t, caps.Thinking)
+	assert.False(t, caps.Seed)
+
+	_, ok = ModelCapabilities("mock", "m")
+	assert.False(t, ok, "mock doesn't describe its models")
+
+	families, err := ModelFamilies("openai")
+	require.NoError(t, err)
+	assert.NotEmpty(t, families)
+
+	families, err = ModelFamilies("mock")
+	require.NoError(t, err)
+	assert.Nil(t, families)
+
+	_, err = ModelFamilies("nonexistent")
+	assert.ErrorContains(t, err, "unsupported provider")
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	seed := 7
+	requestAll := func() *config.Config {
+		cfg := &config.Config{}
+		cfg.Parameters.Thinking = "high"
+		cfg.Parameters.Seed = &seed
+		cfg.Parameters.Tools = []string{"date"}
+		cfg.Tools = map[string]config.Tool{"date": {Command: "date"}}
+		cfg.Format.JSON = true
+		return cfg
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		cfg      *config.Config
+		want     []common.Feature
+	}{
+		{"nothing requested", "together", "meta-llama/Llama-3.3-70B-Instruct-Turbo", &config.Config{}, nil},
+		{"all honored", "openai", "o3", requestAll(), nil},
+		{"no thinking or seed", "anthropic", "claude-haiku-4-5", requestAll(), []common.Feature{common.FeatureThinking, common.FeatureJSON, common.FeatureSeed}},
+		{"thinking off", "cohere", "command-a-03-2025", func() *config.Config {
+			cfg := requestAll()
+			cfg.Parameters.Thinking = "off"
+			return cfg
+		}(), nil},
+		{"schema supersedes json", "anthropic", "claude-opus-4-7", func() *config.Config {
+			cfg := &config.Config{}
+			cfg.Format.JSON = true
+			cfg.Parameters.ResponseSchema = `{"type":"object"}`
+			return cfg
+		}(), nil},
+		{"provider without capabilities", "mock", "m", requestAll(), nil},
+		{"unknown provider", "nonexistent", "m", requestAll(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnsupportedFeatures(tt.provider, tt.model, tt.cfg))
+		})
+	}
+}
+
+// shortEmbedder answers every embedding request with a single vector
+type shortEmbedder struct {
+	mockProvider
+}
+
+func (s *shortEmbedder) Embed(ctx context.Context, cfg *config.Config, modelName string, inputs []string, _ common.EmbedInputType) (*common.EmbedResult, error) {
+	return &common.EmbedResult{Vectors: [][]float64{{1}}}, nil
+}
+
 func TestBuildProviderOptions(t *testing.T) {
 	// save original providers and restore after test
 	originalProviders := AllProviders
@@ -248,7 +472,6 @@ func TestBuildProviderOptions(t *testing.T) {
 		providerName string
 		setupMock    func()
 		expectNil    bool
-		expectedLen  int
 	}{
 		{
 			name:         "Success - Valid provider",
@@ -258,8 +481,7 @@ func TestBuildProviderOptions(t *testing.T) {
 					name: "test-provider",
 				}
 			},
-			expectNil:   false,
-			expectedLen: 2, // mockProvider returns 2 options
+			expectNil: false,
 		},
 		{
 			name:         "Success - Mock mistral provider",
@@ -271,15 +493,13 @@ func TestBuildProviderOptions(t *testing.T) {
 					requiresAPIKey: true,
 				}
 			},
-			expectNil:   false,
-			expectedLen: 2, // mockProvider returns 2 options
+			expectNil: false,
 		},
 		{
 			name:         "Failure - Unknown provider",
 			providerName: "unknown-provider",
 			setupMock:    func() {}, // no setup needed
 			expectNil:    true,
-			expectedLen:  0,
 		},
 	}
 
@@ -306,10 +526,9 @@ func TestBuildProviderOptions(t *testing.T) {
 			if tt.expectNil {
 				assert.Nil(t, options)
 			} else {
-				assert.NotNil(t, options)
-				if tt.expectedLen > 0 {
-					assert.Len(t, options, tt.expectedLen)
-				}
+				require.NotNil(t, options)
+				require.NotNil(t, options.Temperature)
+				assert.Equal(t, 0.7, *options.Temperature)
 			}
 		})
 	}
@@ -322,7 +541,7 @@ func TestGetAvailableProviders(t *testing.T) {
 	assert.NotEmpty(t, providers)
 
 	// check that expected providers are present
-	expectedProviders := []string{"anthropic", "cohere", "groq", "mistral", "ollama", "openai"}
+	expectedProviders := []string{"anthropic", "azure", "cohere", "gemini", "groq", "mistral", "ollama", "openai"}
 	for _, expected := range expectedProviders {
 		assert.Contains(t, providers, expected)
 	}
@@ -454,10 +673,8 @@ func TestMockProvider_Interface(t *testing.T) {
 
 	t.Run("BuildOptions", func(t *testing.T) {
 		options := mock.BuildOptions(cfg)
-		assert.NotNil(t, options)
-		assert.Len(t, options, 2)
-		assert.Equal(t, "option1", options[0])
-		assert.Equal(t, "option2", options[1])
+		require.NotNil(t, options)
+		assert.NotNil(t, options.Temperature)
 	})
 
 	t.Run("RequiresAPIKey", func(t *testing.T) {
@@ -0,0 +1,50 @@
+package retrieval
+
+import "strings"
+
+// DefaultChunkChars is the chunk size used by slop context index, roughly
+// a few hundred tokens: small enough to be specific, large enough to keep a
+// section together
+const DefaultChunkChars = 1500
+
+// Split breaks content into chunks of whole lines, each at most maxChars
+// unless a single line is longer. a chunk past half full ends at a blank
+// line, so paragraphs and sections tend to stay together
+func Split(path, content string, maxChars int) []Entry {
+	var entries []Entry
+	var current []string
+	size, start, last := 0, 1, 0 // last is the chunk's last non-blank line
+
+	flush := func() {
+		text := strings.TrimRight(strings.Join(current, "\n"), " \t\n")
+		if text != "" {
+			entries = append(entries, Entry{Path: path, StartLine: start, EndLine: last, Text: text})
+		}
+		current, size = nil, 0
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lineNo := i + 1
+		blank := strings.TrimSpace(line) == ""
+		if size > 0 && size+len(line)+1 > maxChars {
+			flush()
+		}
+		if len(current) == 0 {
+			if blank {
+				// don't open a chunk with blank lines
+				continue
+			}
+			start = lineNo
+		}
+		current = append(current, line)
+		size += len(line) + 1
+		if !blank {
+			last = lineNo
+		} else if size > maxChars/2 {
+			flush()
+		}
+	}
+	flush()
+	return entries
+}
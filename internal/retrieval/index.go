@@ -0,0 +1,226 @@
+// Package retrieval keeps an on-disk vector index of the project context
+// files, so a prompt can be sent only the passages relevant to it instead of
+// every file in full.
+package retrieval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	slopContext "github.com/chriscorrea/slop/internal/context"
+)
+
+// ErrNoIndex is returned by Load when the project has not been indexed
+var ErrNoIndex = errors.New("no context index; run 'slop context index' first")
+
+// EmbedFunc returns one vector per text, in order
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+// Entry is one indexed chunk of a file and its embedding
+type Entry struct {
+	Path      string    `json:"path"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	Vector    []float64 `json:"vector"`
+}
+
+// Index is a JSON file of chunk embeddings, made with a single model
+type Index struct {
+	path      string
+	Provider  string            `json:"provider"`
+	Model     string            `json:"model"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Files     map[string]string `json:"files"` // path -> content hash
+	Entries   []Entry           `json:"entries"`
+}
+
+// UpdateStats summarizes what Update changed
+type UpdateStats struct {
+	Files    int // files in the index
+	Chunks   int // chunks in the index
+	Embedded int // chunks embedded by this update
+	Removed  int // files dropped from the index
+}
+
+// DefaultPath returns the index location for a project, .slop/index.json
+func DefaultPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".slop", "index.json")
+}
+
+// New creates an empty index that embeds with the given model
+func New(path, provider, model string) *Index {
+	return &Index{path: path, Provider: provider, Model: model, Files: make(map[string]string)}
+}
+
+// Load reads the index at path, returning ErrNoIndex when there is none
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoIndex
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context index: %w", err)
+	}
+
+	ix := &Index{path: path}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("failed to parse context index %s: %w", path, err)
+	}
+	if ix.Files == nil {
+		ix.Files = make(map[string]string)
+	}
+	return ix, nil
+}
+
+// Path returns the index file path
+func (ix *Index) Path() string {
+	return ix.path
+}
+
+// Update brings the index in line with files: chunks of unchanged files are
+// kept, changed and new files are chunked and embedded, and files no longer
+// listed are dropped
+func (ix *Index) Update(ctx context.Context, files []slopContext.ContextFile, chunkChars int, embed EmbedFunc) (UpdateStats, error) {
+	kept := make(map[string]bool)
+	var entries, pending []Entry
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		hash := contentHash(file.Content)
+		hashes[file.Path] = hash
+		if ix.Files[file.Path] == hash {
+			kept[file.Path] = true
+			continue
+		}
+		pending = append(pending, Split(file.Path, file.Content, chunkChars)...)
+	}
+	for _, entry := range ix.Entries {
+		if kept[entry.Path] {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(pending) > 0 {
+		texts := make([]string, len(pending))
+		for i, entry := range pending {
+			// the file name helps match questions about a file by name
+			texts[i] = filepath.Base(entry.Path) + "\n\n" + entry.Text
+		}
+		vectors, err := embed(ctx, texts)
+		if err != nil {
+			return UpdateStats{}, err
+		}
+		if len(vectors) != len(pending) {
+			return UpdateStats{}, fmt.Errorf("got %d embeddings for %d chunks", len(vectors), len(pending))
+		}
+		for i := range pending {
+			pending[i].Vector = vectors[i]
+		}
+		entries = append(entries, pending...)
+	}
+
+	removed := 0
+	for path := range ix.Files {
+		if _, ok := hashes[path]; !ok {
+			removed++
+		}
+	}
+
+	ix.Files = hashes
+	ix.Entries = entries
+	ix.UpdatedAt = time.Now().UTC()
+	return UpdateStats{Files: len(hashes), Chunks: len(entries), Embedded: len(pending), Removed: removed}, nil
+}
+
+// Stale returns the paths among files whose content differs from what was
+// indexed, including files the index has never seen
+func (ix *Index) Stale(files []slopContext.ContextFile) []string {
+	var stale []string
+	for _, file := range files {
+		if ix.Files[file.Path] != contentHash(file.Content) {
+			stale = append(stale, file.Path)
+		}
+	}
+	return stale
+}
+
+// Search returns the k chunks most similar to the query vector, best first
+func (ix *Index) Search(query []float64, k int) []slopContext.Chunk {
+	chunks := make([]slopContext.Chunk, 0, len(ix.Entries))
+	for _, entry := range ix.Entries {
+		chunks = append(chunks, slopContext.Chunk{
+			Path:      entry.Path,
+			StartLine: entry.StartLine,
+			EndLine:   entry.EndLine,
+			Content:   entry.Text,
+			Score:     cosine(query, entry.Vector),
+		})
+	}
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].Score > chunks[j].Score })
+	if k < len(chunks) {
+		chunks = chunks[:k]
+	}
+	return chunks
+}
+
+// Save writes the index, replacing the file in one rename so a concurrent
+// reader never sees a partial write
+func (ix *Index) Save() error {
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("failed to encode context index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0700); err != nil {
+		return fmt.Errorf("failed to create .slop directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ix.path), ".index-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write context index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write context index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write context index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ix.path); err != nil {
+		return fmt.Errorf("failed to write context index: %w", err)
+	}
+	return nil
+}
+
+// contentHash identifies a file's content, so unchanged files keep their chunks
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// cosine returns the cosine similarity of two vectors; 0 when they differ in
+// length, as vectors from another model would
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,134 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slopContext "github.com/chriscorrea/slop/internal/context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	t.Run("small file is one chunk", func(t *testing.T) {
+		entries := Split("a.md", "\n# Title\n\nSome text.\n\n", 100)
+		require.Len(t, entries, 1)
+		assert.Equal(t, Entry{Path: "a.md", StartLine: 2, EndLine: 4, Text: "# Title\n\nSome text."}, entries[0])
+	})
+
+	t.Run("breaks at blank lines once half full", func(t *testing.T) {
+		para := strings.Repeat("x", 30)
+		content := strings.Join([]string{para, para, "", para, para, "", para}, "\n")
+		entries := Split("a.md", content, 100)
+		require.Len(t, entries, 3)
+		assert.Equal(t, [2]int{1, 2}, [2]int{entries[0].StartLine, entries[0].EndLine})
+		assert.Equal(t, [2]int{4, 5}, [2]int{entries[1].StartLine, entries[1].EndLine})
+		assert.Equal(t, [2]int{7, 7}, [2]int{entries[2].StartLine, entries[2].EndLine})
+	})
+
+	t.Run("never exceeds the limit between lines", func(t *testing.T) {
+		content := strings.Repeat(strings.Repeat("y", 40)+"\n", 10)
+		for _, entry := range Split("a.md", content, 100) {
+			assert.LessOrEqual(t, len(entry.Text), 100)
+		}
+	})
+
+	t.Run("blank content has no chunks", func(t *testing.T) {
+		assert.Empty(t, Split("a.md", "\n  \n", 100))
+	})
+}
+
+// countingEmbedder returns a vector per text from its length, counting texts
+type countingEmbedder struct {
+	calls int
+	texts int
+}
+
+func (c *countingEmbedder) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	c.calls++
+	c.texts += len(texts)
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float64{float64(len(text)), 1}
+	}
+	return vectors, nil
+}
+
+func TestIndex_Update(t *testing.T) {
+	index := New(filepath.Join(t.TempDir(), "index.json"), "mock", "test-embed")
+	embedder := &countingEmbedder{}
+	files := []slopContext.ContextFile{
+		{Path: "/p/a.md", Content: "alpha"},
+		{Path: "/p/b.md", Content: "beta"},
+	}
+
+	stats, err := index.Update(context.Background(), files, 100, embedder.embed)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateStats{Files: 2, Chunks: 2, Embedded: 2}, stats)
+	assert.Empty(t, index.Stale(files))
+
+	// only the changed file is embedded again, and the dropped one removed
+	files = []slopContext.ContextFile{{Path: "/p/a.md", Content: "alpha, revised"}}
+	assert.Equal(t, []string{"/p/a.md"}, index.Stale(files))
+	stats, err = index.Update(context.Background(), files, 100, embedder.embed)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateStats{Files: 1, Chunks: 1, Embedded: 1, Removed: 1}, stats)
+	assert.Equal(t, 3, embedder.texts)
+	assert.Equal(t, "alpha, revised", index.Entries[0].Text)
+
+	// nothing to embed makes no request
+	_, err = index.Update(context.Background(), files, 100, embedder.embed)
+	require.NoError(t, err)
+	assert.Equal(t, 2, embedder.calls)
+
+	failing := func(ctx context.Context, texts []string) ([][]float64, error) {
+		return nil, errors.New("offline")
+	}
+	_, err = index.Update(context.Background(), []slopContext.ContextFile{{Path: "/p/c.md", Content: "gamma"}}, 100, failing)
+	assert.EqualError(t, err, "offline")
+	assert.Len(t, index.Entries, 1, "a failed update leaves the index alone")
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := New("", "mock", "test-embed")
+	index.Entries = []Entry{
+		{Path: "a.md", StartLine: 1, EndLine: 3, Text: "east", Vector: []float64{1, 0}},
+		{Path: "b.md", StartLine: 4, EndLine: 9, Text: "north", Vector: []float64{0, 1}},
+		{Path: "c.md", StartLine: 1, EndLine: 1, Text: "northeast", Vector: []float64{1, 1}},
+		{Path: "d.md", StartLine: 1, EndLine: 1, Text: "other model", Vector: []float64{1, 1, 1}},
+	}
+
+	chunks := index.Search([]float64{0, 2}, 2)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "b.md", chunks[0].Path)
+	assert.Equal(t, [2]int{4, 9}, [2]int{chunks[0].StartLine, chunks[0].EndLine})
+	assert.Equal(t, "north", chunks[0].Content)
+	assert.InDelta(t, 1.0, chunks[0].Score, 1e-9)
+	assert.Equal(t, "c.md", chunks[1].Path)
+	assert.InDelta(t, 0.7071, chunks[1].Score, 1e-4)
+
+	assert.Len(t, index.Search([]float64{1, 0}, 10), 4)
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".slop", "index.json")
+	_, err := Load(path)
+	assert.ErrorIs(t, err, ErrNoIndex)
+
+	index := New(path, "ollama", "nomic-embed-text")
+	_, err = index.Update(context.Background(), []slopContext.ContextFile{{Path: "/p/a.md", Content: "alpha"}}, 100, (&countingEmbedder{}).embed)
+	require.NoError(t, err)
+	require.NoError(t, index.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, loaded.Path())
+	assert.Equal(t, "ollama", loaded.Provider)
+	assert.Equal(t, "nomic-embed-text", loaded.Model)
+	assert.Equal(t, index.Entries, loaded.Entries)
+	assert.Equal(t, index.Files, loaded.Files)
+}
@@ -1,6 +1,10 @@
 package template
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -34,6 +38,60 @@ func ProcessTemplate(template, userInput string) string {
 	return template + "\n" + userInput
 }
 
+// fieldPlaceholder matches {name} placeholders for the fields of a record
+var fieldPlaceholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ProcessRecord renders a template for one JSON record, as slop batch does.
+// {field} placeholders take the record's top-level values. the record's
+// input - its "input" field, or else the whole record - fills {input} or,
+// when the template names no field, is placed as ProcessTemplate places user input
+func ProcessRecord(template string, record []byte) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(record, &value); err != nil {
+		return "", fmt.Errorf("invalid JSON record: %w", err)
+	}
+
+	fields, _ := value.(map[string]interface{})
+	input := recordText(value, bytes.TrimSpace(record))
+	if field, ok := fields["input"]; ok {
+		input = recordText(field, nil)
+	}
+
+	named := false
+	rendered := fieldPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if name == "input" {
+			named = true
+			return input
+		}
+		if field, ok := fields[name]; ok {
+			named = true
+			return recordText(field, nil)
+		}
+		return placeholder
+	})
+	if named {
+		return rendered, nil
+	}
+	return ProcessTemplate(template, input), nil
+}
+
+// recordText is a record value as prompt text: strings as they are, null as
+// nothing, anything else as JSON (raw when given)
+func recordText(value interface{}, raw []byte) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	if raw != nil {
+		return string(raw)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
 // HasPlaceholder checks if a template contains the input placeholder
 func HasPlaceholder(template string) bool {
 	return strings.Contains(template, InputPlaceholder)
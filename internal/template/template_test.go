@@ -83,6 +83,74 @@ func TestProcessTemplate(t *testing.T) {
 	}
 }
 
+func TestProcessRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		record   string
+		expected string
+	}{
+		{
+			name:     "input field fills the input placeholder",
+			template: "Classify: {input}",
+			record:   `{"id": 7, "input": "refund please"}`,
+			expected: "Classify: refund please",
+		},
+		{
+			name:     "fields fill their placeholders",
+			template: "Title: {title}\nBody: {body}\nTags: {tags}",
+			record:   `{"title": "Crash", "body": "on save", "tags": ["bug"]}`,
+			expected: "Title: Crash\nBody: on save\nTags: [\"bug\"]",
+		},
+		{
+			name:     "unknown placeholders are left alone",
+			template: "{title} {missing}",
+			record:   `{"title": "Crash"}`,
+			expected: "Crash {missing}",
+		},
+		{
+			name:     "record without an input field is the input",
+			template: "Classify:",
+			record:   ` {"title": "Crash"} `,
+			expected: "Classify:\n{\"title\": \"Crash\"}",
+		},
+		{
+			name:     "string record is the input",
+			template: "Translate: {input}",
+			record:   `"bonjour"`,
+			expected: "Translate: bonjour",
+		},
+		{
+			name:     "empty template sends the input",
+			template: "",
+			record:   `{"input": "hello", "id": 1}`,
+			expected: "hello",
+		},
+		{
+			name:     "JSON in the template is not a placeholder",
+			template: `Reply as {"label": "..."} for {input}`,
+			record:   `"text"`,
+			expected: `Reply as {"label": "..."} for text`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ProcessRecord(tt.template, []byte(tt.record))
+			if err != nil {
+				t.Fatalf("ProcessRecord(%q, %q) error = %v", tt.template, tt.record, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ProcessRecord(%q, %q) = %q; want %q", tt.template, tt.record, result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ProcessRecord("{input}", []byte("not json")); err == nil {
+		t.Error("Expected an error for a record that isn't JSON")
+	}
+}
+
 func TestHasPlaceholder(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+const (
+	// defaultTimeout bounds a single tool run when the tool sets no timeout
+	defaultTimeout = 30 * time.Second
+
+	// maxOutputBytes caps the tool output fed back to the model
+	maxOutputBytes = 16 * 1024
+)
+
+// ConfirmFunc asks the user whether a tool call may run
+type ConfirmFunc func(name, arguments string) (bool, error)
+
+// Runner executes tool calls requested by the model. only tools declared under
+// [tools] and enabled via parameters.tools (the allowlist) can run
+type Runner struct {
+	tools   map[string]config.Tool
+	confirm ConfirmFunc
+	logger  *slog.Logger
+}
+
+// NewRunner creates a Runner for the tools enabled in the configuration
+func NewRunner(cfg *config.Config, logger *slog.Logger) *Runner {
+	enabled := make(map[string]config.Tool)
+	if cfg != nil {
+		for _, name := range cfg.Parameters.Tools {
+			if tool, ok := cfg.Tools[name]; ok {
+				enabled[name] = tool
+			}
+		}
+	}
+	return &Runner{
+		tools:   enabled,
+		confirm: PromptConfirm,
+		logger:  logger,
+	}
+}
+
+// WithConfirm replaces the interactive confirmation prompt
+func (r *Runner) WithConfirm(fn ConfirmFunc) *Runner {
+	r.confirm = fn
+	return r
+}
+
+// Enabled reports whether any tools are available to the model
+func (r *Runner) Enabled() bool {
+	return len(r.tools) > 0
+}
+
+// Run executes a tool call and returns the text to send back to the model.
+// failures (unknown tool, declined confirmation, non-zero exit) are reported
+// to the model as results rather than aborting the conversation
+func (r *Runner) Run(ctx context.Context, call common.ToolCall) string {
+	name := call.Function.Name
+	tool, ok := r.tools[name]
+	if !ok {
+		r.logWarn("Model requested a tool that is not enabled", "tool", name)
+		return fmt.Sprintf("error: tool %q is not available", name)
+	}
+
+	args, err := parseArguments(call.Function.Arguments)
+	if err != nil {
+		return fmt.Sprintf("error: invalid arguments for %s: %v", name, err)
+	}
+
+	if tool.RequiresConfirmation() {
+		approved, err := r.confirm(name, call.Function.Arguments)
+		if err != nil {
+			r.logWarn("Tool confirmation failed", "tool", name, "error", err)
+			return fmt.Sprintf("error: %s was not run because confirmation failed: %v", name, err)
+		}
+		if !approved {
+			return fmt.Sprintf("error: the user declined to run %s", name)
+		}
+	}
+
+	timeout := defaultTimeout
+	if tool.Timeout > 0 {
+		timeout = time.Duration(tool.Timeout) * time.Second
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if r.logger != nil {
+		r.logger.Info("Running tool", "tool", name, "timeout", timeout)
+	}
+
+	// arguments reach the command through stdin and the environment only,
+	// so model output is never parsed by the shell
+	cmd := exec.CommandContext(runCtx, "sh", "-c", tool.Command)
+	cmd.Env = append(os.Environ(), argumentEnv(name, call.Function.Arguments, args)...)
+	cmd.Stdin = strings.NewReader(call.Function.Arguments)
+
+	// don't wait on children that still hold the output pipes after a timeout
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\n[stderr]\n" + stderr.String()
+	}
+	output = truncate(strings.TrimRight(output, "\n"))
+
+	if runErr != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Sprintf("error: %s timed out after %s\n%s", name, timeout, output)
+		}
+		return fmt.Sprintf("error: %s failed: %v\n%s", name, runErr, output)
+	}
+	return output
+}
+
+// parseArguments decodes the model's JSON arguments; empty means no arguments
+func parseArguments(raw string) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	if strings.TrimSpace(raw) == "" {
+		return args, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// argumentEnv exposes the call to the command as SLOP_TOOL_NAME, SLOP_TOOL_ARGS
+// and one SLOP_ARG_<NAME> variable per top-level argument. strings are passed
+// as-is; other values are JSON-encoded
+func argumentEnv(name, raw string, args map[string]interface{}) []string {
+	env := []string{
+		"SLOP_TOOL_NAME=" + name,
+		"SLOP_TOOL_ARGS=" + raw,
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var value string
+		switch v := args[key].(type) {
+		case string:
+			value = v
+		default:
+			encoded, _ := json.Marshal(v)
+			value = string(encoded)
+		}
+		env = append(env, "SLOP_ARG_"+envName(key)+"="+value)
+	}
+	return env
+}
+
+// envName upper-cases an argument name and replaces characters that are not
+// valid in environment variable names
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// truncate caps output so a chatty command can't flood the context window
+func truncate(output string) string {
+	if len(output) <= maxOutputBytes {
+		return output
+	}
+	return output[:maxOutputBytes] + fmt.Sprintf("\n[output truncated: %d bytes omitted]", len(output)-maxOutputBytes)
+}
+
+// logWarn logs a warning when a logger is configured
+func (r *Runner) logWarn(msg string, args ...interface{}) {
+	if r.logger != nil {
+		r.logger.Warn(msg, args...)
+	}
+}
+
+// PromptConfirm asks on the controlling terminal before running a tool. stdin
+// is often a pipe, so the prompt reads /dev/tty; without a terminal the call is declined
+func PromptConfirm(name, arguments string) (bool, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, fmt.Errorf("no terminal available to confirm (set confirm = false on the tool to allow it unattended)")
+	}
+	defer tty.Close()
+
+	approved := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Run tool %s with %s?", name, arguments),
+	}
+	if err := survey.AskOne(prompt, &approved, survey.WithStdio(tty, tty, os.Stderr)); err != nil {
+		return false, err
+	}
+	return approved, nil
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRunner enables the given tools without confirmation prompts
+func newTestRunner(tools map[string]config.Tool, approve bool) *Runner {
+	cfg := &config.Config{Tools: tools}
+	for name := range tools {
+		cfg.Parameters.Tools = append(cfg.Parameters.Tools, name)
+	}
+	return NewRunner(cfg, nil).WithConfirm(func(name, arguments string) (bool, error) {
+		return approve, nil
+	})
+}
+
+func toolCall(name, arguments string) common.ToolCall {
+	return common.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: common.FunctionCall{Name: name, Arguments: arguments},
+	}
+}
+
+func TestRunner_Enabled(t *testing.T) {
+	cfg := &config.Config{
+		Tools: map[string]config.Tool{"echo": {Command: "echo hi"}},
+	}
+
+	// declared but not on the allowlist
+	assert.False(t, NewRunner(cfg, nil).Enabled())
+
+	cfg.Parameters.Tools = []string{"echo"}
+	assert.True(t, NewRunner(cfg, nil).Enabled())
+}
+
+func TestRunner_Run(t *testing.T) {
+	runner := newTestRunner(map[string]config.Tool{
+		"greet":  {Command: `echo "hello $SLOP_ARG_NAME ($SLOP_ARG_COUNT)"`},
+		"stdin":  {Command: "cat"},
+		"fail":   {Command: "echo oops >&2; exit 3"},
+		"sleepy": {Command: "sleep 5", Timeout: 1},
+	}, true)
+
+	tests := []struct {
+		name     string
+		call     common.ToolCall
+		expected string
+	}{
+		{
+			name:     "arguments are exposed as environment variables",
+			call:     toolCall("greet", `{"name":"Boxer","count":2}`),
+			expected: "hello Boxer (2)",
+		},
+		{
+			name:     "arguments are passed on stdin",
+			call:     toolCall("stdin", `{"a":1}`),
+			expected: `{"a":1}`,
+		},
+		{
+			name:     "shell metacharacters in arguments are not evaluated",
+			call:     toolCall("greet", `{"name":"$(echo pwned)","count":1}`),
+			expected: "hello $(echo pwned) (1)",
+		},
+		{
+			name:     "non-zero exit is reported with stderr",
+			call:     toolCall("fail", ""),
+			expected: "error: fail failed: exit status 3\n\n[stderr]\noops",
+		},
+		{
+			name:     "tools outside the allowlist are refused",
+			call:     toolCall("rm", `{}`),
+			expected: `error: tool "rm" is not available`,
+		},
+		{
+			name:     "invalid arguments are reported",
+			call:     toolCall("greet", `not json`),
+			expected: "error: invalid arguments for greet",
+		},
+		{
+			name:     "slow tools time out",
+			call:     toolCall("sleepy", ""),
+			expected: "error: sleepy timed out after 1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runner.Run(context.Background(), tt.call)
+			if strings.HasPrefix(tt.expected, "error:") {
+				assert.True(t, strings.HasPrefix(result, tt.expected), "got %q", result)
+			} else {
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunner_Run_Declined(t *testing.T) {
+	runner := newTestRunner(map[string]config.Tool{
+		"touch": {Command: "echo ran"},
+	}, false)
+
+	result := runner.Run(context.Background(), toolCall("touch", `{}`))
+
+	assert.Equal(t, "error: the user declined to run touch", result)
+}
+
+func TestRunner_Run_NoConfirmation(t *testing.T) {
+	no := false
+	runner := newTestRunner(map[string]config.Tool{
+		"date": {Command: "echo today", Confirm: &no},
+	}, false)
+
+	// a declining prompt is never consulted when confirm = false
+	assert.Equal(t, "today", runner.Run(context.Background(), toolCall("date", "")))
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("x", maxOutputBytes+10)
+
+	result := truncate(long)
+
+	assert.True(t, strings.HasPrefix(result, strings.Repeat("x", maxOutputBytes)))
+	assert.Contains(t, result, "[output truncated: 10 bytes omitted]")
+	assert.Equal(t, "short", truncate("short"))
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "FILE_PATH", envName("file-path"))
+	assert.Equal(t, "MAX2", envName("max2"))
+}
@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one successful generation recorded in the ledger
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Command          string    `json:"command,omitempty"` // named command; empty for a direct prompt
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	LatencyMS        int64     `json:"latency_ms"`
+
+	// Cost is the USD cost at the list prices in effect when the entry was
+	// recorded; nil when the model has no known price
+	Cost *float64 `json:"cost_usd,omitempty"`
+}
+
+// Ledger is an append-only JSONL file of generation entries
+type Ledger struct {
+	path string
+}
+
+// DefaultPath returns the ledger location, ~/.slop/usage.jsonl
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".slop", "usage.jsonl"), nil
+}
+
+// NewLedger creates a ledger backed by the file at path
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Path returns the ledger file path
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Append writes an entry as a single line. each write is one small append,
+// so concurrent slop processes don't interleave partial lines
+func (l *Ledger) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage ledger directory: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	return nil
+}
+
+// Entries reads every entry in the ledger. a missing ledger is empty, and
+// malformed lines (e.g. from an interrupted write) are skipped
+func (l *Ledger) Entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger_AppendAndEntries(t *testing.T) {
+	ledger := NewLedger(filepath.Join(t.TempDir(), ".slop", "usage.jsonl"))
+
+	// a missing ledger reads as empty
+	entries, err := ledger.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	cost := 0.0123
+	first := Entry{
+		Time:             time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Provider:         "anthropic",
+		Model:            "claude-haiku-4-5-20251001",
+		Command:          "review",
+		PromptTokens:     1200,
+		CompletionTokens: 300,
+		LatencyMS:        2400,
+		Cost:             &cost,
+	}
+	second := Entry{
+		Time:             time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+		Provider:         "ollama",
+		Model:            "gemma3:latest",
+		PromptTokens:     80,
+		CompletionTokens: 40,
+		LatencyMS:        900,
+	}
+	require.NoError(t, ledger.Append(first))
+	require.NoError(t, ledger.Append(second))
+
+	entries, err = ledger.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, first.Model, entries[0].Model)
+	assert.Equal(t, "review", entries[0].Command)
+	require.NotNil(t, entries[0].Cost)
+	assert.InDelta(t, 0.0123, *entries[0].Cost, 1e-9)
+	assert.True(t, first.Time.Equal(entries[0].Time))
+	assert.Nil(t, entries[1].Cost)
+	assert.Empty(t, entries[1].Command)
+
+	info, err := os.Stat(ledger.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLedger_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	content := `{"time":"2026-03-01T09:30:00Z","provider":"openai","model":"gpt-4o","prompt_tokens":10,"completion_tokens":5,"latency_ms":100}
+{"time":"2026-03-01T09:31:00Z","provider":"open
+{"time":"2026-03-01T09:32:00Z","provider":"groq","model":"llama-3.1-8b-instant","prompt_tokens":7,"completion_tokens":3,"latency_ms":50}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	entries, err := NewLedger(path).Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "openai", entries[0].Provider)
+	assert.Equal(t, "groq", entries[1].Provider)
+}
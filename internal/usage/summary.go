@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GroupBy values accepted by Summarize
+var GroupBy = []string{"day", "provider", "model", "command"}
+
+// directCommand labels entries recorded without a named command
+const directCommand = "(direct)"
+
+// Summary aggregates the ledger entries sharing one group key
+type Summary struct {
+	Key              string
+	Runs             int
+	PromptTokens     int
+	CompletionTokens int
+	TotalLatency     time.Duration
+
+	// Cost sums the priced entries; Unpriced counts entries without a price
+	Cost     float64
+	Unpriced int
+}
+
+// AverageLatency returns the mean latency per run
+func (s Summary) AverageLatency() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Runs)
+}
+
+// add folds an entry into the summary
+func (s *Summary) add(entry Entry) {
+	s.Runs++
+	s.PromptTokens += entry.PromptTokens
+	s.CompletionTokens += entry.CompletionTokens
+	s.TotalLatency += time.Duration(entry.LatencyMS) * time.Millisecond
+	if entry.Cost != nil {
+		s.Cost += *entry.Cost
+	} else {
+		s.Unpriced++
+	}
+}
+
+// Summarize groups entries by day (in the local time zone), provider, model
+// or command, sorted by key. the second return value totals every entry
+func Summarize(entries []Entry, groupBy string) ([]Summary, Summary, error) {
+	keyFn, err := groupKey(groupBy)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+
+	total := Summary{Key: "total"}
+	groups := make(map[string]*Summary)
+	for _, entry := range entries {
+		key := keyFn(entry)
+		group, ok := groups[key]
+		if !ok {
+			group = &Summary{Key: key}
+			groups[key] = group
+		}
+		group.add(entry)
+		total.add(entry)
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, *group)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Key < summaries[j].Key
+	})
+	return summaries, total, nil
+}
+
+// Since returns the entries recorded at or after the given time
+func Since(entries []Entry, since time.Time) []Entry {
+	var recent []Entry
+	for _, entry := range entries {
+		if !entry.Time.Before(since) {
+			recent = append(recent, entry)
+		}
+	}
+	return recent
+}
+
+// groupKey returns the function that extracts an entry's group key
+func groupKey(groupBy string) (func(Entry) string, error) {
+	switch groupBy {
+	case "day":
+		return func(e Entry) string { return e.Time.Local().Format("2006-01-02") }, nil
+	case "provider":
+		return func(e Entry) string { return e.Provider }, nil
+	case "model":
+		return func(e Entry) string { return e.Provider + "/" + e.Model }, nil
+	case "command":
+		return func(e Entry) string {
+			if e.Command == "" {
+				return directCommand
+			}
+			return e.Command
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid grouping %q (valid: %v)", groupBy, GroupBy)
+}
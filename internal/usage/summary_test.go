@@ -0,0 +1,82 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntries() []Entry {
+	cost := func(c float64) *float64 { return &c }
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	return []Entry{
+		{Time: day1, Provider: "anthropic", Model: "claude-haiku-4-5", Command: "review", PromptTokens: 100, CompletionTokens: 50, LatencyMS: 1000, Cost: cost(0.01)},
+		{Time: day1, Provider: "ollama", Model: "gemma3:latest", PromptTokens: 40, CompletionTokens: 20, LatencyMS: 3000, Cost: cost(0)},
+		{Time: day2, Provider: "anthropic", Model: "claude-haiku-4-5", PromptTokens: 200, CompletionTokens: 100, LatencyMS: 2000, Cost: cost(0.02)},
+		{Time: day2, Provider: "openai", Model: "gpt-5.4", Command: "review", PromptTokens: 10, CompletionTokens: 10, LatencyMS: 500},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("by day", func(t *testing.T) {
+		summaries, total, err := Summarize(testEntries(), "day")
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+
+		assert.Equal(t, "2026-03-01", summaries[0].Key)
+		assert.Equal(t, 2, summaries[0].Runs)
+		assert.Equal(t, 140, summaries[0].PromptTokens)
+		assert.Equal(t, 2*time.Second, summaries[0].AverageLatency())
+		assert.InDelta(t, 0.01, summaries[0].Cost, 1e-9)
+
+		assert.Equal(t, "2026-03-02", summaries[1].Key)
+		assert.Equal(t, 1, summaries[1].Unpriced)
+
+		assert.Equal(t, 4, total.Runs)
+		assert.Equal(t, 350, total.PromptTokens)
+		assert.Equal(t, 180, total.CompletionTokens)
+		assert.InDelta(t, 0.03, total.Cost, 1e-9)
+		assert.Equal(t, 1, total.Unpriced)
+	})
+
+	t.Run("by model", func(t *testing.T) {
+		summaries, _, err := Summarize(testEntries(), "model")
+		require.NoError(t, err)
+		require.Len(t, summaries, 3)
+		assert.Equal(t, "anthropic/claude-haiku-4-5", summaries[0].Key)
+		assert.Equal(t, 2, summaries[0].Runs)
+	})
+
+	t.Run("by command", func(t *testing.T) {
+		summaries, _, err := Summarize(testEntries(), "command")
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+		assert.Equal(t, "(direct)", summaries[0].Key)
+		assert.Equal(t, "review", summaries[1].Key)
+		assert.Equal(t, 2, summaries[1].Runs)
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		_, _, err := Summarize(testEntries(), "week")
+		assert.ErrorContains(t, err, "invalid grouping")
+	})
+
+	t.Run("empty ledger", func(t *testing.T) {
+		summaries, total, err := Summarize(nil, "provider")
+		require.NoError(t, err)
+		assert.Empty(t, summaries)
+		assert.Zero(t, total.Runs)
+		assert.Zero(t, total.AverageLatency())
+	})
+}
+
+func TestSince(t *testing.T) {
+	entries := testEntries()
+	recent := Since(entries, time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local))
+	require.Len(t, recent, 2)
+	assert.Equal(t, "anthropic", recent[0].Provider)
+	assert.Equal(t, "openai", recent[1].Provider)
+}
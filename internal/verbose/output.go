@@ -7,6 +7,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
 
 	"github.com/fatih/color"
 )
@@ -117,3 +118,38 @@ func printRow(w io.Writer, outputCfg *OutputConfig, key1, value1, key2, value2 s
 		)
 	}
 }
+
+// PrintGenerationSummary displays what a completed generation reported:
+// the resolved model, finish reason and token usage
+func PrintGenerationSummary(result *common.GenerateResult, outputCfg *OutputConfig) {
+	if result == nil {
+		return
+	}
+	if outputCfg == nil {
+		outputCfg = DefaultOutputConfig(os.Stderr)
+	}
+
+	w := tabwriter.NewWriter(outputCfg.Writer, 0, 0, 3, ' ', 0)
+
+	finishReason := result.FinishReason
+	if finishReason == "" {
+		finishReason = "unknown"
+	}
+
+	fmt.Fprintf(w, "\n")
+	printRow(w, outputCfg, "Resolved Model", result.Model, "Finish Reason", finishReason)
+	if result.Usage != nil {
+		printRow(w, outputCfg,
+			"Prompt Tokens", fmt.Sprintf("%d", result.Usage.PromptTokens),
+			"Completion Tokens", fmt.Sprintf("%d", result.Usage.CompletionTokens))
+		printRow(w, outputCfg, "Total Tokens", fmt.Sprintf("%d", result.Usage.TotalTokens), "", "")
+	}
+	if result.Thinking != "" {
+		printRow(w, outputCfg, "Thinking", fmt.Sprintf("%d chars", len(result.Thinking)), "", "")
+	}
+	if result.Cached {
+		printRow(w, outputCfg, "Cached", "yes", "", "")
+	}
+
+	w.Flush()
+}
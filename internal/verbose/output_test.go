@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/chriscorrea/slop/internal/config"
+	"github.com/chriscorrea/slop/internal/llm/common"
 
 	"github.com/fatih/color"
 )
@@ -153,3 +154,57 @@ func TestPrintLLMParameters(t *testing.T) {
 		}
 	})
 }
+
+func TestPrintGenerationSummary(t *testing.T) {
+	t.Run("FullResult", func(t *testing.T) {
+		var buf bytes.Buffer
+		outputCfg := DefaultOutputConfig(&buf)
+		outputCfg.EnableColors = false
+
+		PrintGenerationSummary(&common.GenerateResult{
+			Model:        "oink3-2025-07-05",
+			FinishReason: "length",
+			Thinking:     "snort",
+			Usage:        &common.Usage{PromptTokens: 12, CompletionTokens: 30, TotalTokens: 42},
+		}, outputCfg)
+
+		output := buf.String()
+		expectedStrings := []string{
+			"Resolved Model:", "oink3-2025-07-05",
+			"Finish Reason:", "length",
+			"Prompt Tokens:", "12",
+			"Completion Tokens:", "30",
+			"Total Tokens:", "42",
+			"Thinking:", "5 chars",
+		}
+		for _, expected := range expectedStrings {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected output to contain %q, got: %s", expected, output)
+			}
+		}
+	})
+
+	t.Run("WithoutUsage", func(t *testing.T) {
+		var buf bytes.Buffer
+		outputCfg := DefaultOutputConfig(&buf)
+		outputCfg.EnableColors = false
+
+		PrintGenerationSummary(&common.GenerateResult{Model: "smollm2:latest"}, outputCfg)
+
+		output := buf.String()
+		if !strings.Contains(output, "unknown") {
+			t.Errorf("Expected unknown finish reason, got: %s", output)
+		}
+		if strings.Contains(output, "Tokens") {
+			t.Errorf("Expected no token rows without usage, got: %s", output)
+		}
+	})
+
+	t.Run("NilResult", func(t *testing.T) {
+		var buf bytes.Buffer
+		PrintGenerationSummary(nil, DefaultOutputConfig(&buf))
+		if buf.Len() != 0 {
+			t.Errorf("Expected no output for nil result, got: %s", buf.String())
+		}
+	})
+}